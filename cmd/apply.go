@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/Qovery/pleco/pkg"
+	"github.com/Qovery/pleco/pkg/common"
+)
+
+var applyCmd = &cobra.Command{
+	Use:   "apply <plan file>",
+	Short: "Destroy the resources of a plan file, if none of them changed since the plan",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		_ = setLogLevel()
+		log.Infof("Starting Pleco %s", GetCurrentVersion())
+
+		planKey := getPlanKey(cmd)
+		if unsigned, _ := cmd.Flags().GetBool("unsigned"); planKey == "" && !unsigned {
+			log.Fatal("A plan key is required to apply a plan, set --plan-key or $PLECO_PLAN_KEY, or --unsigned to apply a checksummed plan")
+		}
+
+		maxAge, _ := cmd.Flags().GetDuration("max-age")
+		plan, err := common.LoadPlan(args[0], planKey, maxAge)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+
+		if err := common.SetSelectionFlags(cmd, plan.Flags); err != nil {
+			log.Fatal(err.Error())
+		}
+
+		confirm, _ := cmd.Flags().GetBool("confirm")
+		if err := pkg.StartApply(plan, cmd, func(plan *common.Plan) bool {
+			return !confirm || confirmPlan(plan)
+		}); err != nil {
+			log.Fatal(err.Error())
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(applyCmd)
+
+	// the selection flags are read from the plan
+	addSelectionFlags(applyCmd)
+	applyCmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		flag.Hidden = true
+	})
+
+	applyCmd.Flags().String("plan-key", "", "Key the plan file has been signed with (default is $PLECO_PLAN_KEY)")
+	applyCmd.Flags().Bool("unsigned", false, "Apply a plan file written without plan key, only checksummed")
+	applyCmd.Flags().Duration("max-age", time.Hour, "Refuse plans older than this duration, 0 to accept any")
+	applyCmd.Flags().Bool("confirm", false, "Show the resources to delete and ask for confirmation")
+	addAWSFlags(applyCmd)
+	addDestroyFlags(applyCmd)
+	addNotifyFlags(applyCmd)
+}
+
+func confirmPlan(plan *common.Plan) bool {
+	fmt.Println("")
+	plan.PrintTable(os.Stdout)
+	fmt.Printf("\n%d resources will be deleted. Only 'yes' will be accepted to confirm: ", len(plan.Resources))
+
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+
+	return strings.TrimSpace(answer) == "yes"
+}
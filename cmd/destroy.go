@@ -2,8 +2,8 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 	"strings"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -13,7 +13,7 @@ import (
 )
 
 var destroy = &cobra.Command{
-	Use:   "destroy",
+	Use:   "destroy <provider[,provider...]>",
 	Short: "Destroy resources having a specific tag value",
 	Run: func(cmd *cobra.Command, args []string) {
 		_ = setLogLevel()
@@ -24,7 +24,10 @@ var destroy = &cobra.Command{
 
 		if commandIsValid(cmd, args) {
 			disableDryRun, _ := cmd.Flags().GetBool("disable-dry-run")
-			pkg.StartDestroy(args[0], disableDryRun, cmd)
+			if policy := common.GetPolicy(); policy != nil {
+				disableDryRun = disableDryRun || policy.DisableDryRun
+			}
+			pkg.StartDestroy(common.ParseProviders(strings.Join(args, ",")), disableDryRun, cmd)
 		}
 	},
 }
@@ -32,27 +35,57 @@ var destroy = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(destroy)
 
-	destroy.Flags().StringP("tag-name", "", "", "The tag name attached to a resource to clean")
-	destroy.Flags().StringP("tag-value", "", "", "The corresponding tag value attached to a resource to clean")
 	destroy.Flags().BoolP("disable-dry-run", "y", false, "Disable dry run mode")
-	destroy.Flags().StringP("kube-conn", "k", "off", "Kubernetes connection method, choose between : off/in/out")
+	addSelectionFlags(destroy)
+	addAWSFlags(destroy)
+	addDestroyFlags(destroy)
+	addNotifyFlags(destroy)
+}
 
-	if len(os.Args) > 2 {
-		common.InitFlags(os.Args[2], destroy)
-	}
+// addSelectionFlags registers the flags selecting the resources to delete.
+func addSelectionFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("tag-name", "", "", "The tag name attached to a resource to clean")
+	cmd.Flags().StringP("tag-value", "", "", "The corresponding tag value attached to a resource to clean")
+	addProviderFlags(cmd)
+}
+
+// addAWSFlags registers the flags giving access to the AWS accounts to clean and setting how resources are read.
+func addAWSFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("aws-profile", "", "", "AWS shared config profile, eg. an SSO profile (default is $AWS_PROFILE, or the default credential chain)")
+	cmd.Flags().StringP("aws-role-name", "", "OrganizationAccountAccessRole", "Role assumed in the AWS accounts given by ID or listed with Organizations")
+	cmd.Flags().BoolP("aws-tagging-api", "", false, "Read the AWS tags with a Resource Groups Tagging API sweep per region and check, and report tagged resources of unsupported types")
+}
+
+// addDestroyFlags registers the flags of the commands deleting resources once.
+func addDestroyFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("kube-conn", "k", "off", "Kubernetes connection method, choose between : off/in/out")
+	cmd.Flags().Duration("timeout", 30*time.Minute, "Stop waiting for the resources still not deleted after this duration")
+
+	cmd.Flags().StringP("report-format", "", "", "Write a report of the resources to delete at each check, choose between : json/yaml")
+	cmd.Flags().StringP("report-file", "", "", "Report file path (default is stdout)")
+
+	cmd.Flags().StringP("state-backend", "", "", "Persist resources states (first seen, deletion attempts...), choose between : file/configmap, disabled if empty")
+	cmd.Flags().StringP("state-file", "", "pleco-state.json", "State file path, with the file state backend")
+	cmd.Flags().StringP("state-configmap", "", "pleco-state", "State config map name, with the configmap state backend")
+	cmd.Flags().StringP("state-namespace", "", "", "State config map namespace (default is $POD_NAMESPACE, or default)")
+	cmd.Flags().Duration("stuck-after", 24*time.Hour, "Report resources still not deleted this long after their expiration as stuck")
 }
 
 func commandIsValid(cmd *cobra.Command, args []string) bool {
 	valid := true
 
-	if len(args) < 1 {
-		log.Errorf("The cloud provider is mandatory (aws, scaleway, do)")
+	cloudProviders := common.ParseProviders(strings.Join(args, ","))
+	if len(cloudProviders) < 1 {
+		log.Errorf("At least one cloud provider is mandatory (aws, azure, scaleway, do, gcp, kubernetes)")
+		return false
 	}
 
-	tagName, err := cmd.Flags().GetString("tag-name")
-	if err != nil || strings.TrimSpace(tagName) == "" {
-		log.Errorf("The 'tag-name' option is mandatory")
-		valid = false
+	for _, cloudProvider := range cloudProviders {
+		if strings.TrimSpace(common.GetTagName(cloudProvider, cmd)) == "" {
+			log.Errorf("The 'tag-name' option is mandatory")
+			valid = false
+			break
+		}
 	}
 
 	tagValue, err := cmd.Flags().GetString("tag-value")
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/Qovery/pleco/pkg"
+	"github.com/Qovery/pleco/pkg/common"
+)
+
+var extendCmd = &cobra.Command{
+	Use:   "extend <provider> <resource id>",
+	Short: "Keep a resource longer by updating its ttl or expiration date tag",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		_ = setLogLevel()
+		log.Infof("Starting Pleco %s", GetCurrentVersion())
+
+		by, _ := cmd.Flags().GetDuration("by")
+		if by <= 0 {
+			log.Fatal("The 'by' option is mandatory and must be positive, eg. --by 4h")
+		}
+		if _, ok := common.GetProvider(args[0]); !ok {
+			log.Fatalf("Unknown cloud provider: %s", args[0])
+		}
+
+		kind, _ := cmd.Flags().GetString("kind")
+		extended, err := pkg.StartExtend(args[0], &common.Extension{Identifier: args[1], Kind: kind, By: by}, cmd)
+		for _, message := range extended {
+			fmt.Println(message)
+		}
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(extendCmd)
+
+	extendCmd.Flags().Duration("by", 0, "Duration to keep the resource for, from its expiration date or from now if already expired")
+	extendCmd.Flags().String("kind", "", "Kind of the resource, when several kinds share the identifier")
+	addSelectionFlags(extendCmd)
+	addAWSFlags(extendCmd)
+	_ = extendCmd.Flags().MarkHidden("tag-value")
+}
@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// addNotifyFlags registers the notification flags of the commands deleting resources.
+func addNotifyFlags(cmd *cobra.Command) {
+	cmd.Flags().String("notify-slack-webhook", "", "Slack incoming webhook URL to notify (default is $PLECO_SLACK_WEBHOOK_URL)")
+	cmd.Flags().String("notify-webhook", "", "URL notifications are posted to as JSON (default is $PLECO_WEBHOOK_URL)")
+	cmd.Flags().String("notify-smtp-server", "", "SMTP server (host:port) to email notifications with, authenticated with $PLECO_SMTP_USERNAME and $PLECO_SMTP_PASSWORD if set")
+	cmd.Flags().String("notify-smtp-from", "pleco@localhost", "Notification emails sender")
+	cmd.Flags().StringSlice("notify-smtp-to", nil, "Notification emails recipients")
+	cmd.Flags().Duration("notify-warning-window", 24*time.Hour, "Notify resources expiring within this duration, 0 to disable")
+	cmd.Flags().Int("notify-failures-after", 3, "Notify resources failing to be deleted this many times in a row")
+	cmd.Flags().Bool("notify-digest", false, "Send a single notification per cycle")
+	cmd.Flags().String("owner-tag", "owner", "Tag holding the owner of a resource, notified when it expires soon (emailed with SMTP when it's an address)")
+	cmd.Flags().Bool("tag-warned", false, "Tag the resources expiring soon with pleco/warned-at, so they are notified once")
+}
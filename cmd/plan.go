@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/Qovery/pleco/pkg"
+	"github.com/Qovery/pleco/pkg/common"
+)
+
+var planCmd = &cobra.Command{
+	Use:   "plan <provider[,provider...]>",
+	Short: "Write the resources having a specific tag value to a plan file, to destroy them with apply",
+	Run: func(cmd *cobra.Command, args []string) {
+		_ = setLogLevel()
+		log.Infof("Starting Pleco %s", GetCurrentVersion())
+
+		if !commandIsValid(cmd, args) {
+			os.Exit(1)
+		}
+
+		plan := pkg.StartPlan(common.ParseProviders(strings.Join(args, ",")), cmd)
+		if cmd.Context().Err() != nil {
+			log.Fatal("Plan interrupted, nothing written")
+		}
+
+		planFile, _ := cmd.Flags().GetString("plan-file")
+		planKey := getPlanKey(cmd)
+		if planKey == "" {
+			log.Warnf("No plan key given, %s will only be checksummed, not signed", planFile)
+		}
+		if err := plan.Save(planFile, planKey); err != nil {
+			log.Fatalf("Can't save plan: %s", err.Error())
+		}
+
+		fmt.Println("")
+		plan.PrintTable(os.Stdout)
+		fmt.Println("")
+		log.Infof("%d resources to delete written to %s, run \"pleco apply %s\" to delete them", len(plan.Resources), planFile, planFile)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(planCmd)
+
+	planCmd.Flags().StringP("plan-file", "", "pleco.plan", "Plan file path")
+	planCmd.Flags().StringP("plan-key", "", "", "Key signing the plan file (default is $PLECO_PLAN_KEY)")
+	addSelectionFlags(planCmd)
+	addAWSFlags(planCmd)
+	addDestroyFlags(planCmd)
+}
+
+func getPlanKey(cmd *cobra.Command) string {
+	if key, _ := cmd.Flags().GetString("plan-key"); key != "" {
+		return key
+	}
+
+	return os.Getenv("PLECO_PLAN_KEY")
+}
@@ -1,16 +1,25 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
 
 	"github.com/mitchellh/go-homedir"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
+	"github.com/spf13/pflag"
+
+	"github.com/Qovery/pleco/pkg/common"
 )
 
 var cfgFile string
+var logLevel string
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
@@ -21,32 +30,44 @@ Pleco will look at Cloud and Kubernetes resources tags. If a ttl is set, then it
 of a resource.
 
 Pleco has been designed to run as a pod in a Kubernetes cluster and manages ttl of a single cluster. Regarding
-Cloud providers, it can support only one provider at a time`,
+Cloud providers, several of them can be checked at once, eg. "pleco start aws,gcp,scaleway"`,
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	initProviderFlags(os.Args[1:])
+	if err := rootCmd.ExecuteContext(signalContext()); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
 }
 
+// signalContext is cancelled on SIGTERM or SIGINT: deletions in progress are completed, and nothing else is started.
+// A second signal stops Pleco right away.
+func signalContext() context.Context {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	go func() {
+		<-ctx.Done()
+		stop()
+		logrus.Warn("Stopping once the deletions in progress are completed, interrupt again to stop right away")
+	}()
+
+	return ctx
+}
+
 func init() {
 	cobra.OnInitialize(initConfig)
 
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.pleco.yaml)")
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "level", "info", "set log level")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "policy file (default is $HOME/.pleco.yaml)")
+	rootCmd.PersistentFlags().StringVar(&logLevel, "level", "info", "set log level")
+	rootCmd.PersistentFlags().Duration("api-timeout", 5*time.Minute, "Timeout of every list, and of every deletion of a resource")
 	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 }
 
-// initConfig reads in config file and ENV variables if set.
+// initConfig reads the policy file, if any. Command line flags take precedence over it.
 func initConfig() {
-	if cfgFile != "" {
-		// Use config file from the flag.
-		viper.SetConfigFile(cfgFile)
-	} else {
+	if cfgFile == "" {
 		// Find home directory.
 		home, err := homedir.Dir()
 		if err != nil {
@@ -54,23 +75,72 @@ func initConfig() {
 			os.Exit(1)
 		}
 
-		// Search config in home directory with name ".pleco-ttl" (without extension).
-		viper.AddConfigPath(home)
-		viper.SetConfigName(".pleco")
+		defaultFile := filepath.Join(home, ".pleco.yaml")
+		if _, err := os.Stat(defaultFile); err != nil {
+			return
+		}
+		cfgFile = defaultFile
+	}
+
+	policy, err := common.LoadPolicy(cfgFile)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	fmt.Println("Using config file:", cfgFile)
+	common.SetPolicy(policy)
+}
+
+// providerCommands are the commands taking providers as first argument, their provider flags are added by Execute.
+var providerCommands []*cobra.Command
+
+// addProviderFlags registers a command whose provider flags, whose shorthands depend on the providers given as
+// argument, are added once the command line is known.
+func addProviderFlags(cmd *cobra.Command) {
+	providerCommands = append(providerCommands, cmd)
+}
+
+// initProviderFlags adds the provider flags of every provider command, with the shorthands of the providers given to
+// the invoked one.
+func initProviderFlags(arguments []string) {
+	invoked, args, err := rootCmd.Find(arguments)
+	for _, cmd := range providerCommands {
+		var providers []string
+		if err == nil && cmd == invoked {
+			providers = argsProviders(cmd, args)
+		}
+		common.InitFlags(providers, cmd)
 	}
+}
 
-	viper.AutomaticEnv() // read in environment variables that match
+// argsProviders returns the providers given as first argument of the command, before its provider flags exist.
+// The arguments are parsed against a copy of the flags known so far, the unknown ones being ignored.
+func argsProviders(cmd *cobra.Command, args []string) []string {
+	flags := pflag.NewFlagSet(cmd.Name(), pflag.ContinueOnError)
+	flags.ParseErrorsWhitelist.UnknownFlags = true
+	flags.SetOutput(io.Discard)
+	flags.Usage = func() {}
+	// cobra adds the help flag when the command is executed
+	flags.BoolP("help", "h", false, "")
+	copyFlag := func(flag *pflag.Flag) {
+		if flags.Lookup(flag.Name) == nil {
+			flags.StringP(flag.Name, flag.Shorthand, "", "")
+			flags.Lookup(flag.Name).NoOptDefVal = flag.NoOptDefVal
+		}
+	}
+	cmd.Flags().VisitAll(copyFlag)
+	cmd.InheritedFlags().VisitAll(copyFlag)
 
-	// If a config file is found, read it in.
-	if err := viper.ReadInConfig(); err == nil {
-		fmt.Println("Using config file:", viper.ConfigFileUsed())
+	if err := flags.Parse(args); err != nil || flags.NArg() == 0 {
+		return nil
 	}
+
+	return common.ParseProviders(flags.Arg(0))
 }
 
 func setLogLevel() error {
 	// set log level
-	logLevel, _ := rootCmd.Flags().GetString("level")
-
 	lvl, err := logrus.ParseLevel(logLevel)
 	if err != nil {
 		return err
@@ -3,6 +3,8 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -13,25 +15,22 @@ import (
 
 // startCmd represents the start command
 var startCmd = &cobra.Command{
-	Use:   "start",
+	Use:   "start <provider[,provider...]>",
 	Short: "Start Pleco as a daemon",
 	Run: func(cmd *cobra.Command, args []string) {
 		_ = setLogLevel()
 
-		disableDryRun, _ := cmd.Flags().GetBool("disable-dry-run")
-		interval, _ := cmd.Flags().GetInt64("check-interval")
-		disableTTLCheck, _ := cmd.Flags().GetBool("disable-ttl-check")
-
 		fmt.Println("")
 		fmt.Println(" ____  _     _____ ____ ___  \n|  _ \\| |   | ____/ ___/ _ \\ \n| |_) | |   |  _|| |  | | | |\n|  __/| |___| |__| |__| |_| |\n|_|   |_____|_____\\____\\___/\nBy Qovery")
 		log.Infof("Starting Pleco %s", GetCurrentVersion())
 
-		if len(args) < 1 {
-			log.Errorf("The provider is mandatory (aws | scaleway | do | gcp)")
+		cloudProviders := common.ParseProviders(strings.Join(args, ","))
+		if len(cloudProviders) < 1 {
+			log.Errorf("At least one provider is mandatory (aws | azure | scaleway | do | gcp | kubernetes), eg. aws,gcp")
 			os.Exit(1)
 		}
 
-		pkg.StartDaemon(args[0], disableDryRun, interval, cmd, disableTTLCheck)
+		pkg.StartDaemon(cloudProviders, cmd)
 	},
 }
 
@@ -42,9 +41,26 @@ func init() {
 	startCmd.Flags().Int64P("check-interval", "i", 120, "Check interval in seconds")
 	startCmd.Flags().StringP("tag-name", "t", "ttl", "Set the tag name to check for deletion")
 	startCmd.Flags().StringP("kube-conn", "k", "off", "Kubernetes connection method, choose between : off/in/out")
+	startCmd.Flags().Duration("kube-stuck-namespaces-after", 0, "Report namespaces terminating for longer than this duration, with the objects blocking them, disabled if 0")
+	startCmd.Flags().Bool("kube-remove-finalizers", false, "Remove the finalizers of the objects left in stuck namespaces, then of the namespaces")
 	startCmd.Flags().BoolP("disable-ttl-check", "j", false, "Disable ttl check and delete resources created more than 4 hours ago")
+	startCmd.Flags().StringP("policy-resource", "", "", "Read the policy from this CleanupPolicy object, reloaded on change, and write the checks status to it")
+	startCmd.Flags().StringP("policy-namespace", "", "", "CleanupPolicy object namespace (default is $POD_NAMESPACE, or default)")
+	startCmd.Flags().BoolP("leader-elect", "", false, "Run the checks only while holding a Lease, to run several replicas")
+	startCmd.Flags().StringP("leader-elect-lease", "", "pleco", "Leader election Lease name")
+	startCmd.Flags().StringP("leader-elect-namespace", "", "", "Leader election Lease namespace (default is $POD_NAMESPACE, or default)")
+	startCmd.Flags().StringP("metrics-address", "", "", "Serve Prometheus metrics, /healthz and /readyz on this address (eg. :8080), disabled if empty")
+
+	startCmd.Flags().StringP("report-format", "", "", "Write a report of the resources to delete at each check, choose between : json/yaml")
+	startCmd.Flags().StringP("report-file", "", "", "Report file path (default is stdout)")
+
+	startCmd.Flags().StringP("state-backend", "", "", "Persist resources states (first seen, deletion attempts...), choose between : file/configmap, disabled if empty")
+	startCmd.Flags().StringP("state-file", "", "pleco-state.json", "State file path, with the file state backend")
+	startCmd.Flags().StringP("state-configmap", "", "pleco-state", "State config map name, with the configmap state backend")
+	startCmd.Flags().StringP("state-namespace", "", "", "State config map namespace (default is $POD_NAMESPACE, or default)")
+	startCmd.Flags().Duration("stuck-after", 24*time.Hour, "Report resources still not deleted this long after their expiration as stuck")
 
-	if len(os.Args) > 2 {
-		common.InitFlags(os.Args[2], startCmd)
-	}
+	addNotifyFlags(startCmd)
+	addProviderFlags(startCmd)
+	addAWSFlags(startCmd)
 }
@@ -1,9 +1,11 @@
 package pkg
 
 import (
+	"context"
+	"fmt"
+	"os"
 	"strings"
 	"sync"
-	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -17,8 +19,76 @@ import (
 	"github.com/Qovery/pleco/pkg/scaleway"
 )
 
-func StartDaemon(cloudProvider string, disableDryRun bool, interval int64, cmd *cobra.Command, disableTTLCheck bool) {
+// StartDaemon checks the providers every interval. With leader election, only the replica holding the Lease runs the
+// checks.
+func StartDaemon(cloudProviders []string, cmd *cobra.Command) {
+	log.Infof("Cloud providers: %s", strings.ToUpper(strings.Join(cloudProviders, ", ")))
+
+	initPolicyResource(cmd)
+	initReporter(cmd)
+	initStateStore(cmd)
+	initNotifiers(cmd)
+	initTimeouts(cmd)
+
+	if metricsAddress := getCmdString(cmd, "metrics-address"); metricsAddress != "" {
+		common.StartMetricsServer(metricsAddress)
+	}
+
+	ctx := cmd.Context()
+	if !getCmdBool(cmd, "leader-elect") {
+		runChecks(ctx, cloudProviders, cmd)
+		return
+	}
+
+	config, err := k8s.Config(getCmdString(cmd, "kube-conn"))
+	if err != nil {
+		log.Fatalf("Can't start leader election: %s", err.Error())
+	}
+
+	common.SetStandby(true)
+	err = k8s.RunLeaderElection(ctx, config, getNamespace(cmd, "leader-elect-namespace"), getCmdString(cmd, "leader-elect-lease"), func(ctx context.Context) {
+		common.SetStandby(false)
+		runChecks(ctx, cloudProviders, cmd)
+	})
+	if err != nil {
+		log.Fatalf("Can't start leader election: %s", err.Error())
+	}
+}
+
+// runChecks runs the checks until ctx is cancelled, started again when the policy changes their settings.
+func runChecks(ctx context.Context, cloudProviders []string, cmd *cobra.Command) {
+	for {
+		policyChanged := common.PolicyChanged()
+		runDaemon(ctx, cloudProviders, cmd)
+		if ctx.Err() != nil {
+			log.Info("Checks stopped")
+			return
+		}
+
+		select {
+		case <-policyChanged:
+			log.Info("Starting checks again with the new policy")
+		default:
+			return
+		}
+	}
+}
+
+// runDaemon runs every check until the policy changes their settings.
+func runDaemon(ctx context.Context, cloudProviders []string, cmd *cobra.Command) {
 	var wg sync.WaitGroup
+
+	disableDryRun := getCmdBool(cmd, "disable-dry-run")
+	interval, _ := cmd.Flags().GetInt64("check-interval")
+	disableTTLCheck := getCmdBool(cmd, "disable-ttl-check")
+	if policy := common.GetPolicy(); policy != nil {
+		disableDryRun = disableDryRun || policy.DisableDryRun
+		disableTTLCheck = disableTTLCheck || policy.DisableTTLCheck
+		if !cmd.Flags().Changed("check-interval") && policy.CheckInterval > 0 {
+			interval = policy.CheckInterval
+		}
+	}
+
 	dryRun := true
 	if disableDryRun {
 		dryRun = false
@@ -33,184 +103,435 @@ func StartDaemon(cloudProvider string, disableDryRun bool, interval int64, cmd *
 		log.Info("TTL check enabled")
 	}
 
-	log.Infof("Cloud provider: %s", strings.ToUpper(cloudProvider))
+	for _, cloudProvider := range cloudProviders {
+		common.CheckEnvVars(cloudProvider, cmd)
+	}
+
+	k8s.RunPlecoKubernetes(ctx, cmd, interval, dryRun, disableTTLCheck, &wg)
+
+	for _, cloudProvider := range cloudProviders {
+		wg.Add(1)
+		run(ctx, cloudProvider, dryRun, interval, disableTTLCheck, cmd, &wg)
+	}
+
+	wg.Wait()
+}
+
+func StartDestroy(cloudProviders []string, disableDryRun bool, cmd *cobra.Command) {
+	initDestroy(cloudProviders, cmd)
+	runDestroy(cloudProviders, !disableDryRun, cmd)
+}
+
+// StartPlan lists the resources the destroy command would delete, without deleting them, and returns them as a plan.
+func StartPlan(cloudProviders []string, cmd *cobra.Command) *common.Plan {
+	initDestroy(cloudProviders, cmd)
+
+	plan := common.NewPlan(cloudProviders, common.GetSelectionFlags(cmd))
+	common.SetPlanRecorder(plan)
+	runDestroy(cloudProviders, true, cmd)
+	common.SetPlanRecorder(nil)
+
+	return plan
+}
+
+// StartApply deletes the planned resources. The resources are listed again first, and nothing is deleted if one of
+// them changed or isn't eligible to deletion anymore. confirm is called with the plan before any deletion.
+func StartApply(plan *common.Plan, cmd *cobra.Command, confirm func(plan *common.Plan) bool) error {
+	if len(plan.Resources) == 0 {
+		log.Info("The plan is empty, nothing to delete")
+		return nil
+	}
+
+	initDestroy(plan.Providers, cmd)
+
+	current := common.NewPlan(plan.Providers, nil)
+	common.SetPlanRecorder(current)
+	runDestroy(plan.Providers, true, cmd)
+	common.SetPlanRecorder(nil)
+	if cmd.Context().Err() != nil {
+		return fmt.Errorf("apply interrupted, nothing deleted")
+	}
+
+	if differences := plan.Diff(current); len(differences) > 0 {
+		for _, difference := range differences {
+			log.Error(difference)
+		}
+		return fmt.Errorf("%d planned resources changed since the plan, run plan again", len(differences))
+	}
 
+	if !confirm(plan) {
+		return fmt.Errorf("apply cancelled")
+	}
+
+	common.SetAppliedPlan(plan)
+	defer common.SetAppliedPlan(nil)
+	runDestroy(plan.Providers, false, cmd)
+
+	return nil
+}
+
+// StartExtend keeps the resources of the identifier longer, by updating their ttl or expiration date tags.
+func StartExtend(cloudProvider string, extension *common.Extension, cmd *cobra.Command) ([]string, error) {
 	common.CheckEnvVars(cloudProvider, cmd)
+	common.EnableAllFeatures(cloudProvider, cmd)
 
-	k8s.RunPlecoKubernetes(cmd, interval, dryRun, disableTTLCheck, &wg)
+	common.SetExtension(extension)
+	defer common.SetExtension(nil)
 
-	run(cloudProvider, dryRun, interval, disableTTLCheck, cmd, &wg)
+	initTimeouts(cmd)
 
+	var wg sync.WaitGroup
+	wg.Add(1)
+	run(cmd.Context(), cloudProvider, true, 0, false, cmd, &wg)
 	wg.Wait()
+
+	return extension.Result()
 }
 
-func StartDestroy(cloudProvider string, disableDryRun bool, cmd *cobra.Command) {
+func initDestroy(cloudProviders []string, cmd *cobra.Command) {
+	log.Infof("Cloud providers: %s", strings.ToUpper(strings.Join(cloudProviders, ", ")))
+
+	for _, cloudProvider := range cloudProviders {
+		common.CheckEnvVars(cloudProvider, cmd)
+	}
+	initReporter(cmd)
+	initStateStore(cmd)
+	initNotifiers(cmd)
+
+	initTimeouts(cmd)
+
+	if timeout, err := cmd.Flags().GetDuration("timeout"); err == nil {
+		common.SetDestroyTimeout(timeout)
+	}
+}
+
+func initTimeouts(cmd *cobra.Command) {
+	if timeout, err := cmd.Flags().GetDuration("api-timeout"); err == nil {
+		common.SetCallTimeout(timeout)
+	}
+}
+
+// runDestroy runs every engine until none of its resources remains, or the timeout is reached.
+func runDestroy(cloudProviders []string, dryRun bool, cmd *cobra.Command) {
 	var wg sync.WaitGroup
-	dryRun := true
-	if disableDryRun {
-		dryRun = false
-		log.Warn("Dry run mode disabled")
-	} else {
+	if dryRun {
 		log.Info("Dry run mode enabled")
+	} else {
+		log.Warn("Dry run mode disabled")
 	}
-	log.Infof("Cloud provider: %s", strings.ToUpper(cloudProvider))
 
-	common.CheckEnvVars(cloudProvider, cmd)
-
-	for i := 1; i <= 10; i++ {
+	for _, cloudProvider := range cloudProviders {
 		wg.Add(1)
-		run(cloudProvider, dryRun, 0, false, cmd, &wg)
-		time.Sleep(time.Minute)
+		run(cmd.Context(), cloudProvider, dryRun, 0, false, cmd, &wg)
 	}
 
 	wg.Wait()
 }
 
-func run(cloudProvider string, dryRun bool, interval int64, disableTTLCheck bool, cmd *cobra.Command, wg *sync.WaitGroup) {
+func run(ctx context.Context, cloudProvider string, dryRun bool, interval int64, disableTTLCheck bool, cmd *cobra.Command, wg *sync.WaitGroup) {
+	if concurrency, err := cmd.Flags().GetInt(cloudProvider + "-concurrency"); err == nil {
+		common.SetConcurrency(cloudProvider, concurrency)
+	}
+
 	switch cloudProvider {
 	case "aws":
-		startAWS(cmd, interval, dryRun, disableTTLCheck, wg)
+		startAWS(ctx, cmd, interval, dryRun, disableTTLCheck, wg)
 	case "azure":
-		startAzure(cmd, interval, dryRun, disableTTLCheck, wg)
+		startAzure(ctx, cmd, interval, dryRun, disableTTLCheck, wg)
 	case "scaleway":
-		startScaleway(cmd, interval, dryRun, disableTTLCheck, wg)
+		startScaleway(ctx, cmd, interval, dryRun, disableTTLCheck, wg)
 	case "do":
-		startDO(cmd, interval, dryRun, disableTTLCheck, wg)
+		startDO(ctx, cmd, interval, dryRun, disableTTLCheck, wg)
 	case "gcp":
-		startGCP(cmd, interval, dryRun, disableTTLCheck, wg)
+		startGCP(ctx, cmd, interval, dryRun, disableTTLCheck, wg)
+	case "kubernetes":
+		startKubernetes(ctx, cmd, interval, dryRun, wg)
 	default:
 		log.Fatalf("Unknown cloud provider: %s", cloudProvider)
 	}
 }
 
-func startAWS(cmd *cobra.Command, interval int64, dryRun bool, disableTTLCheck bool, wg *sync.WaitGroup) {
-	regions, _ := cmd.Flags().GetStringSlice("aws-regions")
+func startAWS(ctx context.Context, cmd *cobra.Command, interval int64, dryRun bool, disableTTLCheck bool, wg *sync.WaitGroup) {
 	tagValue := getCmdString(cmd, "tag-value")
 
+	aws.SetProfile(getCmdString(cmd, "aws-profile"))
+	identity, err := aws.CheckCredentials(ctx)
+	if err != nil {
+		log.Fatalf("Invalid AWS credentials: %s", err.Error())
+	}
+	log.Infof("AWS credentials of %s", *identity.Arn)
+
+	regions, err := common.ResolveLocations("aws", cmd, func() ([]string, error) {
+		return aws.DiscoverRegions(ctx)
+	})
+	if err != nil {
+		log.Fatalf("Can't get AWS regions: %s", err.Error())
+	}
+	log.Infof("AWS regions: %s", strings.Join(regions, ", "))
+
+	accounts, err := aws.ResolveAccounts(ctx, identity, common.GetAccounts("aws", cmd), getCmdString(cmd, "aws-role-name"))
+	if err != nil {
+		log.Fatalf("Can't get AWS accounts: %s", err.Error())
+	}
+	accountNames := make([]string, 0, len(accounts))
+	for _, account := range accounts {
+		accountNames = append(accountNames, account.String())
+	}
+	log.Infof("AWS accounts: %s", strings.Join(accountNames, ", "))
+
 	awsOptions := aws.AwsOptions{
-		DryRun:                 dryRun,
-		TagName:                getCmdString(cmd, "tag-name"),
-		TagValue:               tagValue,
-		DisableTTLCheck:        disableTTLCheck,
-		IsDestroyingCommand:    strings.TrimSpace(tagValue) != "",
-		EnableRDS:              getCmdBool(cmd, "enable-rds"),
-		EnableDocumentDB:       getCmdBool(cmd, "enable-documentdb"),
-		EnableElastiCache:      getCmdBool(cmd, "enable-elasticache"),
-		EnableEKS:              getCmdBool(cmd, "enable-eks"),
-		EnableELB:              getCmdBool(cmd, "enable-elb"),
-		EnableEBS:              getCmdBool(cmd, "enable-ebs"),
-		EnableVPC:              getCmdBool(cmd, "enable-vpc"),
-		EnableS3:               getCmdBool(cmd, "enable-s3"),
-		EnableCloudWatchLogs:   getCmdBool(cmd, "enable-cloudwatch-logs"),
-		EnableKMS:              getCmdBool(cmd, "enable-kms"),
-		EnableIAM:              getCmdBool(cmd, "enable-iam"),
-		EnableSSH:              getCmdBool(cmd, "enable-ssh"),
-		EnableECR:              getCmdBool(cmd, "enable-ecr"),
-		EnableSQS:              getCmdBool(cmd, "enable-sqs"),
-		EnableLambda:           getCmdBool(cmd, "enable-lambda"),
-		EnableSFN:              getCmdBool(cmd, "enable-sfn"),
-		EnableCloudFormation:   getCmdBool(cmd, "enable-cloudformation"),
-		EnableEC2Instance:      getCmdBool(cmd, "enable-ec2-instance"),
-		EnableCloudWatchEvents: getCmdBool(cmd, "enable-cloudwatch-events"),
-	}
-	aws.RunPlecoAWS(cmd, regions, interval, wg, awsOptions)
+		DryRun:              dryRun,
+		TagName:             common.GetTagName("aws", cmd),
+		TagValue:            tagValue,
+		DisableTTLCheck:     disableTTLCheck,
+		IsDestroyingCommand: strings.TrimSpace(tagValue) != "",
+		Features:            common.GetEnabledFeatures("aws", cmd),
+		TaggingAPI:          getCmdBool(cmd, "aws-tagging-api"),
+	}
+	aws.RunPlecoAWS(ctx, accounts, regions, interval, wg, awsOptions)
 	wg.Done()
 }
 
-func startAzure(cmd *cobra.Command, interval int64, dryRun bool, disableTTLCheck bool, wg *sync.WaitGroup) {
-	locations, _ := cmd.Flags().GetStringSlice("az-regions")
+func startAzure(ctx context.Context, cmd *cobra.Command, interval int64, dryRun bool, disableTTLCheck bool, wg *sync.WaitGroup) {
+	locations, err := common.ResolveLocations("azure", cmd, func() ([]string, error) {
+		return azure.DiscoverLocations(ctx)
+	})
+	if err != nil {
+		log.Fatalf("Can't get Azure locations: %s", err.Error())
+	}
+	log.Infof("Azure locations: %s", strings.Join(locations, ", "))
+
 	tagValue := getCmdString(cmd, "tag-value")
 
 	azureOptions := azure.AzureOptions{
-		TagName:              getCmdString(cmd, "tag-name"),
-		TagValue:             tagValue,
-		DisableTTLCheck:      disableTTLCheck,
-		IsDestroyingCommand:  strings.TrimSpace(tagValue) != "",
-		DryRun:               dryRun,
-		EnableRG:             getCmdBool(cmd, "enable-rg"),
-		EnableStorageAccount: getCmdBool(cmd, "enable-storage-account"),
-		EnableACR:            getCmdBool(cmd, "enable-acr"),
+		TagName:             common.GetTagName("azure", cmd),
+		TagValue:            tagValue,
+		DisableTTLCheck:     disableTTLCheck,
+		IsDestroyingCommand: strings.TrimSpace(tagValue) != "",
+		DryRun:              dryRun,
+		Features:            common.GetEnabledFeatures("azure", cmd),
 	}
-
-	azure.RunPlecoAzure(locations, interval, wg, azureOptions)
+	azure.RunPlecoAzure(ctx, locations, interval, wg, azureOptions)
 	wg.Done()
 }
 
-func startScaleway(cmd *cobra.Command, interval int64, dryRun bool, disableTTLCheck bool, wg *sync.WaitGroup) {
-	zones, _ := cmd.Flags().GetStringSlice("scw-zones")
+func startScaleway(ctx context.Context, cmd *cobra.Command, interval int64, dryRun bool, disableTTLCheck bool, wg *sync.WaitGroup) {
+	zones, err := common.ResolveLocations("scaleway", cmd, scaleway.DiscoverZones)
+	if err != nil {
+		log.Fatalf("Can't get Scaleway zones: %s", err.Error())
+	}
+	log.Infof("Scaleway zones: %s", strings.Join(zones, ", "))
+
 	tagValue := getCmdString(cmd, "tag-value")
 
 	scalewayOptions := scaleway.ScalewayOptions{
-		TagName:              getCmdString(cmd, "tag-name"),
-		TagValue:             tagValue,
-		DisableTTLCheck:      disableTTLCheck,
-		IsDestroyingCommand:  strings.TrimSpace(tagValue) != "",
-		DryRun:               dryRun,
-		EnableCluster:        getCmdBool(cmd, "enable-cluster"),
-		EnableDB:             getCmdBool(cmd, "enable-db"),
-		EnableCR:             getCmdBool(cmd, "enable-cr"),
-		EnableBucket:         getCmdBool(cmd, "enable-s3"),
-		EnableLB:             getCmdBool(cmd, "enable-lb"),
-		EnableVolume:         getCmdBool(cmd, "enable-volume"),
-		EnableSG:             getCmdBool(cmd, "enable-sg"),
-		EnableOrphanIP:       getCmdBool(cmd, "enable-orphan-ip"),
-		EnableVPC:            getCmdBool(cmd, "enable-vpc"),
-		EnablePrivateNetwork: getCmdBool(cmd, "enable-private-network"),
-	}
-	scaleway.RunPlecoScaleway(zones, interval, wg, scalewayOptions)
+		TagName:             common.GetTagName("scaleway", cmd),
+		TagValue:            tagValue,
+		DisableTTLCheck:     disableTTLCheck,
+		IsDestroyingCommand: strings.TrimSpace(tagValue) != "",
+		DryRun:              dryRun,
+		Features:            common.GetEnabledFeatures("scaleway", cmd),
+	}
+	scaleway.RunPlecoScaleway(ctx, zones, interval, wg, scalewayOptions)
 	wg.Done()
 }
 
-func startDO(cmd *cobra.Command, interval int64, dryRun bool, disableTTLCheck bool, wg *sync.WaitGroup) {
-	regions, _ := cmd.Flags().GetStringSlice("do-regions")
+func startDO(ctx context.Context, cmd *cobra.Command, interval int64, dryRun bool, disableTTLCheck bool, wg *sync.WaitGroup) {
+	regions, err := common.ResolveLocations("do", cmd, func() ([]string, error) {
+		return do.DiscoverRegions(ctx)
+	})
+	if err != nil {
+		log.Fatalf("Can't get Digital Ocean regions: %s", err.Error())
+	}
+	log.Infof("Digital Ocean regions: %s", strings.Join(regions, ", "))
+
 	tagValue := getCmdString(cmd, "tag-value")
 
 	DOOptions := do.DOOptions{
-		TagName:             getCmdString(cmd, "tag-name"),
+		TagName:             common.GetTagName("do", cmd),
 		TagValue:            tagValue,
 		DisableTTLCheck:     disableTTLCheck,
 		IsDestroyingCommand: strings.TrimSpace(tagValue) != "",
 		DryRun:              dryRun,
-		EnableCluster:       getCmdBool(cmd, "enable-cluster"),
-		EnableDB:            getCmdBool(cmd, "enable-db"),
-		EnableBucket:        getCmdBool(cmd, "enable-s3"),
-		EnableLB:            getCmdBool(cmd, "enable-lb"),
-		EnableVolume:        getCmdBool(cmd, "enable-volume"),
-		EnableFirewall:      getCmdBool(cmd, "enable-firewall"),
-		EnableVPC:           getCmdBool(cmd, "enable-vpc"),
-	}
-	do.RunPlecoDO(regions, interval, wg, DOOptions)
+		Features:            common.GetEnabledFeatures("do", cmd),
+	}
+	do.RunPlecoDO(ctx, regions, interval, wg, DOOptions)
 	wg.Done()
 }
 
-func startGCP(cmd *cobra.Command, interval int64, dryRun bool, disableTTLCheck bool, wg *sync.WaitGroup) {
-	locations, _ := cmd.Flags().GetStringSlice("gcp-regions")
+func startGCP(ctx context.Context, cmd *cobra.Command, interval int64, dryRun bool, disableTTLCheck bool, wg *sync.WaitGroup) {
 	tagValue := getCmdString(cmd, "tag-value")
 
+	projects, err := gcp.ResolveProjects(ctx, common.GetAccounts("gcp", cmd))
+	if err != nil {
+		log.Fatalf("Can't get GCP projects: %s", err.Error())
+	}
+	log.Infof("GCP projects: %s", strings.Join(projects, ", "))
+
+	locations, err := common.ResolveLocations("gcp", cmd, func() ([]string, error) {
+		if len(projects) == 0 {
+			return nil, nil
+		}
+		return gcp.DiscoverRegions(ctx, projects[0])
+	})
+	if err != nil {
+		log.Fatalf("Can't get GCP regions: %s", err.Error())
+	}
+	log.Infof("GCP regions: %s", strings.Join(locations, ", "))
+
 	gcpOptions := gcp.GCPOptions{
-		ProjectID:              "qovery-gcp-tests",
-		TagValue:               tagValue,
-		DisableTTLCheck:        disableTTLCheck,
-		IsDestroyingCommand:    strings.TrimSpace(tagValue) != "",
-		DryRun:                 dryRun,
-		EnableCluster:          getCmdBool(cmd, "enable-cluster"),
-		EnableBucket:           getCmdBool(cmd, "enable-object-storage"),
-		EnableNetwork:          getCmdBool(cmd, "enable-network"),
-		EnableRouter:           getCmdBool(cmd, "enable-router"),
-		EnableArtifactRegistry: getCmdBool(cmd, "enable-artifact-registry"),
-		EnableIAM:              getCmdBool(cmd, "enable-iam"),
-		EnableJob:              getCmdBool(cmd, "enable-job"),
-	}
-
-	gcp.RunPlecoGCP(locations, interval, wg, gcpOptions)
+		TagName:             common.GetTagName("gcp", cmd),
+		TagValue:            tagValue,
+		DisableTTLCheck:     disableTTLCheck,
+		IsDestroyingCommand: strings.TrimSpace(tagValue) != "",
+		DryRun:              dryRun,
+		Features:            common.GetEnabledFeatures("gcp", cmd),
+	}
+
+	gcp.RunPlecoGCP(ctx, projects, locations, interval, wg, gcpOptions)
+	wg.Done()
+}
+
+// startKubernetes cleans the objects inside namespaces, the ttl check can't be disabled for them.
+func startKubernetes(ctx context.Context, cmd *cobra.Command, interval int64, dryRun bool, wg *sync.WaitGroup) {
+	connection := getCmdString(cmd, "kube-conn")
+	if connection != "in" && connection != "out" {
+		log.Fatalf("Cleaning Kubernetes objects requires a Kubernetes connection, set --kube-conn to in or out")
+	}
+	tagValue := getCmdString(cmd, "tag-value")
+
+	kubernetesOptions := k8s.KubernetesOptions{
+		Connection:          connection,
+		TagName:             common.GetTagName("kubernetes", cmd),
+		TagValue:            tagValue,
+		IsDestroyingCommand: strings.TrimSpace(tagValue) != "",
+		DryRun:              dryRun,
+		Features:            common.GetEnabledFeatures("kubernetes", cmd),
+	}
+	k8s.RunPlecoKubernetesObjects(ctx, common.GetLocations("kubernetes", cmd), interval, wg, kubernetesOptions)
 	wg.Done()
 }
 
+// initPolicyResource watches the CleanupPolicy object, if any. It replaces the config file policy once loaded.
+func initPolicyResource(cmd *cobra.Command) {
+	name := getCmdString(cmd, "policy-resource")
+	if name == "" {
+		return
+	}
+
+	config, err := k8s.Config(getCmdString(cmd, "kube-conn"))
+	if err != nil {
+		log.Fatalf("Can't watch CleanupPolicy %s: %s", name, err.Error())
+	}
+
+	watcher, err := k8s.WatchPolicy(config, getNamespace(cmd, "policy-namespace"), name)
+	if err != nil {
+		log.Fatalf("Can't watch CleanupPolicy %s: %s", name, err.Error())
+	}
+	common.SetStatusWriter(watcher)
+}
+
+func initReporter(cmd *cobra.Command) {
+	reportFormat := getCmdString(cmd, "report-format")
+	if reportFormat == "" {
+		return
+	}
+
+	reporter, err := common.NewReporter(reportFormat, getCmdString(cmd, "report-file"))
+	if err != nil {
+		log.Fatalf("Can't initialize report: %s", err.Error())
+	}
+
+	common.SetReporter(reporter)
+}
+
+func initNotifiers(cmd *cobra.Command) {
+	var notifiers []common.Notifier
+	if url := getCmdStringOrEnv(cmd, "notify-slack-webhook", "PLECO_SLACK_WEBHOOK_URL"); url != "" {
+		notifiers = append(notifiers, common.SlackNotifier{WebhookURL: url})
+	}
+	if url := getCmdStringOrEnv(cmd, "notify-webhook", "PLECO_WEBHOOK_URL"); url != "" {
+		notifiers = append(notifiers, common.WebhookNotifier{URL: url})
+	}
+	ownerTag := getCmdString(cmd, "owner-tag")
+	if server := getCmdString(cmd, "notify-smtp-server"); server != "" {
+		// without recipients, only the owners of the resources expiring soon are emailed
+		recipients, _ := cmd.Flags().GetStringSlice("notify-smtp-to")
+		if len(recipients) == 0 && ownerTag == "" {
+			log.Fatalf("--notify-smtp-to or --owner-tag is required to send notifications with SMTP")
+		}
+		notifiers = append(notifiers, common.SMTPNotifier{
+			Server:   server,
+			From:     getCmdString(cmd, "notify-smtp-from"),
+			To:       recipients,
+			Username: os.Getenv("PLECO_SMTP_USERNAME"),
+			Password: os.Getenv("PLECO_SMTP_PASSWORD"),
+		})
+	}
+
+	warningWindow, _ := cmd.Flags().GetDuration("notify-warning-window")
+	failuresAfter, _ := cmd.Flags().GetInt("notify-failures-after")
+	for _, notifier := range notifiers {
+		log.Infof("Notifications enabled: %s", notifier.Name())
+	}
+	common.SetNotifiers(notifiers, common.NotifyOptions{
+		WarningWindow: warningWindow,
+		FailuresAfter: failuresAfter,
+		Digest:        getCmdBool(cmd, "notify-digest"),
+		OwnerTag:      ownerTag,
+		StampWarned:   getCmdBool(cmd, "tag-warned"),
+	})
+}
+
+func initStateStore(cmd *cobra.Command) {
+	if stuckAfter, err := cmd.Flags().GetDuration("stuck-after"); err == nil {
+		common.SetStuckAfter(stuckAfter)
+	}
+
+	var store common.StateStore
+	switch backend := getCmdString(cmd, "state-backend"); backend {
+	case "":
+		return
+	case "file":
+		store = common.NewFileStateStore(getCmdString(cmd, "state-file"))
+	case "configmap":
+		clientSet, err := k8s.Authenticate(getCmdString(cmd, "kube-conn"))
+		if err != nil {
+			log.Fatalf("Can't initialize state store: %s", err.Error())
+		}
+
+		store = k8s.NewConfigMapStateStore(clientSet, getNamespace(cmd, "state-namespace"), getCmdString(cmd, "state-configmap"))
+	default:
+		log.Fatalf("Unknown state backend %s, should be file or configmap", backend)
+	}
+
+	if err := common.SetStateStore(store); err != nil {
+		log.Fatalf("Can't initialize state store: %s", err.Error())
+	}
+}
+
 func getCmdString(cmd *cobra.Command, name string) string {
 	v, _ := cmd.Flags().GetString(name)
 	return v
 }
 
+func getCmdStringOrEnv(cmd *cobra.Command, name string, envVar string) string {
+	if v := getCmdString(cmd, name); v != "" {
+		return v
+	}
+
+	return os.Getenv(envVar)
+}
+
+// getNamespace returns the namespace flag value, else the namespace Pleco runs in, else default.
+func getNamespace(cmd *cobra.Command, name string) string {
+	if namespace := getCmdStringOrEnv(cmd, name, "POD_NAMESPACE"); namespace != "" {
+		return namespace
+	}
+
+	return "default"
+}
+
 func getCmdBool(cmd *cobra.Command, name string) bool {
 	v, _ := cmd.Flags().GetBool(name)
 	return v
@@ -0,0 +1,165 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/service/organizations"
+	"github.com/aws/aws-sdk-go/service/sts"
+)
+
+// defaultRegion is the region of the calls to global endpoints, eg. STS and Organizations, and of the global check when
+// no region is cleaned.
+const defaultRegion = "us-east-1"
+
+var accountIDPattern = regexp.MustCompile(`^[0-9]{12}$`)
+
+// Account is an AWS account to clean, through an assumed role. Without role, the credentials are used as is.
+type Account struct {
+	ID      string
+	RoleARN string
+}
+
+func (account Account) String() string {
+	if account.RoleARN == "" {
+		return account.ID
+	}
+
+	return fmt.Sprintf("%s (%s)", account.ID, account.RoleARN)
+}
+
+// ResolveAccounts returns the accounts to clean. Entries are role ARNs, account IDs, whose roleName role is assumed,
+// or "organization", roots ("r-<id>") and organizational units ("ou-<id>") whose active member accounts, child units
+// included, are enumerated with AWS Organizations. Without entries, the account of the credentials is used.
+func ResolveAccounts(ctx context.Context, identity *sts.GetCallerIdentityOutput, entries []string, roleName string) ([]Account, error) {
+	if len(entries) == 0 {
+		return []Account{{ID: aws.StringValue(identity.Account)}}, nil
+	}
+
+	// the role is assumed in the other accounts, eg. OrganizationAccountAccessRole which doesn't exist in the
+	// management account
+	memberAccount := func(accountID string) Account {
+		if accountID == aws.StringValue(identity.Account) {
+			return Account{ID: accountID}
+		}
+		return Account{ID: accountID, RoleARN: roleARN(identity, accountID, roleName)}
+	}
+
+	var orgs *organizations.Organizations
+	seen := make(map[string]bool)
+	var accounts []Account
+	addAccount := func(account Account) {
+		if !seen[account.ID] {
+			seen[account.ID] = true
+			accounts = append(accounts, account)
+		}
+	}
+
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		switch {
+		case arn.IsARN(entry):
+			parsed, err := arn.Parse(entry)
+			if err != nil || parsed.Service != "iam" || !strings.HasPrefix(parsed.Resource, "role/") {
+				return nil, fmt.Errorf("%s is not an IAM role ARN", entry)
+			}
+			addAccount(Account{ID: parsed.AccountID, RoleARN: entry})
+		case accountIDPattern.MatchString(entry):
+			addAccount(memberAccount(entry))
+		case entry == "organization" || strings.HasPrefix(entry, "r-") || strings.HasPrefix(entry, "ou-"):
+			if orgs == nil {
+				orgs = organizations.New(CreateSession(defaultRegion))
+			}
+
+			accountIDs, err := listAccounts(ctx, orgs, entry)
+			if err != nil {
+				return nil, err
+			}
+			for _, accountID := range accountIDs {
+				addAccount(memberAccount(accountID))
+			}
+		default:
+			return nil, fmt.Errorf("unknown AWS account %s, expected a role ARN, an account ID, organization, r-<id> or ou-<id>", entry)
+		}
+	}
+
+	return accounts, nil
+}
+
+// roleARN returns the ARN of the role to assume in an account, in the partition of the credentials.
+func roleARN(identity *sts.GetCallerIdentityOutput, accountID string, roleName string) string {
+	partition := "aws"
+	if callerARN, err := arn.Parse(aws.StringValue(identity.Arn)); err == nil {
+		partition = callerARN.Partition
+	}
+
+	return arn.ARN{
+		Partition: partition,
+		Service:   "iam",
+		AccountID: accountID,
+		Resource:  "role/" + roleName,
+	}.String()
+}
+
+// listAccounts returns the active accounts of the organization, or of a root or an organizational unit and of their
+// child units.
+func listAccounts(ctx context.Context, orgs *organizations.Organizations, parent string) ([]string, error) {
+	var accountIDs []string
+	if parent == "organization" {
+		err := orgs.ListAccountsPagesWithContext(ctx, &organizations.ListAccountsInput{}, func(page *organizations.ListAccountsOutput, lastPage bool) bool {
+			for _, account := range page.Accounts {
+				if aws.StringValue(account.Status) == organizations.AccountStatusActive {
+					accountIDs = append(accountIDs, aws.StringValue(account.Id))
+				}
+			}
+			return true
+		})
+		if err != nil {
+			return nil, fmt.Errorf("can't list the accounts of the organization: %s", err.Error())
+		}
+
+		return accountIDs, nil
+	}
+
+	err := orgs.ListAccountsForParentPagesWithContext(ctx, &organizations.ListAccountsForParentInput{
+		ParentId: aws.String(parent),
+	}, func(page *organizations.ListAccountsForParentOutput, lastPage bool) bool {
+		for _, account := range page.Accounts {
+			if aws.StringValue(account.Status) == organizations.AccountStatusActive {
+				accountIDs = append(accountIDs, aws.StringValue(account.Id))
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("can't list the accounts of %s: %s", parent, err.Error())
+	}
+
+	var units []string
+	err = orgs.ListChildrenPagesWithContext(ctx, &organizations.ListChildrenInput{
+		ParentId:  aws.String(parent),
+		ChildType: aws.String(organizations.ChildTypeOrganizationalUnit),
+	}, func(page *organizations.ListChildrenOutput, lastPage bool) bool {
+		for _, child := range page.Children {
+			units = append(units, aws.StringValue(child.Id))
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("can't list the organizational units of %s: %s", parent, err.Error())
+	}
+
+	for _, unit := range units {
+		unitAccountIDs, err := listAccounts(ctx, orgs, unit)
+		if err != nil {
+			return nil, err
+		}
+		accountIDs = append(accountIDs, unitAccountIDs...)
+	}
+
+	return accountIDs, nil
+}
@@ -0,0 +1,117 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Qovery/pleco/pkg/common"
+)
+
+const providerName = "aws"
+
+func init() {
+	common.RegisterProvider(common.ProviderDefinition{
+		Name:                  providerName,
+		LocationsFlag:         "aws-regions",
+		LocationsShorthand:    "a",
+		LocationsUsage:        "Set AWS regions (default is all, the regions enabled in the account of the credentials)",
+		ExcludedLocationsFlag: "aws-exclude-regions",
+		AccountsFlag:          "aws-accounts",
+		AccountsUsage:         "Set AWS role ARNs or account IDs, or organization, r-<id> and ou-<id> to clean all their accounts (default is the account of the credentials)",
+		Features: []common.Feature{
+			{Name: "eks", Shorthand: "e", Usage: "Enable EKS watch", Implies: []string{"elb", "ebs"}},
+			{Name: "rds", Shorthand: "r", Usage: "Enable RDS databases and and its children (subnet groups & parameter groups) watch"},
+			{Name: "documentdb", Shorthand: "m", Usage: "Enable DocumentDB watch"},
+			{Name: "elasticache", Shorthand: "c", Usage: "Enable Elasticache watch"},
+			{Name: "elb", Shorthand: "l", Usage: "Enable Elastic Load Balancers watch (true is eks is enabled)"},
+			{Name: "ebs", Shorthand: "b", Usage: "Enable Elastic Volumes watch (true is eks is enabled)"},
+			{Name: "vpc", Shorthand: "p", Usage: "Enable VPC and its children (internet gateways, route tables, subnets, security groups) watch"},
+			{Name: "s3", Shorthand: "s", Usage: "Enable S3 watch"},
+			{Name: "cloudwatch-logs", Shorthand: "w", Usage: "Enable Cloudwatch Logs watch"},
+			{Name: "kms", Shorthand: "n", Usage: "Enable KMS watch"},
+			{Name: "iam", Shorthand: "u", Usage: "Enable IAM (groups, policies, roles, users) watch"},
+			{Name: "ssh-keys", Shorthand: "z", Usage: "Enable Key Pair watch"},
+			{Name: "ecr", Shorthand: "o", Usage: "Enable ECR watch"},
+			{Name: "sqs", Shorthand: "q", Usage: "Enable SQS watch"},
+			{Name: "lambda", Shorthand: "f", Usage: "Enable Lambda Function watch"},
+			{Name: "sfn", Shorthand: "x", Usage: "Enable Step Function State Machines watch"},
+			{Name: "cloudformation", Shorthand: "d", Usage: "Enable Cloudformation watch"},
+			{Name: "ec2-instance", Shorthand: "g", Usage: "Enable EC2 Instance watch"},
+			{Name: "cloudwatch-events", Shorthand: "v", Usage: "Enable CloudWatch events watch"},
+		},
+	})
+
+	// deletion order: EKS clusters (node groups and Fargate profiles first), then load balancers and volumes they
+	// created, then databases and their groups, then VPC children and finally VPCs
+	common.RegisterDependencies(providerName, "elb-load-balancer", "eks-cluster")
+	common.RegisterDependencies(providerName, "ebs-volume", "eks-cluster", "ec2-instance")
+	common.RegisterDependencies(providerName, "cloudwatch-log-group", "eks-cluster")
+	common.RegisterDependencies(providerName, "rds-subnet-group", "rds-database", "documentdb-cluster")
+	common.RegisterDependencies(providerName, "rds-parameter-group", "rds-database")
+	common.RegisterDependencies(providerName, "elasticache-subnet-group", "elasticache-cluster")
+	common.RegisterDependencies(providerName, "nat-gateway", "eks-cluster", "ec2-instance")
+	common.RegisterDependencies(providerName, "elastic-ip", "nat-gateway", "ec2-instance")
+	vpcChildren := []string{"eks-cluster", "elb-load-balancer", "ec2-instance", "lambda-function", "rds-database", "documentdb-cluster",
+		"elasticache-cluster", "rds-subnet-group", "elasticache-subnet-group", "nat-gateway", "elastic-ip"}
+	common.RegisterDependencies(providerName, "vpc-linked-resources", vpcChildren...)
+	common.RegisterDependencies(providerName, "vpc", vpcChildren...)
+	common.RegisterDependencies(providerName, "iam-role", "iam-instance-profile")
+}
+
+// awsCleaner is embedded by every AWS cleaner, it is also the provider scope given to the cleaners constructors.
+type awsCleaner struct {
+	common.TTLEvaluator
+	sessions AWSSessions
+	options  AwsOptions
+}
+
+func newAWSCleaner(sessions AWSSessions, options AwsOptions, scope common.Scope) awsCleaner {
+	if options.TaggingAPI {
+		options.tagIndex = newTaggedResources(scope == common.GlobalScope, options.DisableTTLCheck)
+	}
+
+	return awsCleaner{
+		TTLEvaluator: common.TTLEvaluator{TagValue: options.TagValue, DisableTTLCheck: options.DisableTTLCheck},
+		sessions:     sessions,
+		options:      options,
+	}
+}
+
+// Discover reads the tags of the location with the tagging API, when enabled, and returns the tagged resources of types
+// Pleco doesn't clean.
+func (c awsCleaner) Discover(ctx context.Context) ([]common.UnsupportedResource, error) {
+	if c.options.tagIndex == nil {
+		return nil, nil
+	}
+
+	if err := c.options.tagIndex.load(ctx, c.sessions.TaggingAPI); err != nil {
+		return nil, fmt.Errorf("can't get the tagged resources: %s", err.Error())
+	}
+
+	return c.options.tagIndex.unsupported(c.options.TagName), nil
+}
+
+func (c awsCleaner) region() string {
+	return c.options.Region
+}
+
+func registerCleaner(scope common.Scope, feature string, kind string, description string, newCleaner func(base awsCleaner) common.Cleaner) {
+	common.RegisterCleaner(common.CleanerDefinition{
+		Provider:    providerName,
+		Feature:     feature,
+		Kind:        kind,
+		Description: description,
+		Scope:       scope,
+		New: func(providerScope interface{}) (common.Cleaner, error) {
+			return newCleaner(providerScope.(awsCleaner)), nil
+		},
+	})
+}
+
+func registerRegionalCleaner(feature string, kind string, description string, newCleaner func(base awsCleaner) common.Cleaner) {
+	registerCleaner(common.RegionScope, feature, kind, description, newCleaner)
+}
+
+func registerGlobalCleaner(feature string, kind string, description string, newCleaner func(base awsCleaner) common.Cleaner) {
+	registerCleaner(common.GlobalScope, feature, kind, description, newCleaner)
+}
@@ -1,6 +1,9 @@
 package aws
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/Qovery/pleco/pkg/common"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/eventbridge"
@@ -11,7 +14,7 @@ type cloudWatchEvent struct {
 	common.CloudProviderResource
 }
 
-func listTaggedCloudWatchEvents(svc eventbridge.EventBridge, tagName string) ([]cloudWatchEvent, error) {
+func listTaggedCloudWatchEvents(ctx context.Context, svc eventbridge.EventBridge, tagName string, tagIndex *taggedResources) ([]cloudWatchEvent, error) {
 	var taggedCloudwatchEvents []cloudWatchEvent
 
 	MaxResultsPerPager := int64(100)
@@ -21,26 +24,28 @@ func listTaggedCloudWatchEvents(svc eventbridge.EventBridge, tagName string) ([]
 	}
 
 	for {
-		result, err := svc.ListRules(params)
+		result, err := svc.ListRulesWithContext(ctx, params)
 		if err != nil {
 			return nil, err
 		}
 
-		if len(result.Rules) == 0 {
-			return nil, nil
-		}
-
 		for _, rule := range result.Rules {
-			tags, err := svc.ListTagsForResource(
-				&eventbridge.ListTagsForResourceInput{
-					ResourceARN: rule.Arn,
-				},
-			)
-			if err != nil {
-				continue
+			var tags interface{}
+			if indexedTags, ok := tagIndex.lookup(aws.StringValue(rule.Arn)); ok {
+				tags = indexedTags
+			} else {
+				output, err := svc.ListTagsForResourceWithContext(ctx,
+					&eventbridge.ListTagsForResourceInput{
+						ResourceARN: rule.Arn,
+					},
+				)
+				if err != nil {
+					return nil, fmt.Errorf("can't get tags of rule %s: %w", *rule.Name, err)
+				}
+				tags = output.Tags
 			}
 
-			essentialTags := common.GetEssentialTags(tags.Tags, tagName)
+			essentialTags := common.GetEssentialTags(tags, tagName)
 
 			taggedCloudwatchEvents = append(taggedCloudwatchEvents, cloudWatchEvent{
 				CloudProviderResource: common.CloudProviderResource{
@@ -50,6 +55,9 @@ func listTaggedCloudWatchEvents(svc eventbridge.EventBridge, tagName string) ([]
 					TTL:          essentialTags.TTL,
 					Tag:          essentialTags.Tag,
 					IsProtected:  essentialTags.IsProtected,
+					ExpiresAt:    essentialTags.ExpiresAt,
+					Warnings:     essentialTags.Warnings,
+					Tags:         essentialTags.Tags,
 				},
 			})
 		}
@@ -63,28 +71,10 @@ func listTaggedCloudWatchEvents(svc eventbridge.EventBridge, tagName string) ([]
 	return taggedCloudwatchEvents, nil
 }
 
-func getExpiredCloudWatchEvents(ECsession *eventbridge.EventBridge, options *AwsOptions) ([]cloudWatchEvent, string) {
-	cloudwatchEvents, err := listTaggedCloudWatchEvents(*ECsession, options.TagName)
-	region := *ECsession.Config.Region
-	if err != nil {
-		log.Errorf("Can't list Cloudwatch event in region %s: %s", region, err.Error())
-	}
-
-	var expiredCloudwatchEvents []cloudWatchEvent
-	for _, cloudwatchEvent := range cloudwatchEvents {
-
-		if cloudwatchEvent.IsResourceExpired(options.TagValue, options.DisableTTLCheck) {
-			expiredCloudwatchEvents = append(expiredCloudwatchEvents, cloudwatchEvent)
-		}
-	}
-
-	return expiredCloudwatchEvents, region
-}
-
-func deleteCloudWatchEvent(svc eventbridge.EventBridge, event cloudWatchEvent) error {
+func deleteCloudWatchEvent(ctx context.Context, svc eventbridge.EventBridge, event cloudWatchEvent) error {
 	force := true
 
-	_, err := svc.DeleteRule(
+	_, err := svc.DeleteRuleWithContext(ctx,
 		&eventbridge.DeleteRuleInput{
 			Force: &force,
 			Name:  aws.String(event.Identifier),
@@ -97,13 +87,13 @@ func deleteCloudWatchEvent(svc eventbridge.EventBridge, event cloudWatchEvent) e
 	return nil
 }
 
-func removeTarget(svc eventbridge.EventBridge, event cloudWatchEvent) error {
+func removeTarget(ctx context.Context, svc eventbridge.EventBridge, event cloudWatchEvent) error {
 	targetsInput := &eventbridge.ListTargetsByRuleInput{
 		Rule: aws.String(event.Identifier),
 	}
 	var targetIdsPtr []*string
 
-	targetsOutput, err := svc.ListTargetsByRule(targetsInput)
+	targetsOutput, err := svc.ListTargetsByRuleWithContext(ctx, targetsInput)
 	if err == nil && len(targetsOutput.Targets) > 0 {
 		for _, target := range targetsOutput.Targets {
 			targetIdsPtr = append(targetIdsPtr, target.Id)
@@ -115,7 +105,7 @@ func removeTarget(svc eventbridge.EventBridge, event cloudWatchEvent) error {
 			Force: aws.Bool(true),
 		}
 
-		_, err = svc.RemoveTargets(removeInput)
+		_, err = svc.RemoveTargetsWithContext(ctx, removeInput)
 		if err != nil {
 			log.Errorf("Remove target error %s: %s", event.Identifier, err.Error())
 		}
@@ -124,32 +114,38 @@ func removeTarget(svc eventbridge.EventBridge, event cloudWatchEvent) error {
 	return nil
 }
 
-func DeleteExpiredCloudWatchEvents(sessions AWSSessions, options AwsOptions) {
-	expiredCloudwatchEvents, region := getExpiredCloudWatchEvents(sessions.EventBridge, &options)
-
-	count, start := common.ElemToDeleteFormattedInfos("expired CloudWatch Events", len(expiredCloudwatchEvents), region)
+type cloudWatchEventCleaner struct {
+	awsCleaner
+}
 
-	log.Info(count)
+func init() {
+	registerRegionalCleaner("cloudwatch-events", "cloudwatch-event", "expired CloudWatch Event", func(base awsCleaner) common.Cleaner {
+		return cloudWatchEventCleaner{base}
+	})
+}
 
-	if options.DryRun || len(expiredCloudwatchEvents) == 0 {
-		return
+func (c cloudWatchEventCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	events, err := listTaggedCloudWatchEvents(ctx, *c.sessions.EventBridge, c.options.TagName, c.options.tagIndex)
+	if err != nil {
+		return nil, err
 	}
 
-	log.Info(start)
+	var resources []common.CloudProviderResource
+	for _, event := range events {
+		resource := event.CloudProviderResource
+		resource.Payload = event
+		resources = append(resources, resource)
+	}
 
-	for _, cloudwatchEvent := range expiredCloudwatchEvents {
-		log.Debugf("cloudwatchEvent %s in %s deleted.", cloudwatchEvent.Identifier, region)
+	return resources, nil
+}
 
-		removeError := removeTarget(*sessions.EventBridge, cloudwatchEvent)
-		if removeError != nil {
-			log.Errorf("Remove target error %s/%s: %s", cloudwatchEvent.Identifier, region, removeError.Error())
-		}
+func (c cloudWatchEventCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	event := resource.Payload.(cloudWatchEvent)
 
-		deletionErr := deleteCloudWatchEvent(*sessions.EventBridge, cloudwatchEvent)
-		if deletionErr != nil {
-			log.Errorf("Deletion CloudWatch event error %s/%s: %s", cloudwatchEvent.Identifier, region, deletionErr.Error())
-		} else {
-			log.Debugf("CloudWatch event %s in %s deleted.", cloudwatchEvent.Identifier, region)
-		}
+	if err := removeTarget(ctx, *c.sessions.EventBridge, event); err != nil {
+		log.Errorf("Remove target error %s/%s: %s", event.Identifier, c.region(), err.Error())
 	}
+
+	return deleteCloudWatchEvent(ctx, *c.sessions.EventBridge, event)
 }
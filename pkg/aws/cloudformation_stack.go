@@ -1,6 +1,8 @@
 package aws
 
 import (
+	"context"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/cloudformation"
@@ -17,7 +19,7 @@ func CloudformationSession(sess session.Session, region string) *cloudformation.
 	return cloudformation.New(&sess, &aws.Config{Region: aws.String(region)})
 }
 
-func tagStacks(svc cloudformation.CloudFormation, Stack *cloudformation.ListStacksOutput, tagName string) []CloudformationStack {
+func tagStacks(ctx context.Context, svc cloudformation.CloudFormation, Stack *cloudformation.ListStacksOutput, tagName string) []CloudformationStack {
 	var taggedStacks []CloudformationStack
 
 	for _, stack := range Stack.StackSummaries {
@@ -25,8 +27,8 @@ func tagStacks(svc cloudformation.CloudFormation, Stack *cloudformation.ListStac
 			StackName: aws.String(*stack.StackName),
 		}
 
-		stackDescriptionList, err := svc.DescribeStacks(describeStacksInput)
-
+		// the deleted stacks are listed too but can't be described anymore
+		stackDescriptionList, err := svc.DescribeStacksWithContext(ctx, describeStacksInput)
 		if err != nil {
 			continue
 		}
@@ -42,6 +44,9 @@ func tagStacks(svc cloudformation.CloudFormation, Stack *cloudformation.ListStac
 				TTL:          essentialTags.TTL,
 				Tag:          essentialTags.Tag,
 				IsProtected:  essentialTags.IsProtected,
+				ExpiresAt:    essentialTags.ExpiresAt,
+				Warnings:     essentialTags.Warnings,
+				Tags:         essentialTags.Tags,
 			},
 		})
 
@@ -49,9 +54,9 @@ func tagStacks(svc cloudformation.CloudFormation, Stack *cloudformation.ListStac
 	return taggedStacks
 }
 
-func listTaggedStacks(svc cloudformation.CloudFormation, tagName string) ([]CloudformationStack, error) {
+func listTaggedStacks(ctx context.Context, svc cloudformation.CloudFormation, tagName string) ([]CloudformationStack, error) {
 
-	result, err := svc.ListStacks(nil)
+	result, err := svc.ListStacksWithContext(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -60,28 +65,28 @@ func listTaggedStacks(svc cloudformation.CloudFormation, tagName string) ([]Clou
 		return nil, nil
 	}
 
-	taggedStacks := tagStacks(svc, result, tagName)
+	taggedStacks := tagStacks(ctx, svc, result, tagName)
 
 	for result.NextToken != nil {
-		result, err = svc.ListStacks(&cloudformation.ListStacksInput{
+		result, err = svc.ListStacksWithContext(ctx, &cloudformation.ListStacksInput{
 			NextToken: result.NextToken,
 		})
 
 		if err != nil {
 			return nil, err
 		}
-		taggedStacks = append(taggedStacks, tagStacks(svc, result, tagName)...)
+		taggedStacks = append(taggedStacks, tagStacks(ctx, svc, result, tagName)...)
 	}
 
 	return taggedStacks, nil
 }
 
-func deleteStack(svc cloudformation.CloudFormation, stack CloudformationStack) error {
+func deleteStack(ctx context.Context, svc cloudformation.CloudFormation, stack CloudformationStack) error {
 
 	log.Infof("Deleting CloudFormation Stack %s in %s, expired after %d seconds",
 		stack.Identifier, *svc.Config.Region, stack.TTL)
 
-	_, err := svc.DeleteStack(&cloudformation.DeleteStackInput{
+	_, err := svc.DeleteStackWithContext(ctx, &cloudformation.DeleteStackInput{
 		StackName: &stack.Identifier,
 	},
 	)
@@ -92,42 +97,32 @@ func deleteStack(svc cloudformation.CloudFormation, stack CloudformationStack) e
 	return nil
 }
 
-func getExpiredStacks(ECsession *cloudformation.CloudFormation, options *AwsOptions) ([]CloudformationStack, string) {
-	stacks, err := listTaggedStacks(*ECsession, options.TagName)
-	region := *ECsession.Config.Region
-	if err != nil {
-		log.Errorf("Can't list CloudFormation Stacks in region %s: %s", region, err.Error())
-	}
-
-	var expiredStacks []CloudformationStack
-	for _, stack := range stacks {
-		if stack.IsResourceExpired(options.TagValue, options.DisableTTLCheck) {
-			expiredStacks = append(expiredStacks, stack)
-		}
-	}
-
-	return expiredStacks, region
+type cloudformationStackCleaner struct {
+	awsCleaner
 }
 
-func DeleteExpiredStacks(sessions AWSSessions, options AwsOptions) {
-	expiredStacks, region := getExpiredStacks(sessions.CloudFormation, &options)
-
-	count, start := common.ElemToDeleteFormattedInfos("expired CloudFormation Stacks", len(expiredStacks), region)
+func init() {
+	registerRegionalCleaner("cloudformation", "cloudformation-stack", "expired CloudFormation Stack", func(base awsCleaner) common.Cleaner {
+		return cloudformationStackCleaner{base}
+	})
+}
 
-	log.Info(count)
+func (c cloudformationStackCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	items, err := listTaggedStacks(ctx, *c.sessions.CloudFormation, c.options.TagName)
+	if err != nil {
+		return nil, err
+	}
 
-	if options.DryRun || len(expiredStacks) == 0 {
-		return
+	var resources []common.CloudProviderResource
+	for _, item := range items {
+		resource := item.CloudProviderResource
+		resource.Payload = item
+		resources = append(resources, resource)
 	}
 
-	log.Info(start)
+	return resources, nil
+}
 
-	for _, stack := range expiredStacks {
-		deletionErr := deleteStack(*sessions.CloudFormation, stack)
-		if deletionErr != nil {
-			log.Errorf("Deletion CloudFormation Stack error %s/%s: %s", stack.Identifier, region, deletionErr.Error())
-		} else {
-			log.Debugf("CloudFormation Stack %s in %s deleted.", stack.Identifier, region)
-		}
-	}
+func (c cloudformationStackCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	return deleteStack(ctx, *c.sessions.CloudFormation, resource.Payload.(CloudformationStack))
 }
@@ -1,26 +1,95 @@
 package aws
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/sts"
 	"github.com/sirupsen/logrus"
+
+	"github.com/Qovery/pleco/pkg/common"
 )
 
+// profile is the shared config profile of the sessions, the default one is used if empty.
+var profile string
+
+// SetProfile sets the shared config profile, eg. an SSO profile, the credentials are read from.
+func SetProfile(name string) {
+	profile = name
+}
+
+// CreateSession returns a session with the credentials of the environment: the profile, static keys, a web identity
+// token (IRSA), or the instance role.
 func CreateSession(region string) *session.Session {
-	sess, err := session.NewSession(&aws.Config{
-		Region: aws.String(region)},
-	)
+	sess, err := newSession(aws.Config{Region: aws.String(region)})
 	if err != nil {
 		logrus.Fatalf("Can't connect to AWS: %s", err.Error())
 	}
 	return sess
 }
 
+// CreateAccountSession returns a session on the account, whose role credentials are refreshed before they expire.
+func CreateAccountSession(region string, account Account) *session.Session {
+	sess := CreateSession(region)
+	if account.RoleARN == "" {
+		return sess
+	}
+
+	return sess.Copy(&aws.Config{
+		Credentials: stscreds.NewCredentials(sess, account.RoleARN, func(provider *stscreds.AssumeRoleProvider) {
+			provider.RoleSessionName = "pleco"
+		}),
+	})
+}
+
 func CreateSessionWithoutRegion() (*session.Session, error) {
-	sess, err := session.NewSession()
+	sess, err := newSession(aws.Config{})
 	if err != nil {
 		logrus.Errorf("Can't connect to AWS: %s", err)
 		return nil, err
 	}
 	return sess, nil
 }
+
+func newSession(config aws.Config) (*session.Session, error) {
+	return session.NewSessionWithOptions(session.Options{
+		Config:            config,
+		Profile:           profile,
+		SharedConfigState: session.SharedConfigEnable,
+	})
+}
+
+// CheckCredentials returns the identity of the credentials, or an error if they are missing or invalid.
+func CheckCredentials(ctx context.Context) (*sts.GetCallerIdentityOutput, error) {
+	ctx, cancel := common.CallContext(ctx)
+	defer cancel()
+
+	identity, err := sts.New(CreateSession(defaultRegion)).GetCallerIdentityWithContext(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return nil, fmt.Errorf("can't get the identity of the AWS credentials: %s", err.Error())
+	}
+
+	return identity, nil
+}
+
+// DiscoverRegions returns the regions enabled in the account of the credentials.
+func DiscoverRegions(ctx context.Context) ([]string, error) {
+	ctx, cancel := common.CallContext(ctx)
+	defer cancel()
+
+	output, err := ec2.New(CreateSession(defaultRegion)).DescribeRegionsWithContext(ctx, &ec2.DescribeRegionsInput{})
+	if err != nil {
+		return nil, err
+	}
+
+	regions := make([]string, 0, len(output.Regions))
+	for _, region := range output.Regions {
+		regions = append(regions, aws.StringValue(region.RegionName))
+	}
+
+	return regions, nil
+}
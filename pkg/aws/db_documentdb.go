@@ -1,6 +1,8 @@
 package aws
 
 import (
+	"context"
+	"fmt"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -17,15 +19,27 @@ type documentDBCluster struct {
 	Status           string
 }
 
-func getDBClusters(svc rds.RDS, tagName string) []documentDBCluster {
-	result, err := svc.DescribeDBClusters(&rds.DescribeDBClustersInput{})
+func init() {
+	registerRegionalCleaner("documentdb", "documentdb-cluster", "expired DocumentDB database", func(base awsCleaner) common.Cleaner {
+		return documentDBClusterCleaner{base}
+	})
+	registerRegionalCleaner("documentdb", "documentdb-cluster-snapshot", "expired RDS cluster snapshot", func(base awsCleaner) common.Cleaner {
+		return documentDBClusterSnapshotCleaner{awsCleaner: base}
+	})
+}
+
+func getDBClusters(ctx context.Context, svc rds.RDS, tagName string) ([]documentDBCluster, error) {
+	var clusters []*rds.DBCluster
+	err := svc.DescribeDBClustersPagesWithContext(ctx, &rds.DescribeDBClustersInput{}, func(page *rds.DescribeDBClustersOutput, lastPage bool) bool {
+		clusters = append(clusters, page.DBClusters...)
+		return true
+	})
 	if err != nil {
-		log.Errorf("Can't get DB clusters in %s: %s", *svc.Config.Region, err.Error())
-		return nil
+		return nil, fmt.Errorf("can't get DB clusters: %w", err)
 	}
 
 	var dbClusters []documentDBCluster
-	for _, cluster := range result.DBClusters {
+	for _, cluster := range clusters {
 		var dbClusterMembers []string
 		for _, instance := range cluster.DBClusterMembers {
 			dbClusterMembers = append(dbClusterMembers, *instance.DBInstanceIdentifier)
@@ -42,6 +56,9 @@ func getDBClusters(svc rds.RDS, tagName string) []documentDBCluster {
 				TTL:          essentialTags.TTL,
 				Tag:          essentialTags.Tag,
 				IsProtected:  essentialTags.IsProtected,
+				ExpiresAt:    essentialTags.ExpiresAt,
+				Warnings:     essentialTags.Warnings,
+				Tags:         essentialTags.Tags,
 			},
 			DBClusterMembers: dbClusterMembers,
 			SubnetGroupName:  *cluster.DBSubnetGroup,
@@ -49,40 +66,25 @@ func getDBClusters(svc rds.RDS, tagName string) []documentDBCluster {
 		})
 	}
 
-	return dbClusters
+	return dbClusters, nil
 }
 
-func listExpiredDocumentDBClusters(svc rds.RDS, options *AwsOptions) []documentDBCluster {
-	dbClusters := getDBClusters(svc, options.TagName)
-
-	var expiredClusters []documentDBCluster
-
-	for _, cluster := range dbClusters {
-		if cluster.IsResourceExpired(options.TagValue, options.DisableTTLCheck) {
-			expiredClusters = append(expiredClusters, cluster)
-		}
-	}
-
-	return expiredClusters
-}
-
-func deleteClusterInstances(svc rds.RDS, cluster documentDBCluster) {
+func deleteClusterInstances(ctx context.Context, svc rds.RDS, cluster documentDBCluster) {
 	for _, instance := range cluster.DBClusterMembers {
-		rdsInstanceInfo, err := GetRDSInstanceInfos(svc, instance)
+		rdsInstanceInfo, err := GetRDSInstanceInfos(ctx, svc, instance)
 		if err != nil {
 			log.Errorf("Can't access RDS instance %s information for DocumentDB cluster %s: %s",
 				instance, cluster.Identifier, err)
 			continue
 		}
 
-		DeleteRDSDatabase(svc, rdsInstanceInfo)
+		DeleteRDSDatabase(ctx, svc, rdsInstanceInfo)
 	}
 }
 
-func deleteDocumentDBCluster(svc rds.RDS, cluster documentDBCluster, dryRun bool) error {
+func deleteDocumentDBCluster(ctx context.Context, svc rds.RDS, cluster documentDBCluster, dryRun bool) error {
 	if cluster.Status == "deleting" {
-		log.Infof("DocumentDB cluster %s is already in deletion process, skipping...", cluster.Identifier)
-		return nil
+		return common.NewDeletionInProgressError("DocumentDB cluster %s is already in deletion process", cluster.Identifier)
 	} else {
 		log.Infof("Deleting DocumentDB cluster %s in %s, expired after %d seconds",
 			cluster.Identifier, *svc.Config.Region, cluster.TTL)
@@ -92,10 +94,10 @@ func deleteDocumentDBCluster(svc rds.RDS, cluster documentDBCluster, dryRun bool
 		return nil
 	}
 	// delete instance before deleting the cluster (otherwise it fails)
-	deleteClusterInstances(svc, cluster)
+	deleteClusterInstances(ctx, svc, cluster)
 
 	// delete cluster
-	_, err := svc.DeleteDBCluster(
+	_, err := svc.DeleteDBClusterWithContext(ctx,
 		&rds.DeleteDBClusterInput{
 			DBClusterIdentifier: aws.String(cluster.Identifier),
 			SkipFinalSnapshot:   aws.Bool(true),
@@ -108,43 +110,55 @@ func deleteDocumentDBCluster(svc rds.RDS, cluster documentDBCluster, dryRun bool
 	return nil
 }
 
-func DeleteExpiredDocumentDBClusters(sessions AWSSessions, options AwsOptions) {
-	region := *sessions.RDS.Config.Region
-	expiredClusters := listExpiredDocumentDBClusters(*sessions.RDS, &options)
-
-	count, start := common.ElemToDeleteFormattedInfos("expired DocumentDB database", len(expiredClusters), region)
-
-	log.Info(count)
+type documentDBClusterCleaner struct {
+	awsCleaner
+}
 
-	if options.DryRun || len(expiredClusters) == 0 {
-		return
+func (c documentDBClusterCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	clusters, err := getDBClusters(ctx, *c.sessions.RDS, c.options.TagName)
+	if err != nil {
+		return nil, err
 	}
 
-	log.Info(start)
-
-	for _, cluster := range expiredClusters {
-		DeleteRDSSubnetGroup(*sessions.RDS, cluster.SubnetGroupName)
-		deletionErr := deleteDocumentDBCluster(*sessions.RDS, cluster, options.DryRun)
-		if deletionErr != nil {
-			log.Errorf("Deletion DocumentDB cluster error %s in %s: %s",
-				cluster.Identifier, region, deletionErr.Error())
-		}
+	var resources []common.CloudProviderResource
+	for _, cluster := range clusters {
+		resource := cluster.CloudProviderResource
+		resource.Payload = cluster
+		resources = append(resources, resource)
 	}
+
+	return resources, nil
 }
 
-func listClusterSnapshots(svc rds.RDS) []*rds.DBClusterSnapshot {
-	result, err := svc.DescribeDBClusterSnapshots(&rds.DescribeDBClusterSnapshotsInput{SnapshotType: aws.String("manual")})
+func (c documentDBClusterCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	cluster := resource.Payload.(documentDBCluster)
+
+	DeleteRDSSubnetGroup(ctx, *c.sessions.RDS, cluster.SubnetGroupName)
+	return deleteDocumentDBCluster(ctx, *c.sessions.RDS, cluster, c.options.DryRun)
+}
 
+func listClusterSnapshots(ctx context.Context, svc rds.RDS) ([]*rds.DBClusterSnapshot, error) {
+	var snapshots []*rds.DBClusterSnapshot
+	err := svc.DescribeDBClusterSnapshotsPagesWithContext(ctx, &rds.DescribeDBClusterSnapshotsInput{SnapshotType: aws.String("manual")}, func(page *rds.DescribeDBClusterSnapshotsOutput, lastPage bool) bool {
+		snapshots = append(snapshots, page.DBClusterSnapshots...)
+		return true
+	})
 	if err != nil {
-		log.Errorf("Can't list RDS snapshots in region %s: %s", *svc.Config.Region, err.Error())
+		return nil, fmt.Errorf("can't list RDS cluster snapshots: %w", err)
 	}
 
-	return result.DBClusterSnapshots
+	return snapshots, nil
 }
 
-func getExpiredClusterSnapshots(svc rds.RDS, options *AwsOptions) []*rds.DBClusterSnapshot {
-	dbs := listRDSDatabases(svc)
-	snaps := listClusterSnapshots(svc)
+func getExpiredClusterSnapshots(ctx context.Context, svc rds.RDS, options *AwsOptions) ([]*rds.DBClusterSnapshot, error) {
+	dbs, err := listRDSDatabases(ctx, svc)
+	if err != nil {
+		return nil, err
+	}
+	snaps, err := listClusterSnapshots(ctx, svc)
+	if err != nil {
+		return nil, err
+	}
 
 	expiredSnaps := []*rds.DBClusterSnapshot{}
 
@@ -156,7 +170,7 @@ func getExpiredClusterSnapshots(svc rds.RDS, options *AwsOptions) []*rds.DBClust
 			}
 		}
 
-		return expiredSnaps
+		return expiredSnaps, nil
 	}
 
 	snapsChecking := make(map[string]*rds.DBClusterSnapshot)
@@ -178,34 +192,38 @@ func getExpiredClusterSnapshots(svc rds.RDS, options *AwsOptions) []*rds.DBClust
 		}
 	}
 
-	return expiredSnaps
+	return expiredSnaps, nil
 }
 
-func deleteClusterSnapshot(svc rds.RDS, snapName string) {
-	_, err := svc.DeleteDBClusterSnapshot(&rds.DeleteDBClusterSnapshotInput{DBClusterSnapshotIdentifier: aws.String(snapName)})
+func deleteClusterSnapshot(ctx context.Context, svc rds.RDS, snapName string) error {
+	_, err := svc.DeleteDBClusterSnapshotWithContext(ctx, &rds.DeleteDBClusterSnapshotInput{DBClusterSnapshotIdentifier: aws.String(snapName)})
 
-	if err != nil {
-		log.Errorf("Can't delete RDS snapshot %s in region %s: %s", snapName, *svc.Config.Region, err.Error())
-	} else {
-		log.Debugf("RDS snapshot %s in %s deleted.", snapName, *svc.Config.Region)
-	}
+	return err
 }
 
-func DeleteExpiredClusterSnapshots(sessions AWSSessions, options AwsOptions) {
-	expiredSnapshots := getExpiredClusterSnapshots(*sessions.RDS, &options)
-	region := *sessions.RDS.Config.Region
-
-	count, start := common.ElemToDeleteFormattedInfos("expired RDS cluster snapshot", len(expiredSnapshots), region)
+type documentDBClusterSnapshotCleaner struct {
+	awsCleaner
+	common.AlwaysEvaluator
+}
 
-	log.Info(count)
+func (c documentDBClusterSnapshotCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	snapshots, err := getExpiredClusterSnapshots(ctx, *c.sessions.RDS, &c.options)
+	if err != nil {
+		return nil, err
+	}
 
-	if options.DryRun || len(expiredSnapshots) == 0 || expiredSnapshots == nil {
-		return
+	var resources []common.CloudProviderResource
+	for _, snapshot := range snapshots {
+		resources = append(resources, common.CloudProviderResource{
+			Identifier:   *snapshot.DBClusterSnapshotIdentifier,
+			Description:  "RDS cluster snapshot: " + *snapshot.DBClusterSnapshotIdentifier,
+			CreationDate: aws.TimeValue(snapshot.SnapshotCreateTime),
+		})
 	}
 
-	log.Info(start)
+	return resources, nil
+}
 
-	for _, snapshot := range expiredSnapshots {
-		deleteClusterSnapshot(*sessions.RDS, *snapshot.DBClusterSnapshotIdentifier)
-	}
+func (c documentDBClusterSnapshotCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	return deleteClusterSnapshot(ctx, *c.sessions.RDS, resource.Identifier)
 }
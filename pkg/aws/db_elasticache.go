@@ -1,6 +1,8 @@
 package aws
 
 import (
+	"context"
+	"fmt"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -19,33 +21,52 @@ type elasticacheCluster struct {
 	SubnetGroup        string
 }
 
+func init() {
+	registerRegionalCleaner("elasticache", "elasticache-cluster", "expired Elasticache database", func(base awsCleaner) common.Cleaner {
+		return elasticacheClusterCleaner{base}
+	})
+	registerRegionalCleaner("elasticache", "elasticache-subnet-group", "unliked Elasticache subnet group", func(base awsCleaner) common.Cleaner {
+		return elasticacheSubnetGroupCleaner{awsCleaner: base}
+	})
+	registerRegionalCleaner("elasticache", "elasticache-snapshot", "expired Elasticache snapshot", func(base awsCleaner) common.Cleaner {
+		return elasticacheSnapshotCleaner{awsCleaner: base}
+	})
+}
+
 func ElasticacheSession(sess session.Session, region string) *elasticache.ElastiCache {
 	return elasticache.New(&sess, &aws.Config{Region: aws.String(region)})
 }
 
-func listTaggedElasticacheDatabases(svc elasticache.ElastiCache, tagName string) ([]elasticacheCluster, error) {
+func listTaggedElasticacheDatabases(ctx context.Context, svc elasticache.ElastiCache, tagName string, tagIndex *taggedResources) ([]elasticacheCluster, error) {
 	var taggedClusters []elasticacheCluster
 
-	result, err := svc.DescribeCacheClusters(nil)
+	var clusters []*elasticache.CacheCluster
+	err := svc.DescribeCacheClustersPagesWithContext(ctx, &elasticache.DescribeCacheClustersInput{}, func(page *elasticache.DescribeCacheClustersOutput, lastPage bool) bool {
+		clusters = append(clusters, page.CacheClusters...)
+		return true
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	if len(result.CacheClusters) == 0 {
-		return nil, nil
-	}
-
-	for _, cluster := range result.CacheClusters {
-		tags, err := svc.ListTagsForResource(
-			&elasticache.ListTagsForResourceInput{
-				ResourceName: aws.String(*cluster.ARN),
-			},
-		)
-		if err != nil {
-			if *cluster.CacheClusterStatus == "available" {
-				log.Errorf("Can't get tags for Elasticache cluster: %s", *cluster.CacheClusterId)
+	for _, cluster := range clusters {
+		var tags interface{}
+		if indexedTags, ok := tagIndex.lookup(aws.StringValue(cluster.ARN)); ok {
+			tags = indexedTags
+		} else {
+			output, err := svc.ListTagsForResourceWithContext(ctx,
+				&elasticache.ListTagsForResourceInput{
+					ResourceName: aws.String(*cluster.ARN),
+				},
+			)
+			if err != nil {
+				// the tags of clusters being created or deleted can't be read
+				if *cluster.CacheClusterStatus == "available" {
+					return nil, fmt.Errorf("can't get tags for Elasticache cluster %s: %w", *cluster.CacheClusterId, err)
+				}
+				continue
 			}
-			continue
+			tags = output.TagList
 		}
 
 		// required for replicas deletion
@@ -54,7 +75,7 @@ func listTaggedElasticacheDatabases(svc elasticache.ElastiCache, tagName string)
 			replicationGroupId = *cluster.ReplicationGroupId
 		}
 
-		essentialTags := common.GetEssentialTags(tags.TagList, tagName)
+		essentialTags := common.GetEssentialTags(tags, tagName)
 		time, _ := time.Parse(time.RFC3339, cluster.CacheClusterCreateTime.Format(time.RFC3339))
 		taggedClusters = append(taggedClusters, elasticacheCluster{
 			CloudProviderResource: common.CloudProviderResource{
@@ -64,6 +85,9 @@ func listTaggedElasticacheDatabases(svc elasticache.ElastiCache, tagName string)
 				TTL:          essentialTags.TTL,
 				Tag:          essentialTags.Tag,
 				IsProtected:  essentialTags.IsProtected,
+				ExpiresAt:    essentialTags.ExpiresAt,
+				Warnings:     essentialTags.Warnings,
+				Tags:         essentialTags.Tags,
 			},
 			ReplicationGroupId: replicationGroupId,
 			ClusterStatus:      *cluster.CacheClusterStatus,
@@ -75,10 +99,9 @@ func listTaggedElasticacheDatabases(svc elasticache.ElastiCache, tagName string)
 	return taggedClusters, nil
 }
 
-func deleteElasticacheCluster(svc elasticache.ElastiCache, cluster elasticacheCluster) error {
+func deleteElasticacheCluster(ctx context.Context, svc elasticache.ElastiCache, cluster elasticacheCluster) error {
 	if cluster.ClusterStatus == "deleting" {
-		log.Infof("Elasticache cluster %s is already in deletion process, skipping...", cluster.Identifier)
-		return nil
+		return common.NewDeletionInProgressError("Elasticache cluster %s is already in deletion process", cluster.Identifier)
 	} else {
 		log.Infof("Deleting Elasticache cluster %s in %s, expired after %d seconds",
 			cluster.Identifier, *svc.Config.Region, cluster.TTL)
@@ -86,7 +109,7 @@ func deleteElasticacheCluster(svc elasticache.ElastiCache, cluster elasticacheCl
 
 	// with replicas
 	if cluster.ReplicationGroupId != "" {
-		_, err := svc.DeleteReplicationGroup(
+		_, err := svc.DeleteReplicationGroupWithContext(ctx,
 			&elasticache.DeleteReplicationGroupInput{
 				ReplicationGroupId:   aws.String(cluster.ReplicationGroupId),
 				RetainPrimaryCluster: aws.Bool(false),
@@ -97,7 +120,7 @@ func deleteElasticacheCluster(svc elasticache.ElastiCache, cluster elasticacheCl
 		}
 	}
 
-	_, err := svc.DeleteCacheCluster(
+	_, err := svc.DeleteCacheClusterWithContext(ctx,
 		&elasticache.DeleteCacheClusterInput{
 			CacheClusterId: aws.String(cluster.Identifier),
 		},
@@ -109,47 +132,35 @@ func deleteElasticacheCluster(svc elasticache.ElastiCache, cluster elasticacheCl
 	return nil
 }
 
-func getExpiredClusters(ECsession *elasticache.ElastiCache, options *AwsOptions) ([]elasticacheCluster, string) {
-	clusters, err := listTaggedElasticacheDatabases(*ECsession, options.TagName)
-	region := *ECsession.Config.Region
+type elasticacheClusterCleaner struct {
+	awsCleaner
+}
+
+func (c elasticacheClusterCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	clusters, err := listTaggedElasticacheDatabases(ctx, *c.sessions.ElastiCache, c.options.TagName, c.options.tagIndex)
 	if err != nil {
-		log.Errorf("Can't list Elasticache databases in region %s: %s", region, err.Error())
+		return nil, err
 	}
 
-	var expiredClusters []elasticacheCluster
+	var resources []common.CloudProviderResource
 	for _, cluster := range clusters {
-		if cluster.IsResourceExpired(options.TagValue, options.DisableTTLCheck) {
-			expiredClusters = append(expiredClusters, cluster)
-		}
+		resource := cluster.CloudProviderResource
+		resource.Payload = cluster
+		resources = append(resources, resource)
 	}
 
-	return expiredClusters, region
+	return resources, nil
 }
 
-func DeleteExpiredElasticacheDatabases(sessions AWSSessions, options AwsOptions) {
-	expiredClusters, region := getExpiredClusters(sessions.ElastiCache, &options)
-
-	count, start := common.ElemToDeleteFormattedInfos("expired Elasticache database", len(expiredClusters), region)
+func (c elasticacheClusterCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	cluster := resource.Payload.(elasticacheCluster)
 
-	log.Info(count)
-
-	if options.DryRun || len(expiredClusters) == 0 {
-		return
-	}
-
-	log.Info(start)
-
-	for _, cluster := range expiredClusters {
-		deleteECSubnetGroups(sessions.ElastiCache, cluster.SubnetGroup)
-		deletionErr := deleteElasticacheCluster(*sessions.ElastiCache, cluster)
-		if deletionErr != nil {
-			log.Errorf("Deletion Elasticache cluster error %s/%s: %s", cluster.Identifier, region, deletionErr.Error())
-		}
-	}
+	_ = deleteECSubnetGroups(ctx, c.sessions.ElastiCache, cluster.SubnetGroup)
+	return deleteElasticacheCluster(ctx, *c.sessions.ElastiCache, cluster)
 }
 
-func deleteECSubnetGroups(ECsession *elasticache.ElastiCache, subnetGroupName string) {
-	_, err := ECsession.DeleteCacheSubnetGroup(
+func deleteECSubnetGroups(ctx context.Context, ECsession *elasticache.ElastiCache, subnetGroupName string) error {
+	_, err := ECsession.DeleteCacheSubnetGroupWithContext(ctx,
 		&elasticache.DeleteCacheSubnetGroupInput{
 			CacheSubnetGroupName: aws.String(subnetGroupName),
 		},
@@ -160,22 +171,35 @@ func deleteECSubnetGroups(ECsession *elasticache.ElastiCache, subnetGroupName st
 	} else {
 		log.Debugf("elasticache subnet group %s in %s deleted.", subnetGroupName, *ECsession.Config.Region)
 	}
-}
 
-func getECSubnetGroups(ECsession *elasticache.ElastiCache) []*elasticache.CacheSubnetGroup {
-	result, err := ECsession.DescribeCacheSubnetGroups(
-		&elasticache.DescribeCacheSubnetGroupsInput{})
+	return err
+}
 
+func getECSubnetGroups(ctx context.Context, ECsession *elasticache.ElastiCache) ([]*elasticache.CacheSubnetGroup, error) {
+	var subnetGroups []*elasticache.CacheSubnetGroup
+	err := ECsession.DescribeCacheSubnetGroupsPagesWithContext(ctx,
+		&elasticache.DescribeCacheSubnetGroupsInput{}, func(page *elasticache.DescribeCacheSubnetGroupsOutput, lastPage bool) bool {
+			subnetGroups = append(subnetGroups, page.CacheSubnetGroups...)
+			return true
+		})
 	if err != nil {
-		log.Errorf("Can't list elasticache subnet groups: %s", err.Error())
+		return nil, fmt.Errorf("can't list Elasticache subnet groups: %w", err)
 	}
 
-	return result.CacheSubnetGroups
+	return subnetGroups, nil
 }
 
-func getUnlinkedSubnetGroupNames(ECsession *elasticache.ElastiCache, ec2Session *ec2.EC2) []string {
-	subnetGroups := getECSubnetGroups(ECsession)
-	VPCs := GetAllVPCs(ec2Session)
+// getUnlinkedSubnetGroupNames returns the subnet groups of VPCs which don't exist anymore, the subnet groups and the
+// VPCs have both to be listed.
+func getUnlinkedSubnetGroupNames(ctx context.Context, ECsession *elasticache.ElastiCache, ec2Session *ec2.EC2) ([]string, error) {
+	subnetGroups, err := getECSubnetGroups(ctx, ECsession)
+	if err != nil {
+		return nil, err
+	}
+	VPCs, err := GetAllVPCs(ctx, ec2Session)
+	if err != nil {
+		return nil, err
+	}
 
 	comp := make(map[string]*string)
 
@@ -194,43 +218,57 @@ func getUnlinkedSubnetGroupNames(ECsession *elasticache.ElastiCache, ec2Session
 		}
 	}
 
-	return unlinkedSubenetGroupNames
+	return unlinkedSubenetGroupNames, nil
 }
 
-func DeleteUnlinkedECSubnetGroups(sessions AWSSessions, options AwsOptions) {
-	unlinkedSubnetGroupNames := getUnlinkedSubnetGroupNames(sessions.ElastiCache, sessions.EC2)
-
-	count, start := common.ElemToDeleteFormattedInfos("unliked Elasticache subnet group", len(unlinkedSubnetGroupNames), *sessions.ElastiCache.Config.Region)
-
-	log.Info(count)
+type elasticacheSubnetGroupCleaner struct {
+	awsCleaner
+	common.AlwaysEvaluator
+}
 
-	if options.DryRun || len(unlinkedSubnetGroupNames) == 0 {
-		return
+func (c elasticacheSubnetGroupCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	subnetGroupNames, err := getUnlinkedSubnetGroupNames(ctx, c.sessions.ElastiCache, c.sessions.EC2)
+	if err != nil {
+		return nil, err
 	}
 
-	log.Info(start)
-
-	for _, unlinkedSubnetGroupName := range unlinkedSubnetGroupNames {
-		deleteECSubnetGroups(sessions.ElastiCache, unlinkedSubnetGroupName)
+	var resources []common.CloudProviderResource
+	for _, subnetGroupName := range subnetGroupNames {
+		resources = append(resources, common.CloudProviderResource{
+			Identifier:  subnetGroupName,
+			Description: "Elasticache subnet group: " + subnetGroupName,
+		})
 	}
+
+	return resources, nil
 }
 
-func listElasticacheSnapshots(svc elasticache.ElastiCache) []*elasticache.Snapshot {
-	result, err := svc.DescribeSnapshots(&elasticache.DescribeSnapshotsInput{})
+func (c elasticacheSubnetGroupCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	return deleteECSubnetGroups(ctx, c.sessions.ElastiCache, resource.Identifier)
+}
 
+func listElasticacheSnapshots(ctx context.Context, svc elasticache.ElastiCache) ([]*elasticache.Snapshot, error) {
+	var snapshots []*elasticache.Snapshot
+	err := svc.DescribeSnapshotsPagesWithContext(ctx, &elasticache.DescribeSnapshotsInput{}, func(page *elasticache.DescribeSnapshotsOutput, lastPage bool) bool {
+		snapshots = append(snapshots, page.Snapshots...)
+		return true
+	})
 	if err != nil {
-		log.Errorf("Can't list Elasticache snapshots in region %s: %s", *svc.Config.Region, err.Error())
+		return nil, fmt.Errorf("can't list Elasticache snapshots: %w", err)
 	}
 
-	return result.Snapshots
+	return snapshots, nil
 }
 
-func getExpiredElasticacheSnapshots(svc elasticache.ElastiCache, options *AwsOptions) []*elasticache.Snapshot {
-	dbs, err := listTaggedElasticacheDatabases(svc, options.TagName)
+func getExpiredElasticacheSnapshots(ctx context.Context, svc elasticache.ElastiCache, options *AwsOptions) ([]*elasticache.Snapshot, error) {
+	dbs, err := listTaggedElasticacheDatabases(ctx, svc, options.TagName, options.tagIndex)
 	if err != nil {
-		log.Errorf("Can't list Elasticache databases in region %s: %s", *svc.Config.Region, err.Error())
+		return nil, fmt.Errorf("can't list Elasticache databases: %w", err)
+	}
+	snaps, err := listElasticacheSnapshots(ctx, svc)
+	if err != nil {
+		return nil, err
 	}
-	snaps := listElasticacheSnapshots(svc)
 
 	expiredSnaps := []*elasticache.Snapshot{}
 
@@ -242,7 +280,7 @@ func getExpiredElasticacheSnapshots(svc elasticache.ElastiCache, options *AwsOpt
 			}
 		}
 
-		return expiredSnaps
+		return expiredSnaps, nil
 	}
 
 	snapsChecking := make(map[string]*elasticache.Snapshot)
@@ -262,34 +300,38 @@ func getExpiredElasticacheSnapshots(svc elasticache.ElastiCache, options *AwsOpt
 		}
 	}
 
-	return expiredSnaps
+	return expiredSnaps, nil
 }
 
-func deleteElasticacheSnapshot(svc elasticache.ElastiCache, snapName string) {
-	_, err := svc.DeleteSnapshot(&elasticache.DeleteSnapshotInput{SnapshotName: aws.String(snapName)})
+func deleteElasticacheSnapshot(ctx context.Context, svc elasticache.ElastiCache, snapName string) error {
+	_, err := svc.DeleteSnapshotWithContext(ctx, &elasticache.DeleteSnapshotInput{SnapshotName: aws.String(snapName)})
 
-	if err != nil {
-		log.Errorf("Can't delete Elasticache snapshot %s in region %s: %s", snapName, *svc.Config.Region, err.Error())
-	} else {
-		log.Debugf("Elasticache snapshot %s in %s deleted.", snapName, *svc.Config.Region)
-	}
+	return err
 }
 
-func DeleteExpiredElasticacheSnapshots(sessions AWSSessions, options AwsOptions) {
-	expiredSnapshots := getExpiredElasticacheSnapshots(*sessions.ElastiCache, &options)
-	region := *sessions.ElastiCache.Config.Region
-
-	count, start := common.ElemToDeleteFormattedInfos("expired Elasticache snapshot", len(expiredSnapshots), region)
+type elasticacheSnapshotCleaner struct {
+	awsCleaner
+	common.AlwaysEvaluator
+}
 
-	log.Info(count)
+func (c elasticacheSnapshotCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	snapshots, err := getExpiredElasticacheSnapshots(ctx, *c.sessions.ElastiCache, &c.options)
+	if err != nil {
+		return nil, err
+	}
 
-	if options.DryRun || len(expiredSnapshots) == 0 || expiredSnapshots == nil {
-		return
+	var resources []common.CloudProviderResource
+	for _, snapshot := range snapshots {
+		resources = append(resources, common.CloudProviderResource{
+			Identifier:   *snapshot.SnapshotName,
+			Description:  "Elasticache snapshot: " + *snapshot.SnapshotName,
+			CreationDate: aws.TimeValue(snapshot.CacheClusterCreateTime),
+		})
 	}
 
-	log.Info(start)
+	return resources, nil
+}
 
-	for _, snapshot := range expiredSnapshots {
-		deleteElasticacheSnapshot(*sessions.ElastiCache, *snapshot.SnapshotName)
-	}
+func (c elasticacheSnapshotCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	return deleteElasticacheSnapshot(ctx, *c.sessions.ElastiCache, resource.Identifier)
 }
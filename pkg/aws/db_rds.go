@@ -1,6 +1,8 @@
 package aws
 
 import (
+	"context"
+	"fmt"
 	"strings"
 	"time"
 
@@ -24,29 +26,45 @@ type RDSSubnetGroup struct {
 	ID string
 }
 
+func init() {
+	registerRegionalCleaner("rds", "rds-database", "expired RDS database", func(base awsCleaner) common.Cleaner {
+		return rdsDatabaseCleaner{base}
+	})
+	registerRegionalCleaner("rds", "rds-subnet-group", "expired RDS subnet group", func(base awsCleaner) common.Cleaner {
+		return rdsSubnetGroupCleaner{base}
+	})
+	registerRegionalCleaner("rds", "rds-parameter-group", "expired RDS Parameter Group", func(base awsCleaner) common.Cleaner {
+		return rdsParameterGroupCleaner{base}
+	})
+	registerRegionalCleaner("rds", "rds-snapshot", "expired RDS snapshot", func(base awsCleaner) common.Cleaner {
+		return rdsSnapshotCleaner{awsCleaner: base}
+	})
+}
+
 func RdsSession(sess session.Session, region string) *rds.RDS {
 	return rds.New(&sess, &aws.Config{Region: aws.String(region)})
 }
 
-func listRDSDatabases(svc rds.RDS) []*rds.DBInstance {
-	result, err := svc.DescribeDBInstances(&rds.DescribeDBInstancesInput{})
-
+func listRDSDatabases(ctx context.Context, svc rds.RDS) ([]*rds.DBInstance, error) {
+	var databases []*rds.DBInstance
+	err := svc.DescribeDBInstancesPagesWithContext(ctx, &rds.DescribeDBInstancesInput{}, func(page *rds.DescribeDBInstancesOutput, lastPage bool) bool {
+		databases = append(databases, page.DBInstances...)
+		return true
+	})
 	if err != nil {
-		log.Errorf("Can't get RDS databases in %s: %s", *svc.Config.Region, err.Error())
-		return []*rds.DBInstance{}
+		return nil, fmt.Errorf("can't get RDS databases: %w", err)
 	}
 
-	return result.DBInstances
+	return databases, nil
 }
 
-func listExpiredRDSDatabases(svc rds.RDS, options *AwsOptions) []rdsDatabase {
-	dbs := listRDSDatabases(svc)
-
-	if len(dbs) == 0 {
-		return nil
+func listTaggedRDSDatabases(ctx context.Context, svc rds.RDS, options *AwsOptions) ([]rdsDatabase, error) {
+	dbs, err := listRDSDatabases(ctx, svc)
+	if err != nil {
+		return nil, err
 	}
 
-	var expiredDatabases []rdsDatabase
+	var databases []rdsDatabase
 
 	for _, instance := range dbs {
 		if *instance.DBInstanceStatus == "deleting" {
@@ -66,6 +84,11 @@ func listExpiredRDSDatabases(svc rds.RDS, options *AwsOptions) []rdsDatabase {
 		essentialTags := common.GetEssentialTags(instance.TagList, options.TagName)
 		time, _ := time.Parse(time.RFC3339, instance.InstanceCreateTime.Format(time.RFC3339))
 
+		var vpcId string
+		if instance.DBSubnetGroup != nil {
+			vpcId = aws.StringValue(instance.DBSubnetGroup.VpcId)
+		}
+
 		if instance.DBInstanceIdentifier != nil {
 			database := rdsDatabase{
 				CloudProviderResource: common.CloudProviderResource{
@@ -75,30 +98,31 @@ func listExpiredRDSDatabases(svc rds.RDS, options *AwsOptions) []rdsDatabase {
 					TTL:          essentialTags.TTL,
 					Tag:          essentialTags.Tag,
 					IsProtected:  essentialTags.IsProtected,
+					ExpiresAt:    essentialTags.ExpiresAt,
+					Warnings:     essentialTags.Warnings,
+					Tags:         essentialTags.Tags,
+					VpcId:        vpcId,
 				},
 				DBInstanceStatus: *instance.DBInstanceStatus,
 				SubnetGroup:      instance.DBSubnetGroup,
 				ParameterGroups:  instance.DBParameterGroups,
 			}
-			if database.CloudProviderResource.IsResourceExpired(options.TagValue, options.DisableTTLCheck) {
-				expiredDatabases = append(expiredDatabases, database)
-			}
+			databases = append(databases, database)
 		}
 	}
 
-	return expiredDatabases
+	return databases, nil
 }
 
-func DeleteRDSDatabase(svc rds.RDS, database rdsDatabase) {
+func DeleteRDSDatabase(ctx context.Context, svc rds.RDS, database rdsDatabase) error {
 	if database.DBInstanceStatus == "deleting" {
-		log.Infof("RDS instance %s is already in deletion process, skipping...", database.Identifier)
-		return
+		return common.NewDeletionInProgressError("RDS instance %s is already in deletion process", database.Identifier)
 	} else {
 		log.Infof("Deleting RDS database %s in %s, expired after %d seconds",
 			database.Identifier, *svc.Config.Region, database.TTL)
 	}
 
-	_, instanceErr := svc.DeleteDBInstance(
+	_, instanceErr := svc.DeleteDBInstanceWithContext(ctx,
 		&rds.DeleteDBInstanceInput{
 			DBInstanceIdentifier:   aws.String(database.Identifier),
 			DeleteAutomatedBackups: aws.Bool(true),
@@ -107,21 +131,24 @@ func DeleteRDSDatabase(svc rds.RDS, database rdsDatabase) {
 	)
 	if instanceErr != nil {
 		log.Errorf("Can't delete RDS instance %s in %s: %s", database.Identifier, *svc.Config.Region, instanceErr.Error())
-	} else {
-		DeleteRDSSubnetGroup(svc, *database.SubnetGroup.DBSubnetGroupName)
+		return instanceErr
+	}
 
-		for _, parameterGroup := range database.ParameterGroups {
-			deleteRDSParameterGroups(svc, *parameterGroup.DBParameterGroupName)
-		}
+	DeleteRDSSubnetGroup(ctx, svc, *database.SubnetGroup.DBSubnetGroupName)
+
+	for _, parameterGroup := range database.ParameterGroups {
+		deleteRDSParameterGroups(ctx, svc, *parameterGroup.DBParameterGroupName)
 	}
+
+	return nil
 }
 
-func GetRDSInstanceInfos(svc rds.RDS, databaseIdentifier string) (rdsDatabase, error) {
+func GetRDSInstanceInfos(ctx context.Context, svc rds.RDS, databaseIdentifier string) (rdsDatabase, error) {
 	input := rds.DescribeDBInstancesInput{
 		DBInstanceIdentifier: aws.String(databaseIdentifier),
 	}
 
-	result, err := svc.DescribeDBInstances(&input)
+	result, err := svc.DescribeDBInstancesWithContext(ctx, &input)
 	// ignore if creation is in progress to avoid nil fields
 	if err != nil || *result.DBInstances[0].DBInstanceStatus == "creating" {
 		return rdsDatabase{
@@ -152,27 +179,32 @@ func GetRDSInstanceInfos(svc rds.RDS, databaseIdentifier string) (rdsDatabase, e
 	}, nil
 }
 
-func DeleteExpiredRDSDatabases(sessions AWSSessions, options AwsOptions) {
-	expiredDatabases := listExpiredRDSDatabases(*sessions.RDS, &options)
-	region := *sessions.RDS.Config.Region
-
-	count, start := common.ElemToDeleteFormattedInfos("expired RDS database", len(expiredDatabases), region)
+type rdsDatabaseCleaner struct {
+	awsCleaner
+}
 
-	log.Info(count)
+func (c rdsDatabaseCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	databases, err := listTaggedRDSDatabases(ctx, *c.sessions.RDS, &c.options)
+	if err != nil {
+		return nil, err
+	}
 
-	if options.DryRun || len(expiredDatabases) == 0 || expiredDatabases == nil {
-		return
+	var resources []common.CloudProviderResource
+	for _, database := range databases {
+		resource := database.CloudProviderResource
+		resource.Payload = database
+		resources = append(resources, resource)
 	}
 
-	log.Info(start)
+	return resources, nil
+}
 
-	for _, database := range expiredDatabases {
-		DeleteRDSDatabase(*sessions.RDS, database)
-	}
+func (c rdsDatabaseCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	return DeleteRDSDatabase(ctx, *c.sessions.RDS, resource.Payload.(rdsDatabase))
 }
 
-func DeleteRDSSubnetGroup(svc rds.RDS, dbSubnetGroupName string) {
-	_, err := svc.DeleteDBSubnetGroup(
+func DeleteRDSSubnetGroup(ctx context.Context, svc rds.RDS, dbSubnetGroupName string) error {
+	_, err := svc.DeleteDBSubnetGroupWithContext(ctx,
 		&rds.DeleteDBSubnetGroupInput{
 			DBSubnetGroupName: aws.String(dbSubnetGroupName),
 		})
@@ -180,10 +212,12 @@ func DeleteRDSSubnetGroup(svc rds.RDS, dbSubnetGroupName string) {
 	if err != nil {
 		log.Errorf("Can't delete RDS Subnet Group %s in region %s: %s", dbSubnetGroupName, *svc.Config.Region, err.Error())
 	}
+
+	return err
 }
 
-func deleteRDSParameterGroups(svc rds.RDS, dbParameterGroupName string) {
-	_, err := svc.DeleteDBParameterGroup(
+func deleteRDSParameterGroups(ctx context.Context, svc rds.RDS, dbParameterGroupName string) error {
+	_, err := svc.DeleteDBParameterGroupWithContext(ctx,
 		&rds.DeleteDBParameterGroupInput{
 			DBParameterGroupName: aws.String(dbParameterGroupName),
 		})
@@ -191,36 +225,51 @@ func deleteRDSParameterGroups(svc rds.RDS, dbParameterGroupName string) {
 	if err != nil {
 		log.Errorf("Can't delete RDS parameter group %s in region %s: %s", dbParameterGroupName, *svc.Config.Region, err.Error())
 	}
-}
 
-func listRDSSubnetGroups(svc rds.RDS) []*rds.DBSubnetGroup {
-	result, err := svc.DescribeDBSubnetGroups(
-		&rds.DescribeDBSubnetGroupsInput{})
+	return err
+}
 
+func listRDSSubnetGroups(ctx context.Context, svc rds.RDS) ([]*rds.DBSubnetGroup, error) {
+	var subnetGroups []*rds.DBSubnetGroup
+	err := svc.DescribeDBSubnetGroupsPagesWithContext(ctx,
+		&rds.DescribeDBSubnetGroupsInput{}, func(page *rds.DescribeDBSubnetGroupsOutput, lastPage bool) bool {
+			subnetGroups = append(subnetGroups, page.DBSubnetGroups...)
+			return true
+		})
 	if err != nil {
-		log.Errorf("Can't list RDS subnet groups in region %s: %s", *svc.Config.Region, err.Error())
+		return nil, fmt.Errorf("can't list RDS subnet groups: %w", err)
 	}
 
-	return result.DBSubnetGroups
+	return subnetGroups, nil
 }
 
-func getRDSSubnetGroupTags(svc rds.RDS, subnetGroupName string) []*rds.Tag {
-	result, err := svc.ListTagsForResource(
+func getRDSSubnetGroupTags(ctx context.Context, svc rds.RDS, subnetGroupName string) ([]*rds.Tag, error) {
+	result, err := svc.ListTagsForResourceWithContext(ctx,
 		&rds.ListTagsForResourceInput{ResourceName: aws.String(subnetGroupName)})
-
 	if err != nil {
-		log.Errorf("Can't get RDS subnet groups tags for %s: %s", subnetGroupName, err.Error())
+		return nil, fmt.Errorf("can't get RDS subnet group tags for %s: %w", subnetGroupName, err)
 	}
 
-	return result.TagList
+	return result.TagList, nil
 }
 
-func getExpiredRDSSubnetGroups(svc rds.RDS, options *AwsOptions) []RDSSubnetGroup {
-	SGs := listRDSSubnetGroups(svc)
+func getRDSSubnetGroups(ctx context.Context, svc rds.RDS, options *AwsOptions) ([]RDSSubnetGroup, error) {
+	SGs, err := listRDSSubnetGroups(ctx, svc)
+	if err != nil {
+		return nil, err
+	}
 
-	expiredRDSSubnetGroups := []RDSSubnetGroup{}
+	rdsSubnetGroups := []RDSSubnetGroup{}
 	for _, SG := range SGs {
-		tags := getRDSSubnetGroupTags(svc, *SG.DBSubnetGroupArn)
+		var tags interface{}
+		if indexedTags, ok := options.tagIndex.lookup(aws.StringValue(SG.DBSubnetGroupArn)); ok {
+			tags = indexedTags
+		} else {
+			tags, err = getRDSSubnetGroupTags(ctx, svc, *SG.DBSubnetGroupArn)
+			if err != nil {
+				return nil, err
+			}
+		}
 		essentialTags := common.GetEssentialTags(tags, options.TagName)
 		rDSSubnetGroup := RDSSubnetGroup{
 			CloudProviderResource: common.CloudProviderResource{
@@ -230,34 +279,39 @@ func getExpiredRDSSubnetGroups(svc rds.RDS, options *AwsOptions) []RDSSubnetGrou
 				TTL:          essentialTags.TTL,
 				Tag:          essentialTags.Tag,
 				IsProtected:  essentialTags.IsProtected,
+				ExpiresAt:    essentialTags.ExpiresAt,
+				Warnings:     essentialTags.Warnings,
+				Tags:         essentialTags.Tags,
+				VpcId:        aws.StringValue(SG.VpcId),
 			},
 			ID: *SG.DBSubnetGroupArn,
 		}
-		if rDSSubnetGroup.IsResourceExpired(options.TagValue, options.DisableTTLCheck) {
-			expiredRDSSubnetGroups = append(expiredRDSSubnetGroups, rDSSubnetGroup)
-		}
+		rdsSubnetGroups = append(rdsSubnetGroups, rDSSubnetGroup)
 	}
 
-	return expiredRDSSubnetGroups
+	return rdsSubnetGroups, nil
 }
 
-func DeleteExpiredRDSSubnetGroups(sessions AWSSessions, options AwsOptions) {
-	region := *sessions.RDS.Config.Region
-	expiredRDSSubnetGroups := getExpiredRDSSubnetGroups(*sessions.RDS, &options)
-
-	count, start := common.ElemToDeleteFormattedInfos("expired RDS subnet group", len(expiredRDSSubnetGroups), region)
+type rdsSubnetGroupCleaner struct {
+	awsCleaner
+}
 
-	log.Info(count)
+func (c rdsSubnetGroupCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	subnetGroups, err := getRDSSubnetGroups(ctx, *c.sessions.RDS, &c.options)
+	if err != nil {
+		return nil, err
+	}
 
-	if options.DryRun || len(expiredRDSSubnetGroups) == 0 {
-		return
+	var resources []common.CloudProviderResource
+	for _, subnetGroup := range subnetGroups {
+		resources = append(resources, subnetGroup.CloudProviderResource)
 	}
 
-	log.Info(start)
+	return resources, nil
+}
 
-	for _, expiredRDSSubnetGroup := range expiredRDSSubnetGroups {
-		DeleteRDSSubnetGroup(*sessions.RDS, expiredRDSSubnetGroup.Identifier)
-	}
+func (c rdsSubnetGroupCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	return DeleteRDSSubnetGroup(ctx, *c.sessions.RDS, resource.Identifier)
 }
 
 type RDSParameterGroups struct {
@@ -265,19 +319,24 @@ type RDSParameterGroups struct {
 	ID string
 }
 
-func listParametersGroups(svc rds.RDS) []*rds.DBParameterGroup {
-	results, err := svc.DescribeDBParameterGroups(&rds.DescribeDBParameterGroupsInput{})
-
+func listParametersGroups(ctx context.Context, svc rds.RDS) ([]*rds.DBParameterGroup, error) {
+	var parameterGroups []*rds.DBParameterGroup
+	err := svc.DescribeDBParameterGroupsPagesWithContext(ctx, &rds.DescribeDBParameterGroupsInput{}, func(page *rds.DescribeDBParameterGroupsOutput, lastPage bool) bool {
+		parameterGroups = append(parameterGroups, page.DBParameterGroups...)
+		return true
+	})
 	if err != nil {
-		log.Errorf("Can't get RDS Parameter Groups in %s: %s", *svc.Config.Region, err.Error())
-		return nil
+		return nil, fmt.Errorf("can't get RDS parameter groups: %w", err)
 	}
 
-	return results.DBParameterGroups
+	return parameterGroups, nil
 }
 
-func getCompleteRDSParameterGroups(svc rds.RDS, options *AwsOptions) []RDSParameterGroups {
-	results := listParametersGroups(svc)
+func getCompleteRDSParameterGroups(ctx context.Context, svc rds.RDS, options *AwsOptions) ([]RDSParameterGroups, error) {
+	results, err := listParametersGroups(ctx, svc)
+	if err != nil {
+		return nil, err
+	}
 
 	completeRDSParameterGroups := []RDSParameterGroups{}
 
@@ -287,14 +346,19 @@ func getCompleteRDSParameterGroups(svc rds.RDS, options *AwsOptions) []RDSParame
 			continue
 		}
 
-		tags, tagsErr := svc.ListTagsForResource(&rds.ListTagsForResourceInput{ResourceName: aws.String(*result.DBParameterGroupArn)})
+		var tags interface{}
+		if indexedTags, ok := options.tagIndex.lookup(aws.StringValue(result.DBParameterGroupArn)); ok {
+			tags = indexedTags
+		} else {
+			output, tagsErr := svc.ListTagsForResourceWithContext(ctx, &rds.ListTagsForResourceInput{ResourceName: aws.String(*result.DBParameterGroupArn)})
 
-		if tagsErr != nil {
-			log.Errorf("Can't get RDS Parameter Groups Tags in %s: %s", *svc.Config.Region, tagsErr.Error())
-			return completeRDSParameterGroups
+			if tagsErr != nil {
+				return nil, fmt.Errorf("can't get RDS parameter group tags for %s: %w", *result.DBParameterGroupName, tagsErr)
+			}
+			tags = output.TagList
 		}
 
-		essentialTags := common.GetEssentialTags(tags.TagList, options.TagName)
+		essentialTags := common.GetEssentialTags(tags, options.TagName)
 
 		completeRDSParameterGroups = append(completeRDSParameterGroups, RDSParameterGroups{
 			CloudProviderResource: common.CloudProviderResource{
@@ -304,59 +368,63 @@ func getCompleteRDSParameterGroups(svc rds.RDS, options *AwsOptions) []RDSParame
 				TTL:          essentialTags.TTL,
 				Tag:          essentialTags.Tag,
 				IsProtected:  essentialTags.IsProtected,
+				ExpiresAt:    essentialTags.ExpiresAt,
+				Warnings:     essentialTags.Warnings,
+				Tags:         essentialTags.Tags,
 			},
 			ID: *result.DBParameterGroupArn,
 		})
 	}
 
-	return completeRDSParameterGroups
+	return completeRDSParameterGroups, nil
 }
 
-func listExpiredCompleteRDSParameterGroups(svc rds.RDS, options *AwsOptions) []RDSParameterGroups {
-	completeRDSParameterGroups := getCompleteRDSParameterGroups(svc, options)
-	expiredCompleteRDSParameterGroups := []RDSParameterGroups{}
-
-	for _, item := range completeRDSParameterGroups {
-		if item.IsResourceExpired(options.TagValue, options.DisableTTLCheck) {
-			expiredCompleteRDSParameterGroups = append(expiredCompleteRDSParameterGroups, item)
-		}
-	}
-
-	return expiredCompleteRDSParameterGroups
+type rdsParameterGroupCleaner struct {
+	awsCleaner
 }
 
-func DeleteExpiredCompleteRDSParameterGroups(sessions AWSSessions, options AwsOptions) {
-	expiredRDSParameterGroups := listExpiredCompleteRDSParameterGroups(*sessions.RDS, &options)
-	region := *sessions.RDS.Config.Region
-
-	count, start := common.ElemToDeleteFormattedInfos("expired RDS Parameter Group", len(expiredRDSParameterGroups), region)
-
-	log.Info(count)
-
-	if options.DryRun || len(expiredRDSParameterGroups) == 0 || expiredRDSParameterGroups == nil {
-		return
+func (c rdsParameterGroupCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	parameterGroups, err := getCompleteRDSParameterGroups(ctx, *c.sessions.RDS, &c.options)
+	if err != nil {
+		return nil, err
 	}
 
-	log.Info(start)
-
-	for _, dbParameterGroup := range expiredRDSParameterGroups {
-		deleteRDSParameterGroups(*sessions.RDS, dbParameterGroup.Identifier)
+	var resources []common.CloudProviderResource
+	for _, parameterGroup := range parameterGroups {
+		resources = append(resources, parameterGroup.CloudProviderResource)
 	}
+
+	return resources, nil
 }
 
-func listSnapshots(svc rds.RDS) []*rds.DBSnapshot {
-	result, err := svc.DescribeDBSnapshots(&rds.DescribeDBSnapshotsInput{SnapshotType: aws.String("manual")})
+func (c rdsParameterGroupCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	return deleteRDSParameterGroups(ctx, *c.sessions.RDS, resource.Identifier)
+}
 
+func listSnapshots(ctx context.Context, svc rds.RDS) ([]*rds.DBSnapshot, error) {
+	var snapshots []*rds.DBSnapshot
+	err := svc.DescribeDBSnapshotsPagesWithContext(ctx, &rds.DescribeDBSnapshotsInput{SnapshotType: aws.String("manual")}, func(page *rds.DescribeDBSnapshotsOutput, lastPage bool) bool {
+		snapshots = append(snapshots, page.DBSnapshots...)
+		return true
+	})
 	if err != nil {
-		log.Errorf("Can't list RDS snapshots in region %s: %s", *svc.Config.Region, err.Error())
+		return nil, fmt.Errorf("can't list RDS snapshots: %w", err)
 	}
 
-	return result.DBSnapshots
+	return snapshots, nil
 }
 
-func getExpiredSnapshots(svc rds.RDS, options *AwsOptions) []*rds.DBSnapshot {
-	dbs := listRDSDatabases(svc)
-	snaps := listSnapshots(svc)
+// getExpiredSnapshots returns the snapshots of databases which don't exist anymore, the databases and the snapshots
+// have both to be listed.
+func getExpiredSnapshots(ctx context.Context, svc rds.RDS, options *AwsOptions) ([]*rds.DBSnapshot, error) {
+	dbs, err := listRDSDatabases(ctx, svc)
+	if err != nil {
+		return nil, err
+	}
+	snaps, err := listSnapshots(ctx, svc)
+	if err != nil {
+		return nil, err
+	}
 
 	expiredSnaps := []*rds.DBSnapshot{}
 
@@ -368,7 +436,7 @@ func getExpiredSnapshots(svc rds.RDS, options *AwsOptions) []*rds.DBSnapshot {
 			}
 		}
 
-		return expiredSnaps
+		return expiredSnaps, nil
 	}
 
 	snapsChecking := make(map[string]*rds.DBSnapshot)
@@ -391,35 +459,38 @@ func getExpiredSnapshots(svc rds.RDS, options *AwsOptions) []*rds.DBSnapshot {
 		}
 	}
 
-	return expiredSnaps
+	return expiredSnaps, nil
 }
 
-func deleteSnapshot(svc rds.RDS, snapName string) {
-	_, err := svc.DeleteDBSnapshot(&rds.DeleteDBSnapshotInput{DBSnapshotIdentifier: aws.String(snapName)})
-
-	if err != nil {
-		log.Errorf("Can't delete RDS snapshot %s in region %s: %s", snapName, *svc.Config.Region, err.Error())
-	} else {
-		log.Debugf("RDS snapshot %s in %s deleted.", snapName, *svc.Config.Region)
-	}
+func deleteSnapshot(ctx context.Context, svc rds.RDS, snapName string) error {
+	_, err := svc.DeleteDBSnapshotWithContext(ctx, &rds.DeleteDBSnapshotInput{DBSnapshotIdentifier: aws.String(snapName)})
 
+	return err
 }
 
-func DeleteExpiredSnapshots(sessions AWSSessions, options AwsOptions) {
-	expiredSnapshots := getExpiredSnapshots(*sessions.RDS, &options)
-	region := *sessions.RDS.Config.Region
-
-	count, start := common.ElemToDeleteFormattedInfos("expired RDS snapshot", len(expiredSnapshots), region)
+type rdsSnapshotCleaner struct {
+	awsCleaner
+	common.AlwaysEvaluator
+}
 
-	log.Info(count)
+func (c rdsSnapshotCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	snapshots, err := getExpiredSnapshots(ctx, *c.sessions.RDS, &c.options)
+	if err != nil {
+		return nil, err
+	}
 
-	if options.DryRun || len(expiredSnapshots) == 0 || expiredSnapshots == nil {
-		return
+	var resources []common.CloudProviderResource
+	for _, snapshot := range snapshots {
+		resources = append(resources, common.CloudProviderResource{
+			Identifier:   *snapshot.DBSnapshotIdentifier,
+			Description:  "RDS snapshot: " + *snapshot.DBSnapshotIdentifier,
+			CreationDate: aws.TimeValue(snapshot.SnapshotCreateTime),
+		})
 	}
 
-	log.Info(start)
+	return resources, nil
+}
 
-	for _, snapshot := range expiredSnapshots {
-		deleteSnapshot(*sessions.RDS, *snapshot.DBSnapshotIdentifier)
-	}
+func (c rdsSnapshotCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	return deleteSnapshot(ctx, *c.sessions.RDS, resource.Identifier)
 }
@@ -1,13 +1,13 @@
 package aws
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
-	"github.com/aws/aws-sdk-go/service/eks"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/Qovery/pleco/pkg/common"
@@ -18,7 +18,7 @@ type EBSVolume struct {
 	Status string
 }
 
-func TagVolumesFromEksClusterForDeletion(ec2Session *ec2.EC2, tagKey string, clusterName string) error {
+func TagVolumesFromEksClusterForDeletion(ctx context.Context, ec2Session *ec2.EC2, tagKey string, clusterName string) error {
 	var volumesIds []*string
 
 	input := &ec2.DescribeVolumesInput{
@@ -32,21 +32,21 @@ func TagVolumesFromEksClusterForDeletion(ec2Session *ec2.EC2, tagKey string, clu
 		},
 	}
 
-	result, err := ec2Session.DescribeVolumes(input)
+	volumes, err := describeVolumes(ctx, ec2Session, input)
 	if err != nil {
 		return fmt.Errorf("Can't get volumes for cluster %s in region %s: %s", clusterName, *ec2Session.Config.Region, err.Error())
 	}
 
-	if len(result.Volumes) == 0 {
+	if len(volumes) == 0 {
 		log.Debugf("No volume to tag for cluster %s", clusterName)
 		return nil
 	}
 
-	for _, currentVolume := range result.Volumes {
+	for _, currentVolume := range volumes {
 		volumesIds = append(volumesIds, currentVolume.VolumeId)
 	}
 
-	_, err = ec2Session.CreateTags(
+	_, err = ec2Session.CreateTagsWithContext(ctx,
 		&ec2.CreateTagsInput{
 			Resources: volumesIds,
 			Tags: []*ec2.Tag{
@@ -67,51 +67,48 @@ func TagVolumesFromEksClusterForDeletion(ec2Session *ec2.EC2, tagKey string, clu
 	return nil
 }
 
-func deleteVolumes(ec2Session ec2.EC2, VolumesList []EBSVolume) {
-	for _, volume := range VolumesList {
-		switch volume.Status {
-		case "deleting":
-			log.Debugf("Volume %s in region %s is already in deletion process, skipping...", volume.Identifier, *ec2Session.Config.Region)
-			continue
-		case "creating":
-			continue
-		case "deleted":
-			continue
-		case "in-use":
-			continue
-		}
-
-		_, err := ec2Session.DeleteVolume(
-			&ec2.DeleteVolumeInput{
-				VolumeId: &volume.Identifier,
-			},
-		)
-		if err != nil {
-			log.Errorf("Can't delete EBS %s in %s", volume.Identifier, *ec2Session.Config.Region)
-		} else {
-			log.Debugf("EBS %s in %s deleted.", volume.Identifier, *ec2Session.Config.Region)
-		}
+func deleteVolume(ctx context.Context, ec2Session ec2.EC2, volume EBSVolume) error {
+	switch volume.Status {
+	case "deleting":
+		log.Debugf("Volume %s in region %s is already in deletion process, skipping...", volume.Identifier, *ec2Session.Config.Region)
+		return nil
+	case "creating", "deleted", "in-use":
+		return nil
 	}
+
+	_, err := ec2Session.DeleteVolumeWithContext(ctx,
+		&ec2.DeleteVolumeInput{
+			VolumeId: &volume.Identifier,
+		},
+	)
+
+	return err
+}
+
+func describeVolumes(ctx context.Context, ec2Session *ec2.EC2, input *ec2.DescribeVolumesInput) ([]*ec2.Volume, error) {
+	var volumes []*ec2.Volume
+	err := ec2Session.DescribeVolumesPagesWithContext(ctx, input, func(page *ec2.DescribeVolumesOutput, lastPage bool) bool {
+		volumes = append(volumes, page.Volumes...)
+		return true
+	})
+
+	return volumes, err
 }
 
-func listExpiredVolumes(eksSession *eks.EKS, ec2Session *ec2.EC2, options *AwsOptions) ([]EBSVolume, error) {
-	result, err := ec2Session.DescribeVolumes(&ec2.DescribeVolumesInput{})
+func listVolumes(ctx context.Context, ec2Session *ec2.EC2, options *AwsOptions) ([]EBSVolume, error) {
+	allVolumes, err := describeVolumes(ctx, ec2Session, &ec2.DescribeVolumesInput{})
 	if err != nil {
 		return nil, err
 	}
 
-	if len(result.Volumes) == 0 {
-		return nil, nil
-	}
-
-	var expiredVolumes []EBSVolume
-	for _, currentVolume := range result.Volumes {
+	var volumes []EBSVolume
+	for _, currentVolume := range allVolumes {
 		if strings.Contains(*currentVolume.State, "in-use") {
 			continue
 		}
 
 		essentialTags := common.GetEssentialTags(currentVolume.Tags, options.TagName)
-		volume := EBSVolume{
+		volumes = append(volumes, EBSVolume{
 			CloudProviderResource: common.CloudProviderResource{
 				Identifier:   *currentVolume.VolumeId,
 				Description:  "EBS Volume: " + *currentVolume.VolumeId,
@@ -119,35 +116,43 @@ func listExpiredVolumes(eksSession *eks.EKS, ec2Session *ec2.EC2, options *AwsOp
 				TTL:          essentialTags.TTL,
 				Tag:          essentialTags.Tag,
 				IsProtected:  essentialTags.IsProtected,
+				ExpiresAt:    essentialTags.ExpiresAt,
+				Warnings:     essentialTags.Warnings,
+				Tags:         essentialTags.Tags,
 			},
 			Status: *currentVolume.State,
-		}
-
-		if !volume.IsProtected && volume.IsResourceExpired(options.TagValue, options.DisableTTLCheck) {
-			expiredVolumes = append(expiredVolumes, volume)
-		}
+		})
 	}
 
-	return expiredVolumes, nil
+	return volumes, nil
 }
 
-func DeleteExpiredVolumes(sessions AWSSessions, options AwsOptions) {
-	expiredVolumes, err := listExpiredVolumes(sessions.EKS, sessions.EC2, &options)
-	region := *sessions.EC2.Config.Region
-	if err != nil {
-		log.Errorf("Can't list volumes: %s\n", err)
-		return
-	}
+type ebsVolumeCleaner struct {
+	awsCleaner
+}
 
-	count, start := common.ElemToDeleteFormattedInfos("expired EBS volume", len(expiredVolumes), region)
+func init() {
+	registerRegionalCleaner("ebs", "ebs-volume", "expired EBS volume", func(base awsCleaner) common.Cleaner {
+		return ebsVolumeCleaner{base}
+	})
+}
 
-	log.Info(count)
+func (c ebsVolumeCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	volumes, err := listVolumes(ctx, c.sessions.EC2, &c.options)
+	if err != nil {
+		return nil, err
+	}
 
-	if options.DryRun || len(expiredVolumes) == 0 {
-		return
+	var resources []common.CloudProviderResource
+	for _, volume := range volumes {
+		resource := volume.CloudProviderResource
+		resource.Payload = volume
+		resources = append(resources, resource)
 	}
 
-	log.Info(start)
+	return resources, nil
+}
 
-	deleteVolumes(*sessions.EC2, expiredVolumes)
+func (c ebsVolumeCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	return deleteVolume(ctx, *c.sessions.EC2, resource.Payload.(EBSVolume))
 }
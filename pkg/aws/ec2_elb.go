@@ -1,8 +1,8 @@
 package aws
 
 import (
+	"context"
 	"fmt"
-	"os"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -17,12 +17,11 @@ type ElasticLoadBalancer struct {
 	common.CloudProviderResource
 	Arn       string
 	Status    string
-	VpcId     string
 	Tags      []*elbv2.Tag
 	PublicIps []string
 }
 
-func TagLoadBalancersForDeletion(lbSession *elbv2.ELBV2, tagKey string, loadBalancersList []ElasticLoadBalancer, clusterName string) error {
+func TagLoadBalancersForDeletion(ctx context.Context, lbSession *elbv2.ELBV2, tagKey string, loadBalancersList []ElasticLoadBalancer, clusterName string) error {
 	var lbArns []*string
 
 	if len(loadBalancersList) == 0 {
@@ -38,7 +37,7 @@ func TagLoadBalancersForDeletion(lbSession *elbv2.ELBV2, tagKey string, loadBala
 	}
 
 	for _, lbArn := range lbArns {
-		_, err := lbSession.AddTags(
+		_, err := lbSession.AddTagsWithContext(ctx,
 			&elbv2.AddTagsInput{
 				ResourceArns: aws.StringSlice([]string{*lbArn}),
 				Tags: []*elbv2.Tag{
@@ -61,10 +60,10 @@ func TagLoadBalancersForDeletion(lbSession *elbv2.ELBV2, tagKey string, loadBala
 	return nil
 }
 
-func ListExpiredLoadBalancers(eksSession *eks.EKS, lbSession *elbv2.ELBV2, options *AwsOptions) ([]ElasticLoadBalancer, error) {
+func ListExpiredLoadBalancers(ctx context.Context, eksSession *eks.EKS, lbSession *elbv2.ELBV2, options *AwsOptions) ([]ElasticLoadBalancer, error) {
 	var taggedLoadBalancers []ElasticLoadBalancer
 
-	allLoadBalancers, err := ListLoadBalancers(lbSession, options.TagName)
+	allLoadBalancers, err := ListLoadBalancers(ctx, lbSession, options.TagName, options.tagIndex)
 	if err != nil {
 		return nil, fmt.Errorf("Error while getting loadbalancer list on region %s\n", *lbSession.Config.Region)
 	}
@@ -74,18 +73,7 @@ func ListExpiredLoadBalancers(eksSession *eks.EKS, lbSession *elbv2.ELBV2, optio
 	}
 
 	for _, currentLb := range allLoadBalancers {
-		if options.DisableTTLCheck {
-			vpcId, isOk := os.LookupEnv("PROTECTED_VPC_ID")
-			if !isOk || vpcId == "" {
-				log.Fatalf("Unable to get PROTECTED_VPC_ID environment variable in order to protect VPC resources.")
-			}
-			if vpcId == currentLb.VpcId {
-				log.Debugf("Skipping load balancer %s in region %s (protected vpc)", currentLb.Identifier, *lbSession.Config.Region)
-				continue
-			}
-		}
-
-		if !currentLb.IsProtected && (!common.IsAssociatedToLivingCluster(currentLb.Tags, eksSession) || currentLb.IsResourceExpired(options.TagValue, options.DisableTTLCheck)) {
+		if !currentLb.IsProtected && (!common.IsAssociatedToLivingCluster(ctx, currentLb.Tags, eksSession) || currentLb.IsResourceExpired(options.TagValue, options.DisableTTLCheck)) {
 			log.Infof("Load Balancer found to delete: %s (vpc = %s)", currentLb.Arn, currentLb.VpcId)
 			taggedLoadBalancers = append(taggedLoadBalancers, currentLb)
 		}
@@ -94,31 +82,37 @@ func ListExpiredLoadBalancers(eksSession *eks.EKS, lbSession *elbv2.ELBV2, optio
 	return taggedLoadBalancers, nil
 }
 
-func ListLoadBalancers(lbSession *elbv2.ELBV2, tagName string) ([]ElasticLoadBalancer, error) {
+func ListLoadBalancers(ctx context.Context, lbSession *elbv2.ELBV2, tagName string, tagIndex *taggedResources) ([]ElasticLoadBalancer, error) {
 	var allLoadBalancers []ElasticLoadBalancer
 
 	input := elbv2.DescribeLoadBalancersInput{}
 
-	result, err := lbSession.DescribeLoadBalancers(&input)
+	var loadBalancers []*elbv2.LoadBalancer
+	err := lbSession.DescribeLoadBalancersPagesWithContext(ctx, &input, func(page *elbv2.DescribeLoadBalancersOutput, lastPage bool) bool {
+		loadBalancers = append(loadBalancers, page.LoadBalancers...)
+		return true
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	if len(result.LoadBalancers) == 0 {
-		return nil, nil
-	}
-
 	region := *lbSession.Config.Region
-	for _, currentLb := range result.LoadBalancers {
-		input := elbv2.DescribeTagsInput{ResourceArns: []*string{currentLb.LoadBalancerArn}}
-		result, err := lbSession.DescribeTags(&input)
+	for _, currentLb := range loadBalancers {
 		currentLbName := *currentLb.LoadBalancerName
 
-		if err != nil {
-			log.Errorf("Error while getting load balancer tags from %s in %s", currentLbName, region)
-			continue
+		var loadBalancerTags []*elbv2.Tag
+		if indexedTags, ok := tagIndex.lookup(aws.StringValue(currentLb.LoadBalancerArn)); ok {
+			for _, key := range sortedKeys(indexedTags) {
+				loadBalancerTags = append(loadBalancerTags, &elbv2.Tag{Key: aws.String(key), Value: aws.String(indexedTags[key])})
+			}
+		} else {
+			input := elbv2.DescribeTagsInput{ResourceArns: []*string{currentLb.LoadBalancerArn}}
+			result, err := lbSession.DescribeTagsWithContext(ctx, &input)
+			if err != nil {
+				return nil, fmt.Errorf("can't get load balancer tags from %s in %s: %w", currentLbName, region, err)
+			}
+			loadBalancerTags = result.TagDescriptions[0].Tags
 		}
-		loadBalancerTags := result.TagDescriptions[0].Tags
 		essentialTags := common.GetEssentialTags(loadBalancerTags, tagName)
 
 		var lbAdresses []*elbv2.LoadBalancerAddress
@@ -139,10 +133,13 @@ func ListLoadBalancers(lbSession *elbv2.ELBV2, tagName string) ([]ElasticLoadBal
 				TTL:          essentialTags.TTL,
 				Tag:          essentialTags.Tag,
 				IsProtected:  essentialTags.IsProtected,
+				ExpiresAt:    essentialTags.ExpiresAt,
+				Warnings:     essentialTags.Warnings,
+				Tags:         essentialTags.Tags,
+				VpcId:        aws.StringValue(currentLb.VpcId),
 			},
 			Arn:       *currentLb.LoadBalancerArn,
 			Status:    *currentLb.State.Code,
-			VpcId:     *currentLb.VpcId,
 			Tags:      loadBalancerTags,
 			PublicIps: ips,
 		})
@@ -151,20 +148,21 @@ func ListLoadBalancers(lbSession *elbv2.ELBV2, tagName string) ([]ElasticLoadBal
 	return allLoadBalancers, nil
 }
 
-func deleteLoadBalancers(lbSession *elbv2.ELBV2, loadBalancersList []ElasticLoadBalancer, dryRun bool) {
-	if dryRun {
-		return
-	}
+func deleteLoadBalancer(ctx context.Context, lbSession *elbv2.ELBV2, lb ElasticLoadBalancer) error {
+	_, err := lbSession.DeleteLoadBalancerWithContext(ctx,
+		&elbv2.DeleteLoadBalancerInput{LoadBalancerArn: &lb.Arn},
+	)
 
-	if len(loadBalancersList) == 0 {
+	return err
+}
+
+func deleteLoadBalancers(ctx context.Context, lbSession *elbv2.ELBV2, loadBalancersList []ElasticLoadBalancer, dryRun bool) {
+	if dryRun {
 		return
 	}
 
 	for _, lb := range loadBalancersList {
-		_, err := lbSession.DeleteLoadBalancer(
-			&elbv2.DeleteLoadBalancerInput{LoadBalancerArn: &lb.Arn},
-		)
-		if err != nil {
+		if err := deleteLoadBalancer(ctx, lbSession, lb); err != nil {
 			log.Errorf("Can't delete ELB %s in %s", lb.Identifier, *lbSession.Config.Region)
 		} else {
 			log.Debugf("ELB %s: %v in %s deleted.", lb.Identifier, lb.PublicIps, *lbSession.Config.Region)
@@ -172,29 +170,40 @@ func deleteLoadBalancers(lbSession *elbv2.ELBV2, loadBalancersList []ElasticLoad
 	}
 }
 
-func DeleteExpiredLoadBalancers(sessions AWSSessions, options AwsOptions) {
-	expiredLoadBalancers, err := ListExpiredLoadBalancers(sessions.EKS, sessions.ELB, &options)
-	region := *sessions.ELB.Config.Region
-	if err != nil {
-		log.Errorf("Can't list Load Balancers: %s\n", err)
-		return
-	}
+// loadBalancerCleaner relies on ListExpiredLoadBalancers which also selects load balancers not linked to a living cluster.
+type loadBalancerCleaner struct {
+	awsCleaner
+	common.AlwaysEvaluator
+}
 
-	count, start := common.ElemToDeleteFormattedInfos("expired ELB load balancer", len(expiredLoadBalancers), region)
+func init() {
+	registerRegionalCleaner("elb", "elb-load-balancer", "expired ELB load balancer", func(base awsCleaner) common.Cleaner {
+		return loadBalancerCleaner{awsCleaner: base}
+	})
+}
 
-	log.Info(count)
+func (c loadBalancerCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	loadBalancers, err := ListExpiredLoadBalancers(ctx, c.sessions.EKS, c.sessions.ELB, &c.options)
+	if err != nil {
+		return nil, err
+	}
 
-	if options.DryRun || len(expiredLoadBalancers) == 0 {
-		return
+	var resources []common.CloudProviderResource
+	for _, lb := range loadBalancers {
+		resource := lb.CloudProviderResource
+		resource.Payload = lb
+		resources = append(resources, resource)
 	}
 
-	log.Info(start)
+	return resources, nil
+}
 
-	deleteLoadBalancers(sessions.ELB, expiredLoadBalancers, options.DryRun)
+func (c loadBalancerCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	return deleteLoadBalancer(ctx, c.sessions.ELB, resource.Payload.(ElasticLoadBalancer))
 }
 
-func getLoadBalancerByVpId(lbSession *elbv2.ELBV2, vpc VpcInfo) ElasticLoadBalancer {
-	lbs, err := ListLoadBalancers(lbSession, "")
+func getLoadBalancerByVpId(ctx context.Context, lbSession *elbv2.ELBV2, vpc VpcInfo) ElasticLoadBalancer {
+	lbs, err := ListLoadBalancers(ctx, lbSession, "", nil)
 	if err != nil {
 		log.Errorf("Can't list Load Balancers: %s\n", err)
 		return ElasticLoadBalancer{}
@@ -209,10 +218,10 @@ func getLoadBalancerByVpId(lbSession *elbv2.ELBV2, vpc VpcInfo) ElasticLoadBalan
 	return ElasticLoadBalancer{}
 }
 
-func DeleteLoadBalancerByVpcId(lbSession *elbv2.ELBV2, vpc VpcInfo, dryRun bool) {
-	lb := getLoadBalancerByVpId(lbSession, vpc)
+func DeleteLoadBalancerByVpcId(ctx context.Context, lbSession *elbv2.ELBV2, vpc VpcInfo, dryRun bool) {
+	lb := getLoadBalancerByVpId(ctx, lbSession, vpc)
 	if lb.Arn != "" {
-		deleteLoadBalancers(lbSession, []ElasticLoadBalancer{lb}, dryRun)
-		time.Sleep(30 * time.Second)
+		deleteLoadBalancers(ctx, lbSession, []ElasticLoadBalancer{lb}, dryRun)
+		_ = common.Sleep(ctx, 30*time.Second)
 	}
 }
@@ -1,9 +1,10 @@
 package aws
 
 import (
+	"context"
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	log "github.com/sirupsen/logrus"
-	"os"
 
 	"github.com/Qovery/pleco/pkg/common"
 )
@@ -12,32 +13,26 @@ type EC2Instance struct {
 	common.CloudProviderResource
 }
 
-func deleteEC2Instances(ec2Session *ec2.EC2, ec2Instances []EC2Instance) {
-	for _, ec2Instance := range ec2Instances {
-		instanceIds := []*string{&ec2Instance.Identifier}
-		_, err := ec2Session.TerminateInstances(&ec2.TerminateInstancesInput{
-			InstanceIds: instanceIds,
-		})
-		if err != nil {
-			log.Errorf("Can't delete %s in %s", ec2Instance.Identifier, *ec2Session.Config.Region)
-		} else {
-			log.Debugf("EC2 instance %s in %s deleted.", ec2Instance.Identifier, *ec2Session.Config.Region)
-		}
-	}
+func deleteEC2Instance(ctx context.Context, ec2Session *ec2.EC2, ec2Instance EC2Instance) error {
+	_, err := ec2Session.TerminateInstancesWithContext(ctx, &ec2.TerminateInstancesInput{
+		InstanceIds: []*string{&ec2Instance.Identifier},
+	})
+
+	return err
 }
 
-func listExpiredEC2Instances(ec2Session *ec2.EC2, options *AwsOptions) ([]EC2Instance, error) {
-	result, err := ec2Session.DescribeInstances(&ec2.DescribeInstancesInput{})
+func listEC2Instances(ctx context.Context, ec2Session *ec2.EC2, options *AwsOptions) ([]EC2Instance, error) {
+	var reservations []*ec2.Reservation
+	err := ec2Session.DescribeInstancesPagesWithContext(ctx, &ec2.DescribeInstancesInput{}, func(page *ec2.DescribeInstancesOutput, lastPage bool) bool {
+		reservations = append(reservations, page.Reservations...)
+		return true
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	if len(result.Reservations) == 0 {
-		return nil, nil
-	}
-
-	var expiredEC2Instances []EC2Instance
-	for _, currentReservation := range result.Reservations {
+	var ec2Instances []EC2Instance
+	for _, currentReservation := range reservations {
 		for _, ec2Instance := range currentReservation.Instances {
 			// available instance states listed here: https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_InstanceState.html
 			if *ec2Instance.State.Name != "running" {
@@ -45,17 +40,6 @@ func listExpiredEC2Instances(ec2Session *ec2.EC2, options *AwsOptions) ([]EC2Ins
 				continue
 			}
 
-			if options.DisableTTLCheck {
-				vpcId, isOk := os.LookupEnv("PROTECTED_VPC_ID")
-				if !isOk || vpcId == "" {
-					log.Fatalf("Unable to get PROTECTED_VPC_ID environment variable in order to protect VPC resources.")
-				}
-				if vpcId == *ec2Instance.VpcId {
-					log.Debugf("Skipping EC2 instance %s in region %s (protected vpc)", *ec2Instance.InstanceId, *ec2Session.Config.Region)
-					continue
-				}
-			}
-
 			essentialTags := common.GetEssentialTags(ec2Instance.Tags, options.TagName)
 			ec2Instance := EC2Instance{
 				CloudProviderResource: common.CloudProviderResource{
@@ -65,35 +49,46 @@ func listExpiredEC2Instances(ec2Session *ec2.EC2, options *AwsOptions) ([]EC2Ins
 					TTL:          essentialTags.TTL,
 					Tag:          essentialTags.Tag,
 					IsProtected:  essentialTags.IsProtected,
+					ExpiresAt:    essentialTags.ExpiresAt,
+					Warnings:     essentialTags.Warnings,
+					Tags:         essentialTags.Tags,
+					VpcId:        aws.StringValue(ec2Instance.VpcId),
 				},
 			}
 
-			if ec2Instance.IsResourceExpired(options.TagValue, options.DisableTTLCheck) {
-				expiredEC2Instances = append(expiredEC2Instances, ec2Instance)
-			}
+			ec2Instances = append(ec2Instances, ec2Instance)
 		}
 	}
 
-	return expiredEC2Instances, nil
+	return ec2Instances, nil
 }
 
-func DeleteExpiredEC2Instances(sessions AWSSessions, options AwsOptions) {
-	expiredEC2Instances, err := listExpiredEC2Instances(sessions.EC2, &options)
-	region := *sessions.EC2.Config.Region
-	if err != nil {
-		log.Errorf("Can't list instances: %s\n", err)
-		return
-	}
+type ec2InstanceCleaner struct {
+	awsCleaner
+}
 
-	count, start := common.ElemToDeleteFormattedInfos("expired EC2 instance", len(expiredEC2Instances), region)
+func init() {
+	registerRegionalCleaner("ec2-instance", "ec2-instance", "expired EC2 instance", func(base awsCleaner) common.Cleaner {
+		return ec2InstanceCleaner{base}
+	})
+}
 
-	log.Info(count)
+func (c ec2InstanceCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	instances, err := listEC2Instances(ctx, c.sessions.EC2, &c.options)
+	if err != nil {
+		return nil, err
+	}
 
-	if options.DryRun || len(expiredEC2Instances) == 0 {
-		return
+	var resources []common.CloudProviderResource
+	for _, instance := range instances {
+		resource := instance.CloudProviderResource
+		resource.Payload = instance
+		resources = append(resources, resource)
 	}
 
-	log.Info(start)
+	return resources, nil
+}
 
-	deleteEC2Instances(sessions.EC2, expiredEC2Instances)
+func (c ec2InstanceCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	return deleteEC2Instance(ctx, c.sessions.EC2, resource.Payload.(EC2Instance))
 }
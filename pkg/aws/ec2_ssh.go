@@ -1,9 +1,10 @@
 package aws
 
 import (
+	"context"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/Qovery/pleco/pkg/common"
 )
@@ -13,13 +14,11 @@ type KeyPair struct {
 	KeyName string
 }
 
-func getSshKeys(ec2session *ec2.EC2, tagName string) []KeyPair {
-	result, err := ec2session.DescribeKeyPairs(
+func getSshKeys(ctx context.Context, ec2session *ec2.EC2, tagName string) ([]KeyPair, error) {
+	result, err := ec2session.DescribeKeyPairsWithContext(ctx,
 		&ec2.DescribeKeyPairsInput{})
-
 	if err != nil {
-		log.Error(err)
-		return nil
+		return nil, err
 	}
 
 	var keys []KeyPair
@@ -33,17 +32,20 @@ func getSshKeys(ec2session *ec2.EC2, tagName string) []KeyPair {
 				TTL:          essentialTags.TTL,
 				Tag:          essentialTags.Tag,
 				IsProtected:  essentialTags.IsProtected,
+				ExpiresAt:    essentialTags.ExpiresAt,
+				Warnings:     essentialTags.Warnings,
+				Tags:         essentialTags.Tags,
 			},
 			KeyName: *key.KeyName,
 		}
 		keys = append(keys, newKey)
 	}
 
-	return keys
+	return keys, nil
 }
 
-func deleteKeyPair(ec2session *ec2.EC2, keyId string) error {
-	_, err := ec2session.DeleteKeyPair(
+func deleteKeyPair(ctx context.Context, ec2session *ec2.EC2, keyId string) error {
+	_, err := ec2session.DeleteKeyPairWithContext(ctx,
 		&ec2.DeleteKeyPairInput{
 			KeyPairId: aws.String(keyId),
 		})
@@ -51,32 +53,30 @@ func deleteKeyPair(ec2session *ec2.EC2, keyId string) error {
 	return err
 }
 
-func DeleteExpiredKeyPairs(sessions AWSSessions, options AwsOptions) {
-	keys := getSshKeys(sessions.EC2, options.TagName)
-	region := sessions.EC2.Config.Region
-	var expiredKeys []KeyPair
-	for _, key := range keys {
-		if key.IsResourceExpired(options.TagValue, options.DisableTTLCheck) {
-			expiredKeys = append(expiredKeys, key)
-		}
-	}
+type keyPairCleaner struct {
+	awsCleaner
+}
 
-	count, start := common.ElemToDeleteFormattedInfos("expired SSH key pairs", len(expiredKeys), *region)
+func init() {
+	registerRegionalCleaner("ssh-keys", "ssh-key-pair", "expired SSH key pair", func(base awsCleaner) common.Cleaner {
+		return keyPairCleaner{base}
+	})
+}
 
-	log.Info(count)
+func (c keyPairCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	keys, err := getSshKeys(ctx, c.sessions.EC2, c.options.TagName)
+	if err != nil {
+		return nil, err
+	}
 
-	if options.DryRun || len(expiredKeys) == 0 {
-		return
+	var resources []common.CloudProviderResource
+	for _, key := range keys {
+		resources = append(resources, key.CloudProviderResource)
 	}
 
-	log.Info(start)
+	return resources, nil
+}
 
-	for _, key := range expiredKeys {
-		deletionErr := deleteKeyPair(sessions.EC2, key.Identifier)
-		if deletionErr != nil {
-			log.Errorf("Deletion EC2 key pair error %s/%s: %s", key.KeyName, *region, deletionErr)
-		} else {
-			log.Debugf("Key Pair %s in %s deleted.", key.KeyName, *region)
-		}
-	}
+func (c keyPairCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	return deleteKeyPair(ctx, c.sessions.EC2, resource.Identifier)
 }
@@ -1,12 +1,12 @@
 package aws
 
 import (
+	"context"
 	"fmt"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ecr"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/Qovery/pleco/pkg/common"
 )
@@ -16,109 +16,127 @@ type Repository struct {
 	imagesIds []*ecr.ImageIdentifier
 }
 
-func getRepositories(ecrSession *ecr.ECR) []*ecr.Repository {
+func getRepositories(ctx context.Context, ecrSession *ecr.ECR) ([]*ecr.Repository, error) {
 	var repo []*ecr.Repository
 
-	var token *string
-	for {
-		result, err := ecrSession.DescribeRepositories(
-			&ecr.DescribeRepositoriesInput{
-				MaxResults: aws.Int64(1000),
-				NextToken:  token,
-			})
-
-		if err != nil {
-			log.Error(err)
-		}
+	err := ecrSession.DescribeRepositoriesPagesWithContext(ctx,
+		&ecr.DescribeRepositoriesInput{
+			MaxResults: aws.Int64(1000),
+		}, func(page *ecr.DescribeRepositoriesOutput, lastPage bool) bool {
+			repo = append(repo, page.Repositories...)
+			return true
+		})
 
-		token = result.NextToken
-		repo = append(repo, result.Repositories...)
+	return repo, err
+}
 
-		if result.NextToken == nil {
-			break
-		}
-	}
+type ecrRepositoryCleaner struct {
+	awsCleaner
+}
 
-	return repo
+func init() {
+	registerRegionalCleaner("ecr", "ecr-repository", "expired ECR repository", func(base awsCleaner) common.Cleaner {
+		return ecrRepositoryCleaner{base}
+	})
 }
 
-func DeleteExpiredRepositories(sessions AWSSessions, options AwsOptions) {
-	repositories := getRepositories(sessions.ECR)
-	region := sessions.ECR.Config.Region
-	var expiredRepository []Repository
+func (c ecrRepositoryCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	repositories, err := getRepositories(ctx, c.sessions.ECR)
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []common.CloudProviderResource
 	for _, repository := range repositories {
 		creationTime, _ := time.Parse(time.RFC3339, repository.CreatedAt.Format(time.RFC3339))
-		result, err := sessions.ECR.ListTagsForResource(&ecr.ListTagsForResourceInput{ResourceArn: repository.RepositoryArn})
-		if err != nil {
-			log.Error(err)
+		var repositoryTags interface{}
+		if indexedTags, ok := c.options.tagIndex.lookup(aws.StringValue(repository.RepositoryArn)); ok {
+			repositoryTags = indexedTags
+		} else {
+			result, err := c.sessions.ECR.ListTagsForResourceWithContext(ctx, &ecr.ListTagsForResourceInput{ResourceArn: repository.RepositoryArn})
+			if err != nil {
+				return nil, err
+			}
+			repositoryTags = result.Tags
 		}
 
-		imagesIds := getRepositoryImageIds(sessions.ECR, *repository.RepositoryName)
-		tags := common.GetEssentialTags(result.Tags, options.TagName)
-
-		if common.CheckIfExpired(creationTime, tags.TTL, fmt.Sprintf("ECR repository: %s", *repository.RepositoryName), options.DisableTTLCheck) {
-			expiredRepository = append(expiredRepository, Repository{
-				name:      *repository.RepositoryName,
-				imagesIds: imagesIds,
-			})
-			continue
+		// a repository whose images can't be listed would be read as empty
+		imageIds, err := getRepositoryImageIds(ctx, c.sessions.ECR, *repository.RepositoryName)
+		if err != nil {
+			return nil, err
 		}
 
-		if len(imagesIds) == 0 && time.Now().UTC().After(repository.CreatedAt.Add(4*time.Hour)) {
-			expiredRepository = append(expiredRepository, Repository{
+		tags := common.GetEssentialTags(repositoryTags, c.options.TagName)
+		resources = append(resources, common.CloudProviderResource{
+			Identifier:   *repository.RepositoryName,
+			Description:  fmt.Sprintf("ECR repository: %s", *repository.RepositoryName),
+			CreationDate: creationTime,
+			TTL:          tags.TTL,
+			Tag:          tags.Tag,
+			IsProtected:  tags.IsProtected,
+			ExpiresAt:    tags.ExpiresAt,
+			Warnings:     tags.Warnings,
+			Tags:         tags.Tags,
+			Payload: Repository{
 				name:      *repository.RepositoryName,
-				imagesIds: imagesIds,
-			})
-		}
+				imagesIds: imageIds,
+			},
+		})
 	}
 
-	s := fmt.Sprintf("There is no expired ECR repository to delete in region %s.", *region)
-	if len(expiredRepository) == 1 {
-		s = fmt.Sprintf("There is 1 expired ECR repository to delete in region %s.", *region)
-	}
-	if len(expiredRepository) > 1 {
-		s = fmt.Sprintf("There are %d expired ECR repositories to delete in region %s.", len(expiredRepository), *region)
+	return resources, nil
+}
+
+// Evaluate also selects empty repositories older than 4 hours.
+func (c ecrRepositoryCleaner) Evaluate(resource common.CloudProviderResource) bool {
+	if c.awsCleaner.Evaluate(resource) {
+		return true
 	}
 
-	log.Info(s)
+	repository := resource.Payload.(Repository)
+	return len(repository.imagesIds) == 0 && time.Now().UTC().After(resource.CreationDate.Add(4*time.Hour))
+}
 
-	if options.DryRun || len(expiredRepository) == 0 {
-		return
+func (c ecrRepositoryCleaner) MatchedRule(resource common.CloudProviderResource) string {
+	if c.awsCleaner.Evaluate(resource) {
+		return c.awsCleaner.MatchedRule(resource)
 	}
 
-	log.Infof("Starting ECR repositories deletion for region %s.", *region)
+	return "empty-repository"
+}
 
-	for _, reposirory := range expiredRepository {
-		deleteRepository(sessions.ECR, reposirory)
-	}
+func (c ecrRepositoryCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	return deleteRepository(ctx, c.sessions.ECR, resource.Payload.(Repository))
 }
 
-func emptyRepository(ecrSession *ecr.ECR, repositoryName *string, imageIds []*ecr.ImageIdentifier) {
-	_, err := ecrSession.BatchDeleteImage(&ecr.BatchDeleteImageInput{RepositoryName: repositoryName, ImageIds: imageIds})
-	if err != nil {
-		log.Error(err)
+func emptyRepository(ctx context.Context, ecrSession *ecr.ECR, repositoryName *string, imageIds []*ecr.ImageIdentifier) error {
+	if len(imageIds) == 0 {
+		return nil
 	}
+
+	_, err := ecrSession.BatchDeleteImageWithContext(ctx, &ecr.BatchDeleteImageInput{RepositoryName: repositoryName, ImageIds: imageIds})
+	return err
 }
 
-func getRepositoryImageIds(ecrSession *ecr.ECR, repositoryName string) []*ecr.ImageIdentifier {
-	result, err := ecrSession.ListImages(&ecr.ListImagesInput{RepositoryName: &repositoryName})
-	if err != nil {
-		log.Error(err)
-	}
-	return result.ImageIds
+func getRepositoryImageIds(ctx context.Context, ecrSession *ecr.ECR, repositoryName string) ([]*ecr.ImageIdentifier, error) {
+	var imageIds []*ecr.ImageIdentifier
+	err := ecrSession.ListImagesPagesWithContext(ctx, &ecr.ListImagesInput{RepositoryName: &repositoryName}, func(page *ecr.ListImagesOutput, lastPage bool) bool {
+		imageIds = append(imageIds, page.ImageIds...)
+		return true
+	})
+
+	return imageIds, err
 }
 
-func deleteRepository(ecrSession *ecr.ECR, repository Repository) {
-	emptyRepository(ecrSession, &repository.name, repository.imagesIds)
-	_, err := ecrSession.DeleteRepository(
+func deleteRepository(ctx context.Context, ecrSession *ecr.ECR, repository Repository) error {
+	if err := emptyRepository(ctx, ecrSession, &repository.name, repository.imagesIds); err != nil {
+		return err
+	}
+
+	_, err := ecrSession.DeleteRepositoryWithContext(ctx,
 		&ecr.DeleteRepositoryInput{
 			RepositoryName: aws.String(repository.name),
 		})
 
-	if err != nil {
-		log.Errorf("Deletion ECR repository error %s/%s: %s",
-			repository.name, *ecrSession.Config.Region, err)
-	} else {
-		log.Debugf("ECR %s in %s deleted.", repository.name, *ecrSession.Config.Region)
-	}
+	return err
 }
@@ -1,6 +1,7 @@
 package aws
 
 import (
+	"context"
 	"fmt"
 	"github.com/Qovery/pleco/pkg/common"
 	"github.com/aws/aws-sdk-go/aws"
@@ -16,7 +17,7 @@ type FargateProfile struct {
 	IsExpired          bool
 }
 
-func ListExpiredFargateProfiles(eksSession *eks.EKS, clusterName string, options *AwsOptions) []FargateProfile {
+func ListExpiredFargateProfiles(ctx context.Context, eksSession *eks.EKS, clusterName string, options *AwsOptions) []FargateProfile {
 	var expiredFargateProfiles []FargateProfile
 
 	var token *string
@@ -25,6 +26,7 @@ func ListExpiredFargateProfiles(eksSession *eks.EKS, clusterName string, options
 			ClusterName: aws.String(clusterName),
 			NextToken:   token,
 		})
+		req.SetContext(ctx)
 
 		err := req.Send()
 		if err != nil {
@@ -32,7 +34,7 @@ func ListExpiredFargateProfiles(eksSession *eks.EKS, clusterName string, options
 		}
 		token = resp.NextToken
 		for _, fargateProfileName := range resp.FargateProfileNames {
-			profile, err := getFargateProfile(eksSession, clusterName, *fargateProfileName, options)
+			profile, err := getFargateProfile(ctx, eksSession, clusterName, *fargateProfileName, options)
 			if err != nil {
 				log.Errorf("Error describing Fargate profile: %v", err)
 				continue
@@ -50,7 +52,7 @@ func ListExpiredFargateProfiles(eksSession *eks.EKS, clusterName string, options
 	return expiredFargateProfiles
 }
 
-func DeleteFargateProfile(eksSession *eks.EKS, fargateProfile FargateProfile, options *AwsOptions) error {
+func DeleteFargateProfile(ctx context.Context, eksSession *eks.EKS, fargateProfile FargateProfile, options *AwsOptions) error {
 	region := eksSession.Config.Region
 	if options.DryRun {
 		log.Infof("Dry run: skipping deletion of Fargate profile %s in region %s.", fargateProfile.FargateProfileName, *region)
@@ -58,7 +60,7 @@ func DeleteFargateProfile(eksSession *eks.EKS, fargateProfile FargateProfile, op
 	}
 	log.Infof("Starting deletion of EKS Fargate profile %s in region %s.", fargateProfile.FargateProfileName, *eksSession.Config.Region)
 
-	_, err := eksSession.DeleteFargateProfile(
+	_, err := eksSession.DeleteFargateProfileWithContext(ctx,
 		&eks.DeleteFargateProfileInput{
 			ClusterName:        aws.String(fargateProfile.ClusterName),
 			FargateProfileName: aws.String(fargateProfile.FargateProfileName),
@@ -73,8 +75,8 @@ func DeleteFargateProfile(eksSession *eks.EKS, fargateProfile FargateProfile, op
 	return nil
 }
 
-func getFargateProfile(eksSession *eks.EKS, clusterName string, profileName string, options *AwsOptions) (FargateProfile, error) {
-	resp, err := eksSession.DescribeFargateProfile(&eks.DescribeFargateProfileInput{
+func getFargateProfile(ctx context.Context, eksSession *eks.EKS, clusterName string, profileName string, options *AwsOptions) (FargateProfile, error) {
+	resp, err := eksSession.DescribeFargateProfileWithContext(ctx, &eks.DescribeFargateProfileInput{
 		ClusterName:        aws.String(clusterName),
 		FargateProfileName: aws.String(profileName),
 	})
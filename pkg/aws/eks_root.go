@@ -1,7 +1,7 @@
 package aws
 
 import (
-	"errors"
+	"context"
 	"fmt"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -53,42 +53,52 @@ func AuthenticateToEks(clusterName string, clusterUrl string, roleArn string, se
 	return clientSet, nil
 }
 
-func ListClusters(svc eks.EKS) ([]*string, error) {
-	input := &eks.ListClustersInput{}
-	result, err := svc.ListClusters(input)
+func ListClusters(ctx context.Context, svc eks.EKS) ([]*string, error) {
+	var clusters []*string
+	err := svc.ListClustersPagesWithContext(ctx, &eks.ListClustersInput{}, func(page *eks.ListClustersOutput, lastPage bool) bool {
+		clusters = append(clusters, page.Clusters...)
+		return true
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	return result.Clusters, nil
+	return clusters, nil
 }
 
-func GetClusterDetails(svc eks.EKS, cluster *string, region string, tagName string) eksCluster {
+func GetClusterDetails(ctx context.Context, svc eks.EKS, cluster *string, region string, tagName string) (eksCluster, error) {
 	currentCluster := eks.DescribeClusterInput{
 		Name: aws.String(*cluster),
 	}
 	clusterName := *currentCluster.Name
 
-	clusterInfo, err := svc.DescribeCluster(&currentCluster)
+	clusterInfo, err := svc.DescribeClusterWithContext(ctx, &currentCluster)
 	if err != nil {
-		log.Errorf("Error while trying to get info from cluster %v (%s)", clusterName, region)
+		return eksCluster{}, fmt.Errorf("can't get info from cluster %s (%s): %w", clusterName, region, err)
 	}
 
 	essentialTags := common.GetEssentialTags(clusterInfo.Cluster.Tags, tagName)
 
-	nodeGroups, err := svc.ListNodegroups(&eks.ListNodegroupsInput{
+	var nodeGroups []*string
+	err = svc.ListNodegroupsPagesWithContext(ctx, &eks.ListNodegroupsInput{
 		ClusterName: &clusterName,
+	}, func(page *eks.ListNodegroupsOutput, lastPage bool) bool {
+		nodeGroups = append(nodeGroups, page.Nodegroups...)
+		return true
 	})
 
 	if err != nil {
-		log.Errorf("Error while trying to get node groups from cluster %s (%s): %s", clusterName, region, err)
+		return eksCluster{}, fmt.Errorf("can't get node groups from cluster %s (%s): %w", clusterName, region, err)
 	}
 
-	var identity string
+	var identity, vpcId string
 
 	if clusterInfo.Cluster != nil && clusterInfo.Cluster.Identity != nil {
 		identity = clusterInfo.Cluster.Identity.String()
 	}
+	if clusterInfo.Cluster != nil && clusterInfo.Cluster.ResourcesVpcConfig != nil {
+		vpcId = aws.StringValue(clusterInfo.Cluster.ResourcesVpcConfig.VpcId)
+	}
 
 	return eksCluster{
 		CloudProviderResource: common.CloudProviderResource{
@@ -98,18 +108,22 @@ func GetClusterDetails(svc eks.EKS, cluster *string, region string, tagName stri
 			TTL:          essentialTags.TTL,
 			Tag:          essentialTags.Tag,
 			IsProtected:  essentialTags.IsProtected,
+			ExpiresAt:    essentialTags.ExpiresAt,
+			Warnings:     essentialTags.Warnings,
+			Tags:         essentialTags.Tags,
+			VpcId:        vpcId,
 		},
-		ClusterNodeGroupsName: nodeGroups.Nodegroups,
+		ClusterNodeGroupsName: nodeGroups,
 		ClusterId:             identity,
 		Status:                *clusterInfo.Cluster.Status,
-	}
+	}, nil
 }
 
-func ListTaggedEKSClusters(svc eks.EKS, options *AwsOptions) ([]eksCluster, error) {
+func ListTaggedEKSClusters(ctx context.Context, svc eks.EKS, options *AwsOptions) ([]eksCluster, error) {
 	var taggedClusters []eksCluster
 	region := *svc.Config.Region
 
-	clusters, err := ListClusters(svc)
+	clusters, err := ListClusters(ctx, svc)
 	if err != nil {
 		return nil, err
 	}
@@ -119,7 +133,10 @@ func ListTaggedEKSClusters(svc eks.EKS, options *AwsOptions) ([]eksCluster, erro
 	}
 
 	for _, cluster := range clusters {
-		detailCluster := GetClusterDetails(svc, cluster, region, options.TagName)
+		detailCluster, err := GetClusterDetails(ctx, svc, cluster, region, options.TagName)
+		if err != nil {
+			return nil, err
+		}
 
 		taggedClusters = append(taggedClusters, detailCluster)
 	}
@@ -127,17 +144,15 @@ func ListTaggedEKSClusters(svc eks.EKS, options *AwsOptions) ([]eksCluster, erro
 	return taggedClusters, nil
 }
 
-func deleteEKSCluster(svc *eks.EKS, ec2Session *ec2.EC2, elbSession *elbv2.ELBV2, cloudwatchLogsSession *cloudwatchlogs.CloudWatchLogs, cluster eksCluster, options *AwsOptions) error {
+func deleteEKSCluster(ctx context.Context, svc *eks.EKS, ec2Session *ec2.EC2, elbSession *elbv2.ELBV2, cloudwatchLogsSession *cloudwatchlogs.CloudWatchLogs, cluster eksCluster, options *AwsOptions) error {
 	if cluster.Status == "DELETING" {
-		log.Debugf("EKS cluster %s (%s) is already in deletion process, skipping...", cluster.Identifier, *svc.Config.Region)
-		return errors.New("cluster deleting")
+		return common.NewDeletionInProgressError("EKS cluster %s (%s) is already in deletion process", cluster.Identifier, *svc.Config.Region)
 	} else if cluster.Status == "CREATING" {
-		log.Debugf("EKS cluster %s (%s) is in creating process, skipping...", cluster.Identifier, *svc.Config.Region)
-		return errors.New("cluster creating")
+		return common.NewDeletionInProgressError("EKS cluster %s (%s) is in creating process", cluster.Identifier, *svc.Config.Region)
 	}
 
 	// delete fargate profiles
-	fargateProfiles := ListExpiredFargateProfiles(svc, cluster.Identifier, options)
+	fargateProfiles := ListExpiredFargateProfiles(ctx, svc, cluster.Identifier, options)
 	for _, fargateProfile := range fargateProfiles {
 		if fargateProfile.Status == "DELETING" {
 			log.Debugf("EKS Fargate profile %v (%s) is already in deletion process, skipping...", fargateProfile.FargateProfileName, cluster.Identifier)
@@ -147,7 +162,7 @@ func deleteEKSCluster(svc *eks.EKS, ec2Session *ec2.EC2, elbSession *elbv2.ELBV2
 			continue
 		}
 
-		err := DeleteFargateProfile(svc, fargateProfile, options)
+		err := DeleteFargateProfile(ctx, svc, fargateProfile, options)
 		if err != nil {
 			return fmt.Errorf("error while deleting Fargate profile %v: %w", fargateProfile.FargateProfileName, err)
 		} else {
@@ -158,13 +173,13 @@ func deleteEKSCluster(svc *eks.EKS, ec2Session *ec2.EC2, elbSession *elbv2.ELBV2
 	// as requests are asynchronous, we'll wait next run to perform delete and avoid obvious failure
 	// because of fargate profile are not yet deleted
 	if len(fargateProfiles) > 0 {
-		return nil
+		return common.NewDeletionInProgressError("waiting for %d Fargate profiles deletion of EKS cluster %s", len(fargateProfiles), cluster.Identifier)
 	}
 
 	// delete node groups
 	if len(cluster.ClusterNodeGroupsName) > 0 {
 		for _, nodeGroupName := range cluster.ClusterNodeGroupsName {
-			nodeGroupStatus, _ := getNodeGroupStatus(svc, cluster, *nodeGroupName)
+			nodeGroupStatus, _ := getNodeGroupStatus(ctx, svc, cluster, *nodeGroupName)
 
 			if nodeGroupStatus == "DELETING" {
 				log.Debugf("EKS cluster nodegroup %v (%s) is already in deletion process, skipping...", *nodeGroupName, cluster.Identifier)
@@ -174,7 +189,7 @@ func deleteEKSCluster(svc *eks.EKS, ec2Session *ec2.EC2, elbSession *elbv2.ELBV2
 				continue
 			}
 
-			err := deleteNodeGroupStatus(svc, cluster, *nodeGroupName, options.DryRun)
+			err := deleteNodeGroupStatus(ctx, svc, cluster, *nodeGroupName, options.DryRun)
 			if err != nil {
 				return fmt.Errorf("Error while deleting node group %v: %s\n", *nodeGroupName, err)
 			} else {
@@ -186,33 +201,33 @@ func deleteEKSCluster(svc *eks.EKS, ec2Session *ec2.EC2, elbSession *elbv2.ELBV2
 	// as requests are asynchronous, we'll wait next run to perform delete and avoid obvious failure
 	// because of nodes groups are not yet deleted
 	if len(cluster.ClusterNodeGroupsName) > 0 {
-		return nil
+		return common.NewDeletionInProgressError("waiting for %d node groups deletion of EKS cluster %s", len(cluster.ClusterNodeGroupsName), cluster.Identifier)
 	}
 
 	// tag associated ebs for deletion
-	expiredELB, err := ListExpiredLoadBalancers(svc, elbSession, options)
+	expiredELB, err := ListExpiredLoadBalancers(ctx, svc, elbSession, options)
 	if err != nil {
 		return err
 	}
-	err = TagLoadBalancersForDeletion(elbSession, options.TagName, expiredELB, cluster.Identifier)
+	err = TagLoadBalancersForDeletion(ctx, elbSession, options.TagName, expiredELB, cluster.Identifier)
 	if err != nil {
 		return err
 	}
 
 	// tag associated ebs for deletion
-	err = TagVolumesFromEksClusterForDeletion(ec2Session, options.TagName, cluster.Identifier)
+	err = TagVolumesFromEksClusterForDeletion(ctx, ec2Session, options.TagName, cluster.Identifier)
 	if err != nil {
 		return err
 	}
 
 	// tag cloudwatch logs for deletion
-	err = TagLogsForDeletion(cloudwatchLogsSession, options.TagName, cluster.ClusterId, cluster.TTL)
+	err = TagLogsForDeletion(ctx, cloudwatchLogsSession, options.TagName, cluster.ClusterId, cluster.TTL)
 	if err != nil {
 		return err
 	}
 
 	// delete EKS cluster
-	_, err = svc.DeleteCluster(
+	_, err = svc.DeleteClusterWithContext(ctx,
 		&eks.DeleteClusterInput{
 			Name: &cluster.Identifier,
 		},
@@ -224,8 +239,8 @@ func deleteEKSCluster(svc *eks.EKS, ec2Session *ec2.EC2, elbSession *elbv2.ELBV2
 	return nil
 }
 
-func getNodeGroupStatus(svc *eks.EKS, cluster eksCluster, nodeGroupName string) (string, error) {
-	result, err := svc.DescribeNodegroup(&eks.DescribeNodegroupInput{
+func getNodeGroupStatus(ctx context.Context, svc *eks.EKS, cluster eksCluster, nodeGroupName string) (string, error) {
+	result, err := svc.DescribeNodegroupWithContext(ctx, &eks.DescribeNodegroupInput{
 		ClusterName:   &cluster.Identifier,
 		NodegroupName: &nodeGroupName,
 	})
@@ -236,12 +251,12 @@ func getNodeGroupStatus(svc *eks.EKS, cluster eksCluster, nodeGroupName string)
 	return *result.Nodegroup.Status, nil
 }
 
-func deleteNodeGroupStatus(svc *eks.EKS, cluster eksCluster, nodeGroupName string, dryRun bool) error {
+func deleteNodeGroupStatus(ctx context.Context, svc *eks.EKS, cluster eksCluster, nodeGroupName string, dryRun bool) error {
 	if dryRun {
 		return nil
 	}
 
-	_, err := svc.DeleteNodegroup(&eks.DeleteNodegroupInput{
+	_, err := svc.DeleteNodegroupWithContext(ctx, &eks.DeleteNodegroupInput{
 		ClusterName:   &cluster.Identifier,
 		NodegroupName: &nodeGroupName,
 	})
@@ -252,41 +267,32 @@ func deleteNodeGroupStatus(svc *eks.EKS, cluster eksCluster, nodeGroupName strin
 	return nil
 }
 
-func DeleteExpiredEKSClusters(sessions AWSSessions, options AwsOptions) {
-	clusters, err := ListTaggedEKSClusters(*sessions.EKS, &options)
-	region := *sessions.EKS.Config.Region
-	if err != nil {
-		log.Errorf("Can't list EKS clusters: %s\n", err)
-		return
-	}
+type eksClusterCleaner struct {
+	awsCleaner
+}
 
-	var expiredCluster []eksCluster
-	for _, cluster := range clusters {
+func init() {
+	registerRegionalCleaner("eks", "eks-cluster", "expired EKS cluster", func(base awsCleaner) common.Cleaner {
+		return eksClusterCleaner{base}
+	})
+}
 
-		if cluster.IsResourceExpired(options.TagValue, options.DisableTTLCheck) {
-			expiredCluster = append(expiredCluster, cluster)
-		}
+func (c eksClusterCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	clusters, err := ListTaggedEKSClusters(ctx, *c.sessions.EKS, &c.options)
+	if err != nil {
+		return nil, err
 	}
 
-	count, start := common.ElemToDeleteFormattedInfos("expired EKS cluster", len(expiredCluster), region)
-
-	log.Info(count)
-
-	if options.DryRun || len(expiredCluster) == 0 {
-		return
+	var resources []common.CloudProviderResource
+	for _, cluster := range clusters {
+		resource := cluster.CloudProviderResource
+		resource.Payload = cluster
+		resources = append(resources, resource)
 	}
 
-	log.Info(start)
-
-	for _, cluster := range expiredCluster {
-		deletionErr := deleteEKSCluster(sessions.EKS, sessions.EC2, sessions.ELB, sessions.CloudWatchLogs, cluster, &options)
-		if deletionErr == errors.New("cluster deleting") || deletionErr == errors.New("cluster creating") {
-		} else if deletionErr != nil {
-			log.Errorf("Deletion EKS cluster error %s/%s: %s",
-				cluster.Identifier, region, deletionErr)
-		} else {
-			log.Debugf("EKS cluster %s in %s deleted.", cluster.Identifier, region)
-		}
+	return resources, nil
+}
 
-	}
+func (c eksClusterCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	return deleteEKSCluster(ctx, c.sessions.EKS, c.sessions.EC2, c.sessions.ELB, c.sessions.CloudWatchLogs, resource.Payload.(eksCluster), &c.options)
 }
@@ -1,6 +1,7 @@
 package aws
 
 import (
+	"context"
 	"strconv"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -8,10 +9,14 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
-func getGroups(iamSession *iam.IAM) []*iam.Group {
-	result, err := iamSession.ListGroups(
+func getGroups(ctx context.Context, iamSession *iam.IAM) []*iam.Group {
+	var groups []*iam.Group
+	err := iamSession.ListGroupsPagesWithContext(ctx,
 		&iam.ListGroupsInput{
 			MaxItems: aws.Int64(1000),
+		}, func(page *iam.ListGroupsOutput, lastPage bool) bool {
+			groups = append(groups, page.Groups...)
+			return true
 		})
 
 	if err != nil {
@@ -19,11 +24,11 @@ func getGroups(iamSession *iam.IAM) []*iam.Group {
 		return nil
 	}
 
-	return result.Groups
+	return groups
 }
 
-func DeleteGroups(iamSession *iam.IAM, dryRun bool) {
-	groups := getGroups(iamSession)
+func DeleteGroups(ctx context.Context, iamSession *iam.IAM, dryRun bool) {
+	groups := getGroups(ctx, iamSession)
 	log.Info("There is " + strconv.FormatInt(int64(len(groups)), 10) + " expired roles to delete.")
 
 	if dryRun {
@@ -31,7 +36,7 @@ func DeleteGroups(iamSession *iam.IAM, dryRun bool) {
 	}
 
 	for _, group := range groups {
-		_, err := iamSession.DeleteGroup(
+		_, err := iamSession.DeleteGroupWithContext(ctx,
 			&iam.DeleteGroupInput{
 				GroupName: aws.String(*group.GroupName),
 			})
@@ -1,9 +1,9 @@
 package aws
 
 import (
+	"context"
 	"github.com/Qovery/pleco/pkg/common"
 	"github.com/aws/aws-sdk-go/service/iam"
-	log "github.com/sirupsen/logrus"
 	"time"
 )
 
@@ -13,22 +13,11 @@ type InstanceProfile struct {
 	Roles               []*iam.Role
 }
 
-func getInstanceProfiles(iamSession *iam.IAM, tagName string) []InstanceProfile {
+func getInstanceProfiles(ctx context.Context, iamSession *iam.IAM, tagName string) ([]InstanceProfile, error) {
 	var instanceProfiles []InstanceProfile
 
-	var token *string
-	for {
-		result, err := iamSession.ListInstanceProfiles(&iam.ListInstanceProfilesInput{
-			Marker: token,
-		})
-
-		if err != nil {
-			log.Error(err)
-		}
-
-		token = result.Marker
-
-		for _, instanceProfile := range result.InstanceProfiles {
+	err := iamSession.ListInstanceProfilesPagesWithContext(ctx, &iam.ListInstanceProfilesInput{}, func(page *iam.ListInstanceProfilesOutput, lastPage bool) bool {
+		for _, instanceProfile := range page.InstanceProfiles {
 			essentialTags := common.GetEssentialTags(instanceProfile.Tags, tagName)
 			instanceProfiles = append(instanceProfiles, InstanceProfile{
 				CloudProviderResource: common.CloudProviderResource{
@@ -38,56 +27,66 @@ func getInstanceProfiles(iamSession *iam.IAM, tagName string) []InstanceProfile
 					TTL:          essentialTags.TTL,
 					Tag:          essentialTags.Tag,
 					IsProtected:  essentialTags.IsProtected,
+					ExpiresAt:    essentialTags.ExpiresAt,
+					Warnings:     essentialTags.Warnings,
+					Tags:         essentialTags.Tags,
 				},
 				InstanceProfileName: *instanceProfile.InstanceProfileName,
 				Roles:               instanceProfile.Roles,
 			})
 		}
 
-		if result.Marker == nil {
-			break
-		}
-	}
+		return true
+	})
 
-	return instanceProfiles
+	return instanceProfiles, err
 }
 
-func getExpiredInstanceProfiles(iamSession *iam.IAM, options *AwsOptions) []InstanceProfile {
-	instanceProfiles := getInstanceProfiles(iamSession, options.TagName)
+type iamInstanceProfileCleaner struct {
+	awsCleaner
+}
+
+func (c iamInstanceProfileCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	instanceProfiles, err := getInstanceProfiles(ctx, c.sessions.IAM, c.options.TagName)
+	if err != nil {
+		return nil, err
+	}
 
-	var expiredInstanceProfiles []InstanceProfile
+	var resources []common.CloudProviderResource
 	for _, instanceProfile := range instanceProfiles {
-		if (len(instanceProfile.Roles) == 0 && time.Now().UTC().After(instanceProfile.CreationDate.Add(4*time.Hour))) || instanceProfile.IsResourceExpired(options.TagValue, options.DisableTTLCheck) {
-			expiredInstanceProfiles = append(expiredInstanceProfiles, instanceProfile)
-		}
+		resource := instanceProfile.CloudProviderResource
+		resource.Payload = instanceProfile
+		resources = append(resources, resource)
 	}
 
-	return expiredInstanceProfiles
+	return resources, nil
 }
 
-func DeleteExpiredInstanceProfiles(sessions *AWSSessions, options *AwsOptions) {
-	expiredInstanceProfiles := getExpiredInstanceProfiles(sessions.IAM, options)
-
-	count, start := common.ElemToDeleteFormattedInfos("expired instance profile", len(expiredInstanceProfiles), "Global")
+// Evaluate also selects instance profiles without roles older than 4 hours.
+func (c iamInstanceProfileCleaner) Evaluate(resource common.CloudProviderResource) bool {
+	instanceProfile := resource.Payload.(InstanceProfile)
+	if len(instanceProfile.Roles) == 0 && time.Now().UTC().After(instanceProfile.CreationDate.Add(4*time.Hour)) {
+		return true
+	}
 
-	log.Info(count)
+	return c.awsCleaner.Evaluate(resource)
+}
 
-	if options.DryRun || len(expiredInstanceProfiles) == 0 {
-		return
+func (c iamInstanceProfileCleaner) MatchedRule(resource common.CloudProviderResource) string {
+	instanceProfile := resource.Payload.(InstanceProfile)
+	if len(instanceProfile.Roles) == 0 && time.Now().UTC().After(instanceProfile.CreationDate.Add(4*time.Hour)) {
+		return "instance-profile-without-role"
 	}
 
-	log.Info(start)
+	return c.awsCleaner.MatchedRule(resource)
+}
 
-	for _, expiredInstanceProfile := range expiredInstanceProfiles {
-		_, err := sessions.IAM.DeleteInstanceProfile(
-			&iam.DeleteInstanceProfileInput{
-				InstanceProfileName: &expiredInstanceProfile.InstanceProfileName,
-			})
+func (c iamInstanceProfileCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	instanceProfile := resource.Payload.(InstanceProfile)
+	_, err := c.sessions.IAM.DeleteInstanceProfileWithContext(ctx,
+		&iam.DeleteInstanceProfileInput{
+			InstanceProfileName: &instanceProfile.InstanceProfileName,
+		})
 
-		if err != nil {
-			log.Errorf("Can't delete instace profile %s : %s", expiredInstanceProfile.InstanceProfileName, err.Error())
-		} else {
-			log.Debugf("Instance profile %s deleted.", expiredInstanceProfile.InstanceProfileName)
-		}
-	}
+	return err
 }
@@ -1,9 +1,10 @@
 package aws
 
 import (
+	"context"
 	"github.com/Qovery/pleco/pkg/common"
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/iam"
-	log "github.com/sirupsen/logrus"
 )
 
 type OpenIDConnectProvider struct {
@@ -11,26 +12,30 @@ type OpenIDConnectProvider struct {
 	OpenIDConnectProviderName string
 }
 
-func getOpenIDConnectProviders(iamSession *iam.IAM, tagName string) []OpenIDConnectProvider {
+func getOpenIDConnectProviders(ctx context.Context, iamSession *iam.IAM, tagName string, tagIndex *taggedResources) ([]OpenIDConnectProvider, error) {
 	var openIDConnectProviders []OpenIDConnectProvider
 
-	result, err := iamSession.ListOpenIDConnectProviders(&iam.ListOpenIDConnectProvidersInput{})
-
+	result, err := iamSession.ListOpenIDConnectProvidersWithContext(ctx, &iam.ListOpenIDConnectProvidersInput{})
 	if err != nil {
-		log.Error(err)
+		return nil, err
 	}
 
 	for _, openIDConnectProvider := range result.OpenIDConnectProviderList {
-		tagsResult, tagsErr := iamSession.ListOpenIDConnectProviderTags(&iam.ListOpenIDConnectProviderTagsInput{
-			OpenIDConnectProviderArn: openIDConnectProvider.Arn,
-		})
+		var tags interface{}
+		if indexedTags, ok := tagIndex.lookup(aws.StringValue(openIDConnectProvider.Arn)); ok {
+			tags = indexedTags
+		} else {
+			tagsResult, tagsErr := iamSession.ListOpenIDConnectProviderTagsWithContext(ctx, &iam.ListOpenIDConnectProviderTagsInput{
+				OpenIDConnectProviderArn: openIDConnectProvider.Arn,
+			})
 
-		if tagsErr != nil {
-			log.Error(tagsErr)
-			continue
+			if tagsErr != nil {
+				return nil, tagsErr
+			}
+			tags = tagsResult.Tags
 		}
 
-		essentialTags := common.GetEssentialTags(tagsResult.Tags, tagName)
+		essentialTags := common.GetEssentialTags(tags, tagName)
 		openIDConnectProviders = append(openIDConnectProviders, OpenIDConnectProvider{
 			CloudProviderResource: common.CloudProviderResource{
 				Identifier:   *openIDConnectProvider.Arn,
@@ -39,51 +44,41 @@ func getOpenIDConnectProviders(iamSession *iam.IAM, tagName string) []OpenIDConn
 				TTL:          essentialTags.TTL,
 				Tag:          essentialTags.Tag,
 				IsProtected:  essentialTags.IsProtected,
+				ExpiresAt:    essentialTags.ExpiresAt,
+				Warnings:     essentialTags.Warnings,
+				Tags:         essentialTags.Tags,
 			},
 			OpenIDConnectProviderName: openIDConnectProvider.String(),
 		})
 
 	}
 
-	return openIDConnectProviders
+	return openIDConnectProviders, nil
 }
 
-func getExpiredOpenIDConnectProviders(iamSession *iam.IAM, options *AwsOptions) []OpenIDConnectProvider {
-	openIDConnectProviders := getOpenIDConnectProviders(iamSession, options.TagName)
-
-	var expiredOpenIDConnectProviders []OpenIDConnectProvider
-	for _, openIDConnectProvider := range openIDConnectProviders {
-		if openIDConnectProvider.IsResourceExpired(options.TagValue, options.DisableTTLCheck) {
-			expiredOpenIDConnectProviders = append(expiredOpenIDConnectProviders, openIDConnectProvider)
-		}
-	}
-
-	return expiredOpenIDConnectProviders
+type oidcProviderCleaner struct {
+	awsCleaner
 }
 
-func DeleteExpiredOpenIDConnectProviders(sessions *AWSSessions, options *AwsOptions) {
-	expiredOpenIDConnectProviders := getExpiredOpenIDConnectProviders(sessions.IAM, options)
-
-	count, start := common.ElemToDeleteFormattedInfos("expired OpenId Connect provider", len(expiredOpenIDConnectProviders), "Global")
-
-	log.Info(count)
+func (c oidcProviderCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	openIDConnectProviders, err := getOpenIDConnectProviders(ctx, c.sessions.IAM, c.options.TagName, c.options.tagIndex)
+	if err != nil {
+		return nil, err
+	}
 
-	if options.DryRun || len(expiredOpenIDConnectProviders) == 0 {
-		return
+	var resources []common.CloudProviderResource
+	for _, openIDConnectProvider := range openIDConnectProviders {
+		resources = append(resources, openIDConnectProvider.CloudProviderResource)
 	}
 
-	log.Info(start)
+	return resources, nil
+}
 
-	for _, expiredOpenIDConnectProvider := range expiredOpenIDConnectProviders {
-		_, err := sessions.IAM.DeleteOpenIDConnectProvider(
-			&iam.DeleteOpenIDConnectProviderInput{
-				OpenIDConnectProviderArn: &expiredOpenIDConnectProvider.Identifier,
-			})
+func (c oidcProviderCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	_, err := c.sessions.IAM.DeleteOpenIDConnectProviderWithContext(ctx,
+		&iam.DeleteOpenIDConnectProviderInput{
+			OpenIDConnectProviderArn: &resource.Identifier,
+		})
 
-		if err != nil {
-			log.Errorf("Can't delete OpenId Connect provider %s : %s", expiredOpenIDConnectProvider.OpenIDConnectProviderName, err.Error())
-		} else {
-			log.Debugf("OpenId Connect provider %s deleted.", expiredOpenIDConnectProvider.OpenIDConnectProviderName)
-		}
-	}
+	return err
 }
@@ -1,12 +1,14 @@
 package aws
 
 import (
-	"fmt"
+	"context"
 	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/iam"
 	log "github.com/sirupsen/logrus"
+
+	"github.com/Qovery/pleco/pkg/common"
 )
 
 type Policy struct {
@@ -14,41 +16,45 @@ type Policy struct {
 	Arn  string
 }
 
-func getPolicies(iamSession *iam.IAM) []*iam.Policy {
-	result, err := iamSession.ListPolicies(
+func getPolicies(ctx context.Context, iamSession *iam.IAM) ([]*iam.Policy, error) {
+	var policies []*iam.Policy
+	err := iamSession.ListPoliciesPagesWithContext(ctx,
 		&iam.ListPoliciesInput{
 			MaxItems: aws.Int64(1000),
+			Scope:    aws.String(iam.PolicyScopeTypeLocal),
+		}, func(page *iam.ListPoliciesOutput, lastPage bool) bool {
+			policies = append(policies, page.Policies...)
+			return true
 		})
 
-	if err != nil {
-		log.Error(err)
-		return nil
-	}
-
-	return result.Policies
+	return policies, err
 }
 
-func getPolicyVersions(iamSession *iam.IAM, policy iam.Policy) []*iam.PolicyVersion {
-	result, err := iamSession.ListPolicyVersions(
+func getPolicyVersions(ctx context.Context, iamSession *iam.IAM, policy iam.Policy) []*iam.PolicyVersion {
+	var versions []*iam.PolicyVersion
+	err := iamSession.ListPolicyVersionsPagesWithContext(ctx,
 		&iam.ListPolicyVersionsInput{
 			MaxItems:  aws.Int64(1000),
 			PolicyArn: aws.String(*policy.Arn),
+		}, func(page *iam.ListPolicyVersionsOutput, lastPage bool) bool {
+			versions = append(versions, page.Versions...)
+			return true
 		})
 
 	if err != nil {
 		log.Errorf("Can't get versions of policy %s : %s", *policy.PolicyName, err.Error())
 	}
 
-	return result.Versions
+	return versions
 
 }
 
-func deletePolicyVersions(iamSession *iam.IAM, policy iam.Policy) {
-	versions := getPolicyVersions(iamSession, policy)
+func deletePolicyVersions(ctx context.Context, iamSession *iam.IAM, policy iam.Policy) {
+	versions := getPolicyVersions(ctx, iamSession, policy)
 
 	for _, version := range versions {
 		if !*version.IsDefaultVersion {
-			_, err := iamSession.DeletePolicyVersion(
+			_, err := iamSession.DeletePolicyVersionWithContext(ctx,
 				&iam.DeletePolicyVersionInput{
 					PolicyArn: aws.String(*policy.Arn),
 					VersionId: aws.String(*version.VersionId),
@@ -63,59 +69,61 @@ func deletePolicyVersions(iamSession *iam.IAM, policy iam.Policy) {
 	}
 }
 
-func DeleteDetachedPolicies(sessions *AWSSessions, dryRun bool) {
-	iamSession := sessions.IAM
-	policies := getPolicies(iamSession)
-	var detachedPolicies []iam.Policy
-
-	for _, policy := range policies {
-		arn := *policy.Arn
-		if *policy.AttachmentCount == 0 && !strings.Contains(arn, ":aws:policy") {
-			detachedPolicies = append(detachedPolicies, *policy)
-		}
-	}
+type iamPolicyCleaner struct {
+	awsCleaner
+	common.AlwaysEvaluator
+}
 
-	s := "There is no detached IAM policy to delete."
-	if len(detachedPolicies) == 1 {
-		s = "There is 1 detached IAM policy to delete."
-	}
-	if len(detachedPolicies) > 1 {
-		s = fmt.Sprintf("There are %d detached IAM policies to delete.", len(detachedPolicies))
+func (c iamPolicyCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	policies, err := getPolicies(ctx, c.sessions.IAM)
+	if err != nil {
+		return nil, err
 	}
 
-	log.Info(s)
-
-	if dryRun || len(detachedPolicies) == 0 {
-		return
+	var resources []common.CloudProviderResource
+	for _, policy := range policies {
+		if *policy.AttachmentCount == 0 && !strings.Contains(*policy.Arn, ":aws:policy") {
+			resources = append(resources, common.CloudProviderResource{
+				Identifier:  *policy.Arn,
+				Description: "IAM policy: " + *policy.PolicyName,
+				Payload:     *policy,
+			})
+		}
 	}
 
-	log.Info("Starting detached policies deletion.")
+	return resources, nil
+}
 
-	for _, expiredPolicy := range detachedPolicies {
-		deletePolicyVersions(iamSession, expiredPolicy)
+func (c iamPolicyCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	deletePolicyVersions(ctx, c.sessions.IAM, resource.Payload.(iam.Policy))
 
-		_, err := iamSession.DeletePolicy(
-			&iam.DeletePolicyInput{
-				PolicyArn: aws.String(*expiredPolicy.Arn),
-			})
+	_, err := c.sessions.IAM.DeletePolicyWithContext(ctx,
+		&iam.DeletePolicyInput{
+			PolicyArn: aws.String(resource.Identifier),
+		})
 
-		if err != nil {
-			log.Errorf("Can't delete policy %s : %s", *expiredPolicy.PolicyName, err.Error())
-		}
-	}
+	return err
 }
 
-func getUserPolicies(iamSession *iam.IAM, userName string) []Policy {
-	attachedPolicies, policyErr := iamSession.ListAttachedUserPolicies(
+func getUserPolicies(ctx context.Context, iamSession *iam.IAM, userName string) []Policy {
+	var attachedPolicies []*iam.AttachedPolicy
+	policyErr := iamSession.ListAttachedUserPoliciesPagesWithContext(ctx,
 		&iam.ListAttachedUserPoliciesInput{
 			MaxItems: aws.Int64(1000),
 			UserName: aws.String(userName),
+		}, func(page *iam.ListAttachedUserPoliciesOutput, lastPage bool) bool {
+			attachedPolicies = append(attachedPolicies, page.AttachedPolicies...)
+			return true
 		})
 
-	policyNames, namesErr := iamSession.ListUserPolicies(
+	var policyNames []*string
+	namesErr := iamSession.ListUserPoliciesPagesWithContext(ctx,
 		&iam.ListUserPoliciesInput{
 			MaxItems: aws.Int64(1000),
 			UserName: aws.String(userName),
+		}, func(page *iam.ListUserPoliciesOutput, lastPage bool) bool {
+			policyNames = append(policyNames, page.PolicyNames...)
+			return true
 		})
 
 	if policyErr != nil {
@@ -129,7 +137,7 @@ func getUserPolicies(iamSession *iam.IAM, userName string) []Policy {
 	}
 
 	var userPolicies []Policy
-	for _, policy := range attachedPolicies.AttachedPolicies {
+	for _, policy := range attachedPolicies {
 		userPolicy := Policy{
 			Arn:  *policy.PolicyArn,
 			Name: *policy.PolicyName,
@@ -137,7 +145,7 @@ func getUserPolicies(iamSession *iam.IAM, userName string) []Policy {
 		userPolicies = append(userPolicies, userPolicy)
 	}
 
-	for _, policyName := range policyNames.PolicyNames {
+	for _, policyName := range policyNames {
 		userPolicy := Policy{
 			Arn:  "",
 			Name: *policyName,
@@ -148,10 +156,10 @@ func getUserPolicies(iamSession *iam.IAM, userName string) []Policy {
 	return userPolicies
 }
 
-func detachUserPolicies(iamSession *iam.IAM, userName string, policies []Policy) {
+func detachUserPolicies(ctx context.Context, iamSession *iam.IAM, userName string, policies []Policy) {
 	for _, policy := range policies {
 		if policy.Arn != "" {
-			_, err := iamSession.DetachUserPolicy(
+			_, err := iamSession.DetachUserPolicyWithContext(ctx,
 				&iam.DetachUserPolicyInput{
 					UserName:  aws.String(userName),
 					PolicyArn: aws.String(policy.Arn),
@@ -166,10 +174,10 @@ func detachUserPolicies(iamSession *iam.IAM, userName string, policies []Policy)
 	}
 }
 
-func deleteUserPolicies(iamSession *iam.IAM, userName string, policies []Policy) {
+func deleteUserPolicies(ctx context.Context, iamSession *iam.IAM, userName string, policies []Policy) {
 	for _, policy := range policies {
 		if !strings.Contains(policy.Arn, ":aws:policy") {
-			_, err := iamSession.DeleteUserPolicy(
+			_, err := iamSession.DeleteUserPolicyWithContext(ctx,
 				&iam.DeleteUserPolicyInput{
 					UserName:   aws.String(userName),
 					PolicyName: aws.String(policy.Name),
@@ -184,23 +192,31 @@ func deleteUserPolicies(iamSession *iam.IAM, userName string, policies []Policy)
 	}
 }
 
-func HandleUserPolicies(iamSession *iam.IAM, userName string) {
-	policies := getUserPolicies(iamSession, userName)
-	detachUserPolicies(iamSession, userName, policies)
-	deleteUserPolicies(iamSession, userName, policies)
+func HandleUserPolicies(ctx context.Context, iamSession *iam.IAM, userName string) {
+	policies := getUserPolicies(ctx, iamSession, userName)
+	detachUserPolicies(ctx, iamSession, userName, policies)
+	deleteUserPolicies(ctx, iamSession, userName, policies)
 }
 
-func getRolePolicies(iamSession *iam.IAM, roleName string) []Policy {
-	attachedPolicies, policyErr := iamSession.ListAttachedRolePolicies(
+func getRolePolicies(ctx context.Context, iamSession *iam.IAM, roleName string) []Policy {
+	var attachedPolicies []*iam.AttachedPolicy
+	policyErr := iamSession.ListAttachedRolePoliciesPagesWithContext(ctx,
 		&iam.ListAttachedRolePoliciesInput{
 			MaxItems: aws.Int64(1000),
 			RoleName: aws.String(roleName),
+		}, func(page *iam.ListAttachedRolePoliciesOutput, lastPage bool) bool {
+			attachedPolicies = append(attachedPolicies, page.AttachedPolicies...)
+			return true
 		})
 
-	policyNames, namesErr := iamSession.ListRolePolicies(
+	var policyNames []*string
+	namesErr := iamSession.ListRolePoliciesPagesWithContext(ctx,
 		&iam.ListRolePoliciesInput{
 			MaxItems: aws.Int64(1000),
 			RoleName: aws.String(roleName),
+		}, func(page *iam.ListRolePoliciesOutput, lastPage bool) bool {
+			policyNames = append(policyNames, page.PolicyNames...)
+			return true
 		})
 
 	if policyErr != nil {
@@ -214,7 +230,7 @@ func getRolePolicies(iamSession *iam.IAM, roleName string) []Policy {
 	}
 
 	var rolePolicies []Policy
-	for _, policy := range attachedPolicies.AttachedPolicies {
+	for _, policy := range attachedPolicies {
 		userPolicy := Policy{
 			Arn:  *policy.PolicyArn,
 			Name: *policy.PolicyName,
@@ -222,7 +238,7 @@ func getRolePolicies(iamSession *iam.IAM, roleName string) []Policy {
 		rolePolicies = append(rolePolicies, userPolicy)
 	}
 
-	for _, policyName := range policyNames.PolicyNames {
+	for _, policyName := range policyNames {
 		userPolicy := Policy{
 			Arn:  "",
 			Name: *policyName,
@@ -233,10 +249,10 @@ func getRolePolicies(iamSession *iam.IAM, roleName string) []Policy {
 	return rolePolicies
 }
 
-func detachRolePolicies(iamSession *iam.IAM, roleName string, policies []Policy) {
+func detachRolePolicies(ctx context.Context, iamSession *iam.IAM, roleName string, policies []Policy) {
 	for _, policy := range policies {
 		if policy.Arn != "" {
-			_, err := iamSession.DetachRolePolicy(
+			_, err := iamSession.DetachRolePolicyWithContext(ctx,
 				&iam.DetachRolePolicyInput{
 					RoleName:  aws.String(roleName),
 					PolicyArn: aws.String(policy.Arn),
@@ -248,10 +264,10 @@ func detachRolePolicies(iamSession *iam.IAM, roleName string, policies []Policy)
 	}
 }
 
-func deleteRolePolicies(iamSession *iam.IAM, roleName string, policies []Policy) {
+func deleteRolePolicies(ctx context.Context, iamSession *iam.IAM, roleName string, policies []Policy) {
 	for _, policy := range policies {
 		if !strings.Contains(policy.Arn, ":aws:policy") {
-			_, err := iamSession.DeleteRolePolicy(
+			_, err := iamSession.DeleteRolePolicyWithContext(ctx,
 				&iam.DeleteRolePolicyInput{
 					RoleName:   aws.String(roleName),
 					PolicyName: aws.String(policy.Name),
@@ -263,8 +279,8 @@ func deleteRolePolicies(iamSession *iam.IAM, roleName string, policies []Policy)
 	}
 }
 
-func HandleRolePolicies(iamSession *iam.IAM, roleName string) {
-	policies := getRolePolicies(iamSession, roleName)
-	deleteRolePolicies(iamSession, roleName, policies)
-	detachRolePolicies(iamSession, roleName, policies)
+func HandleRolePolicies(ctx context.Context, iamSession *iam.IAM, roleName string) {
+	policies := getRolePolicies(ctx, iamSession, roleName)
+	deleteRolePolicies(ctx, iamSession, roleName, policies)
+	detachRolePolicies(ctx, iamSession, roleName, policies)
 }
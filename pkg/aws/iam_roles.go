@@ -1,6 +1,7 @@
 package aws
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -16,25 +17,38 @@ type Role struct {
 	InstanceProfile []*iam.InstanceProfile
 }
 
-func getRoles(iamSession *iam.IAM, tagName string) []Role {
-	result, err := iamSession.ListRoles(
+func getRoles(ctx context.Context, iamSession *iam.IAM, tagName string, tagIndex *taggedResources) ([]Role, error) {
+	var allRoles []*iam.Role
+	err := iamSession.ListRolesPagesWithContext(ctx,
 		&iam.ListRolesInput{
 			MaxItems: aws.Int64(1000),
+		}, func(page *iam.ListRolesOutput, lastPage bool) bool {
+			allRoles = append(allRoles, page.Roles...)
+			return true
 		})
-
 	if err != nil {
-		log.Error(err)
-		return nil
+		return nil, err
 	}
 
 	var roles []Role
 
-	for _, role := range result.Roles {
+	for _, role := range allRoles {
 		if strings.HasPrefix(*role.RoleName, "AWS") {
 			continue
 		}
-		tags := getRoleTags(iamSession, *role.RoleName)
-		instanceProfiles := getRoleInstanceProfile(iamSession, *role.RoleName)
+		var tags interface{}
+		if indexedTags, ok := tagIndex.lookup(aws.StringValue(role.Arn)); ok {
+			tags = indexedTags
+		} else {
+			tags, err = getRoleTags(ctx, iamSession, *role.RoleName)
+			if err != nil {
+				return nil, err
+			}
+		}
+		instanceProfiles, err := getRoleInstanceProfile(ctx, iamSession, *role.RoleName)
+		if err != nil {
+			return nil, err
+		}
 		essentialTags := common.GetEssentialTags(tags, tagName)
 		newRole := Role{
 			CloudProviderResource: common.CloudProviderResource{
@@ -44,6 +58,9 @@ func getRoles(iamSession *iam.IAM, tagName string) []Role {
 				TTL:          essentialTags.TTL,
 				Tag:          essentialTags.Tag,
 				IsProtected:  essentialTags.IsProtected,
+				ExpiresAt:    essentialTags.ExpiresAt,
+				Warnings:     essentialTags.Warnings,
+				Tags:         essentialTags.Tags,
 			},
 			InstanceProfile: instanceProfiles,
 		}
@@ -51,84 +68,75 @@ func getRoles(iamSession *iam.IAM, tagName string) []Role {
 		roles = append(roles, newRole)
 	}
 
-	return roles
+	return roles, nil
 }
 
-func getRoleTags(iamSession *iam.IAM, roleName string) []*iam.Tag {
-	tags, err := iamSession.ListRoleTags(
+func getRoleTags(ctx context.Context, iamSession *iam.IAM, roleName string) ([]*iam.Tag, error) {
+	tags, err := iamSession.ListRoleTagsWithContext(ctx,
 		&iam.ListRoleTagsInput{
 			RoleName: aws.String(roleName),
 		})
-
 	if err != nil {
-		log.Error(err)
-		return nil
+		return nil, err
 	}
 
-	return tags.Tags
+	return tags.Tags, nil
 }
 
-func getRoleInstanceProfile(iamSession *iam.IAM, roleName string) []*iam.InstanceProfile {
-	result, err := iamSession.ListInstanceProfilesForRole(
+func getRoleInstanceProfile(ctx context.Context, iamSession *iam.IAM, roleName string) ([]*iam.InstanceProfile, error) {
+	var instanceProfiles []*iam.InstanceProfile
+	err := iamSession.ListInstanceProfilesForRolePagesWithContext(ctx,
 		&iam.ListInstanceProfilesForRoleInput{
 			MaxItems: aws.Int64(1000),
 			RoleName: aws.String(roleName),
+		}, func(page *iam.ListInstanceProfilesForRoleOutput, lastPage bool) bool {
+			instanceProfiles = append(instanceProfiles, page.InstanceProfiles...)
+			return true
 		})
-
 	if err != nil {
-		log.Errorf("Can't get instance profiles for role %s : %s", roleName, err)
+		return nil, fmt.Errorf("can't get instance profiles for role %s: %w", roleName, err)
 	}
 
-	return result.InstanceProfiles
+	return instanceProfiles, nil
 }
 
-func DeleteExpiredRoles(sessions *AWSSessions, options *AwsOptions) {
-	iamSession := sessions.IAM
-	roles := getRoles(iamSession, options.TagName)
-	var expiredRoles []Role
+type iamRoleCleaner struct {
+	awsCleaner
+}
 
-	for _, role := range roles {
-		if role.CloudProviderResource.IsResourceExpired(options.TagValue, options.DisableTTLCheck) {
-			expiredRoles = append(expiredRoles, role)
-		}
+func (c iamRoleCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	roles, err := getRoles(ctx, c.sessions.IAM, c.options.TagName, c.options.tagIndex)
+	if err != nil {
+		return nil, err
 	}
 
-	s := "There is no expired IAM role to delete."
-	if len(expiredRoles) == 1 {
-		s = "There is 1 expired IAM role to delete."
-	}
-	if len(expiredRoles) > 1 {
-		s = fmt.Sprintf("There are %d expired IAM roles to delete.", len(expiredRoles))
+	var resources []common.CloudProviderResource
+	for _, role := range roles {
+		resource := role.CloudProviderResource
+		resource.Payload = role
+		resources = append(resources, resource)
 	}
 
-	log.Info(s)
-
-	if options.DryRun || len(expiredRoles) == 0 {
-		return
-	}
+	return resources, nil
+}
 
-	log.Info("Starting expired IAM roles deletion.")
+func (c iamRoleCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	role := resource.Payload.(Role)
 
-	for _, role := range expiredRoles {
-		HandleRolePolicies(iamSession, role.Identifier)
-		removeRoleFromInstanceProfile(iamSession, role.InstanceProfile, role.Identifier)
+	HandleRolePolicies(ctx, c.sessions.IAM, role.Identifier)
+	removeRoleFromInstanceProfile(ctx, c.sessions.IAM, role.InstanceProfile, role.Identifier)
 
-		_, err := iamSession.DeleteRole(
-			&iam.DeleteRoleInput{
-				RoleName: aws.String(role.Identifier),
-			})
+	_, err := c.sessions.IAM.DeleteRoleWithContext(ctx,
+		&iam.DeleteRoleInput{
+			RoleName: aws.String(role.Identifier),
+		})
 
-		if err != nil {
-			log.Errorf("Can't delete role %s : %s", role.Identifier, err)
-		} else {
-			log.Debugf("Iam Role %s in %s deleted.", role.Identifier, *iamSession.Config.Region)
-		}
-	}
+	return err
 }
 
 //func deleteRoleInstanceProfiles(iamSession *iam.IAM, roleInstanceProfiles []*iam.InstanceProfile) {
 //	for _, instanceProfile := range roleInstanceProfiles {
-//		_, err := iamSession.DeleteInstanceProfile(
+//		_, err := iamSession.DeleteInstanceProfileWithContext(ctx,
 //			&iam.DeleteInstanceProfileInput{
 //				InstanceProfileName: aws.String(*instanceProfile.InstanceProfileName),
 //			})
@@ -140,9 +148,9 @@ func DeleteExpiredRoles(sessions *AWSSessions, options *AwsOptions) {
 //
 //}
 
-func removeRoleFromInstanceProfile(iamSession *iam.IAM, roleInstanceProfiles []*iam.InstanceProfile, roleName string) {
+func removeRoleFromInstanceProfile(ctx context.Context, iamSession *iam.IAM, roleInstanceProfiles []*iam.InstanceProfile, roleName string) {
 	for _, instanceProfile := range roleInstanceProfiles {
-		_, err := iamSession.RemoveRoleFromInstanceProfile(
+		_, err := iamSession.RemoveRoleFromInstanceProfileWithContext(ctx,
 			&iam.RemoveRoleFromInstanceProfileInput{
 				InstanceProfileName: aws.String(*instanceProfile.InstanceProfileName),
 				RoleName:            aws.String(roleName),
@@ -1,22 +1,25 @@
 package aws
 
 import (
-	log "github.com/sirupsen/logrus"
+	"github.com/Qovery/pleco/pkg/common"
 )
 
-func DeleteExpiredIAM(sessions *AWSSessions, options *AwsOptions) {
-	log.Info("Listing all IAM users.")
-	DeleteExpiredUsers(sessions, options)
-
-	log.Info("Listing all IAM roles.")
-	DeleteExpiredRoles(sessions, options)
-
-	log.Info("Listing all IAM policies.")
-	DeleteDetachedPolicies(sessions, options.DryRun)
-
-	log.Info("Listing all IAM instance profiles.")
-	DeleteExpiredInstanceProfiles(sessions, options)
-
-	log.Info("Listing all IAM OpenId Connect providers.")
-	DeleteExpiredOpenIDConnectProviders(sessions, options)
+// IAM cleaners are registered together so they run in dependency order: users and roles release the policies and
+// instance profiles cleaned afterwards.
+func init() {
+	registerGlobalCleaner("iam", "iam-user", "expired IAM user", func(base awsCleaner) common.Cleaner {
+		return iamUserCleaner{base}
+	})
+	registerGlobalCleaner("iam", "iam-role", "expired IAM role", func(base awsCleaner) common.Cleaner {
+		return iamRoleCleaner{base}
+	})
+	registerGlobalCleaner("iam", "iam-policy", "detached IAM policy", func(base awsCleaner) common.Cleaner {
+		return iamPolicyCleaner{awsCleaner: base}
+	})
+	registerGlobalCleaner("iam", "iam-instance-profile", "expired instance profile", func(base awsCleaner) common.Cleaner {
+		return iamInstanceProfileCleaner{base}
+	})
+	registerGlobalCleaner("iam", "iam-oidc-provider", "expired OpenId Connect provider", func(base awsCleaner) common.Cleaner {
+		return oidcProviderCleaner{base}
+	})
 }
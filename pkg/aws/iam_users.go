@@ -1,7 +1,7 @@
 package aws
 
 import (
-	"fmt"
+	"context"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/iam"
@@ -14,21 +14,31 @@ type User struct {
 	common.CloudProviderResource
 }
 
-func getUsers(iamSession *iam.IAM, tagName string) []User {
-	result, err := iamSession.ListUsers(
+func getUsers(ctx context.Context, iamSession *iam.IAM, tagName string, tagIndex *taggedResources) ([]User, error) {
+	var allUsers []*iam.User
+	err := iamSession.ListUsersPagesWithContext(ctx,
 		&iam.ListUsersInput{
 			MaxItems: aws.Int64(1000),
+		}, func(page *iam.ListUsersOutput, lastPage bool) bool {
+			allUsers = append(allUsers, page.Users...)
+			return true
 		})
-
 	if err != nil {
-		log.Error(err)
-		return nil
+		return nil, err
 	}
 
 	var users []User
 
-	for _, user := range result.Users {
-		tags := getUserTags(iamSession, *user.UserName)
+	for _, user := range allUsers {
+		var tags interface{}
+		if indexedTags, ok := tagIndex.lookup(aws.StringValue(user.Arn)); ok {
+			tags = indexedTags
+		} else {
+			tags, err = getUserTags(ctx, iamSession, *user.UserName)
+			if err != nil {
+				return nil, err
+			}
+		}
 		essentialTags := common.GetEssentialTags(tags, tagName)
 		newUser := User{
 			CloudProviderResource: common.CloudProviderResource{
@@ -38,6 +48,9 @@ func getUsers(iamSession *iam.IAM, tagName string) []User {
 				TTL:          essentialTags.TTL,
 				Tag:          essentialTags.Tag,
 				IsProtected:  essentialTags.IsProtected,
+				ExpiresAt:    essentialTags.ExpiresAt,
+				Warnings:     essentialTags.Warnings,
+				Tags:         essentialTags.Tags,
 			},
 		}
 
@@ -45,25 +58,23 @@ func getUsers(iamSession *iam.IAM, tagName string) []User {
 
 	}
 
-	return users
+	return users, nil
 }
 
-func getUserTags(iamSession *iam.IAM, roleName string) []*iam.Tag {
-	tags, err := iamSession.ListUserTags(
+func getUserTags(ctx context.Context, iamSession *iam.IAM, roleName string) ([]*iam.Tag, error) {
+	tags, err := iamSession.ListUserTagsWithContext(ctx,
 		&iam.ListUserTagsInput{
 			UserName: aws.String(roleName),
 		})
-
 	if err != nil {
-		log.Error(err)
-		return nil
+		return nil, err
 	}
 
-	return tags.Tags
+	return tags.Tags, nil
 }
 
-func getUserAccessKeysIds(iamSession *iam.IAM, userName string) []*string {
-	result, err := iamSession.ListAccessKeys(
+func getUserAccessKeysIds(ctx context.Context, iamSession *iam.IAM, userName string) []*string {
+	result, err := iamSession.ListAccessKeysWithContext(ctx,
 		&iam.ListAccessKeysInput{
 			UserName: aws.String(userName),
 		})
@@ -81,8 +92,8 @@ func getUserAccessKeysIds(iamSession *iam.IAM, userName string) []*string {
 	return accessKeysIds
 }
 
-func deleteUserAccessKey(iamSession *iam.IAM, userName string, accessKeyId string) {
-	_, err := iamSession.DeleteAccessKey(
+func deleteUserAccessKey(ctx context.Context, iamSession *iam.IAM, userName string, accessKeyId string) {
+	_, err := iamSession.DeleteAccessKeyWithContext(ctx,
 		&iam.DeleteAccessKeyInput{
 			UserName:    aws.String(userName),
 			AccessKeyId: aws.String(accessKeyId),
@@ -96,53 +107,40 @@ func deleteUserAccessKey(iamSession *iam.IAM, userName string, accessKeyId strin
 
 }
 
-func deleteExpiredUserAccessKeys(iamSession *iam.IAM, userName string) {
-	accessKeysIds := getUserAccessKeysIds(iamSession, userName)
+func deleteExpiredUserAccessKeys(ctx context.Context, iamSession *iam.IAM, userName string) {
+	accessKeysIds := getUserAccessKeysIds(ctx, iamSession, userName)
 
 	for _, accessKeyId := range accessKeysIds {
-		deleteUserAccessKey(iamSession, userName, *accessKeyId)
+		deleteUserAccessKey(ctx, iamSession, userName, *accessKeyId)
 	}
 }
 
-func DeleteExpiredUsers(sessions *AWSSessions, options *AwsOptions) {
-	iamSession := sessions.IAM
-	users := getUsers(iamSession, options.TagName)
-	var expiredUsers []User
+type iamUserCleaner struct {
+	awsCleaner
+}
 
-	for _, user := range users {
-		if user.IsResourceExpired(options.TagValue, options.DisableTTLCheck) {
-			expiredUsers = append(expiredUsers, user)
-		}
+func (c iamUserCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	users, err := getUsers(ctx, c.sessions.IAM, c.options.TagName, c.options.tagIndex)
+	if err != nil {
+		return nil, err
 	}
 
-	s := "There is no expired IAM user to delete."
-	if len(expiredUsers) == 1 {
-		s = "There is 1 expired IAM user to delete."
-	}
-	if len(expiredUsers) > 1 {
-		s = fmt.Sprintf("There are %d expired IAM users to delete.", len(expiredUsers))
+	var resources []common.CloudProviderResource
+	for _, user := range users {
+		resources = append(resources, user.CloudProviderResource)
 	}
 
-	log.Info(s)
-
-	if options.DryRun || len(expiredUsers) == 0 {
-		return
-	}
+	return resources, nil
+}
 
-	log.Info("Starting expired IAM users deletion.")
+func (c iamUserCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	HandleUserPolicies(ctx, c.sessions.IAM, resource.Identifier)
+	deleteExpiredUserAccessKeys(ctx, c.sessions.IAM, resource.Identifier)
 
-	for _, user := range expiredUsers {
-		HandleUserPolicies(iamSession, user.Identifier)
-		deleteExpiredUserAccessKeys(iamSession, user.Identifier)
+	_, err := c.sessions.IAM.DeleteUserWithContext(ctx,
+		&iam.DeleteUserInput{
+			UserName: aws.String(resource.Identifier),
+		})
 
-		_, userErr := iamSession.DeleteUser(
-			&iam.DeleteUserInput{
-				UserName: aws.String(user.Identifier),
-			})
-		if userErr != nil {
-			log.Errorf("Can't delete user %s : %s", user.Identifier, userErr.Error())
-		} else {
-			log.Debugf("IAM user %s in %s deleted.", user.Identifier, *iamSession.Config.Region)
-		}
-	}
+	return err
 }
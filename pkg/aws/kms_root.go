@@ -1,11 +1,12 @@
 package aws
 
 import (
+	"context"
+	"fmt"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/kms"
 	log "github.com/sirupsen/logrus"
-	"time"
 
 	"github.com/Qovery/pleco/pkg/common"
 )
@@ -16,7 +17,7 @@ type CompleteKey struct {
 	KeyManager string
 }
 
-func getKeys(svc kms.KMS) []*kms.KeyListEntry {
+func getKeys(ctx context.Context, svc kms.KMS) ([]*kms.KeyListEntry, error) {
 	var marker *string
 	var keysOutput []*kms.KeyListEntry
 	for {
@@ -25,8 +26,10 @@ func getKeys(svc kms.KMS) []*kms.KeyListEntry {
 			Marker: marker,
 		}
 
-		keys, err := svc.ListKeys(input)
-		handleKMSError(err)
+		keys, err := svc.ListKeysWithContext(ctx, input)
+		if err != nil {
+			return nil, err
+		}
 		keysOutput = append(keysOutput, keys.Keys...)
 
 		if keys.NextMarker == nil || keys.NextMarker == marker {
@@ -36,26 +39,37 @@ func getKeys(svc kms.KMS) []*kms.KeyListEntry {
 		marker = keys.NextMarker
 	}
 
-	return keysOutput
+	return keysOutput, nil
 }
 
-func getCompleteKey(svc kms.KMS, keyId *string, tagName string) CompleteKey {
-	tags := getKeyTags(svc, keyId)
-	metaData := getKeyMetadata(svc, keyId)
+// getCompleteKey only reads the tags of the enabled customer keys, the other ones are never deleted.
+func getCompleteKey(ctx context.Context, svc kms.KMS, key *kms.KeyListEntry, tagName string, tagIndex *taggedResources) (CompleteKey, error) {
+	keyId := key.KeyId
+	metaData, err := getKeyMetadata(ctx, svc, keyId)
+	if err != nil {
+		return CompleteKey{}, err
+	}
 
-	if metaData == nil || tags == nil {
+	if !isDeletableKey(*metaData.KeyMetadata.KeyState, *metaData.KeyMetadata.KeyManager) {
 		return CompleteKey{
 			CloudProviderResource: common.CloudProviderResource{
-				Identifier:   *keyId,
-				Description:  "KMS: " + *keyId,
-				CreationDate: time.Now().UTC(),
-				TTL:          -1,
-				Tag:          "ttl",
-				IsProtected:  false,
+				Identifier:  *keyId,
+				Description: "KMS: " + *keyId,
 			},
-			Status:     "PendingDeletion",
-			KeyManager: "",
+			Status:     *metaData.KeyMetadata.KeyState,
+			KeyManager: *metaData.KeyMetadata.KeyManager,
+		}, nil
+	}
+
+	var tags interface{}
+	if indexedTags, ok := tagIndex.lookup(aws.StringValue(key.KeyArn)); ok {
+		tags = indexedTags
+	} else {
+		keyTags, err := getKeyTags(ctx, svc, keyId)
+		if err != nil {
+			return CompleteKey{}, err
 		}
+		tags = keyTags
 	}
 	essentialTags := common.GetEssentialTags(tags, tagName)
 
@@ -67,42 +81,53 @@ func getCompleteKey(svc kms.KMS, keyId *string, tagName string) CompleteKey {
 			TTL:          essentialTags.TTL,
 			Tag:          essentialTags.Tag,
 			IsProtected:  essentialTags.IsProtected,
+			ExpiresAt:    essentialTags.ExpiresAt,
+			Warnings:     essentialTags.Warnings,
+			Tags:         essentialTags.Tags,
 		},
 		Status:     *metaData.KeyMetadata.KeyState,
 		KeyManager: *metaData.KeyMetadata.KeyManager,
-	}
+	}, nil
+}
+
+func isDeletableKey(status string, keyManager string) bool {
+	return status != "PendingDeletion" && status != "Disabled" && keyManager == "CUSTOMER"
 }
 
-func deleteKey(svc kms.KMS, keyId string) (*kms.ScheduleKeyDeletionOutput, error) {
+func deleteKey(ctx context.Context, svc kms.KMS, keyId string) (*kms.ScheduleKeyDeletionOutput, error) {
 	input := &kms.ScheduleKeyDeletionInput{
 		KeyId:               aws.String(keyId),
 		PendingWindowInDays: aws.Int64(7),
 	}
 
-	result, err := svc.ScheduleKeyDeletion(input)
+	result, err := svc.ScheduleKeyDeletionWithContext(ctx, input)
 	handleKMSError(err)
 
 	return result, err
 }
 
-func getKeyTags(svc kms.KMS, keyId *string) []*kms.Tag {
+func getKeyTags(ctx context.Context, svc kms.KMS, keyId *string) ([]*kms.Tag, error) {
 	input := &kms.ListResourceTagsInput{
 		KeyId: aws.String(*keyId),
 	}
 
-	tags, err := svc.ListResourceTags(input)
-	handleKMSError(err)
+	tags, err := svc.ListResourceTagsWithContext(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("can't get tags of KMS key %s: %w", *keyId, err)
+	}
 
-	return tags.Tags
+	return tags.Tags, nil
 }
 
-func getKeyMetadata(svc kms.KMS, keyId *string) *kms.DescribeKeyOutput {
+func getKeyMetadata(ctx context.Context, svc kms.KMS, keyId *string) (*kms.DescribeKeyOutput, error) {
 	input := &kms.DescribeKeyInput{KeyId: keyId}
 
-	data, err := svc.DescribeKey(input)
-	handleKMSError(err)
+	data, err := svc.DescribeKeyWithContext(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("can't describe KMS key %s: %w", *keyId, err)
+	}
 
-	return data
+	return data, nil
 }
 
 func handleKMSError(error error) {
@@ -129,34 +154,38 @@ func handleKMSError(error error) {
 	}
 }
 
-func DeleteExpiredKeys(sessions AWSSessions, options AwsOptions) {
-	keys := getKeys(*sessions.KMS)
-	region := sessions.KMS.Config.Region
-	var expiredKeys []CompleteKey
-	for _, key := range keys {
-		completeKey := getCompleteKey(*sessions.KMS, key.KeyId, options.TagName)
-
-		if completeKey.Status != "PendingDeletion" && completeKey.Status != "Disabled" && completeKey.CloudProviderResource.IsResourceExpired(options.TagValue, options.DisableTTLCheck) && completeKey.KeyManager == "CUSTOMER" {
-			expiredKeys = append(expiredKeys, completeKey)
-		}
-	}
-
-	count, start := common.ElemToDeleteFormattedInfos("expired KMS key", len(expiredKeys), *region)
+type kmsKeyCleaner struct {
+	awsCleaner
+}
 
-	log.Info(count)
+func init() {
+	registerRegionalCleaner("kms", "kms-key", "expired KMS key", func(base awsCleaner) common.Cleaner {
+		return kmsKeyCleaner{base}
+	})
+}
 
-	if options.DryRun || len(expiredKeys) == 0 {
-		return
+func (c kmsKeyCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	keys, err := getKeys(ctx, *c.sessions.KMS)
+	if err != nil {
+		return nil, err
 	}
 
-	log.Info(start)
+	var resources []common.CloudProviderResource
+	for _, key := range keys {
+		completeKey, err := getCompleteKey(ctx, *c.sessions.KMS, key, c.options.TagName, c.options.tagIndex)
+		if err != nil {
+			return nil, err
+		}
 
-	for _, key := range expiredKeys {
-		_, deletionErr := deleteKey(*sessions.KMS, key.Identifier)
-		if deletionErr != nil {
-			log.Errorf("Deletion KMS key error %s/%s: %s", key.Identifier, *region, deletionErr)
-		} else {
-			log.Debugf("KMS key %s in %s deleted.", key.Identifier, *sessions.KMS.Config.Region)
+		if isDeletableKey(completeKey.Status, completeKey.KeyManager) {
+			resources = append(resources, completeKey.CloudProviderResource)
 		}
 	}
+
+	return resources, nil
+}
+
+func (c kmsKeyCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	_, err := deleteKey(ctx, *c.sessions.KMS, resource.Identifier)
+	return err
 }
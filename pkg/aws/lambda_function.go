@@ -1,10 +1,12 @@
 package aws
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/lambda"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/Qovery/pleco/pkg/common"
 )
@@ -17,16 +19,16 @@ func LambdaSession(sess session.Session, region string) *lambda.Lambda {
 	return lambda.New(&sess, &aws.Config{Region: aws.String(region)})
 }
 
-func tagFunctions(svc lambda.Lambda, functions *lambda.ListFunctionsOutput, tagName string) []lambdaFunction {
+func tagFunctions(ctx context.Context, svc lambda.Lambda, functions *lambda.ListFunctionsOutput, tagName string) ([]lambdaFunction, error) {
 	var taggedFunctions []lambdaFunction
 
 	for _, function := range functions.Functions {
 		input := &lambda.GetFunctionInput{
 			FunctionName: function.FunctionName,
 		}
-		getFunctionResult, err := svc.GetFunction(input)
+		getFunctionResult, err := svc.GetFunctionWithContext(ctx, input)
 		if err != nil {
-			continue
+			return nil, fmt.Errorf("can't get function %s: %w", *function.FunctionName, err)
 		}
 
 		essentialTags := common.GetEssentialTags(getFunctionResult.Tags, tagName)
@@ -39,17 +41,20 @@ func tagFunctions(svc lambda.Lambda, functions *lambda.ListFunctionsOutput, tagN
 				TTL:          essentialTags.TTL,
 				Tag:          essentialTags.Tag,
 				IsProtected:  essentialTags.IsProtected,
+				ExpiresAt:    essentialTags.ExpiresAt,
+				Warnings:     essentialTags.Warnings,
+				Tags:         essentialTags.Tags,
 			},
 		})
 
 	}
 
-	return taggedFunctions
+	return taggedFunctions, nil
 }
 
-func listTaggedFunctions(svc lambda.Lambda, tagName string) ([]lambdaFunction, error) {
+func listTaggedFunctions(ctx context.Context, svc lambda.Lambda, tagName string) ([]lambdaFunction, error) {
 
-	result, err := svc.ListFunctions(nil)
+	result, err := svc.ListFunctionsWithContext(ctx, nil)
 
 	if err != nil {
 		return nil, err
@@ -60,10 +65,13 @@ func listTaggedFunctions(svc lambda.Lambda, tagName string) ([]lambdaFunction, e
 		return nil, nil
 	}
 
-	taggedFunctions := tagFunctions(svc, result, tagName)
+	taggedFunctions, err := tagFunctions(ctx, svc, result, tagName)
+	if err != nil {
+		return nil, err
+	}
 
 	for result.NextMarker != nil {
-		result, err = svc.ListFunctions(&lambda.ListFunctionsInput{
+		result, err = svc.ListFunctionsWithContext(ctx, &lambda.ListFunctionsInput{
 			Marker: result.NextMarker,
 		})
 
@@ -71,14 +79,18 @@ func listTaggedFunctions(svc lambda.Lambda, tagName string) ([]lambdaFunction, e
 			return nil, err
 		}
 
-		taggedFunctions = append(taggedFunctions, tagFunctions(svc, result, tagName)...)
+		functions, err := tagFunctions(ctx, svc, result, tagName)
+		if err != nil {
+			return nil, err
+		}
+		taggedFunctions = append(taggedFunctions, functions...)
 	}
 
 	return taggedFunctions, nil
 }
 
-func deleteLambdaFunction(svc lambda.Lambda, function lambdaFunction) error {
-	_, err := svc.DeleteFunction(&lambda.DeleteFunctionInput{
+func deleteLambdaFunction(ctx context.Context, svc lambda.Lambda, function lambdaFunction) error {
+	_, err := svc.DeleteFunctionWithContext(ctx, &lambda.DeleteFunctionInput{
 		FunctionName: &function.Identifier,
 	},
 	)
@@ -89,43 +101,32 @@ func deleteLambdaFunction(svc lambda.Lambda, function lambdaFunction) error {
 	return nil
 }
 
-func getExpiredLambdaFunctions(ECsession *lambda.Lambda, options *AwsOptions) ([]lambdaFunction, string) {
-	functions, err := listTaggedFunctions(*ECsession, options.TagName)
-	region := *ECsession.Config.Region
-	if err != nil {
-		log.Errorf("Can't list Lambda Functions in region %s: %s", region, err.Error())
-	}
-
-	var expiredFunctions []lambdaFunction
-	for _, function := range functions {
-		log.Infof("Checking if %s function is expired", function.Identifier)
-		if function.IsResourceExpired(options.TagValue, options.DisableTTLCheck) {
-			expiredFunctions = append(expiredFunctions, function)
-		}
-	}
-
-	return expiredFunctions, region
+type lambdaFunctionCleaner struct {
+	awsCleaner
 }
 
-func DeleteExpiredLambdaFunctions(sessions AWSSessions, options AwsOptions) {
-	expiredFunctions, region := getExpiredLambdaFunctions(sessions.LambdaFunction, &options)
-
-	count, start := common.ElemToDeleteFormattedInfos("expired Lambda Function", len(expiredFunctions), region)
+func init() {
+	registerRegionalCleaner("lambda", "lambda-function", "expired Lambda Function", func(base awsCleaner) common.Cleaner {
+		return lambdaFunctionCleaner{base}
+	})
+}
 
-	log.Info(count)
+func (c lambdaFunctionCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	items, err := listTaggedFunctions(ctx, *c.sessions.LambdaFunction, c.options.TagName)
+	if err != nil {
+		return nil, err
+	}
 
-	if options.DryRun || len(expiredFunctions) == 0 {
-		return
+	var resources []common.CloudProviderResource
+	for _, item := range items {
+		resource := item.CloudProviderResource
+		resource.Payload = item
+		resources = append(resources, resource)
 	}
 
-	log.Info(start)
+	return resources, nil
+}
 
-	for _, function := range expiredFunctions {
-		deletionErr := deleteLambdaFunction(*sessions.LambdaFunction, function)
-		if deletionErr != nil {
-			log.Errorf("Deletion Lambda function error %s/%s: %s", function.Identifier, region, deletionErr.Error())
-		} else {
-			log.Debugf("Lambda function %s in %s deleted.", function.Identifier, region)
-		}
-	}
+func (c lambdaFunctionCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	return deleteLambdaFunction(ctx, *c.sessions.LambdaFunction, resource.Payload.(lambdaFunction))
 }
@@ -1,6 +1,7 @@
 package aws
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
@@ -18,19 +19,32 @@ type CompleteLogGroup struct {
 	clusterId string
 }
 
-func getCloudwatchLogs(svc *cloudwatchlogs.CloudWatchLogs) []*cloudwatchlogs.LogGroup {
+func getCloudwatchLogs(ctx context.Context, svc *cloudwatchlogs.CloudWatchLogs) ([]*cloudwatchlogs.LogGroup, error) {
 	input := &cloudwatchlogs.DescribeLogGroupsInput{
 		Limit: aws.Int64(50),
 	}
 
-	logs, err := svc.DescribeLogGroups(input)
-	handleCloudwatchLogsError(err)
+	var logGroups []*cloudwatchlogs.LogGroup
+	err := svc.DescribeLogGroupsPagesWithContext(ctx, input, func(page *cloudwatchlogs.DescribeLogGroupsOutput, lastPage bool) bool {
+		logGroups = append(logGroups, page.LogGroups...)
+		return true
+	})
 
-	return logs.LogGroups
+	return logGroups, err
 }
 
-func getCompleteLogGroup(svc *cloudwatchlogs.CloudWatchLogs, log cloudwatchlogs.LogGroup, tagName string) CompleteLogGroup {
-	tags := getLogGroupTag(svc, *log.Arn)
+func getCompleteLogGroup(ctx context.Context, svc *cloudwatchlogs.CloudWatchLogs, log cloudwatchlogs.LogGroup, tagName string, tagIndex *taggedResources) (CompleteLogGroup, error) {
+	var tags interface{}
+	// the ARN of a log group ends with :*, unlike the one returned by the tagging API
+	if indexedTags, ok := tagIndex.lookup(strings.TrimSuffix(*log.Arn, ":*")); ok {
+		tags = indexedTags
+	} else {
+		logGroupTags, err := getLogGroupTag(ctx, svc, *log.Arn)
+		if err != nil {
+			return CompleteLogGroup{}, err
+		}
+		tags = logGroupTags
+	}
 	essentialTags := common.GetEssentialTags(tags, tagName)
 
 	return CompleteLogGroup{
@@ -41,29 +55,35 @@ func getCompleteLogGroup(svc *cloudwatchlogs.CloudWatchLogs, log cloudwatchlogs.
 			TTL:          essentialTags.TTL,
 			Tag:          essentialTags.Tag,
 			IsProtected:  essentialTags.IsProtected,
+			ExpiresAt:    essentialTags.ExpiresAt,
+			Warnings:     essentialTags.Warnings,
+			Tags:         essentialTags.Tags,
 		},
 		clusterId: essentialTags.ClusterId,
-	}
+	}, nil
 }
 
-func deleteCloudwatchLog(svc cloudwatchlogs.CloudWatchLogs, logGroupName string) (string, error) {
+func deleteCloudwatchLog(ctx context.Context, svc cloudwatchlogs.CloudWatchLogs, logGroupName string) (string, error) {
 	input := &cloudwatchlogs.DeleteLogGroupInput{
 		LogGroupName: aws.String(logGroupName),
 	}
 
-	result, err := svc.DeleteLogGroup(input)
+	result, err := svc.DeleteLogGroupWithContext(ctx, input)
 	handleCloudwatchLogsError(err)
 
 	return result.String(), err
 }
 
-func getLogGroupTag(svc *cloudwatchlogs.CloudWatchLogs, logGroupARN string) map[string]*string {
-	input := &cloudwatchlogs.ListTagsForResourceInput{ResourceArn: aws.String(logGroupARN)}
+func getLogGroupTag(ctx context.Context, svc *cloudwatchlogs.CloudWatchLogs, logGroupARN string) (map[string]*string, error) {
+	// the tagging API expects the ARN of the log group without the trailing :*
+	input := &cloudwatchlogs.ListTagsForResourceInput{ResourceArn: aws.String(strings.TrimSuffix(logGroupARN, ":*"))}
 
-	req, tags := svc.ListTagsForResourceRequest(input)
-	handleCloudwatchLogsError(req.Error)
+	tags, err := svc.ListTagsForResourceWithContext(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("can't get tags of log group %s: %w", logGroupARN, err)
+	}
 
-	return tags.Tags
+	return tags.Tags, nil
 }
 
 func handleCloudwatchLogsError(err error) {
@@ -90,59 +110,100 @@ func handleCloudwatchLogsError(err error) {
 	}
 }
 
-func DeleteExpiredLogs(sessions AWSSessions, options AwsOptions) {
-	logs := getCloudwatchLogs(sessions.CloudWatchLogs)
-	region := *sessions.CloudWatchLogs.Config.Region
-	var expiredLogs []CompleteLogGroup
-	for _, log := range logs {
-		completeLogGroup := getCompleteLogGroup(sessions.CloudWatchLogs, *log, options.TagName)
-		if completeLogGroup.IsResourceExpired(options.TagValue, options.DisableTTLCheck) {
-			expiredLogs = append(expiredLogs, completeLogGroup)
+type logGroupCleaner struct {
+	awsCleaner
+}
+
+type unlinkedLogGroupCleaner struct {
+	awsCleaner
+	common.AlwaysEvaluator
+}
+
+func init() {
+	registerRegionalCleaner("cloudwatch-logs", "cloudwatch-log-group", "expired Cloudwatch log", func(base awsCleaner) common.Cleaner {
+		return logGroupCleaner{base}
+	})
+	registerRegionalCleaner("cloudwatch-logs", "unlinked-cloudwatch-log-group", "unlinked Cloudwatch log", func(base awsCleaner) common.Cleaner {
+		return unlinkedLogGroupCleaner{awsCleaner: base}
+	})
+}
+
+func (c logGroupCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	logGroups, err := getCloudwatchLogs(ctx, c.sessions.CloudWatchLogs)
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []common.CloudProviderResource
+	for _, logGroup := range logGroups {
+		completeLogGroup, err := getCompleteLogGroup(ctx, c.sessions.CloudWatchLogs, *logGroup, c.options.TagName, c.options.tagIndex)
+		if err != nil {
+			return nil, err
 		}
+		resources = append(resources, completeLogGroup.CloudProviderResource)
 	}
 
-	count, start := common.ElemToDeleteFormattedInfos("expired Cloudwatch log", len(expiredLogs), region)
+	return resources, nil
+}
 
-	log.Info(count)
+func (c logGroupCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	_, err := deleteCloudwatchLog(ctx, *c.sessions.CloudWatchLogs, resource.Identifier)
+	return err
+}
 
-	if options.DryRun || len(expiredLogs) == 0 {
-		return
+func (c unlinkedLogGroupCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	clusters, err := ListClusters(ctx, *c.sessions.EKS)
+	if err != nil {
+		return nil, err
 	}
 
-	log.Info(start)
+	logGroupNames, err := getUnlinkedLogs(ctx, c.sessions.CloudWatchLogs, clusters)
+	if err != nil {
+		return nil, err
+	}
 
-	for _, completeLog := range expiredLogs {
-		_, deletionErr := deleteCloudwatchLog(*sessions.CloudWatchLogs, completeLog.Identifier)
-		if deletionErr != nil {
-			log.Errorf("Deletion Cloudwatch error %s/%s: %s",
-				completeLog.Identifier, region, deletionErr)
-		} else {
-			log.Debugf("Cloudwatch logs %s in %s deleted.", completeLog.Identifier, region)
-		}
+	var resources []common.CloudProviderResource
+	for _, logGroupName := range logGroupNames {
+		resources = append(resources, common.CloudProviderResource{
+			Identifier:  logGroupName,
+			Description: "Log Group Name: " + logGroupName,
+		})
 	}
 
+	return resources, nil
+}
+
+func (c unlinkedLogGroupCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	_, err := deleteCloudwatchLog(ctx, *c.sessions.CloudWatchLogs, resource.Identifier)
+	return err
 }
 
-func addTtlToLogGroup(svc *cloudwatchlogs.CloudWatchLogs, logGroupARN string, ttl int64) (string, error) {
+func addTtlToLogGroup(ctx context.Context, svc *cloudwatchlogs.CloudWatchLogs, logGroupARN string, ttl int64) (string, error) {
 	input := &cloudwatchlogs.TagResourceInput{
 		ResourceArn: aws.String(logGroupARN),
 		Tags:        aws.StringMap(map[string]string{"ttl": fmt.Sprintf("%d", ttl)}),
 	}
 
-	result, err := svc.TagResource(input)
+	result, err := svc.TagResourceWithContext(ctx, input)
 	handleCloudwatchLogsError(err)
 
 	return result.String(), err
 }
 
-func TagLogsForDeletion(svc *cloudwatchlogs.CloudWatchLogs, tagName string, clusterId string, TTL int64) error {
-	logs := getCloudwatchLogs(svc)
+func TagLogsForDeletion(ctx context.Context, svc *cloudwatchlogs.CloudWatchLogs, tagName string, clusterId string, TTL int64) error {
+	logs, err := getCloudwatchLogs(ctx, svc)
+	if err != nil {
+		return err
+	}
 
 	for _, log := range logs {
-		completeLogGroup := getCompleteLogGroup(svc, *log, tagName)
+		completeLogGroup, err := getCompleteLogGroup(ctx, svc, *log, tagName, nil)
+		if err != nil {
+			return err
+		}
 
 		if completeLogGroup.TTL == 0 && strings.Contains(completeLogGroup.Identifier, clusterId) {
-			_, err := addTtlToLogGroup(svc, completeLogGroup.Identifier, TTL)
+			_, err := addTtlToLogGroup(ctx, svc, completeLogGroup.Identifier, TTL)
 			if err != nil {
 				return err
 			}
@@ -152,38 +213,11 @@ func TagLogsForDeletion(svc *cloudwatchlogs.CloudWatchLogs, tagName string, clus
 	return nil
 }
 
-func DeleteUnlinkedLogs(sessions AWSSessions, options AwsOptions) {
-	region := *sessions.EKS.Config.Region
-	clusters, err := ListClusters(*sessions.EKS)
+func getUnlinkedLogs(ctx context.Context, svc *cloudwatchlogs.CloudWatchLogs, clusters []*string) ([]string, error) {
+	logs, err := getCloudwatchLogs(ctx, svc)
 	if err != nil {
-		log.Errorf("Can't list cluster in region %s: %s", region, err.Error())
+		return nil, err
 	}
-
-	deletableLogs := getUnlinkedLogs(sessions.CloudWatchLogs, clusters)
-
-	count, start := common.ElemToDeleteFormattedInfos("unlinked Cloudwatch log", len(deletableLogs), region)
-
-	log.Info(count)
-
-	if options.DryRun || len(deletableLogs) == 0 {
-		return
-	}
-
-	log.Info(start)
-
-	for _, deletableLog := range deletableLogs {
-		if deletableLog != "null" {
-			_, deletionErr := deleteCloudwatchLog(*sessions.CloudWatchLogs, deletableLog)
-			if deletionErr != nil {
-				log.Errorf("Deletion Cloudwatch error %s/%s: %s",
-					deletableLog, region, deletionErr)
-			}
-		}
-	}
-}
-
-func getUnlinkedLogs(svc *cloudwatchlogs.CloudWatchLogs, clusters []*string) []string {
-	logs := getCloudwatchLogs(svc)
 	deletableLogs := make(map[string]string)
 
 	for _, cluster := range clusters {
@@ -205,5 +239,5 @@ func getUnlinkedLogs(svc *cloudwatchlogs.CloudWatchLogs, clusters []*string) []s
 		}
 	}
 
-	return unlinkedLogs
+	return unlinkedLogs, nil
 }
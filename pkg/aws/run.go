@@ -1,9 +1,9 @@
 package aws
 
 import (
+	"context"
 	"github.com/aws/aws-sdk-go/service/eventbridge"
 	"sync"
-	"time"
 
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/cloudformation"
@@ -17,38 +17,28 @@ import (
 	"github.com/aws/aws-sdk-go/service/kms"
 	"github.com/aws/aws-sdk-go/service/lambda"
 	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/sfn"
 	"github.com/aws/aws-sdk-go/service/sqs"
 	"github.com/sirupsen/logrus"
-	"github.com/spf13/cobra"
+
+	"github.com/Qovery/pleco/pkg/common"
 )
 
 type AwsOptions struct {
-	TagName                string
-	TagValue               string
-	DisableTTLCheck        bool
-	IsDestroyingCommand    bool
-	DryRun                 bool
-	EnableRDS              bool
-	EnableElastiCache      bool
-	EnableEKS              bool
-	EnableELB              bool
-	EnableEBS              bool
-	EnableVPC              bool
-	EnableS3               bool
-	EnableCloudWatchLogs   bool
-	EnableKMS              bool
-	EnableIAM              bool
-	EnableSSH              bool
-	EnableDocumentDB       bool
-	EnableECR              bool
-	EnableSQS              bool
-	EnableLambda           bool
-	EnableSFN              bool
-	EnableCloudFormation   bool
-	EnableEC2Instance      bool
-	EnableCloudWatchEvents bool
+	TagName             string
+	TagValue            string
+	DisableTTLCheck     bool
+	IsDestroyingCommand bool
+	DryRun              bool
+	Region              string
+	Account             Account
+	Features            common.FeatureSet
+	// TaggingAPI reads the tags of the resources with a Resource Groups Tagging API sweep per cycle
+	TaggingAPI bool
+	// tagIndex holds the tags read with the tagging API, nil when the tags are read resource per resource
+	tagIndex *taggedResources
 }
 
 type AWSSessions struct {
@@ -67,198 +57,95 @@ type AWSSessions struct {
 	SFN            *sfn.SFN
 	CloudFormation *cloudformation.CloudFormation
 	EventBridge    *eventbridge.EventBridge
+	TaggingAPI     *resourcegroupstaggingapi.ResourceGroupsTaggingAPI
 }
 
-type funcDeleteExpired func(sessions AWSSessions, options AwsOptions)
-
-func RunPlecoAWS(cmd *cobra.Command, regions []string, interval int64, wg *sync.WaitGroup, options AwsOptions) {
-	for _, region := range regions {
-		wg.Add(1)
-		go runPlecoInRegion(region, interval, wg, options)
-	}
-
-	currentSession := CreateSession(regions[0])
-
-	wg.Add(1)
-	go runPlecoInGlobal(cmd, interval, wg, currentSession, options)
-}
-
-func runPlecoInRegion(region string, interval int64, wg *sync.WaitGroup, options AwsOptions) {
-	defer wg.Done()
-
-	sessions := AWSSessions{}
-	currentSession := CreateSession(region)
-
-	logrus.Infof("Starting to check expired resources in region %s.", *currentSession.Config.Region)
-
-	var listServiceToCheckStatus []funcDeleteExpired
-
-	// S3
-	if options.EnableS3 {
-		sessions.S3 = s3.New(currentSession)
-		listServiceToCheckStatus = append(listServiceToCheckStatus, DeleteExpiredBuckets)
-	}
-
-	// RDS
-	if options.EnableRDS {
-		sessions.RDS = RdsSession(*currentSession, region)
-		listServiceToCheckStatus = append(listServiceToCheckStatus, DeleteExpiredRDSDatabases, DeleteExpiredRDSSubnetGroups, DeleteExpiredCompleteRDSParameterGroups, DeleteExpiredSnapshots)
-	}
-
-	// DocumentDB connection
-	if options.EnableDocumentDB {
-		sessions.RDS = RdsSession(*currentSession, region)
-		listServiceToCheckStatus = append(listServiceToCheckStatus, DeleteExpiredDocumentDBClusters, DeleteExpiredClusterSnapshots)
-	}
-
-	// Elasticache connection
-	if options.EnableElastiCache {
-		sessions.ElastiCache = ElasticacheSession(*currentSession, region)
-		sessions.EC2 = ec2.New(currentSession)
-		listServiceToCheckStatus = append(listServiceToCheckStatus, DeleteExpiredElasticacheDatabases, DeleteUnlinkedECSubnetGroups, DeleteExpiredElasticacheSnapshots)
-	}
-
-	// EKS connection
-	if options.EnableEKS {
-		sessions.EKS = eks.New(currentSession)
-		sessions.ELB = elbv2.New(currentSession)
-		options.EnableELB = true
-		sessions.EC2 = ec2.New(currentSession)
-		options.EnableEBS = true
-		sessions.CloudWatchLogs = cloudwatchlogs.New(currentSession)
-		sessions.RDS = RdsSession(*currentSession, region)
-
-		listServiceToCheckStatus = append(listServiceToCheckStatus, DeleteExpiredEKSClusters)
-	}
-
-	// ELB connection
-	if options.EnableELB {
-		sessions.EKS = eks.New(currentSession)
-		sessions.ELB = elbv2.New(currentSession)
-		listServiceToCheckStatus = append(listServiceToCheckStatus, DeleteExpiredLoadBalancers)
-	}
-
-	// EBS connection
-	if options.EnableEBS {
-		sessions.EKS = eks.New(currentSession)
-		sessions.EC2 = ec2.New(currentSession)
-		listServiceToCheckStatus = append(listServiceToCheckStatus, DeleteExpiredVolumes)
-	}
-
-	// VPC
-	if options.EnableVPC {
-		sessions.EC2 = ec2.New(currentSession)
-		sessions.ELB = elbv2.New(currentSession)
-		listServiceToCheckStatus = append(listServiceToCheckStatus, DeleteExpiredVPC, DeleteExpiredElasticIps, DeleteExpiredNatGateways)
-	}
-
-	// Cloudwatch
-	if options.EnableCloudWatchLogs {
-		sessions.CloudWatchLogs = cloudwatchlogs.New(currentSession)
-		listServiceToCheckStatus = append(listServiceToCheckStatus, DeleteExpiredLogs, DeleteUnlinkedLogs)
-	}
-
-	// KMS
-	if options.EnableKMS {
-		sessions.KMS = kms.New(currentSession)
-		listServiceToCheckStatus = append(listServiceToCheckStatus, DeleteExpiredKeys)
-	}
-
-	// SSH
-	if options.EnableSSH {
-		sessions.EC2 = ec2.New(currentSession)
-		listServiceToCheckStatus = append(listServiceToCheckStatus, DeleteExpiredKeyPairs)
-	}
-
-	// ECR
-	if options.EnableECR {
-		sessions.ECR = ecr.New(currentSession)
-		listServiceToCheckStatus = append(listServiceToCheckStatus, DeleteExpiredRepositories)
-	}
-
-	// SQS
-	if options.EnableSQS {
-		sessions.SQS = sqs.New(currentSession)
-		listServiceToCheckStatus = append(listServiceToCheckStatus, DeleteExpiredSQSQueues)
+func RunPlecoAWS(ctx context.Context, accounts []Account, regions []string, interval int64, wg *sync.WaitGroup, options AwsOptions) {
+	// resources linked to a VPC are cleaned without the VPC itself to avoid quota issues
+	if options.DisableTTLCheck {
+		if !common.GetPolicy().ProtectsVPCs(providerName) {
+			logrus.Fatalf("The ttl check is disabled: an exclude rule with the vpcs to protect is required in the config file.")
+		}
+		options.Features.Enable("vpc-quota")
 	}
 
-	// Cloudwatch events
-	if options.EnableCloudWatchEvents {
-		sessions.EventBridge = eventbridge.New(currentSession)
-		listServiceToCheckStatus = append(listServiceToCheckStatus, DeleteExpiredCloudWatchEvents)
+	globalRegion := defaultRegion
+	if len(regions) > 0 {
+		globalRegion = regions[0]
 	}
 
-	// Lambda Function
-	if options.EnableLambda {
-		sessions.LambdaFunction = lambda.New(currentSession)
-		listServiceToCheckStatus = append(listServiceToCheckStatus, DeleteExpiredLambdaFunctions)
-	}
+	for _, account := range accounts {
+		options.Account = account
+		for _, region := range regions {
+			wg.Add(1)
+			go runPlecoInRegion(ctx, region, interval, wg, options)
+		}
 
-	// Step Function State Machines
-	if options.EnableSFN {
-		sessions.SFN = sfn.New(currentSession)
-		listServiceToCheckStatus = append(listServiceToCheckStatus, DeleteExpiredStateMachines)
+		wg.Add(1)
+		go runPlecoInGlobal(ctx, globalRegion, interval, wg, options)
 	}
+}
 
-	// Cloudformation Stacks
-	if options.EnableCloudFormation {
-		sessions.CloudFormation = cloudformation.New(currentSession)
-		listServiceToCheckStatus = append(listServiceToCheckStatus, DeleteExpiredStacks)
-	}
+func runPlecoInRegion(ctx context.Context, region string, interval int64, wg *sync.WaitGroup, options AwsOptions) {
+	defer wg.Done()
 
-	if options.EnableEC2Instance {
-		sessions.EC2 = ec2.New(currentSession)
-		listServiceToCheckStatus = append(listServiceToCheckStatus, DeleteExpiredEC2Instances)
-	}
+	currentSession := CreateAccountSession(region, options.Account)
+	options.Region = region
 
-	if options.DisableTTLCheck {
-		sessions.EC2 = ec2.New(currentSession)
-		sessions.ELB = elbv2.New(currentSession)
-		listServiceToCheckStatus = append(listServiceToCheckStatus, DeleteVPCLinkedResourcesWithQuota)
-	}
+	logrus.Infof("Starting to check expired resources in region %s of %s.", *currentSession.Config.Region, options.Account)
 
-	if options.IsDestroyingCommand {
-		for _, check := range listServiceToCheckStatus {
-			check(sessions, options)
-		}
-	} else {
-		for {
-			for _, check := range listServiceToCheckStatus {
-				check(sessions, options)
-			}
+	engine := common.NewEngine(common.EngineOptions{
+		Provider: providerName,
+		Account:  options.Account.ID,
+		Scope:    common.RegionScope,
+		Location: region,
+		DryRun:   options.DryRun,
+		Features: options.Features,
+	}, newAWSCleaner(newSessions(currentSession, region), options, common.RegionScope))
 
-			time.Sleep(time.Duration(interval) * time.Second)
-		}
-	}
+	engine.Run(ctx, interval, options.IsDestroyingCommand)
 }
 
-func runPlecoInGlobal(cmd *cobra.Command, interval int64, wg *sync.WaitGroup, currentSession *session.Session, options AwsOptions) {
+func runPlecoInGlobal(ctx context.Context, region string, interval int64, wg *sync.WaitGroup, options AwsOptions) {
 	defer wg.Done()
 
-	logrus.Info("Starting to check global expired resources.")
+	currentSession := CreateAccountSession(region, options.Account)
+	options.Region = region
 
-	sessions := AWSSessions{}
+	logrus.Infof("Starting to check global expired resources of %s.", options.Account)
 
-	// IAM
-	iamEnabled, _ := cmd.Flags().GetBool("enable-iam")
-	if iamEnabled {
-		sessions.IAM = iam.New(currentSession)
-	}
+	// the tags of the global resources, eg. IAM ones, are returned by the tagging API in us-east-1
+	sessions := newSessions(currentSession, region)
+	sessions.TaggingAPI = resourcegroupstaggingapi.New(CreateAccountSession(defaultRegion, options.Account))
 
-	if options.IsDestroyingCommand {
-		deleteExpiredIAM(iamEnabled, &sessions, &options)
-	} else {
-		for {
-			deleteExpiredIAM(iamEnabled, &sessions, &options)
-			time.Sleep(time.Duration(interval) * time.Second)
-		}
-	}
+	engine := common.NewEngine(common.EngineOptions{
+		Provider: providerName,
+		Account:  options.Account.ID,
+		Scope:    common.GlobalScope,
+		DryRun:   options.DryRun,
+		Features: options.Features,
+	}, newAWSCleaner(sessions, options, common.GlobalScope))
+
+	engine.Run(ctx, interval, options.IsDestroyingCommand)
 }
 
-func deleteExpiredIAM(iamEnabled bool, sessions *AWSSessions, options *AwsOptions) {
-	// check IAM
-	if iamEnabled {
-		logrus.Debug("Listing all IAM access.")
-		DeleteExpiredIAM(sessions, options)
+func newSessions(currentSession *session.Session, region string) AWSSessions {
+	return AWSSessions{
+		RDS:            RdsSession(*currentSession, region),
+		ElastiCache:    ElasticacheSession(*currentSession, region),
+		EKS:            eks.New(currentSession),
+		ELB:            elbv2.New(currentSession),
+		EC2:            ec2.New(currentSession),
+		S3:             s3.New(currentSession),
+		CloudWatchLogs: cloudwatchlogs.New(currentSession),
+		KMS:            kms.New(currentSession),
+		IAM:            iam.New(currentSession),
+		ECR:            ecr.New(currentSession),
+		SQS:            sqs.New(currentSession),
+		LambdaFunction: lambda.New(currentSession),
+		SFN:            sfn.New(currentSession),
+		CloudFormation: cloudformation.New(currentSession),
+		EventBridge:    eventbridge.New(currentSession),
+		TaggingAPI:     resourcegroupstaggingapi.New(currentSession),
 	}
 }
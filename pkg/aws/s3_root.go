@@ -1,11 +1,13 @@
 package aws
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
 	"github.com/aws/aws-sdk-go/service/s3"
 	log "github.com/sirupsen/logrus"
 
@@ -17,11 +19,11 @@ type s3Bucket struct {
 	ObjectsCount int
 }
 
-func listTaggedBuckets(s3Session s3.S3, tagName string) ([]s3Bucket, error) {
+func listTaggedBuckets(ctx context.Context, s3Session s3.S3, tagName string, tagIndex *taggedResources) ([]s3Bucket, error) {
 	var taggedS3Buckets []s3Bucket
 	currentRegion := s3Session.Config.Region
 
-	result, bucketErr := s3Session.ListBuckets(&s3.ListBucketsInput{})
+	result, bucketErr := s3Session.ListBucketsWithContext(ctx, &s3.ListBucketsInput{})
 	if bucketErr != nil {
 		return nil, bucketErr
 	}
@@ -31,40 +33,49 @@ func listTaggedBuckets(s3Session s3.S3, tagName string) ([]s3Bucket, error) {
 	}
 
 	for _, bucket := range result.Buckets {
-		location, locationErr := s3Session.GetBucketLocation(
+		location, locationErr := s3Session.GetBucketLocationWithContext(ctx,
 			&s3.GetBucketLocationInput{
 				Bucket: aws.String(*bucket.Name),
 			})
 
 		if locationErr != nil {
-			log.Errorf("Location error for bucket %s: %s", *bucket.Name, locationErr.Error())
-			continue
+			return nil, fmt.Errorf("can't get location of bucket %s: %w", *bucket.Name, locationErr)
 		}
 
 		if location.LocationConstraint == nil || *location.LocationConstraint != *currentRegion {
 			continue
 		}
 
-		result, objectErr := s3Session.ListObjectVersions(
+		// only the emptiness of the bucket matters, so pages are read until one holds versions
+		objectsCount := 0
+		objectErr := s3Session.ListObjectVersionsPagesWithContext(ctx,
 			&s3.ListObjectVersionsInput{
 				Bucket: aws.String(*bucket.Name),
+			}, func(page *s3.ListObjectVersionsOutput, lastPage bool) bool {
+				objectsCount += len(page.Versions)
+				return objectsCount == 0
 			})
 		if objectErr != nil {
-			log.Errorf("Listing object error for bucket %s: %s", *bucket.Name, locationErr.Error())
-			continue
+			return nil, fmt.Errorf("can't list objects of bucket %s: %w", *bucket.Name, objectErr)
 		}
 
-		bucketTags, tagErr := s3Session.GetBucketTagging(
-			&s3.GetBucketTaggingInput{
-				Bucket: aws.String(*bucket.Name),
-			})
-
-		if tagErr != nil && !strings.Contains(tagErr.Error(), "NoSuchTagSet") {
-			log.Errorf("Tag error for bucket %s: %s", *bucket.Name, tagErr.Error())
-			continue
+		var tags interface{}
+		bucketARN := arn.ARN{Partition: partition(*currentRegion), Service: "s3", Resource: *bucket.Name}
+		if indexedTags, ok := tagIndex.lookup(bucketARN.String()); ok {
+			tags = indexedTags
+		} else {
+			bucketTags, tagErr := s3Session.GetBucketTaggingWithContext(ctx,
+				&s3.GetBucketTaggingInput{
+					Bucket: aws.String(*bucket.Name),
+				})
+
+			if tagErr != nil && !strings.Contains(tagErr.Error(), "NoSuchTagSet") {
+				return nil, fmt.Errorf("can't get tags of bucket %s: %w", *bucket.Name, tagErr)
+			}
+			tags = bucketTags.TagSet
 		}
 
-		essentialTags := common.GetEssentialTags(bucketTags.TagSet, tagName)
+		essentialTags := common.GetEssentialTags(tags, tagName)
 
 		taggedS3Buckets = append(taggedS3Buckets,
 			s3Bucket{
@@ -75,16 +86,19 @@ func listTaggedBuckets(s3Session s3.S3, tagName string) ([]s3Bucket, error) {
 					TTL:          essentialTags.TTL,
 					Tag:          essentialTags.Tag,
 					IsProtected:  essentialTags.IsProtected,
+					ExpiresAt:    essentialTags.ExpiresAt,
+					Warnings:     essentialTags.Warnings,
+					Tags:         essentialTags.Tags,
 				},
-				ObjectsCount: len(result.Versions),
+				ObjectsCount: objectsCount,
 			})
 	}
 
 	return taggedS3Buckets, nil
 }
 
-func deleteS3Objects(s3session s3.S3, bucket string, objects []*s3.ObjectIdentifier) error {
-	_, err := s3session.DeleteObjects(
+func deleteS3Objects(ctx context.Context, s3session s3.S3, bucket string, objects []*s3.ObjectIdentifier) error {
+	_, err := s3session.DeleteObjectsWithContext(ctx,
 		&s3.DeleteObjectsInput{
 			Bucket: aws.String(bucket),
 			Delete: &s3.Delete{
@@ -99,100 +113,80 @@ func deleteS3Objects(s3session s3.S3, bucket string, objects []*s3.ObjectIdentif
 	return nil
 }
 
-func deleteS3ObjectsVersions(s3session s3.S3, bucket string) error {
-	// list all objects
-	result, err := s3session.ListObjectVersions(
+func deleteS3ObjectsVersions(ctx context.Context, s3session s3.S3, bucket string) error {
+	var deleteErr error
+	err := s3session.ListObjectVersionsPagesWithContext(ctx,
 		&s3.ListObjectVersionsInput{
 			Bucket: aws.String(bucket),
+		}, func(page *s3.ListObjectVersionsOutput, lastPage bool) bool {
+			// a page holds at most 1000 versions and markers, as many as can be deleted at once
+			var objectsIdentifiers []*s3.ObjectIdentifier
+			for _, version := range page.Versions {
+				objectsIdentifiers = append(objectsIdentifiers,
+					&s3.ObjectIdentifier{
+						Key:       version.Key,
+						VersionId: version.VersionId,
+					},
+				)
+			}
+			for _, marker := range page.DeleteMarkers {
+				objectsIdentifiers = append(objectsIdentifiers,
+					&s3.ObjectIdentifier{
+						Key:       marker.Key,
+						VersionId: marker.VersionId,
+					},
+				)
+			}
+
+			if len(objectsIdentifiers) == 0 {
+				return true
+			}
+
+			deleteErr = deleteS3Objects(ctx, s3session, bucket, objectsIdentifiers)
+			return deleteErr == nil
 		})
 	if err != nil {
 		return err
 	}
 
-	// delete all objects
-	var objectsIdentifiers []*s3.ObjectIdentifier
-	counter := 0
-	for _, version := range result.Versions {
-		if counter >= 1000 {
-			_ = deleteS3Objects(s3session, bucket, objectsIdentifiers)
-			objectsIdentifiers = []*s3.ObjectIdentifier{}
-			counter = 0
-		}
-
-		objectsIdentifiers = append(objectsIdentifiers,
-			&s3.ObjectIdentifier{
-				Key:       version.Key,
-				VersionId: version.VersionId,
-			},
-		)
-
-		counter++
-	}
-	_ = deleteS3Objects(s3session, bucket, objectsIdentifiers)
-
-	// delete all Markers
-	objectsIdentifiers = []*s3.ObjectIdentifier{}
-	counter = 0
-	for _, version := range result.DeleteMarkers {
-		if counter >= 1000 {
-			_ = deleteS3Objects(s3session, bucket, objectsIdentifiers)
-			objectsIdentifiers = []*s3.ObjectIdentifier{}
-			counter = 0
-		}
-
-		objectsIdentifiers = append(objectsIdentifiers,
-			&s3.ObjectIdentifier{
-				Key:       version.Key,
-				VersionId: version.VersionId,
-			},
-		)
-
-		counter++
-	}
-	_ = deleteS3Objects(s3session, bucket, objectsIdentifiers)
-
-	return nil
+	return deleteErr
 }
 
-func deleteAllS3Objects(s3session s3.S3, bucket string) error {
-	// list all objects
-	result, err := s3session.ListObjectsV2(
+func deleteAllS3Objects(ctx context.Context, s3session s3.S3, bucket string) error {
+	var deleteErr error
+	err := s3session.ListObjectsV2PagesWithContext(ctx,
 		&s3.ListObjectsV2Input{
 			Bucket: aws.String(bucket),
+		}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+			// a page holds at most 1000 objects, as many as can be deleted at once
+			var objectsIdentifiers []*s3.ObjectIdentifier
+			for _, object := range page.Contents {
+				objectsIdentifiers = append(objectsIdentifiers,
+					&s3.ObjectIdentifier{
+						Key: object.Key,
+					},
+				)
+			}
+
+			if len(objectsIdentifiers) == 0 {
+				return true
+			}
+
+			deleteErr = deleteS3Objects(ctx, s3session, bucket, objectsIdentifiers)
+			return deleteErr == nil
 		})
 	if err != nil {
 		return err
 	}
 
-	// delete all objects
-	var objectsIdentifiers []*s3.ObjectIdentifier
-	counter := 0
-	for _, object := range result.Contents {
-		if counter >= 1000 {
-			_ = deleteS3Objects(s3session, bucket, objectsIdentifiers)
-			objectsIdentifiers = []*s3.ObjectIdentifier{}
-			counter = 0
-		}
-
-		objectsIdentifiers = append(objectsIdentifiers,
-			&s3.ObjectIdentifier{
-				Key: object.Key,
-			},
-		)
-
-		counter++
-	}
-
-	_ = deleteS3Objects(s3session, bucket, objectsIdentifiers)
-
-	return nil
+	return deleteErr
 }
 
-func deleteS3BucketPolicy(s3session s3.S3, bucket string) error {
+func deleteS3BucketPolicy(ctx context.Context, s3session s3.S3, bucket string) error {
 	log.Infof("Deleting policy for bucket %s in %s", bucket, *s3session.Config.Region)
 
 	// delete bucket policy
-	_, err := s3session.DeleteBucketPolicy(
+	_, err := s3session.DeleteBucketPolicyWithContext(ctx,
 		&s3.DeleteBucketPolicyInput{
 			Bucket: &bucket,
 		})
@@ -203,32 +197,32 @@ func deleteS3BucketPolicy(s3session s3.S3, bucket string) error {
 	return nil
 }
 
-func deleteS3Buckets(s3session s3.S3, bucket string) error {
+func deleteS3Buckets(ctx context.Context, s3session s3.S3, bucket string) error {
 	log.Infof("Deleting bucket %s in %s", bucket, *s3session.Config.Region)
 
 	// delete bucket policy
-	err := deleteS3BucketPolicy(s3session, bucket)
+	err := deleteS3BucketPolicy(ctx, s3session, bucket)
 	if err != nil {
 		log.Errorf("Error while deleting kucket policy: %v", err)
 		return err
 	}
 
 	// delete objects versions
-	err = deleteS3ObjectsVersions(s3session, bucket)
+	err = deleteS3ObjectsVersions(ctx, s3session, bucket)
 	if err != nil {
 		log.Errorf("Error while deleting object version file: %v", err)
 		return err
 	}
 
 	// delete objects
-	err = deleteAllS3Objects(s3session, bucket)
+	err = deleteAllS3Objects(ctx, s3session, bucket)
 	if err != nil {
 		log.Errorf("Error while deleting object file: %v", err)
 		return err
 	}
 
 	// delete bucket
-	_, err = s3session.DeleteBucket(
+	_, err = s3session.DeleteBucketWithContext(ctx,
 		&s3.DeleteBucketInput{
 			Bucket: &bucket,
 		})
@@ -239,44 +233,47 @@ func deleteS3Buckets(s3session s3.S3, bucket string) error {
 	return nil
 }
 
-func DeleteExpiredBuckets(sessions AWSSessions, options AwsOptions) {
-	buckets, err := listTaggedBuckets(*sessions.S3, options.TagName)
-	region := sessions.S3.Config.Region
+type s3BucketCleaner struct {
+	awsCleaner
+}
+
+func init() {
+	registerRegionalCleaner("s3", "s3-bucket", "expired S3 bucket", func(base awsCleaner) common.Cleaner {
+		return s3BucketCleaner{base}
+	})
+}
+
+func (c s3BucketCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	buckets, err := listTaggedBuckets(ctx, *c.sessions.S3, c.options.TagName, c.options.tagIndex)
 	if err != nil {
-		log.Errorf("Can't list S3 buckets: %s\n", err)
-		return
+		return nil, err
 	}
-	var expiredBuckets []s3Bucket
+
+	var resources []common.CloudProviderResource
 	for _, bucket := range buckets {
-		// Set to 2 weeks to avoid terraform issue when recreating the bucket after Pleco deleted it, will revert once fixed in terraform/aws provider (QOV-1033)
-		if (bucket.ObjectsCount == 0 && time.Now().UTC().After(bucket.CreationDate.Add(14*24*time.Hour))) || bucket.IsResourceExpired(options.TagValue, options.DisableTTLCheck) {
-			expiredBuckets = append(expiredBuckets, bucket)
-		}
+		resource := bucket.CloudProviderResource
+		resource.Payload = bucket
+		resources = append(resources, resource)
 	}
 
-	s := fmt.Sprintf("There is no expired S3 bucket to delete in %s.", *region)
-	if len(expiredBuckets) == 1 {
-		s = fmt.Sprintf("There is 1 expired S3 bucket to delete in %s.", *region)
-	}
-	if len(expiredBuckets) > 1 {
-		s = fmt.Sprintf("There are %d expired S3 buckets to delete.", len(expiredBuckets))
-	}
+	return resources, nil
+}
+
+func (c s3BucketCleaner) Evaluate(resource common.CloudProviderResource) bool {
+	bucket := resource.Payload.(s3Bucket)
 
-	log.Info(s)
+	// Set to 2 weeks to avoid terraform issue when recreating the bucket after Pleco deleted it, will revert once fixed in terraform/aws provider (QOV-1033)
+	return (bucket.ObjectsCount == 0 && time.Now().UTC().After(bucket.CreationDate.Add(14*24*time.Hour))) || c.awsCleaner.Evaluate(resource)
+}
 
-	if options.DryRun || len(expiredBuckets) == 0 {
-		return
+func (c s3BucketCleaner) MatchedRule(resource common.CloudProviderResource) string {
+	if resource.Payload.(s3Bucket).ObjectsCount == 0 && !c.awsCleaner.Evaluate(resource) {
+		return "empty-bucket"
 	}
 
-	log.Info("Starting expired S3 buckets deletion.")
+	return c.awsCleaner.MatchedRule(resource)
+}
 
-	for _, bucket := range expiredBuckets {
-		deletionErr := deleteS3Buckets(*sessions.S3, bucket.Identifier)
-		if deletionErr != nil {
-			log.Errorf("Deletion S3 Bucket %s/%s error: %s",
-				bucket.Identifier, *region, err)
-		} else {
-			log.Debugf("S3 bucket %s in %s deleted.", bucket.Identifier, *region)
-		}
-	}
+func (c s3BucketCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	return deleteS3Buckets(ctx, *c.sessions.S3, resource.Identifier)
 }
@@ -1,10 +1,14 @@
 package aws
 
 import (
+	"context"
+	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/sqs"
 	log "github.com/sirupsen/logrus"
@@ -20,7 +24,7 @@ func SqsSession(sess session.Session, region string) *sqs.SQS {
 	return sqs.New(&sess, &aws.Config{Region: aws.String(region)})
 }
 
-func listTaggedSqsQueues(svc sqs.SQS, tagName string) ([]sqsQueue, error) {
+func listTaggedSqsQueues(ctx context.Context, svc sqs.SQS, tagName string, tagIndex *taggedResources) ([]sqsQueue, error) {
 	var taggedQueues []sqsQueue
 
 	MaxResultsPerPager := int64(1000)
@@ -29,63 +33,84 @@ func listTaggedSqsQueues(svc sqs.SQS, tagName string) ([]sqsQueue, error) {
 		MaxResults: aws.Int64(MaxResultsPerPager), // Set the maximum number of results per page
 	}
 
-	for result, err := svc.ListQueues(params); err == nil; result, err = svc.ListQueues(params) {
-		if err != nil {
-			return nil, err
-		}
-
-		if len(result.QueueUrls) == 0 {
-			return nil, nil
-		}
+	var queueUrls []*string
+	err := svc.ListQueuesPagesWithContext(ctx, params, func(page *sqs.ListQueuesOutput, lastPage bool) bool {
+		queueUrls = append(queueUrls, page.QueueUrls...)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
 
-		for _, queue := range result.QueueUrls {
-			tags, err := svc.ListQueueTags(
+	for _, queue := range queueUrls {
+		var tags interface{}
+		if indexedTags, ok := tagIndex.lookup(sqsQueueARN(*svc.Config.Region, *queue)); ok {
+			tags = indexedTags
+		} else {
+			output, err := svc.ListQueueTagsWithContext(ctx,
 				&sqs.ListQueueTagsInput{
 					QueueUrl: aws.String(*queue),
 				},
 			)
 			if err != nil {
-				continue
-			}
-
-			essentialTags := common.GetEssentialTags(tags.Tags, tagName)
-			params := &sqs.GetQueueAttributesInput{
-				QueueUrl:       queue,
-				AttributeNames: aws.StringSlice([]string{"CreatedTimestamp"}),
-			}
-			attributes, _ := svc.GetQueueAttributes(params)
-			createdTimestamp, err := strconv.ParseInt(*attributes.Attributes["CreatedTimestamp"], 10, 64)
-
-			if err != nil {
-				log.Errorf("Failed to get queue createdTimestamp: %s", *queue)
-				continue
+				return nil, fmt.Errorf("can't get tags of queue %s: %w", *queue, err)
 			}
+			tags = output.Tags
+		}
 
-			time, _ := time.Parse(time.RFC3339, time.Unix(createdTimestamp, 0).Format(time.RFC3339))
-			taggedQueues = append(taggedQueues, sqsQueue{
-				CloudProviderResource: common.CloudProviderResource{
-					Identifier:   *queue,
-					Description:  "SQS Queue: " + *queue,
-					CreationDate: time,
-					TTL:          essentialTags.TTL,
-					Tag:          essentialTags.Tag,
-					IsProtected:  essentialTags.IsProtected,
-				},
-			})
-			log.Debug(*queue)
+		essentialTags := common.GetEssentialTags(tags, tagName)
+		params := &sqs.GetQueueAttributesInput{
+			QueueUrl:       queue,
+			AttributeNames: aws.StringSlice([]string{"CreatedTimestamp"}),
 		}
-		if result.NextToken != nil {
-			params.NextToken = result.NextToken
-		} else {
-			break // No more pages to retrieve
+		attributes, err := svc.GetQueueAttributesWithContext(ctx, params)
+		if err != nil {
+			return nil, fmt.Errorf("can't get attributes of queue %s: %w", *queue, err)
+		}
+		createdTimestamp, err := strconv.ParseInt(aws.StringValue(attributes.Attributes["CreatedTimestamp"]), 10, 64)
+		if err != nil {
+			log.Errorf("Failed to get queue createdTimestamp: %s", *queue)
+			continue
 		}
+
+		time, _ := time.Parse(time.RFC3339, time.Unix(createdTimestamp, 0).Format(time.RFC3339))
+		taggedQueues = append(taggedQueues, sqsQueue{
+			CloudProviderResource: common.CloudProviderResource{
+				Identifier:   *queue,
+				Description:  "SQS Queue: " + *queue,
+				CreationDate: time,
+				TTL:          essentialTags.TTL,
+				Tag:          essentialTags.Tag,
+				IsProtected:  essentialTags.IsProtected,
+				ExpiresAt:    essentialTags.ExpiresAt,
+				Warnings:     essentialTags.Warnings,
+				Tags:         essentialTags.Tags,
+			},
+		})
+		log.Debug(*queue)
 	}
 
 	return taggedQueues, nil
 }
 
-func deleteSqsQueue(svc sqs.SQS, queue sqsQueue) error {
-	_, err := svc.DeleteQueue(
+// sqsQueueARN returns the ARN of a queue from its URL, https://sqs.<region>.amazonaws.com/<account>/<name>.
+func sqsQueueARN(region string, queueURL string) string {
+	parts := strings.Split(strings.TrimSuffix(queueURL, "/"), "/")
+	if len(parts) < 2 {
+		return ""
+	}
+
+	return arn.ARN{
+		Partition: partition(region),
+		Service:   "sqs",
+		Region:    region,
+		AccountID: parts[len(parts)-2],
+		Resource:  parts[len(parts)-1],
+	}.String()
+}
+
+func deleteSqsQueue(ctx context.Context, svc sqs.SQS, queue sqsQueue) error {
+	_, err := svc.DeleteQueueWithContext(ctx,
 		&sqs.DeleteQueueInput{
 			QueueUrl: aws.String(queue.Identifier),
 		},
@@ -97,43 +122,32 @@ func deleteSqsQueue(svc sqs.SQS, queue sqsQueue) error {
 	return nil
 }
 
-func getExpiredQueues(ECsession *sqs.SQS, options *AwsOptions) ([]sqsQueue, string) {
-	queues, err := listTaggedSqsQueues(*ECsession, options.TagName)
-	region := *ECsession.Config.Region
-	if err != nil {
-		log.Errorf("Can't list SQS Queues in region %s: %s", region, err.Error())
-	}
-
-	var expiredQueues []sqsQueue
-	for _, queue := range queues {
-
-		if queue.IsResourceExpired(options.TagValue, options.DisableTTLCheck) {
-			expiredQueues = append(expiredQueues, queue)
-		}
-	}
-
-	return expiredQueues, region
+type sqsQueueCleaner struct {
+	awsCleaner
 }
 
-func DeleteExpiredSQSQueues(sessions AWSSessions, options AwsOptions) {
-	expiredQueues, region := getExpiredQueues(sessions.SQS, &options)
-
-	count, start := common.ElemToDeleteFormattedInfos("expired SQS Queues", len(expiredQueues), region)
+func init() {
+	registerRegionalCleaner("sqs", "sqs-queue", "expired SQS Queue", func(base awsCleaner) common.Cleaner {
+		return sqsQueueCleaner{base}
+	})
+}
 
-	log.Info(count)
+func (c sqsQueueCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	items, err := listTaggedSqsQueues(ctx, *c.sessions.SQS, c.options.TagName, c.options.tagIndex)
+	if err != nil {
+		return nil, err
+	}
 
-	if options.DryRun || len(expiredQueues) == 0 {
-		return
+	var resources []common.CloudProviderResource
+	for _, item := range items {
+		resource := item.CloudProviderResource
+		resource.Payload = item
+		resources = append(resources, resource)
 	}
 
-	log.Info(start)
+	return resources, nil
+}
 
-	for _, queue := range expiredQueues {
-		deletionErr := deleteSqsQueue(*sessions.SQS, queue)
-		if deletionErr != nil {
-			log.Errorf("Deletion SQS queue error %s/%s: %s", queue.Identifier, region, deletionErr.Error())
-		} else {
-			log.Debugf("SQS queue %s in %s deleted.", queue.Identifier, region)
-		}
-	}
+func (c sqsQueueCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	return deleteSqsQueue(ctx, *c.sessions.SQS, resource.Payload.(sqsQueue))
 }
@@ -1,9 +1,11 @@
 package aws
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/sfn"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/Qovery/pleco/pkg/common"
 )
@@ -13,29 +15,35 @@ type stateMachine struct {
 	machineName string
 }
 
-func listTaggedStateMachines(svc sfn.SFN, tagName string) ([]stateMachine, error) {
+func listTaggedStateMachines(ctx context.Context, svc sfn.SFN, tagName string, tagIndex *taggedResources) ([]stateMachine, error) {
 	var taggedMachines []stateMachine
 
-	result, err := svc.ListStateMachines(nil)
+	var machines []*sfn.StateMachineListItem
+	err := svc.ListStateMachinesPagesWithContext(ctx, &sfn.ListStateMachinesInput{}, func(page *sfn.ListStateMachinesOutput, lastPage bool) bool {
+		machines = append(machines, page.StateMachines...)
+		return true
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	if len(result.StateMachines) == 0 {
-		return nil, nil
-	}
-
-	for _, machine := range result.StateMachines {
-		tags, err := svc.ListTagsForResource(
-			&sfn.ListTagsForResourceInput{
-				ResourceArn: aws.String(*machine.StateMachineArn),
-			},
-		)
-		if err != nil {
-			continue
+	for _, machine := range machines {
+		var tags interface{}
+		if indexedTags, ok := tagIndex.lookup(aws.StringValue(machine.StateMachineArn)); ok {
+			tags = indexedTags
+		} else {
+			output, err := svc.ListTagsForResourceWithContext(ctx,
+				&sfn.ListTagsForResourceInput{
+					ResourceArn: aws.String(*machine.StateMachineArn),
+				},
+			)
+			if err != nil {
+				return nil, fmt.Errorf("can't get tags of state machine %s: %w", *machine.Name, err)
+			}
+			tags = output.Tags
 		}
 
-		essentialTags := common.GetEssentialTags(tags.Tags, tagName)
+		essentialTags := common.GetEssentialTags(tags, tagName)
 
 		taggedMachines = append(taggedMachines, stateMachine{
 			CloudProviderResource: common.CloudProviderResource{
@@ -45,6 +53,9 @@ func listTaggedStateMachines(svc sfn.SFN, tagName string) ([]stateMachine, error
 				TTL:          essentialTags.TTL,
 				Tag:          essentialTags.Tag,
 				IsProtected:  essentialTags.IsProtected,
+				ExpiresAt:    essentialTags.ExpiresAt,
+				Warnings:     essentialTags.Warnings,
+				Tags:         essentialTags.Tags,
 			},
 			machineName: *machine.Name,
 		})
@@ -53,8 +64,8 @@ func listTaggedStateMachines(svc sfn.SFN, tagName string) ([]stateMachine, error
 	return taggedMachines, nil
 }
 
-func deleteStateMachine(svc sfn.SFN, machine stateMachine) error {
-	_, err := svc.DeleteStateMachine(&sfn.DeleteStateMachineInput{
+func deleteStateMachine(ctx context.Context, svc sfn.SFN, machine stateMachine) error {
+	_, err := svc.DeleteStateMachineWithContext(ctx, &sfn.DeleteStateMachineInput{
 		StateMachineArn: &machine.Identifier,
 	},
 	)
@@ -65,42 +76,32 @@ func deleteStateMachine(svc sfn.SFN, machine stateMachine) error {
 	return nil
 }
 
-func getExpiredMachines(ECsession *sfn.SFN, options *AwsOptions) ([]stateMachine, string) {
-	machines, err := listTaggedStateMachines(*ECsession, options.TagName)
-	region := *ECsession.Config.Region
-	if err != nil {
-		log.Errorf("Can't list Step Functions in region %s: %s", region, err.Error())
-	}
-
-	var expiredMachines []stateMachine
-	for _, machine := range machines {
-		if machine.IsResourceExpired(options.TagValue, options.DisableTTLCheck) {
-			expiredMachines = append(expiredMachines, machine)
-		}
-	}
-
-	return expiredMachines, region
+type stateMachineCleaner struct {
+	awsCleaner
 }
 
-func DeleteExpiredStateMachines(sessions AWSSessions, options AwsOptions) {
-	expiredMachines, region := getExpiredMachines(sessions.SFN, &options)
-
-	count, start := common.ElemToDeleteFormattedInfos("expired Step Function", len(expiredMachines), region)
+func init() {
+	registerRegionalCleaner("sfn", "sfn-state-machine", "expired Step Function", func(base awsCleaner) common.Cleaner {
+		return stateMachineCleaner{base}
+	})
+}
 
-	log.Info(count)
+func (c stateMachineCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	items, err := listTaggedStateMachines(ctx, *c.sessions.SFN, c.options.TagName, c.options.tagIndex)
+	if err != nil {
+		return nil, err
+	}
 
-	if options.DryRun || len(expiredMachines) == 0 {
-		return
+	var resources []common.CloudProviderResource
+	for _, item := range items {
+		resource := item.CloudProviderResource
+		resource.Payload = item
+		resources = append(resources, resource)
 	}
 
-	log.Info(start)
+	return resources, nil
+}
 
-	for _, machine := range expiredMachines {
-		deletionErr := deleteStateMachine(*sessions.SFN, machine)
-		if deletionErr != nil {
-			log.Errorf("Deletion Step function error %s/%s: %s", machine.machineName, region, deletionErr.Error())
-		} else {
-			log.Debugf("Step function %s in %s deleted.", machine.machineName, region)
-		}
-	}
+func (c stateMachineCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	return deleteStateMachine(ctx, *c.sessions.SFN, resource.Payload.(stateMachine))
 }
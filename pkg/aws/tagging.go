@@ -0,0 +1,215 @@
+package aws
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
+
+	"github.com/Qovery/pleco/pkg/common"
+)
+
+// taggingAPIServices are the services whose tagged resources are all returned by the Resource Groups Tagging API: a
+// resource of these services missing from the sweep has no tag.
+var taggingAPIServices = map[string]bool{
+	"s3":                   true,
+	"sqs":                  true,
+	"logs":                 true,
+	"kms":                  true,
+	"rds":                  true,
+	"elasticache":          true,
+	"elasticloadbalancing": true,
+	"ecr":                  true,
+	"states":               true,
+	"events":               true,
+}
+
+// supportedResourceTypes are the types, as service:type in the ARNs, of the resources Pleco cleans. The ARNs of S3
+// buckets and SQS queues have no type.
+var supportedResourceTypes = map[string]bool{
+	"ec2:instance":                      true,
+	"ec2:volume":                        true,
+	"ec2:vpc":                           true,
+	"ec2:natgateway":                    true,
+	"ec2:elastic-ip":                    true,
+	"ec2:key-pair":                      true,
+	"ec2:security-group":                true,
+	"ec2:subnet":                        true,
+	"ec2:route-table":                   true,
+	"ec2:internet-gateway":              true,
+	"ec2:network-interface":             true,
+	"ec2:vpc-endpoint":                  true,
+	"ec2:vpc-peering-connection":        true,
+	"elasticloadbalancing:loadbalancer": true,
+	"rds:db":                            true,
+	"rds:subgrp":                        true,
+	"rds:pg":                            true,
+	"rds:snapshot":                      true,
+	"rds:cluster":                       true,
+	"rds:cluster-snapshot":              true,
+	"elasticache:cluster":               true,
+	"elasticache:replicationgroup":      true,
+	"elasticache:subnetgroup":           true,
+	"elasticache:snapshot":              true,
+	"eks:cluster":                       true,
+	"eks:nodegroup":                     true,
+	"eks:fargateprofile":                true,
+	"ecr:repository":                    true,
+	"logs:log-group":                    true,
+	"kms:key":                           true,
+	"iam:role":                          true,
+	"iam:user":                          true,
+	"iam:policy":                        true,
+	"iam:instance-profile":              true,
+	"iam:oidc-provider":                 true,
+	"lambda:function":                   true,
+	"states:stateMachine":               true,
+	"cloudformation:stack":              true,
+	"events:rule":                       true,
+	"s3":                                true,
+	"sqs":                               true,
+}
+
+// taggedResources indexes the tags of the resources of an engine location, read with a single Resource Groups Tagging
+// API sweep per cycle instead of a tag call per resource. The tags are the ones of the start of the cycle.
+type taggedResources struct {
+	// global indexes the resources without region, eg. IAM ones, instead of the regional ones
+	global bool
+	// untaggedWhenMissing considers the resources of the services fully covered by the tagging API as untagged when
+	// missing from the sweep. It is disabled with the ttl check, as an untagged resource is then deleted.
+	untaggedWhenMissing bool
+
+	mutex  sync.RWMutex
+	loaded bool
+	tags   map[string]map[string]string
+}
+
+func newTaggedResources(global bool, disableTTLCheck bool) *taggedResources {
+	return &taggedResources{global: global, untaggedWhenMissing: !disableTTLCheck}
+}
+
+// load reads the tags of every tagged resource of the location. Until it succeeds, lookups miss and the cleaners read
+// the tags on their own.
+func (index *taggedResources) load(ctx context.Context, svc *resourcegroupstaggingapi.ResourceGroupsTaggingAPI) error {
+	index.mutex.Lock()
+	index.loaded = false
+	index.tags = nil
+	index.mutex.Unlock()
+
+	tags := make(map[string]map[string]string)
+	err := svc.GetResourcesPagesWithContext(ctx, &resourcegroupstaggingapi.GetResourcesInput{
+		ResourcesPerPage: aws.Int64(100),
+	}, func(page *resourcegroupstaggingapi.GetResourcesOutput, lastPage bool) bool {
+		for _, mapping := range page.ResourceTagMappingList {
+			resourceTags := make(map[string]string, len(mapping.Tags))
+			for _, tag := range mapping.Tags {
+				resourceTags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+			}
+			tags[aws.StringValue(mapping.ResourceARN)] = resourceTags
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+	index.tags = tags
+	index.loaded = true
+
+	return nil
+}
+
+// lookup returns the tags of a resource, or false when they must be read from the resource itself.
+func (index *taggedResources) lookup(resourceARN string) (map[string]string, bool) {
+	if index == nil {
+		return nil, false
+	}
+
+	index.mutex.RLock()
+	defer index.mutex.RUnlock()
+	if !index.loaded {
+		return nil, false
+	}
+
+	if tags, ok := index.tags[resourceARN]; ok {
+		return tags, true
+	}
+
+	parsed, err := arn.Parse(resourceARN)
+	if err != nil || !index.untaggedWhenMissing || !taggingAPIServices[parsed.Service] {
+		return nil, false
+	}
+
+	return map[string]string{}, true
+}
+
+// unsupported returns the resources of the location carrying Pleco tags whose type no cleaner handles.
+func (index *taggedResources) unsupported(tagName string) []common.UnsupportedResource {
+	index.mutex.RLock()
+	defer index.mutex.RUnlock()
+
+	var resources []common.UnsupportedResource
+	for resourceARN, tags := range index.tags {
+		parsed, err := arn.Parse(resourceARN)
+		if err != nil {
+			continue
+		}
+
+		// S3 buckets ARNs have no region, but the buckets are listed by the regional sweeps
+		global := parsed.Region == "" && parsed.Service != "s3"
+		if global != index.global {
+			continue
+		}
+
+		resourceType := arnResourceType(parsed)
+		if supportedResourceTypes[resourceType] {
+			continue
+		}
+
+		essentialTags := common.GetEssentialTags(tags, tagName)
+		if essentialTags.TTL == -1 && essentialTags.ExpiresAt.IsZero() && essentialTags.Tag == "" {
+			continue
+		}
+
+		resources = append(resources, common.UnsupportedResource{
+			Type:       resourceType,
+			Identifier: resourceARN,
+			Tags:       tags,
+		})
+	}
+	sort.Slice(resources, func(i, j int) bool {
+		return resources[i].Identifier < resources[j].Identifier
+	})
+
+	return resources
+}
+
+// arnResourceType returns service:type, eg. ec2:instance, from the resource of an ARN, either type/id or type:id.
+func arnResourceType(parsed arn.ARN) string {
+	if parsed.Service == "s3" || parsed.Service == "sqs" {
+		return parsed.Service
+	}
+
+	resourceType := parsed.Resource
+	if index := strings.IndexAny(resourceType, "/:"); index >= 0 {
+		resourceType = resourceType[:index]
+	}
+
+	return parsed.Service + ":" + resourceType
+}
+
+// partition returns the partition of a region, eg. aws or aws-cn, ARNs are built with.
+func partition(region string) string {
+	if partition, ok := endpoints.PartitionForRegion(endpoints.DefaultPartitions(), region); ok {
+		return partition.ID()
+	}
+
+	return endpoints.AwsPartitionID
+}
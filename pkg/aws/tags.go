@@ -0,0 +1,328 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ecr"
+	"github.com/aws/aws-sdk-go/service/eks"
+	"github.com/aws/aws-sdk-go/service/elasticache"
+	"github.com/aws/aws-sdk-go/service/eventbridge"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/sfn"
+	"github.com/aws/aws-sdk-go/service/sqs"
+
+	"github.com/Qovery/pleco/pkg/common"
+)
+
+func sortedKeys(tags map[string]string) []string {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func setEC2Tags(ctx context.Context, svc *ec2.EC2, identifier string, tags map[string]string) error {
+	var ec2Tags []*ec2.Tag
+	for _, key := range sortedKeys(tags) {
+		ec2Tags = append(ec2Tags, &ec2.Tag{Key: aws.String(key), Value: aws.String(tags[key])})
+	}
+
+	_, err := svc.CreateTagsWithContext(ctx, &ec2.CreateTagsInput{
+		Resources: []*string{aws.String(identifier)},
+		Tags:      ec2Tags,
+	})
+
+	return err
+}
+
+// setRDSTags tags RDS, and DocumentDB, resources by their ARN.
+func setRDSTags(ctx context.Context, svc *rds.RDS, arn *string, tags map[string]string) error {
+	var rdsTags []*rds.Tag
+	for _, key := range sortedKeys(tags) {
+		rdsTags = append(rdsTags, &rds.Tag{Key: aws.String(key), Value: aws.String(tags[key])})
+	}
+
+	_, err := svc.AddTagsToResourceWithContext(ctx, &rds.AddTagsToResourceInput{
+		ResourceName: arn,
+		Tags:         rdsTags,
+	})
+
+	return err
+}
+
+func (c ec2InstanceCleaner) SetTags(ctx context.Context, resource common.CloudProviderResource, tags map[string]string) error {
+	return setEC2Tags(ctx, c.sessions.EC2, resource.Identifier, tags)
+}
+
+func (c vpcCleaner) SetTags(ctx context.Context, resource common.CloudProviderResource, tags map[string]string) error {
+	return setEC2Tags(ctx, c.sessions.EC2, resource.Identifier, tags)
+}
+
+func (c ebsVolumeCleaner) SetTags(ctx context.Context, resource common.CloudProviderResource, tags map[string]string) error {
+	return setEC2Tags(ctx, c.sessions.EC2, resource.Identifier, tags)
+}
+
+func (c natGatewayCleaner) SetTags(ctx context.Context, resource common.CloudProviderResource, tags map[string]string) error {
+	return setEC2Tags(ctx, c.sessions.EC2, resource.Identifier, tags)
+}
+
+func (c elasticIpCleaner) SetTags(ctx context.Context, resource common.CloudProviderResource, tags map[string]string) error {
+	return setEC2Tags(ctx, c.sessions.EC2, resource.Identifier, tags)
+}
+
+func (c eksClusterCleaner) SetTags(ctx context.Context, resource common.CloudProviderResource, tags map[string]string) error {
+	cluster, err := c.sessions.EKS.DescribeClusterWithContext(ctx, &eks.DescribeClusterInput{Name: aws.String(resource.Identifier)})
+	if err != nil {
+		return err
+	}
+
+	_, err = c.sessions.EKS.TagResourceWithContext(ctx, &eks.TagResourceInput{
+		ResourceArn: cluster.Cluster.Arn,
+		Tags:        aws.StringMap(tags),
+	})
+
+	return err
+}
+
+func (c rdsDatabaseCleaner) SetTags(ctx context.Context, resource common.CloudProviderResource, tags map[string]string) error {
+	result, err := c.sessions.RDS.DescribeDBInstancesWithContext(ctx, &rds.DescribeDBInstancesInput{DBInstanceIdentifier: aws.String(resource.Identifier)})
+	if err != nil {
+		return err
+	}
+	if len(result.DBInstances) == 0 {
+		return fmt.Errorf("database %s not found", resource.Identifier)
+	}
+
+	return setRDSTags(ctx, c.sessions.RDS, result.DBInstances[0].DBInstanceArn, tags)
+}
+
+// SetTags merges the tags with the bucket ones, as a bucket tagging is replaced as a whole.
+func (c s3BucketCleaner) SetTags(ctx context.Context, resource common.CloudProviderResource, tags map[string]string) error {
+	merged := make(map[string]string)
+	current, err := c.sessions.S3.GetBucketTaggingWithContext(ctx, &s3.GetBucketTaggingInput{Bucket: aws.String(resource.Identifier)})
+	if err == nil {
+		for _, tag := range current.TagSet {
+			merged[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+		}
+	}
+	for key, value := range tags {
+		merged[key] = value
+	}
+
+	var tagSet []*s3.Tag
+	for _, key := range sortedKeys(merged) {
+		tagSet = append(tagSet, &s3.Tag{Key: aws.String(key), Value: aws.String(merged[key])})
+	}
+
+	_, err = c.sessions.S3.PutBucketTaggingWithContext(ctx, &s3.PutBucketTaggingInput{
+		Bucket:  aws.String(resource.Identifier),
+		Tagging: &s3.Tagging{TagSet: tagSet},
+	})
+
+	return err
+}
+
+func (c rdsSubnetGroupCleaner) SetTags(ctx context.Context, resource common.CloudProviderResource, tags map[string]string) error {
+	result, err := c.sessions.RDS.DescribeDBSubnetGroupsWithContext(ctx, &rds.DescribeDBSubnetGroupsInput{DBSubnetGroupName: aws.String(resource.Identifier)})
+	if err != nil {
+		return err
+	}
+	if len(result.DBSubnetGroups) == 0 {
+		return fmt.Errorf("subnet group %s not found", resource.Identifier)
+	}
+
+	return setRDSTags(ctx, c.sessions.RDS, result.DBSubnetGroups[0].DBSubnetGroupArn, tags)
+}
+
+func (c rdsParameterGroupCleaner) SetTags(ctx context.Context, resource common.CloudProviderResource, tags map[string]string) error {
+	result, err := c.sessions.RDS.DescribeDBParameterGroupsWithContext(ctx, &rds.DescribeDBParameterGroupsInput{DBParameterGroupName: aws.String(resource.Identifier)})
+	if err != nil {
+		return err
+	}
+	if len(result.DBParameterGroups) == 0 {
+		return fmt.Errorf("parameter group %s not found", resource.Identifier)
+	}
+
+	return setRDSTags(ctx, c.sessions.RDS, result.DBParameterGroups[0].DBParameterGroupArn, tags)
+}
+
+func (c documentDBClusterCleaner) SetTags(ctx context.Context, resource common.CloudProviderResource, tags map[string]string) error {
+	result, err := c.sessions.RDS.DescribeDBClustersWithContext(ctx, &rds.DescribeDBClustersInput{DBClusterIdentifier: aws.String(resource.Identifier)})
+	if err != nil {
+		return err
+	}
+	if len(result.DBClusters) == 0 {
+		return fmt.Errorf("cluster %s not found", resource.Identifier)
+	}
+
+	return setRDSTags(ctx, c.sessions.RDS, result.DBClusters[0].DBClusterArn, tags)
+}
+
+func (c elasticacheClusterCleaner) SetTags(ctx context.Context, resource common.CloudProviderResource, tags map[string]string) error {
+	result, err := c.sessions.ElastiCache.DescribeCacheClustersWithContext(ctx, &elasticache.DescribeCacheClustersInput{CacheClusterId: aws.String(resource.Identifier)})
+	if err != nil {
+		return err
+	}
+	if len(result.CacheClusters) == 0 {
+		return fmt.Errorf("cluster %s not found", resource.Identifier)
+	}
+
+	var elasticacheTags []*elasticache.Tag
+	for _, key := range sortedKeys(tags) {
+		elasticacheTags = append(elasticacheTags, &elasticache.Tag{Key: aws.String(key), Value: aws.String(tags[key])})
+	}
+
+	_, err = c.sessions.ElastiCache.AddTagsToResourceWithContext(ctx, &elasticache.AddTagsToResourceInput{
+		ResourceName: result.CacheClusters[0].ARN,
+		Tags:         elasticacheTags,
+	})
+
+	return err
+}
+
+func (c keyPairCleaner) SetTags(ctx context.Context, resource common.CloudProviderResource, tags map[string]string) error {
+	return setEC2Tags(ctx, c.sessions.EC2, resource.Identifier, tags)
+}
+
+func (c ecrRepositoryCleaner) SetTags(ctx context.Context, resource common.CloudProviderResource, tags map[string]string) error {
+	result, err := c.sessions.ECR.DescribeRepositoriesWithContext(ctx, &ecr.DescribeRepositoriesInput{RepositoryNames: aws.StringSlice([]string{resource.Identifier})})
+	if err != nil {
+		return err
+	}
+	if len(result.Repositories) == 0 {
+		return fmt.Errorf("repository %s not found", resource.Identifier)
+	}
+
+	var ecrTags []*ecr.Tag
+	for _, key := range sortedKeys(tags) {
+		ecrTags = append(ecrTags, &ecr.Tag{Key: aws.String(key), Value: aws.String(tags[key])})
+	}
+
+	_, err = c.sessions.ECR.TagResourceWithContext(ctx, &ecr.TagResourceInput{
+		ResourceArn: result.Repositories[0].RepositoryArn,
+		Tags:        ecrTags,
+	})
+
+	return err
+}
+
+func (c cloudWatchEventCleaner) SetTags(ctx context.Context, resource common.CloudProviderResource, tags map[string]string) error {
+	rule, err := c.sessions.EventBridge.DescribeRuleWithContext(ctx, &eventbridge.DescribeRuleInput{Name: aws.String(resource.Identifier)})
+	if err != nil {
+		return err
+	}
+
+	var eventTags []*eventbridge.Tag
+	for _, key := range sortedKeys(tags) {
+		eventTags = append(eventTags, &eventbridge.Tag{Key: aws.String(key), Value: aws.String(tags[key])})
+	}
+
+	_, err = c.sessions.EventBridge.TagResourceWithContext(ctx, &eventbridge.TagResourceInput{
+		ResourceARN: rule.Arn,
+		Tags:        eventTags,
+	})
+
+	return err
+}
+
+func (c logGroupCleaner) SetTags(ctx context.Context, resource common.CloudProviderResource, tags map[string]string) error {
+	_, err := c.sessions.CloudWatchLogs.TagLogGroupWithContext(ctx, &cloudwatchlogs.TagLogGroupInput{
+		LogGroupName: aws.String(resource.Identifier),
+		Tags:         aws.StringMap(tags),
+	})
+
+	return err
+}
+
+func (c kmsKeyCleaner) SetTags(ctx context.Context, resource common.CloudProviderResource, tags map[string]string) error {
+	var kmsTags []*kms.Tag
+	for _, key := range sortedKeys(tags) {
+		kmsTags = append(kmsTags, &kms.Tag{TagKey: aws.String(key), TagValue: aws.String(tags[key])})
+	}
+
+	_, err := c.sessions.KMS.TagResourceWithContext(ctx, &kms.TagResourceInput{
+		KeyId: aws.String(resource.Identifier),
+		Tags:  kmsTags,
+	})
+
+	return err
+}
+
+func (c lambdaFunctionCleaner) SetTags(ctx context.Context, resource common.CloudProviderResource, tags map[string]string) error {
+	function, err := c.sessions.LambdaFunction.GetFunctionWithContext(ctx, &lambda.GetFunctionInput{FunctionName: aws.String(resource.Identifier)})
+	if err != nil {
+		return err
+	}
+
+	_, err = c.sessions.LambdaFunction.TagResourceWithContext(ctx, &lambda.TagResourceInput{
+		Resource: function.Configuration.FunctionArn,
+		Tags:     aws.StringMap(tags),
+	})
+
+	return err
+}
+
+func (c sqsQueueCleaner) SetTags(ctx context.Context, resource common.CloudProviderResource, tags map[string]string) error {
+	_, err := c.sessions.SQS.TagQueueWithContext(ctx, &sqs.TagQueueInput{
+		QueueUrl: aws.String(resource.Identifier),
+		Tags:     aws.StringMap(tags),
+	})
+
+	return err
+}
+
+func (c stateMachineCleaner) SetTags(ctx context.Context, resource common.CloudProviderResource, tags map[string]string) error {
+	var sfnTags []*sfn.Tag
+	for _, key := range sortedKeys(tags) {
+		sfnTags = append(sfnTags, &sfn.Tag{Key: aws.String(key), Value: aws.String(tags[key])})
+	}
+
+	_, err := c.sessions.SFN.TagResourceWithContext(ctx, &sfn.TagResourceInput{
+		ResourceArn: aws.String(resource.Identifier),
+		Tags:        sfnTags,
+	})
+
+	return err
+}
+
+func iamTags(tags map[string]string) []*iam.Tag {
+	var iamTags []*iam.Tag
+	for _, key := range sortedKeys(tags) {
+		iamTags = append(iamTags, &iam.Tag{Key: aws.String(key), Value: aws.String(tags[key])})
+	}
+
+	return iamTags
+}
+
+func (c iamUserCleaner) SetTags(ctx context.Context, resource common.CloudProviderResource, tags map[string]string) error {
+	_, err := c.sessions.IAM.TagUserWithContext(ctx, &iam.TagUserInput{UserName: aws.String(resource.Identifier), Tags: iamTags(tags)})
+	return err
+}
+
+func (c iamRoleCleaner) SetTags(ctx context.Context, resource common.CloudProviderResource, tags map[string]string) error {
+	_, err := c.sessions.IAM.TagRoleWithContext(ctx, &iam.TagRoleInput{RoleName: aws.String(resource.Identifier), Tags: iamTags(tags)})
+	return err
+}
+
+func (c iamInstanceProfileCleaner) SetTags(ctx context.Context, resource common.CloudProviderResource, tags map[string]string) error {
+	instanceProfile := resource.Payload.(InstanceProfile)
+	_, err := c.sessions.IAM.TagInstanceProfileWithContext(ctx, &iam.TagInstanceProfileInput{InstanceProfileName: aws.String(instanceProfile.InstanceProfileName), Tags: iamTags(tags)})
+	return err
+}
+
+func (c oidcProviderCleaner) SetTags(ctx context.Context, resource common.CloudProviderResource, tags map[string]string) error {
+	_, err := c.sessions.IAM.TagOpenIDConnectProviderWithContext(ctx, &iam.TagOpenIDConnectProviderInput{OpenIDConnectProviderArn: aws.String(resource.Identifier), Tags: iamTags(tags)})
+	return err
+}
@@ -1,6 +1,7 @@
 package aws
 
 import (
+	"context"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	log "github.com/sirupsen/logrus"
@@ -14,7 +15,7 @@ type ElasticIp struct {
 	Ip            string
 }
 
-func getElasticIps(ec2Session *ec2.EC2, tagName string) []ElasticIp {
+func getElasticIps(ctx context.Context, ec2Session *ec2.EC2, tagName string) ([]ElasticIp, error) {
 	input := &ec2.DescribeAddressesInput{
 		// only supporting EIP attached to VPC
 		Filters: []*ec2.Filter{
@@ -25,15 +26,15 @@ func getElasticIps(ec2Session *ec2.EC2, tagName string) []ElasticIp {
 		},
 	}
 
-	elasticIps, err := ec2Session.DescribeAddresses(input)
+	elasticIps, err := ec2Session.DescribeAddressesWithContext(ctx, input)
 	if err != nil {
-		log.Errorf("Can't list elastic IPs in region %s: %s", *ec2Session.Config.Region, err.Error())
+		return nil, err
 	}
 
-	return responseToStruct(elasticIps, tagName)
+	return responseToStruct(elasticIps, tagName), nil
 }
 
-func getElasticIpByNetworkInterfaceId(ec2Session *ec2.EC2, niId string, vpcId string, tagName string) []ElasticIp {
+func getElasticIpByNetworkInterfaceId(ctx context.Context, ec2Session *ec2.EC2, niId string, vpcId string, tagName string) []ElasticIp {
 	input := &ec2.DescribeAddressesInput{
 		// only supporting EIP attached to VPC
 		Filters: []*ec2.Filter{
@@ -48,7 +49,7 @@ func getElasticIpByNetworkInterfaceId(ec2Session *ec2.EC2, niId string, vpcId st
 		},
 	}
 
-	elasticIps, err := ec2Session.DescribeAddresses(input)
+	elasticIps, err := ec2Session.DescribeAddressesWithContext(ctx, input)
 	if err != nil {
 		log.Errorf("Can't list elastic IPs for VPC %s in region %s: %s", vpcId, *ec2Session.Config.Region, err.Error())
 	}
@@ -56,9 +57,9 @@ func getElasticIpByNetworkInterfaceId(ec2Session *ec2.EC2, niId string, vpcId st
 	return responseToStruct(elasticIps, tagName)
 }
 
-func ReleaseElasticIps(ec2Session *ec2.EC2, eips []ElasticIp) {
+func ReleaseElasticIps(ctx context.Context, ec2Session *ec2.EC2, eips []ElasticIp) {
 	for _, eip := range eips {
-		_, detachErr := ec2Session.DisassociateAddress(
+		_, detachErr := ec2Session.DisassociateAddressWithContext(ctx,
 			&ec2.DisassociateAddressInput{
 				AssociationId: aws.String(eip.AssociationId),
 			})
@@ -72,55 +73,49 @@ func ReleaseElasticIps(ec2Session *ec2.EC2, eips []ElasticIp) {
 
 }
 
-func deleteElasticIp(ec2Session *ec2.EC2, eip ElasticIp) {
-	_, releaseErr := ec2Session.ReleaseAddress(
+func deleteElasticIp(ctx context.Context, ec2Session *ec2.EC2, eip ElasticIp) error {
+	_, err := ec2Session.ReleaseAddressWithContext(ctx,
 		&ec2.ReleaseAddressInput{
 			AllocationId: aws.String(eip.Identifier),
 		})
 
-	if releaseErr != nil {
-		log.Errorf("Can't release EIP %s: %s", eip.Identifier, releaseErr.Error())
-	} else {
-		log.Debugf("EIP %s in %s deleted.", eip.Identifier, *ec2Session.Config.Region)
-	}
+	return err
 }
 
-func getExpiredEIPs(ec2Session *ec2.EC2, options *AwsOptions) []ElasticIp {
-	elasticIps := getElasticIps(ec2Session, options.TagName)
-
-	expiredEips := []ElasticIp{}
-	for _, eip := range elasticIps {
-
-		if eip.IsResourceExpired(options.TagValue, options.DisableTTLCheck) {
-			expiredEips = append(expiredEips, eip)
-		}
-	}
-
-	return expiredEips
+type elasticIpCleaner struct {
+	awsCleaner
 }
 
-func DeleteExpiredElasticIps(sessions AWSSessions, options AwsOptions) {
-	expiredEips := getExpiredEIPs(sessions.EC2, &options)
-
-	count, start := common.ElemToDeleteFormattedInfos("expired EIP", len(expiredEips), *sessions.EC2.Config.Region)
+func init() {
+	registerRegionalCleaner("vpc", "elastic-ip", "expired EIP", func(base awsCleaner) common.Cleaner {
+		return elasticIpCleaner{base}
+	})
+}
 
-	log.Info(count)
+func (c elasticIpCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	eips, err := getElasticIps(ctx, c.sessions.EC2, c.options.TagName)
+	if err != nil {
+		return nil, err
+	}
 
-	if options.DryRun || len(expiredEips) == 0 {
-		return
+	var resources []common.CloudProviderResource
+	for _, eip := range eips {
+		resource := eip.CloudProviderResource
+		resource.Payload = eip
+		resources = append(resources, resource)
 	}
 
-	log.Info(start)
+	return resources, nil
+}
 
-	for _, elasticIp := range expiredEips {
-		deleteElasticIp(sessions.EC2, elasticIp)
-	}
+func (c elasticIpCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	return deleteElasticIp(ctx, c.sessions.EC2, resource.Payload.(ElasticIp))
 }
 
-func GetElasticIpsByVpcId(ec2Session *ec2.EC2, vpc VpcInfo, tagName string) []ElasticIp {
+func GetElasticIpsByVpcId(ctx context.Context, ec2Session *ec2.EC2, vpc VpcInfo, tagName string) []ElasticIp {
 	elasticIps := []ElasticIp{}
 	for _, ni := range vpc.NetworkInterfaces {
-		elasticIps = append(elasticIps, getElasticIpByNetworkInterfaceId(ec2Session, ni.Id, vpc.Identifier, tagName)...)
+		elasticIps = append(elasticIps, getElasticIpByNetworkInterfaceId(ctx, ec2Session, ni.Id, vpc.Identifier, tagName)...)
 	}
 
 	return elasticIps
@@ -138,6 +133,9 @@ func responseToStruct(result *ec2.DescribeAddressesOutput, tagName string) []Ela
 				TTL:          essentialTags.TTL,
 				Tag:          essentialTags.Tag,
 				IsProtected:  essentialTags.IsProtected,
+				ExpiresAt:    essentialTags.ExpiresAt,
+				Warnings:     essentialTags.Warnings,
+				Tags:         essentialTags.Tags,
 			},
 			AssociationId: "",
 			Ip:            "",
@@ -1,12 +1,13 @@
 package aws
 
 import (
+	"context"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	log "github.com/sirupsen/logrus"
 )
 
-func DeleteVpcEndpointsByVpcId(ec2Session *ec2.EC2, vpcId string) {
+func DeleteVpcEndpointsByVpcId(ctx context.Context, ec2Session *ec2.EC2, vpcId string) {
 	// Find all VPC endpoints for this VPC
 	input := &ec2.DescribeVpcEndpointsInput{
 		Filters: []*ec2.Filter{
@@ -17,24 +18,28 @@ func DeleteVpcEndpointsByVpcId(ec2Session *ec2.EC2, vpcId string) {
 		},
 	}
 
-	result, err := ec2Session.DescribeVpcEndpoints(input)
+	var endpoints []*ec2.VpcEndpoint
+	err := ec2Session.DescribeVpcEndpointsPagesWithContext(ctx, input, func(page *ec2.DescribeVpcEndpointsOutput, lastPage bool) bool {
+		endpoints = append(endpoints, page.VpcEndpoints...)
+		return true
+	})
 	if err != nil {
 		log.Errorf("Failed to describe VPC endpoints for VPC %s: %s", vpcId, err.Error())
 		return
 	}
 
-	if len(result.VpcEndpoints) == 0 {
+	if len(endpoints) == 0 {
 		log.Debugf("No VPC endpoints found for VPC %s", vpcId)
 		return
 	}
 
 	// Delete each VPC endpoint
-	for _, endpoint := range result.VpcEndpoints {
+	for _, endpoint := range endpoints {
 		if endpoint.VpcEndpointId == nil {
 			continue
 		}
 
-		_, deleteErr := ec2Session.DeleteVpcEndpoints(&ec2.DeleteVpcEndpointsInput{
+		_, deleteErr := ec2Session.DeleteVpcEndpointsWithContext(ctx, &ec2.DeleteVpcEndpointsInput{
 			VpcEndpointIds: []*string{endpoint.VpcEndpointId},
 		})
 
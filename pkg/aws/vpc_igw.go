@@ -1,6 +1,7 @@
 package aws
 
 import (
+	"context"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -17,7 +18,7 @@ type InternetGateway struct {
 	IsProtected  bool
 }
 
-func getInternetGatewaysByVpcId(ec2Session *ec2.EC2, vpcId string) []*ec2.InternetGateway {
+func getInternetGatewaysByVpcId(ctx context.Context, ec2Session *ec2.EC2, vpcId string) []*ec2.InternetGateway {
 	input := &ec2.DescribeInternetGatewaysInput{
 		Filters: []*ec2.Filter{
 			{
@@ -27,18 +28,22 @@ func getInternetGatewaysByVpcId(ec2Session *ec2.EC2, vpcId string) []*ec2.Intern
 		},
 	}
 
-	gateways, err := ec2Session.DescribeInternetGateways(input)
+	var gateways []*ec2.InternetGateway
+	err := ec2Session.DescribeInternetGatewaysPagesWithContext(ctx, input, func(page *ec2.DescribeInternetGatewaysOutput, lastPage bool) bool {
+		gateways = append(gateways, page.InternetGateways...)
+		return true
+	})
 	if err != nil {
 		log.Error(err)
 	}
 
-	return gateways.InternetGateways
+	return gateways
 }
 
-func GetInternetGatewaysIdsByVpcId(ec2Session *ec2.EC2, vpcId string, tagName string) []InternetGateway {
+func GetInternetGatewaysIdsByVpcId(ctx context.Context, ec2Session *ec2.EC2, vpcId string, tagName string) []InternetGateway {
 	var internetGateways []InternetGateway
 
-	gateways := getInternetGatewaysByVpcId(ec2Session, vpcId)
+	gateways := getInternetGatewaysByVpcId(ctx, ec2Session, vpcId)
 
 	for _, gateway := range gateways {
 		essentialTags := common.GetEssentialTags(gateway.Tags, tagName)
@@ -56,11 +61,11 @@ func GetInternetGatewaysIdsByVpcId(ec2Session *ec2.EC2, vpcId string, tagName st
 	return internetGateways
 }
 
-func DeleteInternetGatewaysByIds(ec2Session *ec2.EC2, internetGateways []InternetGateway, vpcId string) {
+func DeleteInternetGatewaysByIds(ctx context.Context, ec2Session *ec2.EC2, internetGateways []InternetGateway, vpcId string) {
 	for _, internetGateway := range internetGateways {
 		if !internetGateway.IsProtected {
 
-			_, detachErr := ec2Session.DetachInternetGateway(
+			_, detachErr := ec2Session.DetachInternetGatewayWithContext(ctx,
 				&ec2.DetachInternetGatewayInput{
 					InternetGatewayId: aws.String(internetGateway.Id),
 					VpcId:             aws.String(vpcId),
@@ -70,7 +75,7 @@ func DeleteInternetGatewaysByIds(ec2Session *ec2.EC2, internetGateways []Interne
 				log.Error(detachErr)
 			}
 
-			_, deleteErr := ec2Session.DeleteInternetGateway(
+			_, deleteErr := ec2Session.DeleteInternetGatewayWithContext(ctx,
 				&ec2.DeleteInternetGatewayInput{
 					InternetGatewayId: aws.String(internetGateway.Id),
 				},
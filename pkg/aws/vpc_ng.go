@@ -1,6 +1,7 @@
 package aws
 
 import (
+	"context"
 	"time"
 
 	"github.com/Qovery/pleco/pkg/common"
@@ -13,7 +14,7 @@ type NatGateway struct {
 	common.CloudProviderResource
 }
 
-func getNatGatewaysByVpcId(ec2Session *ec2.EC2, options *AwsOptions, vpcId string) []NatGateway {
+func getNatGatewaysByVpcId(ctx context.Context, ec2Session *ec2.EC2, options *AwsOptions, vpcId string) []NatGateway {
 	input := &ec2.DescribeNatGatewaysInput{
 		Filter: []*ec2.Filter{
 			{
@@ -23,52 +24,44 @@ func getNatGatewaysByVpcId(ec2Session *ec2.EC2, options *AwsOptions, vpcId strin
 		},
 	}
 
-	gateways, err := ec2Session.DescribeNatGateways(input)
+	gateways, err := describeNatGateways(ctx, ec2Session, input)
 	if err != nil {
 		log.Error(err)
 	}
 
-	return gtwResponseToStruct(gateways.NatGateways, options.TagName)
+	return gtwResponseToStruct(gateways, options.TagName)
 }
 
-func getNatGateways(ec2Session *ec2.EC2, tagName string) []NatGateway {
-	gateways, err := ec2Session.DescribeNatGateways(&ec2.DescribeNatGatewaysInput{})
+func getNatGateways(ctx context.Context, ec2Session *ec2.EC2, tagName string) ([]NatGateway, error) {
+	gateways, err := describeNatGateways(ctx, ec2Session, &ec2.DescribeNatGatewaysInput{})
 	if err != nil {
-		log.Error(err)
+		return nil, err
 	}
 
-	return gtwResponseToStruct(gateways.NatGateways, tagName)
+	return gtwResponseToStruct(gateways, tagName), nil
 }
 
-func getExpiredNatGateways(ec2Session *ec2.EC2, options *AwsOptions) []NatGateway {
-	gateways := getNatGateways(ec2Session, options.TagName)
-
-	expiredGtws := []NatGateway{}
-	for _, gtw := range gateways {
-		if gtw.IsResourceExpired(options.TagValue, options.DisableTTLCheck) {
-			expiredGtws = append(expiredGtws, gtw)
-		}
-	}
+func describeNatGateways(ctx context.Context, ec2Session *ec2.EC2, input *ec2.DescribeNatGatewaysInput) ([]*ec2.NatGateway, error) {
+	var gateways []*ec2.NatGateway
+	err := ec2Session.DescribeNatGatewaysPagesWithContext(ctx, input, func(page *ec2.DescribeNatGatewaysOutput, lastPage bool) bool {
+		gateways = append(gateways, page.NatGateways...)
+		return true
+	})
 
-	return expiredGtws
+	return gateways, err
 }
 
-func GetNatGatewaysIdsByVpcId(ec2Session *ec2.EC2, options *AwsOptions, vpcId string) []NatGateway {
-	return getNatGatewaysByVpcId(ec2Session, options, vpcId)
+func GetNatGatewaysIdsByVpcId(ctx context.Context, ec2Session *ec2.EC2, options *AwsOptions, vpcId string) []NatGateway {
+	return getNatGatewaysByVpcId(ctx, ec2Session, options, vpcId)
 }
 
-func DeleteNatGatewaysByIds(ec2Session *ec2.EC2, natGateways []NatGateway) {
+func DeleteNatGatewaysByIds(ctx context.Context, ec2Session *ec2.EC2, natGateways []NatGateway) {
 	var deletedGatewayIds []*string
 
 	for _, natGateway := range natGateways {
 		if !natGateway.IsProtected {
 
-			_, deleteErr := ec2Session.DeleteNatGateway(
-				&ec2.DeleteNatGatewayInput{
-					NatGatewayId: aws.String(natGateway.Identifier),
-				},
-			)
-
+			deleteErr := deleteNatGateway(ctx, ec2Session, natGateway)
 			if deleteErr != nil {
 				log.Error(deleteErr)
 			} else {
@@ -81,11 +74,11 @@ func DeleteNatGatewaysByIds(ec2Session *ec2.EC2, natGateways []NatGateway) {
 	// Wait for NAT Gateways to be fully deleted
 	if len(deletedGatewayIds) > 0 {
 		log.Debugf("Waiting for %d NAT Gateway(s) to be deleted...", len(deletedGatewayIds))
-		waitForNatGatewayDeletion(ec2Session, deletedGatewayIds)
+		waitForNatGatewayDeletion(ctx, ec2Session, deletedGatewayIds)
 	}
 }
 
-func waitForNatGatewayDeletion(ec2Session *ec2.EC2, natGatewayIds []*string) {
+func waitForNatGatewayDeletion(ctx context.Context, ec2Session *ec2.EC2, natGatewayIds []*string) {
 	maxWaitTime := 10 * time.Minute
 	checkInterval := 15 * time.Second
 	startTime := time.Now()
@@ -96,7 +89,7 @@ func waitForNatGatewayDeletion(ec2Session *ec2.EC2, natGatewayIds []*string) {
 			return
 		}
 
-		result, err := ec2Session.DescribeNatGateways(&ec2.DescribeNatGatewaysInput{
+		result, err := ec2Session.DescribeNatGatewaysWithContext(ctx, &ec2.DescribeNatGatewaysInput{
 			NatGatewayIds: natGatewayIds,
 		})
 
@@ -119,7 +112,10 @@ func waitForNatGatewayDeletion(ec2Session *ec2.EC2, natGatewayIds []*string) {
 			return
 		}
 
-		time.Sleep(checkInterval)
+		if err := common.Sleep(ctx, checkInterval); err != nil {
+			log.Warnf("Stopped waiting for NAT Gateways to be deleted: %s", err.Error())
+			return
+		}
 	}
 }
 
@@ -135,6 +131,10 @@ func gtwResponseToStruct(result []*ec2.NatGateway, tagName string) []NatGateway
 				TTL:          essentialTags.TTL,
 				Tag:          essentialTags.Tag,
 				IsProtected:  essentialTags.IsProtected,
+				ExpiresAt:    essentialTags.ExpiresAt,
+				Warnings:     essentialTags.Warnings,
+				Tags:         essentialTags.Tags,
+				VpcId:        aws.StringValue(key.VpcId),
 			},
 		}
 
@@ -144,20 +144,40 @@ func gtwResponseToStruct(result []*ec2.NatGateway, tagName string) []NatGateway
 	return gtws
 }
 
-func DeleteExpiredNatGateways(sessions AWSSessions, options AwsOptions) {
-	gtws := getExpiredNatGateways(sessions.EC2, &options)
-	region := sessions.EC2.Config.Region
+func deleteNatGateway(ctx context.Context, ec2Session *ec2.EC2, natGateway NatGateway) error {
+	_, err := ec2Session.DeleteNatGatewayWithContext(ctx,
+		&ec2.DeleteNatGatewayInput{
+			NatGatewayId: aws.String(natGateway.Identifier),
+		},
+	)
 
-	count, start := common.ElemToDeleteFormattedInfos("expired Nat Gateway", len(gtws), *region)
+	return err
+}
+
+type natGatewayCleaner struct {
+	awsCleaner
+}
 
-	log.Info(count)
+func init() {
+	registerRegionalCleaner("vpc", "nat-gateway", "expired Nat Gateway", func(base awsCleaner) common.Cleaner {
+		return natGatewayCleaner{base}
+	})
+}
 
-	if options.DryRun || len(gtws) == 0 {
-		return
+func (c natGatewayCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	gateways, err := getNatGateways(ctx, c.sessions.EC2, c.options.TagName)
+	if err != nil {
+		return nil, err
 	}
 
-	log.Info(start)
+	var resources []common.CloudProviderResource
+	for _, gtw := range gateways {
+		resources = append(resources, gtw.CloudProviderResource)
+	}
 
-	DeleteNatGatewaysByIds(sessions.EC2, gtws)
+	return resources, nil
+}
 
+func (c natGatewayCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	return deleteNatGateway(ctx, c.sessions.EC2, NatGateway{CloudProviderResource: resource})
 }
@@ -1,6 +1,7 @@
 package aws
 
 import (
+	"context"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/sirupsen/logrus"
@@ -12,33 +13,37 @@ type NetworkInterface struct {
 	AttachmentId string
 }
 
-func DeleteNetworkInterfacesByVpcId(ec2Session *ec2.EC2, vpcId string) {
-	NIs := listNetworkInterfacesByVpcId(ec2Session, vpcId)
+func DeleteNetworkInterfacesByVpcId(ctx context.Context, ec2Session *ec2.EC2, vpcId string) {
+	NIs := listNetworkInterfacesByVpcId(ctx, ec2Session, vpcId)
 
 	for _, ni := range NIs {
 
 		if ni.AttachmentId != "" {
-			detachNetworkInterfaces(ec2Session, ni)
+			detachNetworkInterfaces(ctx, ec2Session, ni)
 		}
-		deleteNetworkInterface(ec2Session, ni)
+		deleteNetworkInterface(ctx, ec2Session, ni)
 	}
 }
 
-func listNetworkInterfacesByVpcId(ec2Session *ec2.EC2, vpcId string) []NetworkInterface {
-	result, err := ec2Session.DescribeNetworkInterfaces(&ec2.DescribeNetworkInterfacesInput{
+func listNetworkInterfacesByVpcId(ctx context.Context, ec2Session *ec2.EC2, vpcId string) []NetworkInterface {
+	var networkInterfaces []*ec2.NetworkInterface
+	err := ec2Session.DescribeNetworkInterfacesPagesWithContext(ctx, &ec2.DescribeNetworkInterfacesInput{
 		Filters: []*ec2.Filter{
 			{
 				Name:   aws.String("vpc-id"),
 				Values: []*string{aws.String(vpcId)},
 			},
 		},
+	}, func(page *ec2.DescribeNetworkInterfacesOutput, lastPage bool) bool {
+		networkInterfaces = append(networkInterfaces, page.NetworkInterfaces...)
+		return true
 	})
 	if err != nil {
 		logrus.Errorf("Can't list Network interface in region %s: %s.", *ec2Session.Config.Region, err.Error())
 	}
 
 	NIs := []NetworkInterface{}
-	for _, ni := range result.NetworkInterfaces {
+	for _, ni := range networkInterfaces {
 		NI := NetworkInterface{
 			Id:    *ni.NetworkInterfaceId,
 			VpcId: *ni.VpcId,
@@ -54,8 +59,8 @@ func listNetworkInterfacesByVpcId(ec2Session *ec2.EC2, vpcId string) []NetworkIn
 	return NIs
 }
 
-func detachNetworkInterfaces(ec2Session *ec2.EC2, ni NetworkInterface) {
-	_, err := ec2Session.DetachNetworkInterface(
+func detachNetworkInterfaces(ctx context.Context, ec2Session *ec2.EC2, ni NetworkInterface) {
+	_, err := ec2Session.DetachNetworkInterfaceWithContext(ctx,
 		&ec2.DetachNetworkInterfaceInput{
 			AttachmentId: &ni.AttachmentId,
 		})
@@ -64,8 +69,8 @@ func detachNetworkInterfaces(ec2Session *ec2.EC2, ni NetworkInterface) {
 	}
 }
 
-func deleteNetworkInterface(ec2Session *ec2.EC2, ni NetworkInterface) {
-	_, err := ec2Session.DeleteNetworkInterface(
+func deleteNetworkInterface(ctx context.Context, ec2Session *ec2.EC2, ni NetworkInterface) {
+	_, err := ec2Session.DeleteNetworkInterfaceWithContext(ctx,
 		&ec2.DeleteNetworkInterfaceInput{
 			NetworkInterfaceId: aws.String(ni.Id),
 		})
@@ -76,6 +81,6 @@ func deleteNetworkInterface(ec2Session *ec2.EC2, ni NetworkInterface) {
 	}
 }
 
-func GetNetworkInterfacesByVpcId(ec2Session *ec2.EC2, vpcId string) []NetworkInterface {
-	return listNetworkInterfacesByVpcId(ec2Session, vpcId)
+func GetNetworkInterfacesByVpcId(ctx context.Context, ec2Session *ec2.EC2, vpcId string) []NetworkInterface {
+	return listNetworkInterfacesByVpcId(ctx, ec2Session, vpcId)
 }
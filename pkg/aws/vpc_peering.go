@@ -1,12 +1,13 @@
 package aws
 
 import (
+	"context"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	log "github.com/sirupsen/logrus"
 )
 
-func DeleteVpcPeeringConnectionsByVpcId(ec2Session *ec2.EC2, vpcId string) {
+func DeleteVpcPeeringConnectionsByVpcId(ctx context.Context, ec2Session *ec2.EC2, vpcId string) {
 	// Find all VPC peering connections for this VPC (both accepter and requester)
 	input := &ec2.DescribeVpcPeeringConnectionsInput{
 		Filters: []*ec2.Filter{
@@ -17,7 +18,7 @@ func DeleteVpcPeeringConnectionsByVpcId(ec2Session *ec2.EC2, vpcId string) {
 		},
 	}
 
-	result, err := ec2Session.DescribeVpcPeeringConnections(input)
+	peerings, err := describeVpcPeeringConnections(ctx, ec2Session, input)
 	if err != nil {
 		log.Errorf("Failed to describe VPC peering connections for VPC %s: %s", vpcId, err.Error())
 		return
@@ -33,20 +34,20 @@ func DeleteVpcPeeringConnectionsByVpcId(ec2Session *ec2.EC2, vpcId string) {
 		},
 	}
 
-	resultAccepter, err := ec2Session.DescribeVpcPeeringConnections(inputAccepter)
+	accepterPeerings, err := describeVpcPeeringConnections(ctx, ec2Session, inputAccepter)
 	if err != nil {
 		log.Errorf("Failed to describe VPC peering connections (accepter) for VPC %s: %s", vpcId, err.Error())
 	} else {
-		result.VpcPeeringConnections = append(result.VpcPeeringConnections, resultAccepter.VpcPeeringConnections...)
+		peerings = append(peerings, accepterPeerings...)
 	}
 
-	if len(result.VpcPeeringConnections) == 0 {
+	if len(peerings) == 0 {
 		log.Debugf("No VPC peering connections found for VPC %s", vpcId)
 		return
 	}
 
 	// Delete each VPC peering connection
-	for _, peering := range result.VpcPeeringConnections {
+	for _, peering := range peerings {
 		if peering.VpcPeeringConnectionId == nil {
 			continue
 		}
@@ -60,7 +61,7 @@ func DeleteVpcPeeringConnectionsByVpcId(ec2Session *ec2.EC2, vpcId string) {
 			}
 		}
 
-		_, deleteErr := ec2Session.DeleteVpcPeeringConnection(&ec2.DeleteVpcPeeringConnectionInput{
+		_, deleteErr := ec2Session.DeleteVpcPeeringConnectionWithContext(ctx, &ec2.DeleteVpcPeeringConnectionInput{
 			VpcPeeringConnectionId: peering.VpcPeeringConnectionId,
 		})
 
@@ -71,3 +72,13 @@ func DeleteVpcPeeringConnectionsByVpcId(ec2Session *ec2.EC2, vpcId string) {
 		}
 	}
 }
+
+func describeVpcPeeringConnections(ctx context.Context, ec2Session *ec2.EC2, input *ec2.DescribeVpcPeeringConnectionsInput) ([]*ec2.VpcPeeringConnection, error) {
+	var peerings []*ec2.VpcPeeringConnection
+	err := ec2Session.DescribeVpcPeeringConnectionsPagesWithContext(ctx, input, func(page *ec2.DescribeVpcPeeringConnectionsOutput, lastPage bool) bool {
+		peerings = append(peerings, page.VpcPeeringConnections...)
+		return true
+	})
+
+	return peerings, err
+}
@@ -1,10 +1,11 @@
 package aws
 
 import (
+	"context"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	log "github.com/sirupsen/logrus"
-	"os"
 
 	"github.com/Qovery/pleco/pkg/common"
 )
@@ -21,16 +22,15 @@ type VpcInfo struct {
 	Status            string
 }
 
-func GetVpcsIdsByClusterNameTag(ec2Session ec2.EC2, clusterName string) []*string {
-	result, err := ec2Session.DescribeVpcs(
-		&ec2.DescribeVpcsInput{
-			Filters: []*ec2.Filter{
-				{
-					Name:   aws.String("tag:ClusterName"),
-					Values: []*string{aws.String(clusterName)},
-				},
+func GetVpcsIdsByClusterNameTag(ctx context.Context, ec2Session ec2.EC2, clusterName string) []*string {
+	vpcs, err := describeVPCs(ctx, &ec2Session, &ec2.DescribeVpcsInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("tag:ClusterName"),
+				Values: []*string{aws.String(clusterName)},
 			},
-		})
+		},
+	})
 
 	if err != nil {
 		log.Error(err)
@@ -38,46 +38,33 @@ func GetVpcsIdsByClusterNameTag(ec2Session ec2.EC2, clusterName string) []*strin
 	}
 
 	var vpcsIds []*string
-	for _, vpc := range result.Vpcs {
+	for _, vpc := range vpcs {
 		vpcsIds = append(vpcsIds, vpc.VpcId)
 	}
 
 	return vpcsIds
 }
 
-func getVPCs(ec2Session *ec2.EC2, tagName string) []*ec2.Vpc {
-	input := &ec2.DescribeVpcsInput{}
-
-	result, err := ec2Session.DescribeVpcs(input)
-	if err != nil {
-		log.Error(err)
-		return nil
-	}
-
-	if len(result.Vpcs) == 0 {
-		return nil
-	}
+func describeVPCs(ctx context.Context, ec2Session *ec2.EC2, input *ec2.DescribeVpcsInput) ([]*ec2.Vpc, error) {
+	var vpcs []*ec2.Vpc
+	err := ec2Session.DescribeVpcsPagesWithContext(ctx, input, func(page *ec2.DescribeVpcsOutput, lastPage bool) bool {
+		vpcs = append(vpcs, page.Vpcs...)
+		return true
+	})
 
-	return result.Vpcs
+	return vpcs, err
 }
 
-func GetAllVPCs(ec2Session *ec2.EC2) []*ec2.Vpc {
-	result, err := ec2Session.DescribeVpcs(&ec2.DescribeVpcsInput{})
-	if err != nil {
-		log.Error(err)
-		return nil
-	}
-
-	if len(result.Vpcs) == 0 {
-		return nil
-	}
-
-	return result.Vpcs
+func GetAllVPCs(ctx context.Context, ec2Session *ec2.EC2) ([]*ec2.Vpc, error) {
+	return describeVPCs(ctx, ec2Session, &ec2.DescribeVpcsInput{})
 }
 
-func listTaggedVPC(ec2Session *ec2.EC2, options *AwsOptions) ([]VpcInfo, error) {
+func listTaggedVPC(ctx context.Context, ec2Session *ec2.EC2, options *AwsOptions) ([]VpcInfo, error) {
 	var taggedVPCs []VpcInfo
-	var VPCs = getVPCs(ec2Session, options.TagName)
+	VPCs, err := GetAllVPCs(ctx, ec2Session)
+	if err != nil {
+		return nil, err
+	}
 
 	for _, vpc := range VPCs {
 		essentialTags := common.GetEssentialTags(vpc.Tags, options.TagName)
@@ -89,6 +76,10 @@ func listTaggedVPC(ec2Session *ec2.EC2, options *AwsOptions) ([]VpcInfo, error)
 				TTL:          essentialTags.TTL,
 				Tag:          essentialTags.Tag,
 				IsProtected:  essentialTags.IsProtected,
+				ExpiresAt:    essentialTags.ExpiresAt,
+				Warnings:     essentialTags.Warnings,
+				Tags:         essentialTags.Tags,
+				VpcId:        *vpc.VpcId,
 			},
 			Status: *vpc.State,
 		}
@@ -97,8 +88,6 @@ func listTaggedVPC(ec2Session *ec2.EC2, options *AwsOptions) ([]VpcInfo, error)
 			continue
 		}
 
-		taggedVpc = getCompleteVpc(ec2Session, options, taggedVpc)
-
 		if len(vpc.Tags) == 0 {
 			continue
 		}
@@ -115,100 +104,89 @@ func listTaggedVPC(ec2Session *ec2.EC2, options *AwsOptions) ([]VpcInfo, error)
 
 		}
 
-		if taggedVpc.IsResourceExpired(options.TagValue, options.DisableTTLCheck) {
-			taggedVPCs = append(taggedVPCs, taggedVpc)
-		}
-
-		// NOTE: this piece of code is meant to enable resources cleaning for some resources on cluster that should not be deleted.
-		// Doing this will make sure we never get quota issues on cluster we don't delete.
-		if options.TagName == "do_not_delete" && common.CheckIfExpired(taggedVpc.CreationDate, taggedVpc.TTL, taggedVpc.Description, options.DisableTTLCheck) {
-			taggedVPCs = append(taggedVPCs, taggedVpc)
-		}
+		taggedVPCs = append(taggedVPCs, taggedVpc)
 	}
 
 	return taggedVPCs, nil
 }
 
-func deleteVPC(sessions AWSSessions, options AwsOptions, VpcList []VpcInfo) error {
-	if options.DryRun {
-		return nil
+func isVPCExpired(vpc common.CloudProviderResource, options AwsOptions) bool {
+	if vpc.IsResourceExpired(options.TagValue, options.DisableTTLCheck) {
+		return true
 	}
 
-	if len(VpcList) == 0 {
-		return nil
-	}
+	// NOTE: this piece of code is meant to enable resources cleaning for some resources on cluster that should not be deleted.
+	// Doing this will make sure we never get quota issues on cluster we don't delete.
+	return options.TagName == "do_not_delete" && common.CheckIfExpired(vpc.CreationDate, vpc.TTL, vpc.Description, options.DisableTTLCheck)
+}
 
+func deleteVPC(ctx context.Context, sessions AWSSessions, options AwsOptions, vpc VpcInfo) error {
 	ec2Session := sessions.EC2
-	region := ec2Session.Config.Region
 
-	for _, vpc := range VpcList {
-		if options.DisableTTLCheck {
-			vpcId, isOk := os.LookupEnv("PROTECTED_VPC_ID")
-			if !isOk || vpcId == "" {
-				log.Fatalf("Unable to get PROTECTED_VPC_ID environment variable in order to protect VPC resources.")
-			}
-			if vpcId == vpc.Identifier {
-				log.Debugf("Skipping VPC %s in region %s (protected vpc)", vpc.Identifier, *region)
-				continue
-			}
-		}
+	DeleteLoadBalancerByVpcId(ctx, sessions.ELB, vpc, options.DryRun)
+	DeleteVpcEndpointsByVpcId(ctx, ec2Session, vpc.Identifier)
+	DeleteVpcPeeringConnectionsByVpcId(ctx, ec2Session, vpc.Identifier)
+	DeleteNatGatewaysByIds(ctx, ec2Session, vpc.NatGateways)
+	DeleteNetworkInterfacesByVpcId(ctx, ec2Session, vpc.Identifier)
+	ReleaseElasticIps(ctx, ec2Session, vpc.ElasticIps)
+	DeleteInternetGatewaysByIds(ctx, ec2Session, vpc.InternetGateways, vpc.Identifier)
+	DeleteRouteTablesByIds(ctx, ec2Session, vpc.RouteTables)
+	DeleteSecurityGroupsByIds(ctx, ec2Session, vpc.SecurityGroups)
+	DeleteSubnetsByIds(ctx, ec2Session, vpc.Subnets)
+
+	_, err := ec2Session.DeleteVpcWithContext(ctx,
+		&ec2.DeleteVpcInput{
+			VpcId: aws.String(vpc.Identifier),
+		},
+	)
+
+	return err
+}
 
-		DeleteLoadBalancerByVpcId(sessions.ELB, vpc, options.DryRun)
-		DeleteVpcEndpointsByVpcId(ec2Session, vpc.Identifier)
-		DeleteVpcPeeringConnectionsByVpcId(ec2Session, vpc.Identifier)
-		DeleteNatGatewaysByIds(ec2Session, vpc.NatGateways)
-		DeleteNetworkInterfacesByVpcId(ec2Session, vpc.Identifier)
-		ReleaseElasticIps(ec2Session, vpc.ElasticIps)
-		DeleteInternetGatewaysByIds(ec2Session, vpc.InternetGateways, vpc.Identifier)
-		DeleteRouteTablesByIds(ec2Session, vpc.RouteTables)
-		DeleteSecurityGroupsByIds(ec2Session, vpc.SecurityGroups)
-		DeleteSubnetsByIds(ec2Session, vpc.Subnets)
-
-		_, deleteErr := ec2Session.DeleteVpc(
-			&ec2.DeleteVpcInput{
-				VpcId: aws.String(vpc.Identifier),
-			},
-		)
-		if deleteErr != nil {
-			log.Errorf("Can't delete VPC %s in %s yet: %s", vpc.Identifier, *region, deleteErr.Error())
-		} else {
-			log.Debugf("VPC %s in %s deleted.", vpc.Identifier, *ec2Session.Config.Region)
-		}
-	}
+type vpcCleaner struct {
+	awsCleaner
+}
 
-	return nil
+func init() {
+	registerRegionalCleaner("vpc", "vpc", "tagged VPC resource", func(base awsCleaner) common.Cleaner {
+		return vpcCleaner{base}
+	})
 }
 
-func DeleteExpiredVPC(sessions AWSSessions, options AwsOptions) {
-	VPCs, err := listTaggedVPC(sessions.EC2, &options)
-	region := sessions.EC2.Config.Region
+func (c vpcCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	vpcs, err := listTaggedVPC(ctx, c.sessions.EC2, &c.options)
 	if err != nil {
-		log.Errorf("Can't list VPC: %s\n", err)
+		return nil, err
 	}
 
-	count, start := common.ElemToDeleteFormattedInfos("tagged VPC resource", len(VPCs), *region)
-
-	log.Info(count)
-
-	if options.DryRun || len(VPCs) == 0 {
-		return
+	var resources []common.CloudProviderResource
+	for _, vpc := range vpcs {
+		resource := vpc.CloudProviderResource
+		resource.Payload = vpc
+		resources = append(resources, resource)
 	}
 
-	log.Info(start)
+	return resources, nil
+}
 
-	_ = deleteVPC(sessions, options, VPCs)
+func (c vpcCleaner) Evaluate(resource common.CloudProviderResource) bool {
+	return isVPCExpired(resource, c.options)
+}
 
+func (c vpcCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	vpc := getCompleteVpc(ctx, c.sessions.EC2, &c.options, resource.Payload.(VpcInfo))
+	return deleteVPC(ctx, c.sessions, c.options, vpc)
 }
 
-func getCompleteVpc(ec2Session *ec2.EC2, options *AwsOptions, vpc VpcInfo) VpcInfo {
+func getCompleteVpc(ctx context.Context, ec2Session *ec2.EC2, options *AwsOptions, vpc VpcInfo) VpcInfo {
 	fullVpc := vpc
-	fullVpc.SecurityGroups = GetSecurityGroupsIdsByVpcId(ec2Session, fullVpc.Identifier, options.TagName)
-	fullVpc.NetworkInterfaces = GetNetworkInterfacesByVpcId(ec2Session, fullVpc.Identifier)
-	fullVpc.ElasticIps = GetElasticIpsByVpcId(ec2Session, fullVpc, options.TagName)
-	fullVpc.NatGateways = GetNatGatewaysIdsByVpcId(ec2Session, options, fullVpc.Identifier)
-	fullVpc.InternetGateways = GetInternetGatewaysIdsByVpcId(ec2Session, fullVpc.Identifier, options.TagName)
-	fullVpc.Subnets = GetSubnetsIdsByVpcId(ec2Session, fullVpc.Identifier, options.TagName)
-	fullVpc.RouteTables = GetRouteTablesIdsByVpcId(ec2Session, fullVpc.Identifier, options.TagName)
+	fullVpc.SecurityGroups = GetSecurityGroupsIdsByVpcId(ctx, ec2Session, fullVpc.Identifier, options.TagName)
+	fullVpc.NetworkInterfaces = GetNetworkInterfacesByVpcId(ctx, ec2Session, fullVpc.Identifier)
+	fullVpc.ElasticIps = GetElasticIpsByVpcId(ctx, ec2Session, fullVpc, options.TagName)
+	fullVpc.NatGateways = GetNatGatewaysIdsByVpcId(ctx, ec2Session, options, fullVpc.Identifier)
+	fullVpc.InternetGateways = GetInternetGatewaysIdsByVpcId(ctx, ec2Session, fullVpc.Identifier, options.TagName)
+	fullVpc.Subnets = GetSubnetsIdsByVpcId(ctx, ec2Session, fullVpc.Identifier, options.TagName)
+	fullVpc.RouteTables = GetRouteTablesIdsByVpcId(ctx, ec2Session, fullVpc.Identifier, options.TagName)
 
 	return fullVpc
 }
@@ -1,6 +1,7 @@
 package aws
 
 import (
+	"context"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -18,7 +19,7 @@ type RouteTable struct {
 	IsProtected  bool
 }
 
-func getRouteTablesByVpcId(ec2Session *ec2.EC2, vpcId string) []*ec2.RouteTable {
+func getRouteTablesByVpcId(ctx context.Context, ec2Session *ec2.EC2, vpcId string) []*ec2.RouteTable {
 	input := &ec2.DescribeRouteTablesInput{
 		Filters: []*ec2.Filter{
 			{
@@ -28,18 +29,22 @@ func getRouteTablesByVpcId(ec2Session *ec2.EC2, vpcId string) []*ec2.RouteTable
 		},
 	}
 
-	routeTables, err := ec2Session.DescribeRouteTables(input)
+	var routeTables []*ec2.RouteTable
+	err := ec2Session.DescribeRouteTablesPagesWithContext(ctx, input, func(page *ec2.DescribeRouteTablesOutput, lastPage bool) bool {
+		routeTables = append(routeTables, page.RouteTables...)
+		return true
+	})
 	if err != nil {
 		log.Error(err)
 	}
 
-	return routeTables.RouteTables
+	return routeTables
 }
 
-func GetRouteTablesIdsByVpcId(ec2Session *ec2.EC2, vpcId string, tagName string) []RouteTable {
+func GetRouteTablesIdsByVpcId(ctx context.Context, ec2Session *ec2.EC2, vpcId string, tagName string) []RouteTable {
 	var routeTablesStruct []RouteTable
 
-	routeTables := getRouteTablesByVpcId(ec2Session, vpcId)
+	routeTables := getRouteTablesByVpcId(ctx, ec2Session, vpcId)
 
 	for _, routeTable := range routeTables {
 		essentialTags := common.GetEssentialTags(routeTable.Tags, tagName)
@@ -57,7 +62,7 @@ func GetRouteTablesIdsByVpcId(ec2Session *ec2.EC2, vpcId string, tagName string)
 	return routeTablesStruct
 }
 
-func disassociateRouteTable(ec2Session *ec2.EC2, routeTable RouteTable) {
+func disassociateRouteTable(ctx context.Context, ec2Session *ec2.EC2, routeTable RouteTable) {
 	// Disassociate all non-main associations
 	for _, association := range routeTable.Associations {
 		if association.RouteTableAssociationId == nil {
@@ -69,7 +74,7 @@ func disassociateRouteTable(ec2Session *ec2.EC2, routeTable RouteTable) {
 			continue
 		}
 
-		_, err := ec2Session.DisassociateRouteTable(&ec2.DisassociateRouteTableInput{
+		_, err := ec2Session.DisassociateRouteTableWithContext(ctx, &ec2.DisassociateRouteTableInput{
 			AssociationId: association.RouteTableAssociationId,
 		})
 
@@ -81,9 +86,9 @@ func disassociateRouteTable(ec2Session *ec2.EC2, routeTable RouteTable) {
 	}
 }
 
-func deleteRoutesFromRouteTable(ec2Session *ec2.EC2, routeTableId string) {
+func deleteRoutesFromRouteTable(ctx context.Context, ec2Session *ec2.EC2, routeTableId string) {
 	// Describe the route table to get all routes
-	result, err := ec2Session.DescribeRouteTables(&ec2.DescribeRouteTablesInput{
+	result, err := ec2Session.DescribeRouteTablesWithContext(ctx, &ec2.DescribeRouteTablesInput{
 		RouteTableIds: []*string{aws.String(routeTableId)},
 	})
 
@@ -123,7 +128,7 @@ func deleteRoutesFromRouteTable(ec2Session *ec2.EC2, routeTableId string) {
 			deleteInput.DestinationPrefixListId = route.DestinationPrefixListId
 		}
 
-		_, deleteErr := ec2Session.DeleteRoute(deleteInput)
+		_, deleteErr := ec2Session.DeleteRouteWithContext(ctx, deleteInput)
 		if deleteErr != nil {
 			log.Errorf("Failed to delete route from route table %s: %s", routeTableId, deleteErr.Error())
 		} else {
@@ -140,16 +145,16 @@ func deleteRoutesFromRouteTable(ec2Session *ec2.EC2, routeTableId string) {
 	}
 }
 
-func DeleteRouteTablesByIds(ec2Session *ec2.EC2, routeTables []RouteTable) {
+func DeleteRouteTablesByIds(ctx context.Context, ec2Session *ec2.EC2, routeTables []RouteTable) {
 	for _, routeTable := range routeTables {
 		if !isMainRouteTable(routeTable) && !routeTable.IsProtected {
 			// Disassociate route table from subnets first
-			disassociateRouteTable(ec2Session, routeTable)
+			disassociateRouteTable(ctx, ec2Session, routeTable)
 
 			// Delete all non-local routes to avoid dependency violations
-			deleteRoutesFromRouteTable(ec2Session, routeTable.Id)
+			deleteRoutesFromRouteTable(ctx, ec2Session, routeTable.Id)
 
-			_, err := ec2Session.DeleteRouteTable(
+			_, err := ec2Session.DeleteRouteTableWithContext(ctx,
 				&ec2.DeleteRouteTableInput{
 					RouteTableId: aws.String(routeTable.Id),
 				},
@@ -1,6 +1,7 @@
 package aws
 
 import (
+	"context"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -19,7 +20,7 @@ type SecurityGroup struct {
 	IpPermissionEgress  []*ec2.IpPermission
 }
 
-func getSecurityGroupsByVpcId(ec2Session *ec2.EC2, vpcId string) []*ec2.SecurityGroup {
+func getSecurityGroupsByVpcId(ctx context.Context, ec2Session *ec2.EC2, vpcId string) []*ec2.SecurityGroup {
 	input := &ec2.DescribeSecurityGroupsInput{
 		Filters: []*ec2.Filter{
 			{
@@ -29,18 +30,22 @@ func getSecurityGroupsByVpcId(ec2Session *ec2.EC2, vpcId string) []*ec2.Security
 		},
 	}
 
-	result, err := ec2Session.DescribeSecurityGroups(input)
+	var securityGroups []*ec2.SecurityGroup
+	err := ec2Session.DescribeSecurityGroupsPagesWithContext(ctx, input, func(page *ec2.DescribeSecurityGroupsOutput, lastPage bool) bool {
+		securityGroups = append(securityGroups, page.SecurityGroups...)
+		return true
+	})
 	if err != nil {
 		log.Error(err)
 	}
 
-	return result.SecurityGroups
+	return securityGroups
 }
 
-func GetSecurityGroupsIdsByVpcId(ec2Session *ec2.EC2, vpcId string, tagName string) []SecurityGroup {
+func GetSecurityGroupsIdsByVpcId(ctx context.Context, ec2Session *ec2.EC2, vpcId string, tagName string) []SecurityGroup {
 	var securityGroupsStruct []SecurityGroup
 
-	securityGroups := getSecurityGroupsByVpcId(ec2Session, vpcId)
+	securityGroups := getSecurityGroupsByVpcId(ctx, ec2Session, vpcId)
 
 	for _, securityGroup := range securityGroups {
 		if *securityGroup.GroupName != "default" {
@@ -61,12 +66,12 @@ func GetSecurityGroupsIdsByVpcId(ec2Session *ec2.EC2, vpcId string, tagName stri
 	return securityGroupsStruct
 }
 
-func DeleteSecurityGroupsByIds(ec2Session *ec2.EC2, securityGroups []SecurityGroup) {
+func DeleteSecurityGroupsByIds(ctx context.Context, ec2Session *ec2.EC2, securityGroups []SecurityGroup) {
 	for _, securityGroup := range securityGroups {
 		if !securityGroup.IsProtected {
-			deleteIpPermissions(ec2Session, securityGroup)
+			deleteIpPermissions(ctx, ec2Session, securityGroup)
 
-			_, err := ec2Session.DeleteSecurityGroup(
+			_, err := ec2Session.DeleteSecurityGroupWithContext(ctx,
 				&ec2.DeleteSecurityGroupInput{
 					GroupId: aws.String(securityGroup.Id),
 				},
@@ -81,9 +86,9 @@ func DeleteSecurityGroupsByIds(ec2Session *ec2.EC2, securityGroups []SecurityGro
 	}
 }
 
-func deleteIpPermissions(ec2Session *ec2.EC2, securityGroup SecurityGroup) {
+func deleteIpPermissions(ctx context.Context, ec2Session *ec2.EC2, securityGroup SecurityGroup) {
 	if securityGroup.IpPermissionIngress != nil {
-		_, ingressErr := ec2Session.RevokeSecurityGroupIngress(
+		_, ingressErr := ec2Session.RevokeSecurityGroupIngressWithContext(ctx,
 			&ec2.RevokeSecurityGroupIngressInput{
 				GroupId:       aws.String(securityGroup.Id),
 				IpPermissions: securityGroup.IpPermissionIngress,
@@ -94,7 +99,7 @@ func deleteIpPermissions(ec2Session *ec2.EC2, securityGroup SecurityGroup) {
 	}
 
 	if securityGroup.IpPermissionEgress != nil {
-		_, egressErr := ec2Session.RevokeSecurityGroupEgress(
+		_, egressErr := ec2Session.RevokeSecurityGroupEgressWithContext(ctx,
 			&ec2.RevokeSecurityGroupEgressInput{
 				GroupId:       aws.String(securityGroup.Id),
 				IpPermissions: securityGroup.IpPermissionEgress,
@@ -1,6 +1,7 @@
 package aws
 
 import (
+	"context"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -17,7 +18,7 @@ type Subnet struct {
 	IsProtected  bool
 }
 
-func getSubnetsByVpcId(ec2Session *ec2.EC2, vpcId string) []*ec2.Subnet {
+func getSubnetsByVpcId(ctx context.Context, ec2Session *ec2.EC2, vpcId string) []*ec2.Subnet {
 	input := &ec2.DescribeSubnetsInput{
 		Filters: []*ec2.Filter{
 			{
@@ -27,18 +28,22 @@ func getSubnetsByVpcId(ec2Session *ec2.EC2, vpcId string) []*ec2.Subnet {
 		},
 	}
 
-	subnets, err := ec2Session.DescribeSubnets(input)
+	var subnets []*ec2.Subnet
+	err := ec2Session.DescribeSubnetsPagesWithContext(ctx, input, func(page *ec2.DescribeSubnetsOutput, lastPage bool) bool {
+		subnets = append(subnets, page.Subnets...)
+		return true
+	})
 	if err != nil {
 		log.Error(err)
 	}
 
-	return subnets.Subnets
+	return subnets
 }
 
-func GetSubnetsIdsByVpcId(ec2Session *ec2.EC2, vpcId string, tagName string) []Subnet {
+func GetSubnetsIdsByVpcId(ctx context.Context, ec2Session *ec2.EC2, vpcId string, tagName string) []Subnet {
 	var subnetsStruct []Subnet
 
-	subnets := getSubnetsByVpcId(ec2Session, vpcId)
+	subnets := getSubnetsByVpcId(ctx, ec2Session, vpcId)
 
 	for _, subnet := range subnets {
 		essentialTags := common.GetEssentialTags(subnet.Tags, tagName)
@@ -55,10 +60,10 @@ func GetSubnetsIdsByVpcId(ec2Session *ec2.EC2, vpcId string, tagName string) []S
 	return subnetsStruct
 }
 
-func DeleteSubnetsByIds(ec2Session *ec2.EC2, subnets []Subnet) {
+func DeleteSubnetsByIds(ctx context.Context, ec2Session *ec2.EC2, subnets []Subnet) {
 	for _, subnet := range subnets {
 		if !subnet.IsProtected {
-			_, err := ec2Session.DeleteSubnet(
+			_, err := ec2Session.DeleteSubnetWithContext(ctx,
 				&ec2.DeleteSubnetInput{
 					SubnetId: aws.String(subnet.Id),
 				},
@@ -73,49 +78,45 @@ func DeleteSubnetsByIds(ec2Session *ec2.EC2, subnets []Subnet) {
 	}
 }
 
-// DeleteVPCLinkedResourcesWithQuota is used to delete some resources linked to a vpc without deleting the vpc itself.
+// vpcLinkedResourcesCleaner is used to delete some resources linked to a vpc without deleting the vpc itself.
 // This will avoid quota issues on some resources
-func DeleteVPCLinkedResourcesWithQuota(sessions AWSSessions, options AwsOptions) {
-	vpcs, err := listTaggedVPC(sessions.EC2, &options)
-	if err != nil {
-		log.Errorf("Can't list VPC: %s\n", err)
-		return
-	}
+type vpcLinkedResourcesCleaner struct {
+	awsCleaner
+}
+
+func init() {
+	registerRegionalCleaner("vpc-quota", "vpc-linked-resources", "expired VPC linked resource", func(base awsCleaner) common.Cleaner {
+		return vpcLinkedResourcesCleaner{base}
+	})
+}
 
-	region := *sessions.EC2.Config.Region
+func (c vpcLinkedResourcesCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	vpcs, err := listTaggedVPC(ctx, c.sessions.EC2, &c.options)
 	if err != nil {
-		log.Errorf("Can't list instances: %s\n", err)
-		return
+		return nil, err
 	}
 
-	securityGroupCount := 0
-	subnetCount := 0
-	routeTableCount := 0
+	var resources []common.CloudProviderResource
 	for _, vpc := range vpcs {
-		securityGroupCount += len(vpc.SecurityGroups)
-		subnetCount += len(vpc.Subnets)
-		routeTableCount += len(vpc.RouteTables)
+		resource := vpc.CloudProviderResource
+		resource.Description = "Security groups, subnets and route tables of " + vpc.Description
+		resource.Payload = vpc
+		resources = append(resources, resource)
 	}
 
-	sgCount, sgStart := common.ElemToDeleteFormattedInfos("expired VPC Security Group", securityGroupCount, region)
-	sCount, sStart := common.ElemToDeleteFormattedInfos("expired VPC Subnet", subnetCount, region)
-	rtCount, rtStart := common.ElemToDeleteFormattedInfos("expired VPC Route Table", routeTableCount, region)
+	return resources, nil
+}
 
-	log.Info(sgCount)
-	log.Info(sCount)
-	log.Info(rtCount)
+func (c vpcLinkedResourcesCleaner) Evaluate(resource common.CloudProviderResource) bool {
+	return isVPCExpired(resource, c.options)
+}
 
-	if options.DryRun || len(vpcs) == 0 {
-		return
-	}
+func (c vpcLinkedResourcesCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	vpc := getCompleteVpc(ctx, c.sessions.EC2, &c.options, resource.Payload.(VpcInfo))
 
-	log.Info(sgStart)
-	log.Info(sStart)
-	log.Info(rtStart)
+	DeleteSecurityGroupsByIds(ctx, c.sessions.EC2, vpc.SecurityGroups)
+	DeleteSubnetsByIds(ctx, c.sessions.EC2, vpc.Subnets)
+	DeleteRouteTablesByIds(ctx, c.sessions.EC2, vpc.RouteTables)
 
-	for _, vpc := range vpcs {
-		DeleteSecurityGroupsByIds(sessions.EC2, vpc.SecurityGroups)
-		DeleteSubnetsByIds(sessions.EC2, vpc.Subnets)
-		DeleteRouteTablesByIds(sessions.EC2, vpc.RouteTables)
-	}
+	return nil
 }
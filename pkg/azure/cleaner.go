@@ -0,0 +1,98 @@
+package azure
+
+import (
+	"strings"
+	"time"
+
+	"github.com/Qovery/pleco/pkg/common"
+)
+
+const providerName = "azure"
+
+func init() {
+	common.RegisterProvider(common.ProviderDefinition{
+		Name:                  providerName,
+		LocationsFlag:         "az-regions",
+		LocationsShorthand:    "a",
+		LocationsUsage:        "Set Azure regions (default is all, the physical locations of the subscription)",
+		ExcludedLocationsFlag: "az-exclude-regions",
+		RequiredEnvVars:       []string{"AZURE_CLIENT_ID", "AZURE_CLIENT_SECRET", "AZURE_TENANT_ID", "AZURE_SUBSCRIPTION_ID"},
+		Features: []common.Feature{
+			{Name: "rg", Shorthand: "e", Usage: "Enable Resource Groups watch"},
+			{Name: "storage-account", Shorthand: "s", Usage: "Enable Storage Account watch"},
+			{Name: "acr", Shorthand: "c", Usage: "Enable Container Registry watch"},
+		},
+	})
+}
+
+// azureCleaner is embedded by every Azure cleaner, it is also the provider scope given to the cleaners constructors.
+type azureCleaner struct {
+	common.TTLEvaluator
+	sessions AzureSessions
+	options  AzureOptions
+}
+
+func newAzureCleaner(sessions AzureSessions, options AzureOptions) azureCleaner {
+	return azureCleaner{
+		TTLEvaluator: common.TTLEvaluator{TagValue: options.TagValue, DisableTTLCheck: options.DisableTTLCheck},
+		sessions:     sessions,
+		options:      options,
+	}
+}
+
+func registerCleaner(feature string, kind string, description string, newCleaner func(base azureCleaner) common.Cleaner) {
+	common.RegisterCleaner(common.CleanerDefinition{
+		Provider:    providerName,
+		Feature:     feature,
+		Kind:        kind,
+		Description: description,
+		Scope:       common.RegionScope,
+		New: func(providerScope interface{}) (common.Cleaner, error) {
+			return newCleaner(providerScope.(azureCleaner)), nil
+		},
+	})
+}
+
+// inLocation returns whether a resource belongs to the location of the engine. Azure resources are listed for the whole
+// subscription, so that each engine keeps the resources of its own location.
+func (c azureCleaner) inLocation(location *string) bool {
+	if c.options.Location == "" {
+		return true
+	}
+
+	return location != nil && strings.EqualFold(strings.ReplaceAll(*location, " ", ""), c.options.Location)
+}
+
+// getResourceGroupName extracts the resource group from a resource ID:
+// /subscriptions/{subId}/resourceGroups/{resourceGroupName}/providers/...
+func getResourceGroupName(id string) string {
+	idParts := strings.Split(id, "/")
+	for i, part := range idParts {
+		if strings.EqualFold(part, "resourceGroups") && i+1 < len(idParts) {
+			return idParts[i+1]
+		}
+	}
+
+	return ""
+}
+
+// newResource fills the ttl, tag and protection of a resource from its tags. The creation_date tag, when set,
+// takes precedence over the creation date given by Azure.
+func (c azureCleaner) newResource(identifier string, description string, creationDate time.Time, tags map[string]*string) common.CloudProviderResource {
+	essentialTags := common.GetEssentialTags(tags, c.options.TagName)
+	if !essentialTags.CreationDate.IsZero() {
+		creationDate = essentialTags.CreationDate
+	}
+
+	return common.CloudProviderResource{
+		Identifier:   identifier,
+		Description:  description,
+		CreationDate: creationDate.UTC(),
+		TTL:          essentialTags.TTL,
+		Tag:          essentialTags.Tag,
+		IsProtected:  essentialTags.IsProtected,
+		ExpiresAt:    essentialTags.ExpiresAt,
+		Warnings:     essentialTags.Warnings,
+		Tags:         essentialTags.Tags,
+	}
+}
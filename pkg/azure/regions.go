@@ -0,0 +1,48 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armsubscriptions"
+)
+
+// DiscoverLocations returns the physical locations available to the subscription, the logical ones (eg. europe) can't
+// hold resources.
+func DiscoverLocations(ctx context.Context) ([]string, error) {
+	subscriptionID := os.Getenv("AZURE_SUBSCRIPTION_ID")
+	if subscriptionID == "" {
+		return nil, fmt.Errorf("AZURE_SUBSCRIPTION_ID environment variable is not set")
+	}
+
+	cred, err := azidentity.NewDefaultAzureCredential(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create credential: %v", err)
+	}
+
+	client, err := armsubscriptions.NewClient(cred, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create subscriptions client: %v", err)
+	}
+
+	var locations []string
+	pager := client.NewListLocationsPager(subscriptionID, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, location := range page.Value {
+			if location.Name == nil || location.Metadata == nil || location.Metadata.RegionType == nil ||
+				*location.Metadata.RegionType != armsubscriptions.RegionTypePhysical {
+				continue
+			}
+			locations = append(locations, *location.Name)
+		}
+	}
+
+	return locations, nil
+}
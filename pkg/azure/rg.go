@@ -3,85 +3,58 @@ package azure
 import (
 	"context"
 	"fmt"
-	"strconv"
-	"strings"
 	"time"
 
 	log "github.com/sirupsen/logrus"
+
+	"github.com/Qovery/pleco/pkg/common"
 )
 
-// DeleteExpiredRGs identifies and deletes Azure Resource Groups that have expired based on their TTL tags
-func DeleteExpiredRGs(sessions AzureSessions, options AzureOptions) {
-	// Create a context with timeout to prevent hanging operations
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
-	defer cancel()
+// rgCleaner identifies and deletes Azure Resource Groups that have expired based on their TTL tags
+type rgCleaner struct {
+	azureCleaner
+}
+
+func init() {
+	registerCleaner("rg", "resource-group", "expired resource group", func(base azureCleaner) common.Cleaner {
+		return rgCleaner{base}
+	})
+}
 
+func (c rgCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
 	// Ensure we have a valid Resource Groups client
-	if sessions.RG == nil {
-		log.Error("Resource Groups client is not initialized")
-		return
+	if c.sessions.RG == nil {
+		return nil, fmt.Errorf("Resource Groups client is not initialized")
 	}
 
+	var resources []common.CloudProviderResource
+
 	// List all resource groups using pagination
-	pager := sessions.RG.NewListPager(nil)
+	pager := c.sessions.RG.NewListPager(nil)
 	for pager.More() {
 		// Fetch the next page of resource groups
 		page, err := pager.NextPage(ctx)
 		if err != nil {
-			log.Errorf("Error listing resource group, error: %v", err)
-			return
+			return nil, err
 		}
 
 		// Process each resource group on the current page
 		for _, group := range page.Value {
-			// Check if resource group has a TTL tag
-			ttlStr, ok := group.Tags["ttl"]
-			if !ok || strings.TrimSpace(*ttlStr) == "" {
-				log.Info(fmt.Sprintf("No ttl label value found, ignoring this resource group (`%s`)", *group.Name))
-				continue
-			}
-
-			// Parse the TTL value to int64
-			ttl, err := strconv.ParseInt(*ttlStr, 10, 64)
-			if err != nil {
-				log.Warn(fmt.Sprintf("ttl label value `%s` is not parsable to int64, ignoring this resource group (`%s`)", *ttlStr, *group.Name))
-				continue
-			}
-
-			// Check if resource group has a creation_date tag
-			creationTimeStr, ok := group.Tags["creation_date"]
-			if !ok || strings.TrimSpace(*creationTimeStr) == "" {
-				log.Info(fmt.Sprintf("No creation_date label value found, ignoring this resource group (`%s`)", *group.Name))
+			if !c.inLocation(group.Location) {
 				continue
 			}
 
-			// Parse the creation_date value to int64 (Unix timestamp)
-			creationTimeInt64, err := strconv.ParseInt(*creationTimeStr, 10, 64)
-			if err != nil {
-				log.Warn(fmt.Sprintf("creation_date label value `%s` is not parsable to int64, ignoring this resource group (`%s`)", *ttlStr, *group.Name))
-				continue
-			}
-
-			// Convert Unix timestamp to UTC time
-			creationTime := time.Unix(creationTimeInt64, 0).UTC()
-			
-			// Skip if the resource group is not expired or has TTL=0 (protected)
-			// TTL=0 means the resource group should never be automatically deleted
-			if !ok || ttl == 0 || creationTimeInt64 == 0 || time.Now().UTC().Before(creationTime.Add(time.Second*time.Duration(ttl))) {
-				continue
-			}
-
-			// In dry run mode, just log what would be deleted without actually deleting
-			if options.DryRun {
-				log.Info(fmt.Sprintf("Resource Group `%s` will be deleted", *group.Name))
-				continue
-			}
-			
-			// Delete the expired resource group
-			log.Info(fmt.Sprintf("Deleting resource group `%s` created at `%s` UTC (TTL `{%d}` seconds)", *group.Name, creationTime.UTC(), ttl))
-			if _, err := sessions.RG.BeginDelete(ctx, *group.Name, nil); err != nil {
-				log.Error(fmt.Sprintf("Error deleting resource group `%s`, error: %s", *group.Name, err))
-			}
+			// resource groups have no creation date, it has to be set with the creation_date tag
+			resources = append(resources, c.newResource(*group.Name, "Resource Group: "+*group.Name, time.Time{}, group.Tags))
 		}
 	}
+
+	return resources, nil
+}
+
+func (c rgCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	// Delete the expired resource group
+	log.Info(fmt.Sprintf("Deleting resource group `%s` created at `%s` UTC (TTL `{%d}` seconds)", resource.Identifier, resource.CreationDate, resource.TTL))
+	_, err := c.sessions.RG.BeginDelete(ctx, resource.Identifier, nil)
+	return err
 }
@@ -1,44 +1,43 @@
 package azure
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"sync"
-	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	armcontainerregistry "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerregistry/armcontainerregistry"
 	armresources "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	armstorage "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage"
 	"github.com/sirupsen/logrus"
+
+	"github.com/Qovery/pleco/pkg/common"
 )
 
 type AzureOptions struct {
-	TagValue             string
-	TagName              string
-	DisableTTLCheck      bool
-	IsDestroyingCommand  bool
-	DryRun               bool
+	TagValue            string
+	TagName             string
+	DisableTTLCheck     bool
+	IsDestroyingCommand bool
+	DryRun              bool
 	Location            string
 	SubscriptionID      string
 	ResourceGroupName   string
-	EnableRG        	 bool
-	EnableStorageAccount bool
-	EnableACR           bool
+	Features            common.FeatureSet
 }
 
 type AzureSessions struct {
 	RG             *armresources.ResourceGroupsClient
 	StorageAccount *armstorage.AccountsClient
 	ACR            *armcontainerregistry.RegistriesClient
+	Tags           *armresources.TagsClient
 }
 
-type funcDeleteExpired func(sessions AzureSessions, options AzureOptions)
-
 // Initialize creates and returns an AzureSessions object with authenticated clients
 func Initialize() (AzureSessions, error) {
 	var sessions AzureSessions
-	
+
 	// Get subscription ID from environment variable
 	subscriptionID := os.Getenv("AZURE_SUBSCRIPTION_ID")
 	if subscriptionID == "" {
@@ -72,20 +71,28 @@ func Initialize() (AzureSessions, error) {
 	}
 	sessions.ACR = acrClient
 
+	// Initialize Tags client
+	tagsClient, err := armresources.NewTagsClient(subscriptionID, cred, nil)
+	if err != nil {
+		return sessions, fmt.Errorf("failed to create tags client: %v", err)
+	}
+	sessions.Tags = tagsClient
+
 	return sessions, nil
 }
 
-func RunPlecoAzure(locations []string, interval int64, wg *sync.WaitGroup, options AzureOptions) {
+func RunPlecoAzure(ctx context.Context, locations []string, interval int64, wg *sync.WaitGroup, options AzureOptions) {
 	for _, location := range locations {
 		wg.Add(1)
-		go runPlecoInRegion(location, interval, wg, options)
+		go runPlecoInRegion(ctx, location, interval, wg, options)
 	}
 }
 
-func runPlecoInRegion(location string, interval int64, wg *sync.WaitGroup, options AzureOptions) {
+func runPlecoInRegion(ctx context.Context, location string, interval int64, wg *sync.WaitGroup, options AzureOptions) {
 	defer wg.Done()
 	options.Location = location
-	
+	options.SubscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
+
 	// Initialize Azure sessions with authentication
 	sessions, err := Initialize()
 	if err != nil {
@@ -95,30 +102,13 @@ func runPlecoInRegion(location string, interval int64, wg *sync.WaitGroup, optio
 
 	logrus.Infof("Starting to check expired resources in location %s.", options.Location)
 
-	var listServiceToCheckStatus []funcDeleteExpired
+	engine := common.NewEngine(common.EngineOptions{
+		Provider: providerName,
+		Scope:    common.RegionScope,
+		Location: options.Location,
+		DryRun:   options.DryRun,
+		Features: options.Features,
+	}, newAzureCleaner(sessions, options))
 
-	if options.EnableRG {
-		listServiceToCheckStatus = append(listServiceToCheckStatus, DeleteExpiredRGs)
-	}
-	
-	if options.EnableStorageAccount {
-		listServiceToCheckStatus = append(listServiceToCheckStatus, DeleteExpiredStorageAccounts)
-	}
-
-	if options.EnableACR {
-		listServiceToCheckStatus = append(listServiceToCheckStatus, DeleteExpiredACRs)
-	}
-
-	if options.IsDestroyingCommand {
-		for _, check := range listServiceToCheckStatus {
-			check(sessions, options)
-		}
-	} else {
-		for {
-			for _, check := range listServiceToCheckStatus {
-				check(sessions, options)
-			}
-			time.Sleep(time.Duration(interval) * time.Second)
-		}
-	}
+	engine.Run(ctx, interval, options.IsDestroyingCommand)
 }
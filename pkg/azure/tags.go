@@ -0,0 +1,37 @@
+package azure
+
+import (
+	"context"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+
+	"github.com/Qovery/pleco/pkg/common"
+)
+
+// setTags merges the tags with the ones of the resource. Azure tag names can't hold "/".
+func (c azureCleaner) setTags(ctx context.Context, scope string, tags map[string]string) error {
+	azureTags := make(map[string]*string, len(tags))
+	for key, value := range common.LabelTags(tags) {
+		azureTags[key] = to.Ptr(value)
+	}
+
+	_, err := c.sessions.Tags.UpdateAtScope(ctx, scope, armresources.TagsPatchResource{
+		Operation:  to.Ptr(armresources.TagsPatchOperationMerge),
+		Properties: &armresources.Tags{Tags: azureTags},
+	}, nil)
+
+	return err
+}
+
+func (c rgCleaner) SetTags(ctx context.Context, resource common.CloudProviderResource, tags map[string]string) error {
+	return c.setTags(ctx, "/subscriptions/"+c.options.SubscriptionID+"/resourceGroups/"+resource.Identifier, tags)
+}
+
+func (c acrCleaner) SetTags(ctx context.Context, resource common.CloudProviderResource, tags map[string]string) error {
+	return c.setTags(ctx, resource.Identifier, tags)
+}
+
+func (c storageAccountCleaner) SetTags(ctx context.Context, resource common.CloudProviderResource, tags map[string]string) error {
+	return c.setTags(ctx, resource.Identifier, tags)
+}
@@ -0,0 +1,77 @@
+package common
+
+import (
+	"context"
+	"strings"
+)
+
+// Cleaner handles a single kind of cloud resource. The engine lists every resource of the kind,
+// keeps the ones the cleaner evaluates as eligible to deletion and asks the cleaner to delete them.
+type Cleaner interface {
+	List(ctx context.Context) ([]CloudProviderResource, error)
+	Evaluate(resource CloudProviderResource) bool
+	Delete(ctx context.Context, resource CloudProviderResource) error
+}
+
+// Discoverer is implemented by provider scopes sweeping their location once per cycle, before the cleaners list
+// their resources. It returns the tagged resources of types no cleaner handles, which are reported and kept.
+type Discoverer interface {
+	Discover(ctx context.Context) ([]UnsupportedResource, error)
+}
+
+// TTLEvaluator is the default evaluation: a resource is eligible to deletion once its ttl is expired,
+// or when it holds the tag value given to the destroy command.
+type TTLEvaluator struct {
+	TagValue        string
+	DisableTTLCheck bool
+}
+
+func (evaluator TTLEvaluator) Evaluate(resource CloudProviderResource) bool {
+	return resource.IsResourceExpired(evaluator.TagValue, evaluator.DisableTTLCheck)
+}
+
+func (evaluator TTLEvaluator) MatchedRule(resource CloudProviderResource) string {
+	if strings.TrimSpace(evaluator.TagValue) != "" {
+		return RuleTagValue
+	}
+	if !resource.ExpiresAt.IsZero() {
+		return RuleExpiresAt
+	}
+	if resource.TTL == -1 && evaluator.DisableTTLCheck {
+		return RuleTTLCheckDisabled
+	}
+
+	return RuleTTL
+}
+
+// Rules reported for the resources selected by the default evaluation.
+const (
+	RuleTTL              = "ttl"
+	RuleExpiresAt        = "expires-at"
+	RuleTagValue         = "tag-value"
+	RuleTTLCheckDisabled = "ttl-check-disabled"
+)
+
+// RuleExplainer is implemented by evaluators able to tell which rule selected a resource. When a cleaner
+// doesn't implement it or returns an empty rule, the cleaner description is reported as the matched rule.
+type RuleExplainer interface {
+	MatchedRule(resource CloudProviderResource) string
+}
+
+// AlwaysEvaluator is used by cleaners whose List already returns only deletable resources
+// (detached volumes, orphan IPs, unlinked subnet groups...).
+type AlwaysEvaluator struct{}
+
+func (AlwaysEvaluator) Evaluate(resource CloudProviderResource) bool {
+	return true
+}
+
+func (AlwaysEvaluator) MatchedRule(resource CloudProviderResource) string {
+	return ""
+}
+
+// BatchDeleter is implemented by cleaners which must delete all their eligible resources at once,
+// e.g. members of a single IAM policy. The engine calls DeleteBatch instead of Delete.
+type BatchDeleter interface {
+	DeleteBatch(ctx context.Context, resources []CloudProviderResource) error
+}
@@ -0,0 +1,660 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type EngineOptions struct {
+	Provider string
+	// Account is the cloud account the engine runs in, when a provider handles several ones (eg. an AWS account or a GCP project)
+	Account  string
+	Scope    Scope
+	Location string
+	DryRun   bool
+	Features FeatureSet
+}
+
+// destroyRetryDelay is the delay between two cycles of the destroy command, until destroyTimeout.
+const destroyRetryDelay = 30 * time.Second
+
+var destroyTimeout = 30 * time.Minute
+
+func SetDestroyTimeout(timeout time.Duration) {
+	destroyTimeout = timeout
+}
+
+// callTimeout bounds every list, and every deletion or tagging of a resource.
+var callTimeout = 5 * time.Minute
+
+func SetCallTimeout(timeout time.Duration) {
+	if timeout > 0 {
+		callTimeout = timeout
+	}
+}
+
+// DefaultConcurrency is the number of resource kinds an engine checks at once.
+const DefaultConcurrency = 4
+
+var (
+	concurrencyMutex sync.RWMutex
+	// concurrency is keyed by provider
+	concurrency = make(map[string]int)
+)
+
+// SetConcurrency sets the number of resource kinds each engine of the provider checks at once, 1 checks them one
+// after the other.
+func SetConcurrency(provider string, limit int) {
+	if limit < 1 {
+		return
+	}
+
+	concurrencyMutex.Lock()
+	defer concurrencyMutex.Unlock()
+	concurrency[provider] = limit
+}
+
+func getConcurrency(provider string) int {
+	concurrencyMutex.RLock()
+	defer concurrencyMutex.RUnlock()
+	if limit, ok := concurrency[provider]; ok {
+		return limit
+	}
+
+	return DefaultConcurrency
+}
+
+// CallContext bounds a call with the call timeout.
+func CallContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, callTimeout)
+}
+
+// deletionContext isn't cancelled with ctx: a deletion in progress when Pleco stops is completed, within the call
+// timeout, rather than leaving a resource half deleted.
+func deletionContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(ctx), callTimeout)
+}
+
+type registeredCleaner struct {
+	definition CleanerDefinition
+	cleaner    Cleaner
+}
+
+// Engine drives every enabled cleaner of a provider scope: discovery, expiry evaluation, dry run and deletion.
+// Cleaners run concurrently, up to the provider concurrency, once the cleaners of the kinds they depend on are done, and
+// the resources of a kind are only deleted once no expired resource of the kinds it depends on remains.
+type Engine struct {
+	options  EngineOptions
+	cleaners []registeredCleaner
+	// mutex guards the state of the cycle below, it is released during the cloud calls
+	mutex sync.Mutex
+	// discoverer sweeps the location at the start of each cycle, when the provider scope implements it
+	discoverer Discoverer
+	progress   progressTracker
+	// remaining is the number of expired resources of each kind listed during the current cycle
+	remaining map[string]int
+	// unlisted holds the kinds which couldn't be listed during the current cycle, or which depend on such a kind
+	unlisted map[string]bool
+	// notifications are sent at the end of the cycle
+	notifications []Notification
+	// warned holds the expiration date of the resources notified as expiring soon
+	warned map[string]time.Time
+	// status sums up the current cycle
+	status *CycleStatus
+}
+
+func NewEngine(options EngineOptions, providerScope interface{}) *Engine {
+	engine := &Engine{options: options, progress: loadStates(options), warned: make(map[string]time.Time)}
+	engine.discoverer, _ = providerScope.(Discoverer)
+
+	definitions, err := SortByDependencies(GetCleaners(options.Provider, options.Scope, options.Features))
+	if err != nil {
+		log.Fatalf("Can't order %s cleaners: %s", options.Provider, err.Error())
+	}
+
+	for _, definition := range definitions {
+		cleaner, err := definition.New(providerScope)
+		if err != nil {
+			log.Errorf("Can't initialize %s cleaner%s: %s", definition.Kind, engine.locationString(), err.Error())
+			continue
+		}
+
+		engine.cleaners = append(engine.cleaners, registeredCleaner{definition: definition, cleaner: cleaner})
+	}
+
+	return engine
+}
+
+// Run executes a cleaning cycle every interval seconds, until ctx is cancelled. When once is set (destroy command),
+// cycles are run until no expired resource remains or the destroy timeout is reached, a single one in dry run.
+func (engine *Engine) Run(ctx context.Context, interval int64, once bool) {
+	defer engine.close()
+
+	if currentExtension != nil {
+		engine.extend(ctx, currentExtension)
+		return
+	}
+
+	if once {
+		engine.runUntilEmpty(ctx)
+		return
+	}
+
+	// the engines are stopped when the policy changes their regions, features or settings, to be started again
+	policyChanged := PolicyChanged()
+	addPendingEngine()
+	for cycle := 0; ; cycle++ {
+		engine.RunCycle(ctx)
+		if cycle == 0 {
+			removePendingEngine()
+		}
+
+		select {
+		case <-time.After(time.Duration(interval) * time.Second):
+		case <-policyChanged:
+			log.Infof("Policy changed, stopping %s checks%s", engine.options.Provider, engine.locationString())
+			return
+		case <-ctx.Done():
+			log.Infof("Stopping %s checks%s", engine.options.Provider, engine.locationString())
+			return
+		}
+	}
+}
+
+func (engine *Engine) runUntilEmpty(ctx context.Context) {
+	deadline := time.Now().Add(destroyTimeout)
+
+	for {
+		remaining := engine.RunCycle(ctx)
+		if remaining == 0 || engine.options.DryRun || ctx.Err() != nil {
+			return
+		}
+
+		if time.Now().Add(destroyRetryDelay).After(deadline) {
+			engine.logRemaining()
+			return
+		}
+
+		log.Infof("%d %s resources remaining%s, checking again in %s", remaining, engine.options.Provider, engine.locationString(), destroyRetryDelay)
+		select {
+		case <-time.After(destroyRetryDelay):
+		case <-ctx.Done():
+			engine.logRemaining()
+			return
+		}
+	}
+}
+
+// RunCycle returns the number of expired resources listed during the cycle, deleted or not. Once ctx is cancelled, the
+// deletion in progress is completed and the following cleaners are skipped.
+func (engine *Engine) RunCycle(ctx context.Context) int {
+	startedAt := time.Now()
+	report := NewReport(engine.options.Provider, engine.options.Scope, engine.options.Location, engine.options.DryRun)
+	report.Account = engine.options.Account
+	engine.remaining = make(map[string]int)
+	engine.unlisted = make(map[string]bool)
+	engine.status = &CycleStatus{
+		Provider: engine.options.Provider,
+		Account:  engine.options.Account,
+		Location: engine.options.Location,
+		DryRun:   engine.options.DryRun,
+	}
+
+	success := true
+	if engine.discoverer != nil && !engine.discover(ctx, report) {
+		success = false
+	}
+	if !engine.runCleaners(ctx, report) {
+		success = false
+	}
+
+	WriteReport(report)
+	recordPlan(report)
+	RecordCycle(engine.options.Provider, engine.options.Account, engine.options.Location, time.Since(startedAt), success)
+	engine.status.FinishedAt = time.Now().UTC()
+	engine.status.DurationSeconds = time.Since(startedAt).Seconds()
+	writeStatus(*engine.status)
+	engine.reportStuck()
+	saveStates(engine.options, engine.progress)
+	sendNotifications(engine.notifications)
+	engine.notifications = nil
+	engine.pruneWarned(startedAt)
+
+	remaining := 0
+	for _, count := range engine.remaining {
+		remaining += count
+	}
+
+	return remaining
+}
+
+// discover returns false when the sweep failed, the cleaners then read the resources on their own.
+func (engine *Engine) discover(ctx context.Context, report *Report) bool {
+	discoverCtx, cancel := CallContext(ctx)
+	unsupported, err := engine.discoverer.Discover(discoverCtx)
+	cancel()
+	if err != nil {
+		if ctx.Err() != nil {
+			return true
+		}
+		log.Errorf("Can't discover %s resources%s: %s", engine.options.Provider, engine.locationString(), err.Error())
+		engine.status.addError(fmt.Sprintf("can't discover resources: %s", err.Error()))
+		return false
+	}
+
+	for _, resource := range unsupported {
+		log.Warnf("%s %s%s is tagged but its type isn't supported, it is kept", resource.Type, resource.Identifier, engine.locationString())
+	}
+	report.Unsupported = unsupported
+
+	return true
+}
+
+// runCleaners runs the cleaners on a pool of the provider concurrency, so that a slow cleaner doesn't delay the other
+// kinds. A cleaner starts once the cleaners of the kinds it depends on are done, and is skipped once ctx is cancelled.
+// It returns false when a cleaner failed.
+func (engine *Engine) runCleaners(ctx context.Context, report *Report) bool {
+	done := make(map[string]chan struct{}, len(engine.cleaners))
+	for _, registered := range engine.cleaners {
+		done[registered.definition.Kind] = make(chan struct{})
+	}
+
+	workers := make(chan struct{}, getConcurrency(engine.options.Provider))
+	var failed atomic.Bool
+	var wg sync.WaitGroup
+	for _, registered := range engine.cleaners {
+		wg.Add(1)
+		go func(registered registeredCleaner) {
+			defer wg.Done()
+			defer close(done[registered.definition.Kind])
+
+			// the cleaners are sorted by dependencies, so waiting can't deadlock
+			for _, dependency := range GetDependencies(registered.definition.Provider, registered.definition.Kind) {
+				if dependencyDone, ok := done[dependency]; ok {
+					<-dependencyDone
+				}
+			}
+
+			workers <- struct{}{}
+			defer func() { <-workers }()
+
+			if ctx.Err() != nil {
+				return
+			}
+			if !engine.runCleaner(ctx, registered, report) {
+				failed.Store(true)
+			}
+		}(registered)
+	}
+	wg.Wait()
+
+	return !failed.Load()
+}
+
+// unlocked runs a cloud call without holding the engine lock, so that the other cleaners go on meanwhile.
+func (engine *Engine) unlocked(call func()) {
+	engine.mutex.Unlock()
+	defer engine.mutex.Lock()
+	call()
+}
+
+// runCleaner returns false when the cleaner failed to list or to delete a resource.
+func (engine *Engine) runCleaner(ctx context.Context, registered registeredCleaner, report *Report) bool {
+	definition := registered.definition
+	provider, account, location := engine.options.Provider, engine.options.Account, engine.options.Location
+
+	kindPolicy := GetPolicy().GetKindPolicy(provider, definition.Kind)
+	if !kindPolicy.Enabled {
+		log.Debugf("Skipping %s%s: disabled by policy", definition.Kind, engine.locationString())
+		return true
+	}
+
+	listCtx, cancel := CallContext(ctx)
+	resources, err := registered.cleaner.List(listCtx)
+	cancel()
+	if err != nil {
+		engine.mutex.Lock()
+		engine.unlisted[definition.Kind] = true
+		engine.mutex.Unlock()
+		if ctx.Err() != nil {
+			return true
+		}
+		log.Errorf("Can't list %s%s: %s", definition.Kind, engine.locationString(), err.Error())
+		engine.mutex.Lock()
+		engine.status.addError(fmt.Sprintf("can't list %s: %s", definition.Kind, err.Error()))
+		engine.mutex.Unlock()
+		return false
+	}
+
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+	RecordDiscoveredResources(provider, account, location, definition.Kind, len(resources))
+	engine.status.Discovered += len(resources)
+
+	now := time.Now()
+	var expiredResources []CloudProviderResource
+	var states []*ResourceState
+	var reportIndexes []int
+	for _, resource := range resources {
+		if rule, excluded := engine.excludedBy(kindPolicy, definition.Kind, resource); excluded {
+			log.Debugf("Skipping %s%s: excluded by %s", resource.Description, engine.locationString(), rule)
+			report.AddExclusion(definition.Kind, resource, rule)
+			continue
+		}
+
+		for _, warning := range resource.Warnings {
+			log.Warnf("%s%s: %s", resource.Description, engine.locationString(), warning)
+		}
+		report.AddWarnings(definition.Kind, resource)
+
+		kindPolicy.Apply(&resource)
+		if registered.cleaner.Evaluate(resource) {
+			if appliedPlan != nil {
+				planned, changes := appliedPlan.Check(provider, account, location, definition.Kind, resource)
+				if !planned {
+					log.Debugf("Skipping %s%s: not in the applied plan", resource.Description, engine.locationString())
+					continue
+				}
+				if len(changes) > 0 {
+					log.Warnf("Skipping %s%s: changed since the plan, %s", resource.Description, engine.locationString(), strings.Join(changes, ", "))
+					continue
+				}
+			}
+
+			expiredResources = append(expiredResources, resource)
+			states = append(states, engine.progress.get(engine, definition.Kind, resource, now))
+			reportIndexes = append(reportIndexes, report.Add(definition.Kind, resource, matchedRule(registered, resource)))
+		} else {
+			engine.warnExpiringSoon(ctx, registered, resource, now)
+		}
+	}
+	defer func() {
+		for i, state := range states {
+			report.setState(reportIndexes[i], state, now)
+		}
+	}()
+
+	RecordExpiredResources(provider, account, location, definition.Kind, len(expiredResources))
+	engine.status.Expired += len(expiredResources)
+	engine.remaining[definition.Kind] = len(expiredResources)
+	for _, dependency := range GetDependencies(provider, definition.Kind) {
+		if engine.unlisted[dependency] {
+			engine.unlisted[definition.Kind] = true
+		}
+	}
+	engine.progress.prune(definition.Kind, resources, expiredResources, now)
+
+	count, start := ElemToDeleteFormattedInfos(definition.Description, len(expiredResources), engine.options.Location, engine.options.Scope == ZoneScope)
+
+	log.Info(count)
+
+	if engine.options.DryRun || len(expiredResources) == 0 || ctx.Err() != nil {
+		return true
+	}
+
+	if dependency := engine.blockingDependency(definition); dependency != "" {
+		if engine.unlisted[dependency] {
+			log.Infof("Can't delete %s%s: %s resources couldn't be listed", definition.Kind, engine.locationString(), dependency)
+		} else {
+			log.Infof("Waiting for %s deletion before deleting %s%s", dependency, definition.Kind, engine.locationString())
+		}
+		for _, state := range states {
+			state.block(dependency)
+		}
+		return true
+	}
+
+	log.Info(start)
+
+	if batchDeleter, ok := registered.cleaner.(BatchDeleter); ok {
+		var err error
+		engine.unlocked(func() {
+			deleteCtx, cancel := deletionContext(ctx)
+			err = batchDeleter.DeleteBatch(deleteCtx, expiredResources)
+			cancel()
+		})
+		for i, state := range states {
+			state.record(err, now)
+			engine.notifyOutcome(definition.Kind, expiredResources[i], state, err)
+		}
+
+		if err != nil && !errors.Is(err, ErrDeletionInProgress) {
+			log.Errorf("Can't delete %s%s: %s", definition.Description, engine.locationString(), err.Error())
+			RecordFailedResources(provider, account, location, definition.Kind, len(expiredResources))
+			engine.status.Failed += len(expiredResources)
+			engine.status.addError(fmt.Sprintf("can't delete %s: %s", definition.Kind, err.Error()))
+			return false
+		}
+		if err == nil {
+			RecordDeletedResources(provider, account, location, definition.Kind, len(expiredResources))
+			engine.status.Deleted += len(expiredResources)
+		}
+		return true
+	}
+
+	success := true
+	for i, resource := range expiredResources {
+		if ctx.Err() != nil {
+			break
+		}
+
+		state := states[i]
+		if !state.canAttempt(now) {
+			log.Debugf("Skipping %s%s: next attempt at %s", resource.Description, engine.locationString(), state.NextAttempt.Format(time.RFC3339))
+			state.Status = StatusBackoff
+			continue
+		}
+
+		var err error
+		engine.unlocked(func() {
+			deleteCtx, cancel := deletionContext(ctx)
+			err = registered.cleaner.Delete(deleteCtx, resource)
+			cancel()
+		})
+		state.record(err, now)
+		engine.notifyOutcome(definition.Kind, resource, state, err)
+
+		switch {
+		case err == nil:
+			log.Debugf("%s%s deleted.", resource.Description, engine.locationString())
+			RecordDeletedResources(provider, account, location, definition.Kind, 1)
+			engine.status.Deleted++
+		case errors.Is(err, ErrDeletionInProgress):
+			log.Debugf("%s%s: %s", resource.Description, engine.locationString(), err.Error())
+		default:
+			log.Errorf("Can't delete %s%s (attempt %d): %s", resource.Description, engine.locationString(), state.Attempts, err.Error())
+			RecordFailedResources(provider, account, location, definition.Kind, 1)
+			engine.status.Failed++
+			engine.status.addError(fmt.Sprintf("can't delete %s %s: %s", definition.Kind, resource.Identifier, err.Error()))
+			success = false
+		}
+	}
+
+	return success
+}
+
+// excludedBy returns the rule excluding the resource, if any: the exclusions of the kind policy or a policy rule.
+func (engine *Engine) excludedBy(kindPolicy KindPolicy, kind string, resource CloudProviderResource) (string, bool) {
+	if kindPolicy.IsExcluded(resource) {
+		return RuleExclusions, true
+	}
+
+	return GetPolicy().ExcludedBy(RuleTarget{
+		Provider: engine.options.Provider,
+		Account:  engine.options.Account,
+		Location: engine.options.Location,
+		Kind:     kind,
+		Resource: resource,
+	})
+}
+
+func (engine *Engine) notify(event string, kind string, resource CloudProviderResource) *Notification {
+	engine.notifications = append(engine.notifications, Notification{
+		Event:       event,
+		Provider:    engine.options.Provider,
+		Account:     engine.options.Account,
+		Location:    engine.options.Location,
+		Kind:        kind,
+		Identifier:  resource.Identifier,
+		Description: resource.Description,
+	})
+
+	return &engine.notifications[len(engine.notifications)-1]
+}
+
+// warnExpiringSoon notifies, once per expiration date, the resources expiring within the warning window. Resources
+// are stamped with the WarnedAtTag tag when enabled, so they are not notified again after a restart. It is called with
+// the engine locked.
+func (engine *Engine) warnExpiringSoon(ctx context.Context, registered registeredCleaner, resource CloudProviderResource, now time.Time) {
+	sinks, options := getNotifiers()
+	if (len(sinks) == 0 && !options.StampWarned) || options.WarningWindow <= 0 || engine.options.DryRun || resource.IsProtected {
+		return
+	}
+
+	expiresAt, ok := resource.ExpirationDate()
+	if !ok || expiresAt.Before(now) || expiresAt.After(now.Add(options.WarningWindow)) {
+		return
+	}
+
+	kind := registered.definition.Kind
+	key := kind + "/" + resource.Identifier
+	if warnedExpiresAt, ok := engine.warned[key]; ok && warnedExpiresAt.Equal(expiresAt) {
+		return
+	}
+	engine.warned[key] = expiresAt
+
+	// a warning stamped within the window of the current expiration date has already been sent
+	if warnedAt, err := ParseDate(resource.Tags[WarnedAtTag]); err == nil && !warnedAt.Before(expiresAt.Add(-options.WarningWindow)) {
+		return
+	}
+
+	if tagger, ok := registered.cleaner.(Tagger); ok && options.StampWarned {
+		var err error
+		engine.unlocked(func() {
+			tagCtx, cancel := CallContext(ctx)
+			err = tagger.SetTags(tagCtx, resource, map[string]string{WarnedAtTag: now.UTC().Format(time.RFC3339)})
+			cancel()
+		})
+		if err != nil {
+			log.Errorf("Can't tag %s%s as warned: %s", resource.Description, engine.locationString(), err.Error())
+		}
+	}
+
+	if len(sinks) == 0 {
+		return
+	}
+
+	notification := engine.notify(EventExpiringSoon, kind, resource)
+	notification.ExpiresAt = &expiresAt
+	if options.OwnerTag != "" {
+		notification.Owner = resource.Tags[options.OwnerTag]
+	}
+}
+
+// notifyOutcome notifies deleted resources, and the ones failing to be deleted once they reach the failures threshold.
+func (engine *Engine) notifyOutcome(kind string, resource CloudProviderResource, state *ResourceState, err error) {
+	sinks, options := getNotifiers()
+	if len(sinks) == 0 {
+		return
+	}
+
+	switch {
+	case err == nil:
+		engine.notify(EventDeleted, kind, resource)
+	case errors.Is(err, ErrDeletionInProgress):
+	case state.Failures == options.FailuresAfter:
+		notification := engine.notify(EventFailed, kind, resource)
+		notification.Attempts = state.Failures
+		notification.Error = err.Error()
+	}
+}
+
+func (engine *Engine) pruneWarned(now time.Time) {
+	for key, expiresAt := range engine.warned {
+		if expiresAt.Before(now) {
+			delete(engine.warned, key)
+		}
+	}
+}
+
+// blockingDependency returns the first kind the cleaner depends on which still has expired resources, or whose
+// resources couldn't be listed during the cycle.
+func (engine *Engine) blockingDependency(definition CleanerDefinition) string {
+	for _, dependency := range GetDependencies(definition.Provider, definition.Kind) {
+		if engine.remaining[dependency] > 0 || engine.unlisted[dependency] {
+			return dependency
+		}
+	}
+
+	return ""
+}
+
+// reportStuck logs the resources which should have been deleted for a while and updates the stuck resources metric.
+func (engine *Engine) reportStuck() {
+	if engine.options.DryRun {
+		return
+	}
+
+	now := time.Now()
+	for _, registered := range engine.cleaners {
+		kind := registered.definition.Kind
+		stuck := 0
+		for _, state := range engine.progress.pending(kind) {
+			if !state.isStuck(now) {
+				continue
+			}
+
+			stuck++
+			log.Warnf("%s %s%s is stuck: first seen expired at %s, %d deletion attempts (%s): %s", kind, state.Identifier,
+				engine.locationString(), state.FirstSeen.Format(time.RFC3339), state.Attempts, state.Status, state.LastError)
+		}
+		RecordStuckResources(engine.options.Provider, engine.options.Account, engine.options.Location, kind, stuck)
+	}
+}
+
+func (engine *Engine) logRemaining() {
+	for _, registered := range engine.cleaners {
+		kind := registered.definition.Kind
+		for _, state := range engine.progress.pending(kind) {
+			log.Warnf("%s %s%s not deleted after %d attempts (%s): %s", kind, state.Identifier, engine.locationString(), state.Attempts, state.Status, state.LastError)
+		}
+	}
+}
+
+func matchedRule(registered registeredCleaner, resource CloudProviderResource) string {
+	if explainer, ok := registered.cleaner.(RuleExplainer); ok {
+		if rule := explainer.MatchedRule(resource); rule != "" {
+			return rule
+		}
+	}
+
+	return registered.definition.Description
+}
+
+func (engine *Engine) close() {
+	for _, registered := range engine.cleaners {
+		if closer, ok := registered.cleaner.(io.Closer); ok {
+			_ = closer.Close()
+		}
+	}
+}
+
+func (engine *Engine) locationString() string {
+	location := ""
+	if engine.options.Location != "" {
+		location = fmt.Sprintf(" in %s %s", engine.options.Scope, engine.options.Location)
+	}
+	if engine.options.Account != "" {
+		location += fmt.Sprintf(" of %s", engine.options.Account)
+	}
+
+	return location
+}
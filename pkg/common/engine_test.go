@@ -0,0 +1,161 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+// recordingCleaner lists expired resources and records its calls in a log shared by the cleaners of a test.
+type recordingCleaner struct {
+	kind        string
+	identifiers []string
+	deleteErr   error
+	// listErr is returned by List when set, it can be changed between cycles
+	listErr *error
+
+	mutex *sync.Mutex
+	calls *[]string
+}
+
+func (cleaner recordingCleaner) record(call string) {
+	cleaner.mutex.Lock()
+	defer cleaner.mutex.Unlock()
+	*cleaner.calls = append(*cleaner.calls, call)
+}
+
+func (cleaner recordingCleaner) List(ctx context.Context) ([]CloudProviderResource, error) {
+	cleaner.record("list " + cleaner.kind)
+	if cleaner.listErr != nil && *cleaner.listErr != nil {
+		return nil, *cleaner.listErr
+	}
+
+	var resources []CloudProviderResource
+	for _, identifier := range cleaner.identifiers {
+		resources = append(resources, CloudProviderResource{Identifier: identifier, Description: cleaner.kind + " " + identifier})
+	}
+
+	return resources, nil
+}
+
+func (cleaner recordingCleaner) Evaluate(resource CloudProviderResource) bool {
+	return true
+}
+
+func (cleaner recordingCleaner) Delete(ctx context.Context, resource CloudProviderResource) error {
+	cleaner.record("delete " + resource.Identifier)
+	return cleaner.deleteErr
+}
+
+func TestEngineWaitsForDependencies(t *testing.T) {
+	const provider = "engine-test"
+	var mutex sync.Mutex
+	var calls []string
+	cleaners := map[string]recordingCleaner{
+		"instance": {kind: "instance", identifiers: []string{"i-1"}, deleteErr: errors.New("boom")},
+		"subnet":   {kind: "subnet", identifiers: []string{"subnet-1"}},
+		"vpc":      {kind: "vpc", identifiers: []string{"vpc-1"}},
+	}
+
+	RegisterProvider(ProviderDefinition{Name: provider, Features: []Feature{{Name: "network"}}})
+	for _, kind := range []string{"vpc", "subnet", "instance"} {
+		cleaner := cleaners[kind]
+		cleaner.mutex, cleaner.calls = &mutex, &calls
+		RegisterCleaner(CleanerDefinition{Provider: provider, Feature: "network", Kind: kind, Scope: RegionScope, New: func(providerScope interface{}) (Cleaner, error) {
+			return cleaner, nil
+		}})
+	}
+	RegisterDependencies(provider, "vpc", "subnet", "instance")
+
+	engine := NewEngine(EngineOptions{Provider: provider, Scope: RegionScope, Location: "test-1", Features: FeatureSet{"network": true}}, nil)
+	remaining := engine.RunCycle(context.Background())
+	if remaining != 3 {
+		t.Errorf("expected 3 remaining resources, got %d", remaining)
+	}
+
+	index := make(map[string]int)
+	for i, call := range calls {
+		index[call] = i
+	}
+	if _, ok := index["delete vpc-1"]; ok {
+		t.Error("the VPC shouldn't be deleted while an instance remains")
+	}
+	for _, dependency := range []string{"delete i-1", "delete subnet-1"} {
+		if index[dependency] > index["list vpc"] {
+			t.Errorf("%s should happen before the VPC is listed, calls: %v", dependency, calls)
+		}
+	}
+
+	vpcState := engine.progress["vpc"]["vpc-1"]
+	if vpcState == nil || vpcState.Status != StatusBlocked {
+		t.Errorf("the VPC state should be blocked, got %+v", vpcState)
+	}
+	if state := engine.progress["instance"]["i-1"]; state == nil || state.Status != StatusFailed {
+		t.Errorf("the instance state should be failed, got %+v", state)
+	}
+}
+
+func TestEngineKeepsStatesWhenListFails(t *testing.T) {
+	const provider = "engine-list-test"
+	var mutex sync.Mutex
+	var calls []string
+	var listErr error
+	cleaner := recordingCleaner{kind: "volume", identifiers: []string{"vol-1"}, deleteErr: errors.New("boom"), listErr: &listErr, mutex: &mutex, calls: &calls}
+
+	RegisterProvider(ProviderDefinition{Name: provider, Features: []Feature{{Name: "storage"}}})
+	RegisterCleaner(CleanerDefinition{Provider: provider, Feature: "storage", Kind: "volume", Scope: RegionScope, New: func(providerScope interface{}) (Cleaner, error) {
+		return cleaner, nil
+	}})
+
+	engine := NewEngine(EngineOptions{Provider: provider, Scope: RegionScope, Location: "test-1", Features: FeatureSet{"storage": true}}, nil)
+	engine.RunCycle(context.Background())
+	state := engine.progress["volume"]["vol-1"]
+	if state == nil || state.Status != StatusFailed {
+		t.Fatalf("the volume state should be failed, got %+v", state)
+	}
+
+	listErr = errors.New("throttled")
+	engine.RunCycle(context.Background())
+	if engine.progress["volume"]["vol-1"] != state || state.Status != StatusFailed {
+		t.Errorf("the volume state should be kept when the volumes can't be listed, got %+v", engine.progress["volume"]["vol-1"])
+	}
+	if len(engine.status.Errors) != 1 {
+		t.Errorf("expected the list error in the cycle status, got %v", engine.status.Errors)
+	}
+}
+
+func TestEngineBlocksDependentsOfUnlistedKinds(t *testing.T) {
+	const provider = "engine-unlisted-test"
+	var mutex sync.Mutex
+	var calls []string
+	listErr := errors.New("throttled")
+	cleaners := map[string]recordingCleaner{
+		"instance": {kind: "instance", identifiers: []string{"i-1"}, listErr: &listErr},
+		"subnet":   {kind: "subnet"},
+		"vpc":      {kind: "vpc", identifiers: []string{"vpc-1"}},
+	}
+
+	RegisterProvider(ProviderDefinition{Name: provider, Features: []Feature{{Name: "network"}}})
+	for _, kind := range []string{"vpc", "subnet", "instance"} {
+		cleaner := cleaners[kind]
+		cleaner.mutex, cleaner.calls = &mutex, &calls
+		RegisterCleaner(CleanerDefinition{Provider: provider, Feature: "network", Kind: kind, Scope: RegionScope, New: func(providerScope interface{}) (Cleaner, error) {
+			return cleaner, nil
+		}})
+	}
+	RegisterDependencies(provider, "vpc", "subnet")
+	RegisterDependencies(provider, "subnet", "instance")
+
+	engine := NewEngine(EngineOptions{Provider: provider, Scope: RegionScope, Location: "test-1", Features: FeatureSet{"network": true}}, nil)
+	engine.RunCycle(context.Background())
+
+	for _, call := range calls {
+		if call == "delete vpc-1" {
+			t.Errorf("the VPC shouldn't be deleted while the instances can't be listed, calls: %v", calls)
+		}
+	}
+	if state := engine.progress["vpc"]["vpc-1"]; state == nil || state.Status != StatusBlocked {
+		t.Errorf("the VPC state should be blocked, got %+v", state)
+	}
+}
@@ -0,0 +1,215 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// WarnedAtTag is set on resources once notified as expiring soon
+	WarnedAtTag = "pleco/warned-at"
+	// ExtendByTag postpones the expiration of a resource by a duration, eg. 4h
+	ExtendByTag = "pleco/extend-by"
+	// WarnedAtLabel and ExtendByLabel replace WarnedAtTag and ExtendByTag on providers not accepting "/" in tag
+	// keys, as GCP labels and Azure tags
+	WarnedAtLabel = "pleco_warned-at"
+	ExtendByLabel = "pleco_extend-by"
+)
+
+var labelKeys = map[string]string{WarnedAtTag: WarnedAtLabel, ExtendByTag: ExtendByLabel}
+
+// Tagger is implemented by cleaners able to set tags on their resources. It is used to stamp warned resources
+// and to extend their ttl.
+type Tagger interface {
+	SetTags(ctx context.Context, resource CloudProviderResource, tags map[string]string) error
+}
+
+// LabelTags returns the tags with the WarnedAtLabel and ExtendByLabel keys, for providers not accepting "/" in tag keys.
+func LabelTags(tags map[string]string) map[string]string {
+	labels := make(map[string]string, len(tags))
+	for key, value := range tags {
+		if label, ok := labelKeys[key]; ok {
+			key = label
+		}
+		labels[key] = value
+	}
+
+	return labels
+}
+
+// TagList returns the tags list of a provider setting tags as "key<separator>value" strings, as Scaleway and
+// DigitalOcean, updated with the tags. Such tags being split on ":", dates are set as unix timestamps.
+func TagList(current []string, tags map[string]string, separator string) []string {
+	var list []string
+	for _, tag := range current {
+		key := tag
+		if index := strings.IndexAny(tag, "=:"); index >= 0 {
+			key = tag[:index]
+		}
+		if _, ok := tags[key]; !ok {
+			list = append(list, tag)
+		}
+	}
+
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := tags[key]
+		if date, err := time.Parse(time.RFC3339, value); err == nil {
+			value = strconv.FormatInt(date.Unix(), 10)
+		}
+		list = append(list, key+separator+value)
+	}
+
+	return list
+}
+
+// tagExtension returns the extension set by the ExtendByTag tag, in seconds.
+func tagExtension(tags map[string]string) int64 {
+	extension, err := ParseTTL(tags[ExtendByTag])
+	if err != nil || extension < 0 {
+		return 0
+	}
+
+	return extension
+}
+
+// tagKey returns the key of the first tag the resource holds among the keys, else the first key.
+func tagKey(tags map[string]string, keys ...string) string {
+	for _, key := range keys {
+		if _, ok := tags[key]; ok {
+			return key
+		}
+	}
+
+	return keys[0]
+}
+
+// ExtensionTags returns the tags keeping the resource by more than the given duration: its ttl, or expires_at tag,
+// is moved from its expiration date, or from now if it is already expired.
+func ExtensionTags(resource CloudProviderResource, by time.Duration, now time.Time) (map[string]string, time.Time, error) {
+	expiresAt, ok := resource.ExpirationDate()
+	if !ok {
+		return nil, time.Time{}, fmt.Errorf("%s has no ttl nor expiration date, it doesn't expire", resource.Description)
+	}
+	if expiresAt.Before(now) {
+		expiresAt = now
+	}
+	expiresAt = expiresAt.Add(by).UTC()
+
+	// the extension tag is added on top of the ttl and expires_at tags
+	extension := time.Duration(tagExtension(resource.Tags)) * time.Second
+
+	if !resource.ExpiresAt.IsZero() {
+		key := tagKey(resource.Tags, "expires_at", "delete_after")
+		return map[string]string{key: expiresAt.Add(-extension).Format(time.RFC3339)}, expiresAt, nil
+	}
+
+	if resource.CreationDate.Year() < 1972 {
+		return nil, time.Time{}, fmt.Errorf("%s creation date is unknown, its ttl can't be extended", resource.Description)
+	}
+
+	ttl := expiresAt.Add(-extension).Sub(resource.CreationDate)
+	key := tagKey(resource.Tags, "ttl", "Ttl", "TTL")
+	return map[string]string{key: strconv.FormatInt(int64(ttl.Seconds()), 10)}, expiresAt, nil
+}
+
+// Extension is a request of the extend command: the resources of the identifier are kept longer.
+type Extension struct {
+	Identifier string
+	// Kind restricts the extension to a resource kind, when several kinds share identifiers
+	Kind string
+	By   time.Duration
+
+	mutex    sync.Mutex
+	extended []string
+	errs     []string
+}
+
+func (extension *Extension) record(message string, err error) {
+	extension.mutex.Lock()
+	defer extension.mutex.Unlock()
+
+	if err != nil {
+		extension.errs = append(extension.errs, err.Error())
+		return
+	}
+	extension.extended = append(extension.extended, message)
+}
+
+// Result returns the extended resources, or an error when none has been extended.
+func (extension *Extension) Result() ([]string, error) {
+	extension.mutex.Lock()
+	defer extension.mutex.Unlock()
+
+	if len(extension.errs) > 0 {
+		return extension.extended, fmt.Errorf("%s", strings.Join(extension.errs, "; "))
+	}
+	if len(extension.extended) == 0 {
+		return nil, fmt.Errorf("resource %s not found", extension.Identifier)
+	}
+
+	return extension.extended, nil
+}
+
+var currentExtension *Extension
+
+// SetExtension makes the engines extend the resources of the extension instead of running cleaning cycles.
+func SetExtension(extension *Extension) {
+	currentExtension = extension
+}
+
+func (engine *Engine) extend(ctx context.Context, extension *Extension) {
+	now := time.Now()
+	for _, registered := range engine.cleaners {
+		kind := registered.definition.Kind
+		if extension.Kind != "" && kind != extension.Kind {
+			continue
+		}
+
+		listCtx, cancel := CallContext(ctx)
+		resources, err := registered.cleaner.List(listCtx)
+		cancel()
+		if err != nil {
+			log.Errorf("Can't list %s%s: %s", kind, engine.locationString(), err.Error())
+			continue
+		}
+
+		for _, resource := range resources {
+			if resource.Identifier != extension.Identifier {
+				continue
+			}
+
+			tagger, ok := registered.cleaner.(Tagger)
+			if !ok {
+				extension.record("", fmt.Errorf("%s%s: %s tags can't be updated", resource.Description, engine.locationString(), kind))
+				continue
+			}
+
+			GetPolicy().GetKindPolicy(engine.options.Provider, kind).Apply(&resource)
+			tags, expiresAt, err := ExtensionTags(resource, extension.By, now)
+			if err == nil {
+				tagCtx, cancel := CallContext(ctx)
+				err = tagger.SetTags(tagCtx, resource, tags)
+				cancel()
+			}
+			if err != nil {
+				extension.record("", fmt.Errorf("%s%s: %s", resource.Description, engine.locationString(), err.Error()))
+				continue
+			}
+
+			extension.record(fmt.Sprintf("%s%s now expires at %s", resource.Description, engine.locationString(), expiresAt.Format(time.RFC3339)), nil)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLabelTags(t *testing.T) {
+	labels := LabelTags(map[string]string{WarnedAtTag: "1700000000", "ttl": "3600"})
+	expected := map[string]string{WarnedAtLabel: "1700000000", "ttl": "3600"}
+	if !reflect.DeepEqual(labels, expected) {
+		t.Errorf("expected %v, got %v", expected, labels)
+	}
+}
+
+func TestTagList(t *testing.T) {
+	current := []string{"ttl=3600", "team:core", "expires_at:1700000000", "standalone"}
+	tags := map[string]string{"ttl": "7200", "expires_at": "2030-01-02T03:04:05Z"}
+
+	list := TagList(current, tags, "=")
+	expected := []string{"team:core", "standalone", "expires_at=1893553445", "ttl=7200"}
+	if !reflect.DeepEqual(list, expected) {
+		t.Errorf("expected %v, got %v", expected, list)
+	}
+}
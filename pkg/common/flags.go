@@ -1,85 +1,268 @@
 package common
 
-import "github.com/spf13/cobra"
-
-func InitFlags(cloudProvider string, startCmd *cobra.Command) {
-	switch cloudProvider {
-	case "aws":
-		initAWSFlags(startCmd)
-	case "azure":
-		initAzureFlags(startCmd)
-	case "scaleway":
-		initScalewayFlags(startCmd)
-	case "do":
-		initDOFlags(startCmd)
-	case "gcp":
-		initGCPFlags(startCmd)
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// ParseProviders splits a comma separated list of providers, eg. "aws,gcp,scaleway".
+func ParseProviders(value string) []string {
+	var names []string
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// InitFlags registers the locations, concurrency and features flags of every provider. A feature flag shared by several providers,
+// eg. --enable-cluster, enables it for each selected provider having it, while --enable-<provider>-<feature> only
+// enables it for a single one. As providers shorthands clash, they are only set when a single provider is selected.
+func InitFlags(selectedProviders []string, cmd *cobra.Command) {
+	shorthandProvider := ""
+	if len(selectedProviders) == 1 {
+		shorthandProvider = selectedProviders[0]
+	}
+
+	sharedFeatures := make(map[string]bool)
+	for _, name := range GetProviderNames() {
+		provider := providers[name]
+
+		locationsShorthand := ""
+		if name == shorthandProvider {
+			locationsShorthand = provider.LocationsShorthand
+		}
+		cmd.Flags().StringSliceP(provider.LocationsFlag, locationsShorthand, nil, provider.LocationsUsage)
+		if provider.ExcludedLocationsFlag != "" {
+			cmd.Flags().StringSlice(provider.ExcludedLocationsFlag, nil, "Locations not to clean when they are discovered, glob patterns or regular expressions between slashes")
+		}
+		if provider.AccountsFlag != "" {
+			cmd.Flags().StringSlice(provider.AccountsFlag, nil, provider.AccountsUsage)
+		}
+		cmd.Flags().Int(name+"-concurrency", DefaultConcurrency, fmt.Sprintf("Number of %s resource kinds checked at once in each location, 1 checks them one after the other", name))
+
+		for _, feature := range provider.Features {
+			cmd.Flags().Bool("enable-"+name+"-"+feature.Name, false, fmt.Sprintf("%s (%s only)", feature.Usage, name))
+			sharedFeatures[feature.Name] = true
+		}
+	}
+
+	var sharedNames []string
+	for featureName := range sharedFeatures {
+		sharedNames = append(sharedNames, featureName)
+	}
+	sort.Strings(sharedNames)
+
+	for _, featureName := range sharedNames {
+		shorthand := ""
+		usage := fmt.Sprintf("Enable %s watch on every provider", featureName)
+		if provider, ok := GetProvider(shorthandProvider); ok {
+			for _, feature := range provider.Features {
+				if feature.Name == featureName {
+					shorthand = feature.Shorthand
+					usage = feature.Usage
+				}
+			}
+		}
+		cmd.Flags().BoolP("enable-"+featureName, shorthand, false, usage)
+	}
+}
+
+// GetEnabledFeatures returns the features enabled on the command line or in the policy, including the implied ones.
+func GetEnabledFeatures(cloudProvider string, cmd *cobra.Command) FeatureSet {
+	features := FeatureSet{}
+
+	provider, ok := GetProvider(cloudProvider)
+	if !ok {
+		return features
+	}
+
+	for _, feature := range provider.Features {
+		if isUsed(cmd, feature.Name) || isUsed(cmd, cloudProvider+"-"+feature.Name) {
+			features.Enable(feature.Name)
+		}
+	}
+
+	for _, resource := range GetPolicy().GetProviderPolicy(cloudProvider).Resources {
+		features.Enable(resource)
+	}
+
+	return provider.ResolveFeatures(features)
+}
+
+// EnableAllFeatures enables every feature of the provider when none is enabled on the command line or in the policy.
+func EnableAllFeatures(cloudProvider string, cmd *cobra.Command) {
+	provider, ok := GetProvider(cloudProvider)
+	if !ok || len(GetEnabledFeatures(cloudProvider, cmd)) > 0 {
+		return
+	}
+
+	for _, feature := range provider.Features {
+		_ = cmd.Flags().Set("enable-"+cloudProvider+"-"+feature.Name, "true")
+	}
+}
+
+func GetLocations(cloudProvider string, cmd *cobra.Command) []string {
+	provider, ok := GetProvider(cloudProvider)
+	if !ok {
+		return nil
+	}
+
+	locations, _ := cmd.Flags().GetStringSlice(provider.LocationsFlag)
+	if len(locations) == 0 {
+		return GetPolicy().GetProviderPolicy(cloudProvider).Regions
+	}
+
+	return locations
+}
+
+// GetExcludedLocations returns the locations excluded on the command line, else in the policy.
+func GetExcludedLocations(cloudProvider string, cmd *cobra.Command) []string {
+	provider, ok := GetProvider(cloudProvider)
+	if !ok || provider.ExcludedLocationsFlag == "" {
+		return nil
 	}
+
+	excluded, _ := cmd.Flags().GetStringSlice(provider.ExcludedLocationsFlag)
+	if len(excluded) == 0 {
+		return GetPolicy().GetProviderPolicy(cloudProvider).ExcludedRegions
+	}
+
+	return excluded
 }
 
-func initAWSFlags(startCmd *cobra.Command) {
-	startCmd.Flags().StringSliceP("aws-regions", "a", nil, "Set AWS regions")
-	startCmd.Flags().BoolP("enable-eks", "e", false, "Enable EKS watch")
-	startCmd.Flags().BoolP("enable-rds", "r", false, "Enable RDS databases and and its children (subnet groups & parameter groups) watch")
-	startCmd.Flags().BoolP("enable-documentdb", "m", false, "Enable DocumentDB watch")
-	startCmd.Flags().BoolP("enable-elasticache", "c", false, "Enable Elasticache watch")
-	startCmd.Flags().BoolP("enable-elb", "l", false, "Enable Elastic Load Balancers watch (true is eks is enabled)")
-	startCmd.Flags().BoolP("enable-ebs", "b", false, "Enable Elastic Volumes watch (true is eks is enabled)")
-	startCmd.Flags().BoolP("enable-vpc", "p", false, "Enable VPC and its children (internet gateways, route tables, subnets, security groups) watch")
-	startCmd.Flags().BoolP("enable-s3", "s", false, "Enable S3 watch")
-	startCmd.Flags().BoolP("enable-cloudwatch-logs", "w", false, "Enable Cloudwatch Logs watch")
-	startCmd.Flags().BoolP("enable-kms", "n", false, "Enable KMS watch")
-	startCmd.Flags().BoolP("enable-iam", "u", false, "Enable IAM (groups, policies, roles, users) watch")
-	startCmd.Flags().BoolP("enable-ssh-keys", "z", false, "Enable Key Pair watch")
-	startCmd.Flags().BoolP("enable-ecr", "o", false, "Enable ECR watch")
-	startCmd.Flags().BoolP("enable-sqs", "q", false, "Enable SQS watch")
-	startCmd.Flags().BoolP("enable-lambda", "f", false, "Enable Lambda Function watch")
-	startCmd.Flags().BoolP("enable-sfn", "x", false, "Enable Lambda Function watch")
-	startCmd.Flags().BoolP("enable-cloudformation", "d", false, "Enable Cloudformation watch")
-	startCmd.Flags().BoolP("enable-ec2-instance", "g", false, "Enable EC2 Instance watch")
-	startCmd.Flags().BoolP("enable-cloudwatch-events", "v", false, "Enable CloudWatch events watch")
+// IsAllLocations returns whether every location of the provider is cleaned: none or "all" is given.
+func IsAllLocations(locations []string) bool {
+	for _, location := range locations {
+		if location == AllLocations {
+			return true
+		}
+	}
+
+	return len(locations) == 0
 }
 
-func initAzureFlags(startCmd *cobra.Command) {
-	startCmd.Flags().StringSliceP("az-regions", "a", nil, "Set Azure regions")
-	startCmd.Flags().BoolP("enable-rg", "e", false, "Enable Resource Groups watch")
-	startCmd.Flags().BoolP("enable-storage-account", "s", false, "Enable Storage Account watch")
-	startCmd.Flags().BoolP("enable-acr", "c", false, "Enable Container Registry watch")
+// IsExcludedLocation returns whether the location matches one of the excluded patterns.
+func IsExcludedLocation(excluded []string, location string) bool {
+	return matchesAny(excluded, location)
 }
 
-func initScalewayFlags(startCmd *cobra.Command) {
-	startCmd.Flags().StringSliceP("scw-zones", "a", nil, "Set Scaleway regions")
-	startCmd.Flags().BoolP("enable-cluster", "e", false, "Enable Kubernetes clusters watch")
-	startCmd.Flags().BoolP("enable-db", "r", false, "Enable databases watch")
-	startCmd.Flags().BoolP("enable-cr", "o", false, "Enable containers registries watch")
-	startCmd.Flags().BoolP("enable-s3", "s", false, "Enable buckets watch")
-	startCmd.Flags().BoolP("enable-lb", "l", false, "Enable load balancers watch")
-	startCmd.Flags().BoolP("enable-volume", "b", false, "Enable volumes watch")
-	startCmd.Flags().BoolP("enable-sg", "g", false, "Enable security groups watch")
-	startCmd.Flags().BoolP("enable-orphan-ip", "", false, "Enable orphan IPs watch")
-	startCmd.Flags().BoolP("enable-vpc", "p", false, "Enable VPC watch")
-	startCmd.Flags().BoolP("enable-private-network", "n", false, "Enable private networks watch")
+// ResolveLocations returns the locations to clean: the ones given, or the ones returned by discover when none or "all"
+// is given, without the excluded ones.
+func ResolveLocations(cloudProvider string, cmd *cobra.Command, discover func() ([]string, error)) ([]string, error) {
+	locations := GetLocations(cloudProvider, cmd)
+	if IsAllLocations(locations) {
+		var err error
+		locations, err = discover()
+		if err != nil {
+			return nil, fmt.Errorf("can't discover %s locations: %s", cloudProvider, err.Error())
+		}
+	}
+
+	excluded := GetExcludedLocations(cloudProvider, cmd)
+	var resolved []string
+	for _, location := range locations {
+		if !IsExcludedLocation(excluded, location) {
+			resolved = append(resolved, location)
+		}
+	}
+	sort.Strings(resolved)
 
+	return resolved, nil
 }
 
-func initDOFlags(startCmd *cobra.Command) {
-	startCmd.Flags().StringSliceP("do-regions", "a", nil, "Set Digital Ocean regions")
-	startCmd.Flags().BoolP("enable-cluster", "e", false, "Enable Kubernetes clusters watch")
-	startCmd.Flags().BoolP("enable-db", "r", false, "Enable databases watch")
-	startCmd.Flags().BoolP("enable-s3", "s", false, "Enable buckets watch")
-	startCmd.Flags().BoolP("enable-lb", "l", false, "Enable load balancers watch")
-	startCmd.Flags().BoolP("enable-volume", "b", false, "Enable volumes watch")
-	startCmd.Flags().BoolP("enable-firewall", "f", false, "Enable firewalls watch")
-	startCmd.Flags().BoolP("enable-vpc", "v", false, "Enable VPCs watch")
+func GetAccounts(cloudProvider string, cmd *cobra.Command) []string {
+	provider, ok := GetProvider(cloudProvider)
+	if !ok || provider.AccountsFlag == "" {
+		return nil
+	}
+
+	accounts, _ := cmd.Flags().GetStringSlice(provider.AccountsFlag)
+	if len(accounts) == 0 {
+		return GetPolicy().GetProviderPolicy(cloudProvider).Accounts
+	}
+
+	return accounts
 }
 
-func initGCPFlags(startCmd *cobra.Command) {
-	startCmd.Flags().StringSliceP("gcp-regions", "", nil, "Set GCP regions")
-	startCmd.Flags().BoolP("enable-cluster", "", false, "Enable Kubernetes clusters watch")
-	startCmd.Flags().BoolP("enable-object-storage", "", false, "Enable object storage buckets watch")
-	startCmd.Flags().BoolP("enable-artifact-registry", "", false, "Enable security groups watch")
-	startCmd.Flags().BoolP("enable-network", "", false, "Enable Networks and its children watch")
-	startCmd.Flags().BoolP("enable-router", "", false, "Enable Routers and its children watch")
-	startCmd.Flags().BoolP("enable-iam", "", false, "Enable IAM (service accounts) watch")
-	startCmd.Flags().BoolP("enable-job", "", false, "Enable Run Job watch")
+// GetTagName returns the tag name given on the command line, else the one of the provider policy, else the global one.
+func GetTagName(cloudProvider string, cmd *cobra.Command) string {
+	tagName, _ := cmd.Flags().GetString("tag-name")
+	if cmd.Flags().Changed("tag-name") {
+		return tagName
+	}
+
+	policy := GetPolicy()
+	if providerTagName := policy.GetProviderPolicy(cloudProvider).TagName; providerTagName != "" {
+		return providerTagName
+	}
+	if policy != nil && policy.TagName != "" {
+		return policy.TagName
+	}
+
+	return tagName
+}
+
+// isSelectionFlag returns whether the flag selects the resources to delete: tag, locations, accounts or features.
+func isSelectionFlag(name string) bool {
+	if name == "tag-name" || name == "tag-value" || strings.HasPrefix(name, "enable-") {
+		return true
+	}
+
+	for _, provider := range providers {
+		if name == provider.LocationsFlag || (provider.AccountsFlag != "" && name == provider.AccountsFlag) ||
+			(provider.ExcludedLocationsFlag != "" && name == provider.ExcludedLocationsFlag) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetSelectionFlags returns the selection flags set on the command line, so that a plan can be applied with them.
+func GetSelectionFlags(cmd *cobra.Command) map[string]string {
+	flags := make(map[string]string)
+	cmd.Flags().Visit(func(flag *pflag.Flag) {
+		if !isSelectionFlag(flag.Name) {
+			return
+		}
+
+		if slice, ok := flag.Value.(pflag.SliceValue); ok {
+			flags[flag.Name] = strings.Join(slice.GetSlice(), ",")
+		} else {
+			flags[flag.Name] = flag.Value.String()
+		}
+	})
+
+	return flags
+}
+
+// SetSelectionFlags sets the selection flags of a plan. They can't be given on the command line as well.
+func SetSelectionFlags(cmd *cobra.Command, flags map[string]string) error {
+	var err error
+	cmd.Flags().Visit(func(flag *pflag.Flag) {
+		if err == nil && isSelectionFlag(flag.Name) {
+			err = fmt.Errorf("--%s can't be set, it is read from the plan", flag.Name)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	for name, value := range flags {
+		if value == "" {
+			continue
+		}
+		if err := cmd.Flags().Set(name, value); err != nil {
+			return fmt.Errorf("can't set --%s=%s from the plan: %s", name, value, err.Error())
+		}
+	}
+
+	return nil
 }
@@ -0,0 +1,154 @@
+package common
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	log "github.com/sirupsen/logrus"
+)
+
+var resourceLabels = []string{"provider", "account", "region", "kind"}
+
+var (
+	discoveredResources = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "pleco_resources_discovered",
+		Help: "Number of resources listed by Pleco during the last cycle.",
+	}, resourceLabels)
+	expiredResources = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "pleco_resources_expired",
+		Help: "Number of resources eligible to deletion during the last cycle.",
+	}, resourceLabels)
+	deletedResources = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "pleco_resources_deleted_total",
+		Help: "Number of resources deleted.",
+	}, resourceLabels)
+	failedResources = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "pleco_resources_failed_total",
+		Help: "Number of resources Pleco failed to delete.",
+	}, resourceLabels)
+	stuckResources = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "pleco_resources_stuck",
+		Help: "Number of expired resources Pleco didn't manage to delete for a while.",
+	}, resourceLabels)
+	cycleDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "pleco_cycle_duration_seconds",
+		Help:    "Duration of a cleaning cycle.",
+		Buckets: []float64{1, 5, 15, 30, 60, 120, 300, 600, 1200},
+	}, []string{"provider", "account", "region"})
+	lastSuccessfulCycle = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "pleco_last_successful_cycle_timestamp_seconds",
+		Help: "Unix timestamp of the last cleaning cycle completed without error.",
+	}, []string{"provider", "account", "region"})
+)
+
+func init() {
+	prometheus.MustRegister(discoveredResources, expiredResources, deletedResources, failedResources, stuckResources, cycleDuration, lastSuccessfulCycle)
+}
+
+// readiness tracks the engines which haven't completed their first cycle yet.
+var readiness = struct {
+	sync.Mutex
+	started bool
+	pending int
+	standby bool
+}{}
+
+// SetStandby marks a replica waiting for the leadership as ready, as it has no engine to wait for.
+func SetStandby(standby bool) {
+	readiness.Lock()
+	defer readiness.Unlock()
+
+	readiness.standby = standby
+}
+
+func IsStandby() bool {
+	readiness.Lock()
+	defer readiness.Unlock()
+
+	return readiness.standby
+}
+
+func addPendingEngine() {
+	readiness.Lock()
+	defer readiness.Unlock()
+
+	readiness.started = true
+	readiness.pending++
+}
+
+func removePendingEngine() {
+	readiness.Lock()
+	defer readiness.Unlock()
+
+	readiness.pending--
+}
+
+func isReady() bool {
+	readiness.Lock()
+	defer readiness.Unlock()
+
+	return readiness.standby || (readiness.started && readiness.pending <= 0)
+}
+
+// StartMetricsServer exposes Prometheus metrics on /metrics, liveness on /healthz and readiness on /readyz.
+// Pleco is ready once every engine has completed its first cleaning cycle.
+func StartMetricsServer(address string) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok"))
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		if !isReady() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte("not ready"))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok"))
+	})
+
+	server := &http.Server{
+		Addr:              address,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		log.Infof("Serving metrics on %s", address)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("Can't serve metrics on %s: %s", address, err.Error())
+		}
+	}()
+}
+
+func RecordDiscoveredResources(provider string, account string, region string, kind string, count int) {
+	discoveredResources.WithLabelValues(provider, account, region, kind).Set(float64(count))
+}
+
+func RecordExpiredResources(provider string, account string, region string, kind string, count int) {
+	expiredResources.WithLabelValues(provider, account, region, kind).Set(float64(count))
+}
+
+func RecordDeletedResources(provider string, account string, region string, kind string, count int) {
+	deletedResources.WithLabelValues(provider, account, region, kind).Add(float64(count))
+}
+
+func RecordFailedResources(provider string, account string, region string, kind string, count int) {
+	failedResources.WithLabelValues(provider, account, region, kind).Add(float64(count))
+}
+
+func RecordStuckResources(provider string, account string, region string, kind string, count int) {
+	stuckResources.WithLabelValues(provider, account, region, kind).Set(float64(count))
+}
+
+func RecordCycle(provider string, account string, region string, duration time.Duration, success bool) {
+	cycleDuration.WithLabelValues(provider, account, region).Observe(duration.Seconds())
+	if success {
+		lastSuccessfulCycle.WithLabelValues(provider, account, region).SetToCurrentTime()
+	}
+}
@@ -12,23 +12,18 @@ type MinioBucket struct {
 	ObjectsInfos []minio.ObjectInfo
 }
 
-func listBuckets(bucketApi *minio.Client, tagName string, region string, withTags bool) []MinioBucket {
-	buckets, err := bucketApi.ListBuckets(context.TODO())
+func listBuckets(bucketApi *minio.Client, ctx context.Context, tagName string, region string, withTags bool) []MinioBucket {
+	buckets, err := bucketApi.ListBuckets(ctx)
 	if err != nil {
 		log.Errorf("Can't list bucket for region %s: %s", region, err.Error())
 		return []MinioBucket{}
 	}
 
-	bucketLimit := 50
-	if len(buckets) < bucketLimit {
-		bucketLimit = len(buckets)
-	}
-
 	scwBuckets := []MinioBucket{}
-	for _, bucket := range buckets[:bucketLimit] {
+	for _, bucket := range buckets {
 		essentialTags := EssentialTags{}
 		if withTags {
-			bucketTags := listBucketTags(bucketApi, context.TODO(), bucket.Name)
+			bucketTags := listBucketTags(bucketApi, ctx, bucket.Name)
 			essentialTags = GetEssentialTags(bucketTags, tagName)
 		}
 		creationDate, _ := time.Parse(time.RFC3339, bucket.CreationDate.Format(time.RFC3339))
@@ -73,13 +68,13 @@ func ListBucketObjects(bucketApi *minio.Client, ctx context.Context, bucketName
 	return objectsInfos
 }
 
-func GetExpiredBuckets(bucketApi *minio.Client, tagName string, region string, tagValue string, disableTTLCheck bool) []MinioBucket {
-	buckets := listBuckets(bucketApi, tagName, region, true)
+func GetExpiredBuckets(bucketApi *minio.Client, ctx context.Context, tagName string, region string, tagValue string, disableTTLCheck bool) []MinioBucket {
+	buckets := listBuckets(bucketApi, ctx, tagName, region, true)
 
 	expiredBuckets := []MinioBucket{}
 	for _, bucket := range buckets {
 		if bucket.IsResourceExpired(tagValue, disableTTLCheck) || (len(bucket.ObjectsInfos) == 0 && time.Now().UTC().After(bucket.CreationDate.Add(4*time.Hour)) && bucket.TTL == -1) {
-			objectsInfos := ListBucketObjects(bucketApi, context.TODO(), bucket.Identifier)
+			objectsInfos := ListBucketObjects(bucketApi, ctx, bucket.Identifier)
 			bucket.ObjectsInfos = objectsInfos
 			expiredBuckets = append(expiredBuckets, bucket)
 		}
@@ -88,8 +83,8 @@ func GetExpiredBuckets(bucketApi *minio.Client, tagName string, region string, t
 	return expiredBuckets
 }
 
-func GetUnusedBuckets(bucketApi *minio.Client, tagName string, region string, isDestroyingCommand bool) []MinioBucket {
-	buckets := listBuckets(bucketApi, tagName, region, false)
+func GetUnusedBuckets(bucketApi *minio.Client, ctx context.Context, tagName string, region string, isDestroyingCommand bool) []MinioBucket {
+	buckets := listBuckets(bucketApi, ctx, tagName, region, false)
 
 	expiredBuckets := []MinioBucket{}
 	for _, bucket := range buckets {
@@ -101,9 +96,9 @@ func GetUnusedBuckets(bucketApi *minio.Client, tagName string, region string, is
 	return expiredBuckets
 }
 
-func EmptyBucket(bucketApi *minio.Client, bucketName string, objects []minio.ObjectInfo) {
+func EmptyBucket(bucketApi *minio.Client, ctx context.Context, bucketName string, objects []minio.ObjectInfo) {
 	for _, object := range objects {
-		err := bucketApi.RemoveObject(context.TODO(), bucketName, object.Key, minio.RemoveObjectOptions{ForceDelete: true})
+		err := bucketApi.RemoveObject(ctx, bucketName, object.Key, minio.RemoveObjectOptions{ForceDelete: true})
 		if err != nil {
 			log.Errorf("Can't delete object %s for bucket %s: %s", object.Key, bucketName, err.Error())
 		}
@@ -111,8 +106,8 @@ func EmptyBucket(bucketApi *minio.Client, bucketName string, objects []minio.Obj
 
 }
 
-func DeleteBucket(bucketApi *minio.Client, bucket MinioBucket, region string) {
-	err := bucketApi.RemoveBucket(context.Background(), bucket.Identifier)
+func DeleteBucket(bucketApi *minio.Client, ctx context.Context, bucket MinioBucket, region string) {
+	err := bucketApi.RemoveBucket(ctx, bucket.Identifier)
 	if err != nil {
 		log.Errorf("Can't delete bucket %s: %s", bucket.Identifier, err.Error())
 	} else {
@@ -0,0 +1,163 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+var notifyHTTPClient = &http.Client{Timeout: notifyTimeout}
+
+func postJSON(ctx context.Context, url string, payload interface{}) error {
+	content, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(content))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := notifyHTTPClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(response.Body, 512))
+		return fmt.Errorf("%s answered %s: %s", url, response.Status, strings.TrimSpace(string(body)))
+	}
+
+	return nil
+}
+
+// SlackNotifier posts to a Slack incoming webhook.
+type SlackNotifier struct {
+	WebhookURL string
+}
+
+func (notifier SlackNotifier) Name() string {
+	return "slack"
+}
+
+func (notifier SlackNotifier) Notify(ctx context.Context, notifications []Notification) error {
+	text := notificationsText(notifications)
+	if len(notifications) > 1 {
+		text = fmt.Sprintf("*Pleco: %s*\n%s", notificationsSummary(notifications), text)
+	}
+
+	return postJSON(ctx, notifier.WebhookURL, map[string]string{"text": text})
+}
+
+// WebhookNotifier posts the notifications as JSON: {"source": "pleco", "notifications": [...]}.
+type WebhookNotifier struct {
+	URL string
+}
+
+func (notifier WebhookNotifier) Name() string {
+	return "webhook"
+}
+
+func (notifier WebhookNotifier) Notify(ctx context.Context, notifications []Notification) error {
+	return postJSON(ctx, notifier.URL, struct {
+		Source        string         `json:"source"`
+		Notifications []Notification `json:"notifications"`
+	}{Source: "pleco", Notifications: notifications})
+}
+
+// SMTPNotifier sends plain text emails, authenticated when a username is set. The owners of the resources expiring
+// soon are emailed as well, when their owner tag is an email address.
+type SMTPNotifier struct {
+	// Server is the SMTP server address, host:port
+	Server   string
+	From     string
+	To       []string
+	Username string
+	Password string
+}
+
+func (notifier SMTPNotifier) Name() string {
+	return "smtp"
+}
+
+func (notifier SMTPNotifier) Notify(ctx context.Context, notifications []Notification) error {
+	var errs []string
+	if len(notifier.To) > 0 {
+		if err := notifier.send(ctx, notifier.To, notifications); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	var owners []string
+	ownersNotifications := make(map[string][]Notification)
+	for _, notification := range notifications {
+		if notification.Owner == "" || !strings.Contains(notification.Owner, "@") {
+			continue
+		}
+		if _, ok := ownersNotifications[notification.Owner]; !ok {
+			owners = append(owners, notification.Owner)
+		}
+		ownersNotifications[notification.Owner] = append(ownersNotifications[notification.Owner], notification)
+	}
+	for _, owner := range owners {
+		if err := notifier.send(ctx, []string{owner}, ownersNotifications[owner]); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", owner, err.Error()))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+func (notifier SMTPNotifier) send(ctx context.Context, to []string, notifications []Notification) error {
+	host, _, err := net.SplitHostPort(notifier.Server)
+	if err != nil {
+		return fmt.Errorf("invalid SMTP server %s: %s", notifier.Server, err.Error())
+	}
+
+	var auth smtp.Auth
+	if notifier.Username != "" {
+		auth = smtp.PlainAuth("", notifier.Username, notifier.Password, host)
+	}
+
+	// the subject only holds counts, the notifications hold errors and tag values which are kept in the body
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "From: %s\r\n", headerValue(notifier.From))
+	fmt.Fprintf(&message, "To: %s\r\n", headerValue(strings.Join(to, ", ")))
+	fmt.Fprintf(&message, "Subject: %s\r\n", headerValue("Pleco: "+notificationsSummary(notifications)))
+	fmt.Fprintf(&message, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	message.WriteString("MIME-Version: 1.0\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n")
+	message.WriteString(strings.ReplaceAll(notificationsText(notifications), "\n", "\r\n"))
+	message.WriteString("\r\n")
+
+	// smtp.SendMail doesn't take a context, the send is abandoned once the context is done
+	result := make(chan error, 1)
+	go func() {
+		result <- smtp.SendMail(notifier.Server, auth, notifier.From, to, message.Bytes())
+	}()
+
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// headerValue replaces the line breaks of a mail header value, which would end the header and start new ones.
+func headerValue(value string) string {
+	return strings.NewReplacer("\r\n", " ", "\r", " ", "\n", " ").Replace(value)
+}
@@ -0,0 +1,231 @@
+package common
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func testNotifications() []Notification {
+	expiresAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	return []Notification{
+		{Event: EventDeleted, Provider: "aws", Location: "eu-west-3", Kind: "vpc", Identifier: "vpc-1"},
+		{Event: EventExpiringSoon, Provider: "aws", Location: "eu-west-3", Kind: "s3", Identifier: "bucket-1", Owner: "dev@example.com", ExpiresAt: &expiresAt},
+		{Event: EventFailed, Provider: "aws", Kind: "rds", Identifier: "db-1", Attempts: 3, Error: "denied\r\nBcc: evil@example.com"},
+	}
+}
+
+// recordingServer answers the requests with status and records their bodies.
+func recordingServer(t *testing.T, status int) (*httptest.Server, *[]string) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodPost || request.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("expected a JSON POST, got %s %s", request.Method, request.Header.Get("Content-Type"))
+		}
+		body, _ := io.ReadAll(request.Body)
+		bodies = append(bodies, string(body))
+		writer.WriteHeader(status)
+		_, _ = writer.Write([]byte("invalid_payload"))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &bodies
+}
+
+func TestSlackNotifier(t *testing.T) {
+	server, bodies := recordingServer(t, http.StatusOK)
+
+	if err := (SlackNotifier{WebhookURL: server.URL}).Notify(context.Background(), testNotifications()); err != nil {
+		t.Fatalf("Notify returned %s", err.Error())
+	}
+	if len(*bodies) != 1 {
+		t.Fatalf("expected a single post, got %d", len(*bodies))
+	}
+
+	var payload map[string]string
+	if err := json.Unmarshal([]byte((*bodies)[0]), &payload); err != nil {
+		t.Fatalf("invalid payload %s: %s", (*bodies)[0], err.Error())
+	}
+	if !strings.HasPrefix(payload["text"], "*Pleco: 1 deleted, 1 failed, 1 expiring-soon*\n") {
+		t.Errorf("the digest should start with its summary, got %q", payload["text"])
+	}
+	for _, line := range []string{"vpc vpc-1 (aws eu-west-3) has been deleted", "s3 bucket-1 (aws eu-west-3) will be deleted after 2024-01-02T03:04:05Z, owned by dev@example.com"} {
+		if !strings.Contains(payload["text"], line) {
+			t.Errorf("the text should contain %q, got %q", line, payload["text"])
+		}
+	}
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	server, bodies := recordingServer(t, http.StatusAccepted)
+
+	if err := (WebhookNotifier{URL: server.URL}).Notify(context.Background(), testNotifications()); err != nil {
+		t.Fatalf("Notify returned %s", err.Error())
+	}
+
+	var payload struct {
+		Source        string         `json:"source"`
+		Notifications []Notification `json:"notifications"`
+	}
+	if err := json.Unmarshal([]byte((*bodies)[0]), &payload); err != nil {
+		t.Fatalf("invalid payload %s: %s", (*bodies)[0], err.Error())
+	}
+	if payload.Source != "pleco" || len(payload.Notifications) != 3 {
+		t.Fatalf("unexpected payload %s", (*bodies)[0])
+	}
+	if failed := payload.Notifications[2]; failed.Identifier != "db-1" || failed.Attempts != 3 || failed.Error != "denied\r\nBcc: evil@example.com" {
+		t.Errorf("the notifications should be sent as is, got %+v", failed)
+	}
+}
+
+func TestHTTPNotifiersErrors(t *testing.T) {
+	server, _ := recordingServer(t, http.StatusBadRequest)
+
+	notifiers := []Notifier{SlackNotifier{WebhookURL: server.URL}, WebhookNotifier{URL: server.URL}}
+	for _, notifier := range notifiers {
+		err := notifier.Notify(context.Background(), testNotifications())
+		if err == nil || !strings.Contains(err.Error(), "400 Bad Request") || !strings.Contains(err.Error(), "invalid_payload") {
+			t.Errorf("%s: expected the status and the body in the error, got %v", notifier.Name(), err)
+		}
+	}
+}
+
+// smtpServer is a minimal SMTP server recording the recipients and the data of the mails it receives.
+type smtpServer struct {
+	listener net.Listener
+	mutex    sync.Mutex
+	mails    []smtpMail
+}
+
+type smtpMail struct {
+	to   []string
+	data string
+}
+
+func newSMTPServer(t *testing.T) *smtpServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("can't listen: %s", err.Error())
+	}
+	server := &smtpServer{listener: listener}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+
+	return server
+}
+
+func (server *smtpServer) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { _, _ = conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 localhost")
+	var mail smtpMail
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			mail.to = append(mail.to, strings.Trim(strings.TrimSpace(line)[len("RCPT TO:"):], "<>"))
+			reply("250 OK")
+		case command == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				dataLine, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dataLine == ".\r\n" {
+					break
+				}
+				data.WriteString(dataLine)
+			}
+			mail.data = data.String()
+			server.mutex.Lock()
+			server.mails = append(server.mails, mail)
+			server.mutex.Unlock()
+			mail = smtpMail{}
+			reply("250 OK")
+		case command == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func TestSMTPNotifier(t *testing.T) {
+	server := newSMTPServer(t)
+	notifier := SMTPNotifier{Server: server.listener.Addr().String(), From: "pleco@example.com", To: []string{"ops@example.com"}}
+
+	if err := notifier.Notify(context.Background(), testNotifications()); err != nil {
+		t.Fatalf("Notify returned %s", err.Error())
+	}
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	if len(server.mails) != 2 {
+		t.Fatalf("expected a mail to the recipients and one to the owner, got %d", len(server.mails))
+	}
+	if to := server.mails[0].to; len(to) != 1 || to[0] != "ops@example.com" {
+		t.Errorf("the digest should be sent to ops@example.com, got %v", to)
+	}
+	if owner := server.mails[1]; len(owner.to) != 1 || owner.to[0] != "dev@example.com" || strings.Contains(owner.data, "db-1") {
+		t.Errorf("the owner should only receive the notifications of their resources, got %v: %s", owner.to, owner.data)
+	}
+
+	headers, body, _ := strings.Cut(server.mails[0].data, "\r\n\r\n")
+	if !strings.Contains(headers, "\r\nSubject: Pleco: 1 deleted, 1 failed, 1 expiring-soon\r\n") {
+		t.Errorf("unexpected headers %q", headers)
+	}
+	if strings.Contains(headers, "Bcc:") {
+		t.Errorf("the notifications shouldn't reach the headers, got %q", headers)
+	}
+	if !strings.Contains(body, "rds db-1 (aws) can't be deleted after 3 attempts: denied") {
+		t.Errorf("the body should hold the notifications, got %q", body)
+	}
+}
+
+func TestSMTPNotifierUnreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("can't listen: %s", err.Error())
+	}
+	address := listener.Addr().String()
+	_ = listener.Close()
+
+	notifier := SMTPNotifier{Server: address, From: "pleco@example.com", To: []string{"ops@example.com"}}
+	if err := notifier.Notify(context.Background(), testNotifications()[:1]); err == nil {
+		t.Error("expected an error when the SMTP server can't be reached")
+	}
+}
+
+func TestHeaderValue(t *testing.T) {
+	if value := headerValue("Pleco: 1 failed\r\nBcc: evil@example.com\nX-Other: 1\rend"); value != "Pleco: 1 failed Bcc: evil@example.com X-Other: 1 end" {
+		t.Errorf("the line breaks should be replaced, got %q", value)
+	}
+}
@@ -0,0 +1,157 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Events notifiers are called for.
+const (
+	EventExpiringSoon = "expiring-soon"
+	EventDeleted      = "deleted"
+	EventFailed       = "failed"
+)
+
+const notifyTimeout = 30 * time.Second
+
+// Notification is an event about a single resource.
+type Notification struct {
+	Event       string     `json:"event"`
+	Provider    string     `json:"provider"`
+	Account     string     `json:"account,omitempty"`
+	Location    string     `json:"location,omitempty"`
+	Kind        string     `json:"kind"`
+	Identifier  string     `json:"identifier"`
+	Description string     `json:"description,omitempty"`
+	Owner       string     `json:"owner,omitempty"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
+	Attempts    int        `json:"attempts,omitempty"`
+	Error       string     `json:"error,omitempty"`
+}
+
+// String returns a single line message describing the notification.
+func (notification Notification) String() string {
+	where := notification.Provider
+	if notification.Location != "" {
+		where += " " + notification.Location
+	}
+	if notification.Account != "" {
+		where += " of " + notification.Account
+	}
+	resource := fmt.Sprintf("%s %s (%s)", notification.Kind, notification.Identifier, where)
+
+	switch notification.Event {
+	case EventExpiringSoon:
+		message := fmt.Sprintf("%s will be deleted after %s", resource, notification.ExpiresAt.Format(time.RFC3339))
+		if notification.Owner != "" {
+			message += ", owned by " + notification.Owner
+		}
+		return message
+	case EventDeleted:
+		return fmt.Sprintf("%s has been deleted", resource)
+	case EventFailed:
+		return fmt.Sprintf("%s can't be deleted after %d attempts: %s", resource, notification.Attempts, notification.Error)
+	default:
+		return resource
+	}
+}
+
+// Notifier sends notifications to a sink. A single call holds every notification of a cycle in digest mode,
+// a single notification otherwise.
+type Notifier interface {
+	Name() string
+	Notify(ctx context.Context, notifications []Notification) error
+}
+
+type NotifyOptions struct {
+	// WarningWindow is how long before their expiration resources are notified as expiring soon
+	WarningWindow time.Duration
+	// FailuresAfter is the number of consecutive failed deletion attempts after which a resource is notified
+	FailuresAfter int
+	// Digest sends the notifications of a cycle at once
+	Digest bool
+	// OwnerTag is the tag holding the owner of a resource, notified when it expires soon
+	OwnerTag string
+	// StampWarned tags the resources expiring soon with the WarnedAtTag tag
+	StampWarned bool
+}
+
+var notifiers = struct {
+	sync.RWMutex
+	notifiers []Notifier
+	options   NotifyOptions
+}{}
+
+// SetNotifiers enables notifications, they are disabled without notifier. Warned resources can still be stamped.
+func SetNotifiers(newNotifiers []Notifier, options NotifyOptions) {
+	notifiers.Lock()
+	defer notifiers.Unlock()
+
+	notifiers.notifiers = newNotifiers
+	notifiers.options = options
+}
+
+func getNotifiers() ([]Notifier, NotifyOptions) {
+	notifiers.RLock()
+	defer notifiers.RUnlock()
+
+	return notifiers.notifiers, notifiers.options
+}
+
+// sendNotifications sends the notifications of a cycle to every notifier.
+func sendNotifications(notifications []Notification) {
+	sinks, options := getNotifiers()
+	if len(sinks) == 0 || len(notifications) == 0 {
+		return
+	}
+
+	batches := [][]Notification{notifications}
+	if !options.Digest {
+		batches = nil
+		for _, notification := range notifications {
+			batches = append(batches, []Notification{notification})
+		}
+	}
+
+	for _, notifier := range sinks {
+		for _, batch := range batches {
+			ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+			if err := notifier.Notify(ctx, batch); err != nil {
+				log.Errorf("Can't send %d notifications to %s: %s", len(batch), notifier.Name(), err.Error())
+			}
+			cancel()
+		}
+	}
+}
+
+// notificationsSummary counts the notifications of each event, eg. "2 deleted, 1 expiring-soon".
+func notificationsSummary(notifications []Notification) string {
+	counts := make(map[string]int)
+	for _, notification := range notifications {
+		counts[notification.Event]++
+	}
+
+	var parts []string
+	for _, event := range []string{EventDeleted, EventFailed, EventExpiringSoon} {
+		if counts[event] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[event], event))
+		}
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// notificationsText returns a message listing the notifications, one per line.
+func notificationsText(notifications []Notification) string {
+	lines := make([]string, 0, len(notifications))
+	for _, notification := range notifications {
+		lines = append(lines, notification.String())
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,302 @@
+package common
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	planVersion = 1
+
+	planSignatureHMAC   = "hmac-sha256"
+	planSignatureSHA256 = "sha256"
+)
+
+// PlanEntry is a resource the plan command selected for deletion. Its tags and dates are compared on apply to
+// detect a resource changed since the plan.
+type PlanEntry struct {
+	Provider     string     `json:"provider"`
+	Account      string     `json:"account,omitempty"`
+	Location     string     `json:"location,omitempty"`
+	Kind         string     `json:"kind"`
+	Identifier   string     `json:"identifier"`
+	Description  string     `json:"description,omitempty"`
+	TagValue     string     `json:"tagValue,omitempty"`
+	TTL          int64      `json:"ttl"`
+	CreationDate *time.Time `json:"creationDate,omitempty"`
+	ExpiresAt    *time.Time `json:"expiresAt,omitempty"`
+	Rule         string     `json:"rule"`
+}
+
+func (entry PlanEntry) key() string {
+	return strings.Join([]string{entry.Provider, entry.Account, entry.Location, entry.Kind, entry.Identifier}, "/")
+}
+
+// changes returns what differs between the planned resource and the same resource listed again.
+func (entry PlanEntry) changes(current PlanEntry) []string {
+	var changes []string
+	if entry.TagValue != current.TagValue {
+		changes = append(changes, fmt.Sprintf("tag value %q is now %q", entry.TagValue, current.TagValue))
+	}
+	if entry.TTL != current.TTL {
+		changes = append(changes, fmt.Sprintf("ttl %d is now %d", entry.TTL, current.TTL))
+	}
+	if !sameDate(entry.CreationDate, current.CreationDate) {
+		changes = append(changes, "creation date changed")
+	}
+	if !sameDate(entry.ExpiresAt, current.ExpiresAt) {
+		changes = append(changes, "expiration date changed")
+	}
+
+	return changes
+}
+
+func sameDate(a *time.Time, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return a.Equal(*b)
+}
+
+// Plan is the list of resources written by the plan command. The apply command only deletes them, once it checked
+// the plan signature, its age, and that none of them changed.
+type Plan struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"createdAt"`
+	Providers []string  `json:"providers"`
+	// Flags are the selection flags given to the plan command, set again on apply
+	Flags     map[string]string `json:"flags,omitempty"`
+	Resources []PlanEntry       `json:"resources"`
+	Signature string            `json:"signature,omitempty"`
+
+	mutex   sync.Mutex
+	entries map[string]PlanEntry
+}
+
+func NewPlan(providers []string, flags map[string]string) *Plan {
+	return &Plan{
+		Version:   planVersion,
+		CreatedAt: time.Now().UTC(),
+		Providers: providers,
+		Flags:     flags,
+		Resources: []PlanEntry{},
+	}
+}
+
+// add records the resources of a dry run cycle report.
+func (plan *Plan) add(report *Report) {
+	plan.mutex.Lock()
+	defer plan.mutex.Unlock()
+
+	for _, resource := range report.Resources {
+		plan.Resources = append(plan.Resources, PlanEntry{
+			Provider:     resource.Provider,
+			Account:      resource.Account,
+			Location:     report.Location,
+			Kind:         resource.Kind,
+			Identifier:   resource.Identifier,
+			Description:  resource.Description,
+			TagValue:     resource.TagValue,
+			TTL:          resource.TTL,
+			CreationDate: resource.CreationDate,
+			ExpiresAt:    resource.ExpiresAt,
+			Rule:         resource.Rule,
+		})
+	}
+}
+
+func (plan *Plan) sort() {
+	sort.SliceStable(plan.Resources, func(i, j int) bool {
+		return plan.Resources[i].key() < plan.Resources[j].key()
+	})
+}
+
+// Check returns whether a resource eligible to deletion has been planned, and what changed since the plan, eg. a
+// resource re-tagged while the apply confirmation was pending. Only planned resources without change are deleted.
+func (plan *Plan) Check(provider string, account string, location string, kind string, resource CloudProviderResource) (bool, []string) {
+	plan.mutex.Lock()
+	defer plan.mutex.Unlock()
+
+	if plan.entries == nil {
+		plan.entries = make(map[string]PlanEntry)
+		for _, entry := range plan.Resources {
+			plan.entries[entry.key()] = entry
+		}
+	}
+
+	current := PlanEntry{Provider: provider, Account: account, Location: location, Kind: kind, Identifier: resource.Identifier,
+		TagValue: resource.Tag, TTL: resource.TTL}
+	if !resource.CreationDate.IsZero() {
+		creationDate := resource.CreationDate.UTC()
+		current.CreationDate = &creationDate
+	}
+	if expiresAt, ok := resource.ExpirationDate(); ok {
+		current.ExpiresAt = &expiresAt
+	}
+
+	entry, ok := plan.entries[current.key()]
+	if !ok {
+		return false, nil
+	}
+
+	return true, entry.changes(current)
+}
+
+// Diff compares the plan with the resources currently eligible to deletion, and returns why each planned resource
+// can't be deleted anymore. Resources eligible since the plan are ignored, as they won't be deleted.
+func (plan *Plan) Diff(current *Plan) []string {
+	currentEntries := make(map[string]PlanEntry)
+	for _, entry := range current.Resources {
+		currentEntries[entry.key()] = entry
+	}
+
+	var differences []string
+	for _, entry := range plan.Resources {
+		currentEntry, ok := currentEntries[entry.key()]
+		if !ok {
+			differences = append(differences, fmt.Sprintf("%s %s: not found or not eligible to deletion anymore", entry.Kind, entry.key()))
+			continue
+		}
+
+		for _, change := range entry.changes(currentEntry) {
+			differences = append(differences, fmt.Sprintf("%s %s: %s", entry.Kind, entry.key(), change))
+		}
+	}
+
+	return differences
+}
+
+// PrintTable writes the planned resources as a table.
+func (plan *Plan) PrintTable(writer io.Writer) {
+	table := tabwriter.NewWriter(writer, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(table, "PROVIDER\tACCOUNT\tLOCATION\tKIND\tIDENTIFIER\tRULE\tEXPIRED AT")
+	for _, entry := range plan.Resources {
+		expiredAt := "-"
+		if entry.ExpiresAt != nil {
+			expiredAt = entry.ExpiresAt.Format(time.RFC3339)
+		}
+		_, _ = fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", entry.Provider, orDash(entry.Account), orDash(entry.Location),
+			entry.Kind, entry.Identifier, entry.Rule, expiredAt)
+	}
+	_ = table.Flush()
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+
+	return value
+}
+
+// digest returns the signature of the plan content: a HMAC when a key is given, a checksum otherwise.
+func (plan *Plan) digest(key string) (string, error) {
+	signature := plan.Signature
+	plan.Signature = ""
+	content, err := json.Marshal(plan)
+	plan.Signature = signature
+	if err != nil {
+		return "", err
+	}
+
+	if key == "" {
+		sum := sha256.Sum256(content)
+		return planSignatureSHA256 + ":" + hex.EncodeToString(sum[:]), nil
+	}
+
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write(content)
+	return planSignatureHMAC + ":" + hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// Save signs the plan with the key, or only checksums it without key, and writes it to a file.
+func (plan *Plan) Save(path string, key string) error {
+	plan.sort()
+
+	signature, err := plan.digest(key)
+	if err != nil {
+		return err
+	}
+	plan.Signature = signature
+
+	content, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, content, 0600); err != nil {
+		return fmt.Errorf("can't write plan file %s: %s", path, err.Error())
+	}
+
+	return nil
+}
+
+// LoadPlan reads a plan file and checks its signature and its age.
+func LoadPlan(path string, key string, maxAge time.Duration) (*Plan, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't read plan file %s: %s", path, err.Error())
+	}
+
+	plan := &Plan{}
+	if err := json.Unmarshal(content, plan); err != nil {
+		return nil, fmt.Errorf("can't parse plan file %s: %s", path, err.Error())
+	}
+
+	if plan.Version != planVersion {
+		return nil, fmt.Errorf("plan file %s version %d is not supported, expected %d", path, plan.Version, planVersion)
+	}
+
+	if key == "" && strings.HasPrefix(plan.Signature, planSignatureHMAC+":") {
+		return nil, fmt.Errorf("plan file %s is signed, its key is required to apply it", path)
+	}
+	if key != "" && !strings.HasPrefix(plan.Signature, planSignatureHMAC+":") {
+		return nil, fmt.Errorf("plan file %s is not signed", path)
+	}
+
+	signature, err := plan.digest(key)
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal([]byte(signature), []byte(plan.Signature)) {
+		return nil, fmt.Errorf("plan file %s has been modified, or signed with another key", path)
+	}
+
+	if age := time.Since(plan.CreatedAt); maxAge > 0 && age > maxAge {
+		return nil, fmt.Errorf("plan file %s is stale: created %s ago, the maximum is %s", path, age.Round(time.Second), maxAge)
+	}
+
+	return plan, nil
+}
+
+var (
+	// planRecorder collects the resources of every dry run cycle, during the plan command and the apply verification
+	planRecorder *Plan
+	// appliedPlan restricts deletions to the planned resources
+	appliedPlan *Plan
+)
+
+func SetPlanRecorder(plan *Plan) {
+	planRecorder = plan
+}
+
+func SetAppliedPlan(plan *Plan) {
+	appliedPlan = plan
+}
+
+func recordPlan(report *Report) {
+	if planRecorder != nil && report.DryRun {
+		planRecorder.add(report)
+	}
+}
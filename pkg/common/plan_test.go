@@ -0,0 +1,149 @@
+package common
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testPlan() *Plan {
+	creationDate := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	expiresAt := creationDate.Add(time.Hour)
+	plan := NewPlan([]string{"aws"}, map[string]string{"tag-value": "review-42"})
+	plan.Resources = []PlanEntry{
+		{Provider: "aws", Account: "123", Location: "eu-west-3", Kind: "vpc", Identifier: "vpc-1", TagValue: "review-42", TTL: 3600, CreationDate: &creationDate, ExpiresAt: &expiresAt},
+		{Provider: "aws", Account: "123", Location: "eu-west-3", Kind: "ec2-instance", Identifier: "i-1", TagValue: "review-42", TTL: 3600, CreationDate: &creationDate, ExpiresAt: &expiresAt},
+	}
+
+	return plan
+}
+
+func TestPlanDigest(t *testing.T) {
+	plan := testPlan()
+
+	checksum, err := plan.digest("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(checksum, planSignatureSHA256+":") {
+		t.Errorf("a plan without key should be checksummed, got %s", checksum)
+	}
+
+	signature, err := plan.digest("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(signature, planSignatureHMAC+":") {
+		t.Errorf("a plan with key should be signed, got %s", signature)
+	}
+	if otherSignature, _ := plan.digest("other"); otherSignature == signature {
+		t.Error("signatures with different keys should differ")
+	}
+
+	plan.Signature = signature
+	if again, _ := plan.digest("secret"); again != signature {
+		t.Error("the digest shouldn't depend on the current signature")
+	}
+
+	plan.Resources[0].TTL = 7200
+	if changed, _ := plan.digest("secret"); changed == signature {
+		t.Error("the digest should change with the plan content")
+	}
+}
+
+func TestPlanSignature(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.plan")
+	if err := testPlan().Save(path, "secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err := LoadPlan(path, "secret", time.Hour)
+	if err != nil {
+		t.Fatalf("a signed plan should be loaded with its key: %s", err.Error())
+	}
+	if plan.Resources[0].Kind != "ec2-instance" {
+		t.Errorf("saved resources should be sorted, got %s first", plan.Resources[0].Kind)
+	}
+
+	if _, err := LoadPlan(path, "other", time.Hour); err == nil {
+		t.Error("a plan signed with another key should be refused")
+	}
+	if _, err := LoadPlan(path, "", time.Hour); err == nil {
+		t.Error("a signed plan should be refused without key")
+	}
+
+	content, _ := os.ReadFile(path)
+	var tampered map[string]interface{}
+	_ = json.Unmarshal(content, &tampered)
+	tampered["resources"].([]interface{})[0].(map[string]interface{})["identifier"] = "i-2"
+	content, _ = json.Marshal(tampered)
+	if err := os.WriteFile(path, content, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadPlan(path, "secret", time.Hour); err == nil || !strings.Contains(err.Error(), "modified") {
+		t.Errorf("a modified plan should be refused, got %v", err)
+	}
+}
+
+func TestPlanChecksum(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.plan")
+	plan := testPlan()
+	plan.CreatedAt = time.Now().Add(-2 * time.Hour)
+	if err := plan.Save(path, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadPlan(path, "", 0); err != nil {
+		t.Errorf("a checksummed plan should be loaded without key: %s", err.Error())
+	}
+	if _, err := LoadPlan(path, "secret", 0); err == nil {
+		t.Error("a checksummed plan should be refused when a key is given")
+	}
+	if _, err := LoadPlan(path, "", time.Hour); err == nil || !strings.Contains(err.Error(), "stale") {
+		t.Errorf("a plan older than the maximum age should be refused, got %v", err)
+	}
+}
+
+func TestPlanDiff(t *testing.T) {
+	plan := testPlan()
+
+	current := testPlan()
+	if differences := plan.Diff(current); len(differences) != 0 {
+		t.Errorf("unchanged resources shouldn't differ, got %v", differences)
+	}
+
+	current = testPlan()
+	current.Resources[0].TagValue = "review-43"
+	current.Resources[0].TTL = 7200
+	current.Resources = append(current.Resources[:1], PlanEntry{Provider: "aws", Account: "123", Location: "eu-west-3", Kind: "s3", Identifier: "bucket"})
+	expected := []string{
+		`vpc aws/123/eu-west-3/vpc/vpc-1: tag value "review-42" is now "review-43"`,
+		"vpc aws/123/eu-west-3/vpc/vpc-1: ttl 3600 is now 7200",
+		"ec2-instance aws/123/eu-west-3/ec2-instance/i-1: not found or not eligible to deletion anymore",
+	}
+	if differences := plan.Diff(current); !reflect.DeepEqual(differences, expected) {
+		t.Errorf("Diff = %v, expected %v", differences, expected)
+	}
+}
+
+func TestPlanCheck(t *testing.T) {
+	plan := testPlan()
+	entry := plan.Resources[0]
+	resource := CloudProviderResource{Identifier: "vpc-1", Tag: "review-42", TTL: 3600, CreationDate: *entry.CreationDate}
+
+	if planned, changes := plan.Check("aws", "123", "eu-west-3", "vpc", resource); !planned || len(changes) != 0 {
+		t.Errorf("the planned resource should be deleted, got planned %t and changes %v", planned, changes)
+	}
+	if planned, _ := plan.Check("aws", "123", "eu-west-1", "vpc", resource); planned {
+		t.Error("a resource of another location shouldn't be planned")
+	}
+
+	resource.TTL = 86400
+	if planned, changes := plan.Check("aws", "123", "eu-west-3", "vpc", resource); !planned || len(changes) != 2 {
+		t.Errorf("a re-tagged resource should be reported as changed, got planned %t and changes %v", planned, changes)
+	}
+}
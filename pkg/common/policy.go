@@ -0,0 +1,297 @@
+package common
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Policy is the declarative configuration of Pleco, read from the --config file. Command line flags override it.
+type Policy struct {
+	TagName         string                    `json:"tagName,omitempty"`
+	CheckInterval   int64                     `json:"checkInterval,omitempty"`
+	DisableDryRun   bool                      `json:"disableDryRun,omitempty"`
+	DisableTTLCheck bool                      `json:"disableTTLCheck,omitempty"`
+	Providers       map[string]ProviderPolicy `json:"providers,omitempty"`
+	// Rules exclude, or allow list, resources before their expiration is checked. The first matching rule applies.
+	Rules []Rule `json:"rules,omitempty"`
+}
+
+type ProviderPolicy struct {
+	// Regions are the provider locations: regions, or zones for Scaleway
+	Regions []string `json:"regions,omitempty"`
+	// ExcludedRegions are the locations not to clean when they are discovered, glob patterns or regular expressions
+	ExcludedRegions []string `json:"excludedRegions,omitempty"`
+	// Accounts are the accounts to clean, for providers supporting several ones: AWS accounts, GCP projects...
+	Accounts []string `json:"accounts,omitempty"`
+	// Resources are the enabled features, named as their --enable-<name> flag
+	Resources []string `json:"resources,omitempty"`
+	TagName   string   `json:"tagName,omitempty"`
+	// DefaultTTL, in seconds, applies to resources without ttl tag
+	DefaultTTL *int64 `json:"defaultTTL,omitempty"`
+	// Exclusions are identifiers, glob patterns or /regular expressions/ of identifiers, of resources which must never
+	// be deleted
+	Exclusions []string `json:"exclusions,omitempty"`
+	// Overrides are keyed by resource kind
+	Overrides map[string]KindOverride `json:"overrides,omitempty"`
+}
+
+type KindOverride struct {
+	Enabled    *bool    `json:"enabled,omitempty"`
+	DefaultTTL *int64   `json:"defaultTTL,omitempty"`
+	Exclusions []string `json:"exclusions,omitempty"`
+}
+
+// KindPolicy is the policy of a resource kind, once the provider policy and the kind override are merged.
+type KindPolicy struct {
+	Enabled    bool
+	DefaultTTL int64
+	Exclusions []string
+}
+
+func LoadPolicy(filePath string) (*Policy, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("can't read config file %s: %s", filePath, err.Error())
+	}
+
+	policy, err := ParsePolicy(content)
+	if err != nil {
+		return nil, fmt.Errorf("config file %s: %s", filePath, err.Error())
+	}
+
+	return policy, nil
+}
+
+// ParsePolicy parses and validates a YAML, or JSON, policy.
+func ParsePolicy(content []byte) (*Policy, error) {
+	policy := &Policy{}
+	if err := yaml.UnmarshalStrict(content, policy); err != nil {
+		return nil, fmt.Errorf("can't parse policy: %s", err.Error())
+	}
+
+	if errs := policy.Validate(); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid policy:\n  - %s", strings.Join(errs, "\n  - "))
+	}
+
+	return policy, nil
+}
+
+// Validate checks providers, resources and kinds exist in the registry and returns every error found.
+func (policy *Policy) Validate() []string {
+	var errs []string
+
+	if policy.CheckInterval < 0 {
+		errs = append(errs, fmt.Sprintf("checkInterval: must be positive, got %d", policy.CheckInterval))
+	}
+
+	providerNames := make([]string, 0, len(policy.Providers))
+	for name := range policy.Providers {
+		providerNames = append(providerNames, name)
+	}
+	sort.Strings(providerNames)
+
+	for _, name := range providerNames {
+		providerPolicy := policy.Providers[name]
+		field := "providers." + name
+
+		provider, ok := GetProvider(name)
+		if !ok {
+			errs = append(errs, fmt.Sprintf("%s: unknown provider, should be one of %s", field, strings.Join(GetProviderNames(), ", ")))
+			continue
+		}
+
+		features := make(map[string]bool)
+		var featureNames []string
+		for _, feature := range provider.Features {
+			features[feature.Name] = true
+			featureNames = append(featureNames, feature.Name)
+		}
+		for i, resource := range providerPolicy.Resources {
+			if !features[resource] {
+				errs = append(errs, fmt.Sprintf("%s.resources[%d]: unknown resource %q, should be one of %s", field, i, resource, strings.Join(featureNames, ", ")))
+			}
+		}
+
+		if len(providerPolicy.Accounts) > 0 && provider.AccountsFlag == "" {
+			errs = append(errs, fmt.Sprintf("%s.accounts: %s doesn't support several accounts", field, name))
+		}
+		if len(providerPolicy.ExcludedRegions) > 0 && provider.ExcludedLocationsFlag == "" {
+			errs = append(errs, fmt.Sprintf("%s.excludedRegions: %s doesn't discover its locations", field, name))
+		}
+		for i, pattern := range providerPolicy.ExcludedRegions {
+			if err := validatePattern(pattern); err != nil {
+				errs = append(errs, fmt.Sprintf("%s.excludedRegions[%d]: %s", field, i, err.Error()))
+			}
+		}
+
+		errs = append(errs, validateDefaultTTL(field, providerPolicy.DefaultTTL)...)
+		errs = append(errs, validateExclusions(field, providerPolicy.Exclusions)...)
+
+		kinds := make(map[string]bool)
+		var kindNames []string
+		for _, definition := range GetProviderCleaners(name) {
+			kinds[definition.Kind] = true
+			kindNames = append(kindNames, definition.Kind)
+		}
+		for kind, override := range providerPolicy.Overrides {
+			kindField := fmt.Sprintf("%s.overrides.%s", field, kind)
+			if !kinds[kind] {
+				errs = append(errs, fmt.Sprintf("%s: unknown resource kind, should be one of %s", kindField, strings.Join(kindNames, ", ")))
+				continue
+			}
+
+			errs = append(errs, validateDefaultTTL(kindField, override.DefaultTTL)...)
+			errs = append(errs, validateExclusions(kindField, override.Exclusions)...)
+		}
+	}
+
+	for i, rule := range policy.Rules {
+		errs = append(errs, rule.validate(fmt.Sprintf("rules[%d]", i))...)
+	}
+
+	sort.Strings(errs)
+	return errs
+}
+
+func validateDefaultTTL(field string, ttl *int64) []string {
+	if ttl != nil && *ttl < 0 {
+		return []string{fmt.Sprintf("%s.defaultTTL: must be positive, got %d", field, *ttl)}
+	}
+
+	return nil
+}
+
+func validateExclusions(field string, exclusions []string) []string {
+	var errs []string
+	for i, exclusion := range exclusions {
+		if err := validatePattern(exclusion); err != nil {
+			errs = append(errs, fmt.Sprintf("%s.exclusions[%d]: %s", field, i, err.Error()))
+		}
+	}
+
+	return errs
+}
+
+func (policy *Policy) GetProviderPolicy(provider string) ProviderPolicy {
+	if policy == nil {
+		return ProviderPolicy{}
+	}
+
+	return policy.Providers[provider]
+}
+
+func (policy *Policy) GetKindPolicy(provider string, kind string) KindPolicy {
+	providerPolicy := policy.GetProviderPolicy(provider)
+	kindPolicy := KindPolicy{
+		Enabled:    true,
+		DefaultTTL: -1,
+		Exclusions: providerPolicy.Exclusions,
+	}
+
+	if providerPolicy.DefaultTTL != nil {
+		kindPolicy.DefaultTTL = *providerPolicy.DefaultTTL
+	}
+
+	override, ok := providerPolicy.Overrides[kind]
+	if !ok {
+		return kindPolicy
+	}
+
+	if override.Enabled != nil {
+		kindPolicy.Enabled = *override.Enabled
+	}
+	if override.DefaultTTL != nil {
+		kindPolicy.DefaultTTL = *override.DefaultTTL
+	}
+	kindPolicy.Exclusions = append(append([]string{}, kindPolicy.Exclusions...), override.Exclusions...)
+
+	return kindPolicy
+}
+
+func (kindPolicy KindPolicy) IsExcluded(resource CloudProviderResource) bool {
+	return matchesAny(kindPolicy.Exclusions, resource.Identifier)
+}
+
+// Apply sets the default ttl of a resource without ttl tag, extended by its extension tag.
+func (kindPolicy KindPolicy) Apply(resource *CloudProviderResource) {
+	if resource.TTL == -1 && kindPolicy.DefaultTTL >= 0 {
+		resource.TTL = kindPolicy.DefaultTTL
+		if resource.TTL > 0 {
+			resource.TTL = extendTTL(resource.TTL, tagExtension(resource.Tags))
+		}
+	}
+}
+
+var currentPolicy = struct {
+	sync.RWMutex
+	policy *Policy
+	// changed is closed, then replaced, when a policy change requires the engines to be started again
+	changed chan struct{}
+}{changed: make(chan struct{})}
+
+// SetPolicy replaces the current policy. Kinds, default ttls, exclusions and rules apply from the next cycle, while a
+// change of the settings the engines are started with, eg. regions or resources, is signaled by PolicyChanged. The
+// first policy is compared with an empty one, as engines started before it only read the command line flags.
+func SetPolicy(policy *Policy) {
+	currentPolicy.Lock()
+	defer currentPolicy.Unlock()
+
+	previous := currentPolicy.policy
+	currentPolicy.policy = policy
+	if !reflect.DeepEqual(previous.startSettings(), policy.startSettings()) {
+		close(currentPolicy.changed)
+		currentPolicy.changed = make(chan struct{})
+	}
+}
+
+// PolicyChanged returns a channel closed once the policy changes the settings the engines have been started with.
+func PolicyChanged() <-chan struct{} {
+	currentPolicy.RLock()
+	defer currentPolicy.RUnlock()
+
+	return currentPolicy.changed
+}
+
+// startSettings returns the settings read when engines are started, the ones of an empty policy without policy.
+func (policy *Policy) startSettings() interface{} {
+	if policy == nil {
+		policy = &Policy{}
+	}
+
+	type providerSettings struct {
+		Regions, ExcludedRegions, Accounts, Resources []string
+		TagName                                       string
+	}
+	providers := make(map[string]providerSettings)
+	for name, providerPolicy := range policy.Providers {
+		providers[name] = providerSettings{
+			Regions:         providerPolicy.Regions,
+			ExcludedRegions: providerPolicy.ExcludedRegions,
+			Accounts:        providerPolicy.Accounts,
+			Resources:       providerPolicy.Resources,
+			TagName:         providerPolicy.TagName,
+		}
+	}
+
+	return struct {
+		TagName         string
+		CheckInterval   int64
+		DisableDryRun   bool
+		DisableTTLCheck bool
+		Providers       map[string]providerSettings
+	}{policy.TagName, policy.CheckInterval, policy.DisableDryRun, policy.DisableTTLCheck, providers}
+}
+
+// GetPolicy returns the current policy, nil when Pleco runs without config file.
+func GetPolicy() *Policy {
+	currentPolicy.RLock()
+	defer currentPolicy.RUnlock()
+
+	return currentPolicy.policy
+}
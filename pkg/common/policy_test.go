@@ -0,0 +1,49 @@
+package common
+
+import "testing"
+
+func isClosed(channel <-chan struct{}) bool {
+	select {
+	case <-channel:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestSetPolicyChanged(t *testing.T) {
+	SetPolicy(nil)
+	t.Cleanup(func() { SetPolicy(nil) })
+
+	changed := PolicyChanged()
+	SetPolicy(&Policy{Rules: []Rule{{Identifiers: []string{"prod-*"}}}})
+	if isClosed(changed) {
+		t.Fatal("a first policy without start settings shouldn't restart the engines")
+	}
+
+	SetPolicy(&Policy{Providers: map[string]ProviderPolicy{"aws": {Regions: []string{"eu-west-3"}}}})
+	if !isClosed(changed) {
+		t.Fatal("a policy changing the regions should restart the engines")
+	}
+
+	changed = PolicyChanged()
+	if isClosed(changed) {
+		t.Fatal("the changed channel should be replaced once closed")
+	}
+	SetPolicy(&Policy{Providers: map[string]ProviderPolicy{"aws": {Regions: []string{"eu-west-3"}, Exclusions: []string{"vpc-1"}}}})
+	if isClosed(changed) {
+		t.Fatal("exclusions apply from the next cycle, without restarting the engines")
+	}
+}
+
+func TestSetFirstPolicyChanged(t *testing.T) {
+	SetPolicy(nil)
+	t.Cleanup(func() { SetPolicy(nil) })
+
+	// engines started with the flags only, before the policy resource is created
+	changed := PolicyChanged()
+	SetPolicy(&Policy{CheckInterval: 60, Providers: map[string]ProviderPolicy{"aws": {Resources: []string{"s3"}}}})
+	if !isClosed(changed) {
+		t.Fatal("a first policy with start settings should restart the engines")
+	}
+}
@@ -0,0 +1,197 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrDeletionInProgress is returned, wrapped, by cleaners which started an asynchronous deletion step and have to wait
+// for it before going on, eg. EKS node groups before the cluster. The resource is deleted again at the next cycle,
+// without backoff.
+var ErrDeletionInProgress = errors.New("deletion in progress")
+
+func NewDeletionInProgressError(format string, args ...interface{}) error {
+	return fmt.Errorf("%s: %w", fmt.Sprintf(format, args...), ErrDeletionInProgress)
+}
+
+// Deletion status of a resource, as reported.
+const (
+	StatusDeleted    = "deleted"
+	StatusInProgress = "in-progress"
+	StatusFailed     = "failed"
+	StatusBackoff    = "backoff"
+	StatusBlocked    = "blocked"
+	// StatusGone is the outcome of a resource not listed anymore while Pleco didn't manage to delete it, eg. deleted
+	// by someone else or filtered out by its cleaner
+	StatusGone = "gone"
+)
+
+const (
+	minDeletionBackoff = 30 * time.Second
+	maxDeletionBackoff = 30 * time.Minute
+)
+
+// ResourceState tracks an expired resource across cycles, from the first time it is seen expired until it isn't
+// listed anymore. It is persisted by the state store, if any.
+type ResourceState struct {
+	Provider   string     `json:"provider"`
+	Account    string     `json:"account,omitempty"`
+	Scope      Scope      `json:"scope,omitempty"`
+	Location   string     `json:"location,omitempty"`
+	Kind       string     `json:"kind"`
+	Identifier string     `json:"identifier"`
+	FirstSeen  time.Time  `json:"firstSeen"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	Status     string     `json:"status,omitempty"`
+	Attempts   int        `json:"attempts,omitempty"`
+	// Failures is the number of consecutive failed attempts
+	Failures    int        `json:"failures,omitempty"`
+	LastAttempt *time.Time `json:"lastAttempt,omitempty"`
+	NextAttempt *time.Time `json:"nextAttempt,omitempty"`
+	LastError   string     `json:"lastError,omitempty"`
+	// Outcome is set once the resource isn't listed anymore after a deletion attempt
+	Outcome    string     `json:"outcome,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+func (state *ResourceState) canAttempt(now time.Time) bool {
+	return state.NextAttempt == nil || !now.Before(*state.NextAttempt)
+}
+
+// record updates the state with the result of a deletion attempt. Failed deletions are retried with an
+// exponential backoff, in progress ones at the next cycle.
+func (state *ResourceState) record(err error, now time.Time) {
+	state.Attempts++
+	state.LastAttempt = &now
+	state.NextAttempt = nil
+
+	switch {
+	case err == nil:
+		state.Status = StatusDeleted
+		state.LastError = ""
+		state.Failures = 0
+	case errors.Is(err, ErrDeletionInProgress):
+		state.Status = StatusInProgress
+		state.LastError = err.Error()
+		state.Failures = 0
+	default:
+		state.Status = StatusFailed
+		state.LastError = err.Error()
+		state.Failures++
+		nextAttempt := now.Add(deletionBackoff(state.Attempts))
+		state.NextAttempt = &nextAttempt
+	}
+}
+
+func (state *ResourceState) block(dependency string) {
+	state.Status = StatusBlocked
+	state.LastError = fmt.Sprintf("waiting for %s deletion", dependency)
+}
+
+// isStuck tells whether the resource should have been deleted for more than stuckAfter, or failed too many times.
+func (state *ResourceState) isStuck(now time.Time) bool {
+	if state.Outcome != "" {
+		return false
+	}
+	if state.Attempts >= stuckAttempts {
+		return true
+	}
+
+	overdueSince := state.FirstSeen
+	if state.ExpiresAt != nil {
+		overdueSince = *state.ExpiresAt
+	}
+
+	return now.Sub(overdueSince) > stuckAfter
+}
+
+func deletionBackoff(attempts int) time.Duration {
+	backoff := minDeletionBackoff
+	for i := 1; i < attempts && backoff < maxDeletionBackoff; i++ {
+		backoff *= 2
+	}
+
+	if backoff > maxDeletionBackoff {
+		return maxDeletionBackoff
+	}
+
+	return backoff
+}
+
+// progressTracker holds the states of the resources of an engine, keyed by kind then by identifier.
+type progressTracker map[string]map[string]*ResourceState
+
+func (tracker progressTracker) get(engine *Engine, kind string, resource CloudProviderResource, now time.Time) *ResourceState {
+	if tracker[kind] == nil {
+		tracker[kind] = make(map[string]*ResourceState)
+	}
+
+	state, ok := tracker[kind][resource.Identifier]
+	if !ok || state.Outcome != "" {
+		state = &ResourceState{
+			Provider:   engine.options.Provider,
+			Account:    engine.options.Account,
+			Scope:      engine.options.Scope,
+			Location:   engine.options.Location,
+			Kind:       kind,
+			Identifier: resource.Identifier,
+			FirstSeen:  now,
+		}
+		tracker[kind][resource.Identifier] = state
+	}
+
+	if expiresAt, ok := resource.ExpirationDate(); ok {
+		state.ExpiresAt = &expiresAt
+	}
+
+	return state
+}
+
+// prune closes the states of the resources of a kind which aren't expired anymore. The ones still listed, eg. excluded,
+// protected or extended since, are forgotten. The ones not listed anymore are kept, with their outcome, for
+// stateRetention when Pleco tried to delete them: deleted if the last attempt succeeded, gone otherwise.
+func (tracker progressTracker) prune(kind string, listed []CloudProviderResource, expired []CloudProviderResource, now time.Time) {
+	listedIdentifiers := make(map[string]bool)
+	for _, resource := range listed {
+		listedIdentifiers[resource.Identifier] = true
+	}
+	expiredIdentifiers := make(map[string]bool)
+	for _, resource := range expired {
+		expiredIdentifiers[resource.Identifier] = true
+	}
+
+	for identifier, state := range tracker[kind] {
+		switch {
+		case expiredIdentifiers[identifier]:
+			continue
+		case state.Outcome != "":
+			if now.Sub(*state.FinishedAt) > stateRetention {
+				delete(tracker[kind], identifier)
+			}
+		case listedIdentifiers[identifier] || state.Attempts == 0:
+			delete(tracker[kind], identifier)
+		case state.Status == StatusDeleted || state.Status == StatusInProgress:
+			state.Status = StatusDeleted
+			state.Outcome = StatusDeleted
+			state.LastError = ""
+			state.FinishedAt = &now
+		default:
+			state.Status = StatusGone
+			state.Outcome = StatusGone
+			state.FinishedAt = &now
+		}
+	}
+}
+
+// pending returns the states of the resources still not deleted.
+func (tracker progressTracker) pending(kind string) []*ResourceState {
+	var states []*ResourceState
+	for _, state := range tracker[kind] {
+		if state.Outcome == "" {
+			states = append(states, state)
+		}
+	}
+
+	return states
+}
@@ -0,0 +1,101 @@
+package common
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDeletionBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		expected time.Duration
+	}{
+		{attempts: 0, expected: 30 * time.Second},
+		{attempts: 1, expected: 30 * time.Second},
+		{attempts: 2, expected: time.Minute},
+		{attempts: 3, expected: 2 * time.Minute},
+		{attempts: 6, expected: 16 * time.Minute},
+		{attempts: 7, expected: 30 * time.Minute},
+		{attempts: 100, expected: 30 * time.Minute},
+	}
+
+	for _, test := range tests {
+		if backoff := deletionBackoff(test.attempts); backoff != test.expected {
+			t.Errorf("deletionBackoff(%d) = %s, expected %s", test.attempts, backoff, test.expected)
+		}
+	}
+}
+
+func TestResourceStateRecord(t *testing.T) {
+	now := time.Now()
+	state := &ResourceState{}
+
+	state.record(errors.New("boom"), now)
+	if state.Status != StatusFailed || state.Failures != 1 || state.NextAttempt == nil {
+		t.Fatalf("unexpected state after a failure: %+v", state)
+	}
+	if state.canAttempt(now) {
+		t.Error("a failed resource shouldn't be attempted again before its backoff")
+	}
+	if !state.canAttempt(now.Add(minDeletionBackoff)) {
+		t.Error("a failed resource should be attempted again after its backoff")
+	}
+
+	state.record(NewDeletionInProgressError("waiting for %s", "node groups"), now)
+	if state.Status != StatusInProgress || state.Failures != 0 || state.NextAttempt != nil {
+		t.Fatalf("unexpected state after an in progress deletion: %+v", state)
+	}
+
+	state.record(nil, now)
+	if state.Status != StatusDeleted || state.Attempts != 3 || state.LastError != "" {
+		t.Fatalf("unexpected state after a deletion: %+v", state)
+	}
+}
+
+func TestProgressTrackerPrune(t *testing.T) {
+	now := time.Now()
+	old := now.Add(-stateRetention - time.Hour)
+	resource := func(identifier string) CloudProviderResource {
+		return CloudProviderResource{Identifier: identifier}
+	}
+
+	tracker := progressTracker{"kind": {
+		"expired":   {Identifier: "expired", Attempts: 1, Status: StatusFailed},
+		"deleted":   {Identifier: "deleted", Attempts: 1, Status: StatusDeleted},
+		"finishing": {Identifier: "finishing", Attempts: 1, Status: StatusInProgress},
+		"vanished":  {Identifier: "vanished", Attempts: 2, Status: StatusFailed, LastError: "boom"},
+		"excluded":  {Identifier: "excluded", Attempts: 1, Status: StatusFailed},
+		"unseen":    {Identifier: "unseen"},
+		"recent":    {Identifier: "recent", Outcome: StatusDeleted, FinishedAt: &now},
+		"old":       {Identifier: "old", Outcome: StatusDeleted, FinishedAt: &old},
+	}}
+
+	listed := []CloudProviderResource{resource("expired"), resource("excluded")}
+	expired := []CloudProviderResource{resource("expired")}
+	tracker.prune("kind", listed, expired, now)
+
+	expectedOutcomes := map[string]string{
+		"expired":   "",
+		"deleted":   StatusDeleted,
+		"finishing": StatusDeleted,
+		"vanished":  StatusGone,
+		"recent":    StatusDeleted,
+	}
+	if len(tracker["kind"]) != len(expectedOutcomes) {
+		t.Errorf("expected %d states, got %d", len(expectedOutcomes), len(tracker["kind"]))
+	}
+	for identifier, outcome := range expectedOutcomes {
+		state, ok := tracker["kind"][identifier]
+		if !ok {
+			t.Errorf("state of %s shouldn't have been pruned", identifier)
+			continue
+		}
+		if state.Outcome != outcome {
+			t.Errorf("outcome of %s is %q, expected %q", identifier, state.Outcome, outcome)
+		}
+	}
+	if state := tracker["kind"]["vanished"]; state.LastError != "boom" {
+		t.Errorf("last error of a gone resource should be kept, got %q", state.LastError)
+	}
+}
@@ -0,0 +1,215 @@
+package common
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type Scope string
+
+const (
+	RegionScope Scope = "region"
+	ZoneScope   Scope = "zone"
+	GlobalScope Scope = "global"
+)
+
+// Feature is a group of resource kinds enabled with a single "--enable-<name>" flag.
+type Feature struct {
+	Name      string
+	Shorthand string
+	Usage     string
+	Implies   []string
+}
+
+// AllLocations selects every location of a provider, discovered with its API.
+const AllLocations = "all"
+
+type ProviderDefinition struct {
+	Name               string
+	LocationsFlag      string
+	LocationsShorthand string
+	LocationsUsage     string
+	RequiredEnvVars    []string
+	Features           []Feature
+	// AccountsFlag is set by providers able to clean several accounts at once, eg. AWS accounts or GCP projects
+	AccountsFlag  string
+	AccountsUsage string
+	// ExcludedLocationsFlag is set by providers discovering their locations when none or "all" is given
+	ExcludedLocationsFlag string
+}
+
+// CleanerDefinition describes a resource kind a provider knows how to clean. New receives the
+// provider scope (sessions and options of a region, a zone or the global scope) and returns the cleaner.
+type CleanerDefinition struct {
+	Provider    string
+	Feature     string
+	Kind        string
+	Description string
+	Scope       Scope
+	New         func(providerScope interface{}) (Cleaner, error)
+}
+
+type FeatureSet map[string]bool
+
+func (features FeatureSet) IsEnabled(name string) bool {
+	return features[name]
+}
+
+func (features FeatureSet) Enable(name string) {
+	features[name] = true
+}
+
+var (
+	providers          = make(map[string]ProviderDefinition)
+	cleanerDefinitions []CleanerDefinition
+	// dependencies are keyed by provider then by kind
+	dependencies = make(map[string]map[string][]string)
+)
+
+func RegisterProvider(provider ProviderDefinition) {
+	if _, exists := providers[provider.Name]; exists {
+		panic(fmt.Sprintf("provider %s is already registered", provider.Name))
+	}
+
+	providers[provider.Name] = provider
+}
+
+func RegisterCleaner(definition CleanerDefinition) {
+	for _, registered := range cleanerDefinitions {
+		if registered.Provider == definition.Provider && registered.Kind == definition.Kind {
+			panic(fmt.Sprintf("cleaner %s is already registered for provider %s", definition.Kind, definition.Provider))
+		}
+	}
+
+	cleanerDefinitions = append(cleanerDefinitions, definition)
+}
+
+// RegisterDependencies declares the kinds whose expired resources must be deleted before the ones of kind, eg. the
+// VPC children before the VPC itself. Dependencies only apply between cleaners running in the same provider scope.
+func RegisterDependencies(provider string, kind string, dependsOn ...string) {
+	if dependencies[provider] == nil {
+		dependencies[provider] = make(map[string][]string)
+	}
+
+	dependencies[provider][kind] = append(dependencies[provider][kind], dependsOn...)
+}
+
+func GetDependencies(provider string, kind string) []string {
+	return dependencies[provider][kind]
+}
+
+func GetProvider(name string) (ProviderDefinition, bool) {
+	provider, ok := providers[name]
+	return provider, ok
+}
+
+func GetProviderNames() []string {
+	var names []string
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// GetCleaners returns, in registration order, the cleaners of a provider scope whose feature is enabled.
+func GetCleaners(provider string, scope Scope, features FeatureSet) []CleanerDefinition {
+	var definitions []CleanerDefinition
+	for _, definition := range cleanerDefinitions {
+		if definition.Provider == provider && definition.Scope == scope && features.IsEnabled(definition.Feature) {
+			definitions = append(definitions, definition)
+		}
+	}
+
+	return definitions
+}
+
+// GetProviderCleaners returns every cleaner registered for a provider, whatever its scope or feature.
+func GetProviderCleaners(provider string) []CleanerDefinition {
+	var definitions []CleanerDefinition
+	for _, definition := range cleanerDefinitions {
+		if definition.Provider == provider {
+			definitions = append(definitions, definition)
+		}
+	}
+
+	return definitions
+}
+
+// SortByDependencies orders cleaners definitions so that every kind comes after the kinds it depends on, registration
+// order is kept otherwise. Dependencies on kinds absent from definitions are ignored.
+func SortByDependencies(definitions []CleanerDefinition) ([]CleanerDefinition, error) {
+	indexes := make(map[string]int)
+	for i, definition := range definitions {
+		indexes[definition.Kind] = i
+	}
+
+	var sorted []CleanerDefinition
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	states := make([]int, len(definitions))
+
+	var visit func(i int, path []string) error
+	visit = func(i int, path []string) error {
+		definition := definitions[i]
+		switch states[i] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("dependency cycle between %s kinds: %s", definition.Provider, strings.Join(append(path, definition.Kind), " -> "))
+		}
+
+		states[i] = visiting
+		for _, dependency := range GetDependencies(definition.Provider, definition.Kind) {
+			if j, ok := indexes[dependency]; ok {
+				if err := visit(j, append(path, definition.Kind)); err != nil {
+					return err
+				}
+			}
+		}
+		states[i] = visited
+		sorted = append(sorted, definition)
+
+		return nil
+	}
+
+	for i := range definitions {
+		if err := visit(i, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return sorted, nil
+}
+
+// ResolveFeatures adds to the set every feature implied by an enabled one (eg. EKS implies ELB and EBS).
+func (provider ProviderDefinition) ResolveFeatures(features FeatureSet) FeatureSet {
+	resolved := FeatureSet{}
+	for name, enabled := range features {
+		if enabled {
+			resolved.Enable(name)
+		}
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for _, feature := range provider.Features {
+			if !resolved.IsEnabled(feature.Name) {
+				continue
+			}
+			for _, implied := range feature.Implies {
+				if !resolved.IsEnabled(implied) {
+					resolved.Enable(implied)
+					changed = true
+				}
+			}
+		}
+	}
+
+	return resolved
+}
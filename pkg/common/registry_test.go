@@ -0,0 +1,144 @@
+package common
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+type noopCleaner struct {
+	TTLEvaluator
+}
+
+func (cleaner noopCleaner) List(ctx context.Context) ([]CloudProviderResource, error) {
+	return nil, nil
+}
+
+func (cleaner noopCleaner) Delete(ctx context.Context, resource CloudProviderResource) error {
+	return nil
+}
+
+func newNoopCleaner(providerScope interface{}) (Cleaner, error) {
+	return noopCleaner{}, nil
+}
+
+func kinds(definitions []CleanerDefinition) []string {
+	var names []string
+	for _, definition := range definitions {
+		names = append(names, definition.Kind)
+	}
+
+	return names
+}
+
+func expectPanic(t *testing.T, name string, call func()) {
+	t.Helper()
+	defer func() {
+		if recover() == nil {
+			t.Errorf("%s should panic", name)
+		}
+	}()
+	call()
+}
+
+func TestRegistry(t *testing.T) {
+	const provider = "registry-test"
+	RegisterProvider(ProviderDefinition{Name: provider, Features: []Feature{{Name: "compute"}, {Name: "network"}}})
+	expectPanic(t, "registering a provider twice", func() {
+		RegisterProvider(ProviderDefinition{Name: provider})
+	})
+
+	for _, definition := range []CleanerDefinition{
+		{Provider: provider, Feature: "compute", Kind: "instance", Scope: RegionScope, New: newNoopCleaner},
+		{Provider: provider, Feature: "network", Kind: "vpc", Scope: RegionScope, New: newNoopCleaner},
+		{Provider: provider, Feature: "compute", Kind: "volume", Scope: RegionScope, New: newNoopCleaner},
+		{Provider: provider, Feature: "compute", Kind: "image", Scope: GlobalScope, New: newNoopCleaner},
+	} {
+		RegisterCleaner(definition)
+	}
+	expectPanic(t, "registering a cleaner twice", func() {
+		RegisterCleaner(CleanerDefinition{Provider: provider, Kind: "vpc", Scope: GlobalScope, New: newNoopCleaner})
+	})
+
+	if registered, ok := GetProvider(provider); !ok || registered.Name != provider {
+		t.Errorf("provider %s should be registered", provider)
+	}
+	if _, ok := GetProvider("unknown"); ok {
+		t.Error("unknown provider shouldn't be registered")
+	}
+
+	tests := []struct {
+		scope    Scope
+		features FeatureSet
+		expected []string
+	}{
+		{scope: RegionScope, features: FeatureSet{"compute": true}, expected: []string{"instance", "volume"}},
+		{scope: RegionScope, features: FeatureSet{"compute": true, "network": true}, expected: []string{"instance", "vpc", "volume"}},
+		{scope: GlobalScope, features: FeatureSet{"compute": true}, expected: []string{"image"}},
+		{scope: RegionScope, features: FeatureSet{}, expected: nil},
+	}
+	for _, test := range tests {
+		if cleaners := kinds(GetCleaners(provider, test.scope, test.features)); !reflect.DeepEqual(cleaners, test.expected) {
+			t.Errorf("GetCleaners(%s, %v) = %v, expected %v", test.scope, test.features, cleaners, test.expected)
+		}
+	}
+
+	if cleaners := kinds(GetProviderCleaners(provider)); !reflect.DeepEqual(cleaners, []string{"instance", "vpc", "volume", "image"}) {
+		t.Errorf("unexpected provider cleaners %v", cleaners)
+	}
+}
+
+func TestResolveFeatures(t *testing.T) {
+	provider := ProviderDefinition{Features: []Feature{
+		{Name: "eks", Implies: []string{"elb", "ebs"}},
+		{Name: "elb", Implies: []string{"security-groups"}},
+		{Name: "ebs"},
+		{Name: "security-groups"},
+		{Name: "s3"},
+	}}
+
+	resolved := provider.ResolveFeatures(FeatureSet{"eks": true, "s3": false})
+	expected := FeatureSet{"eks": true, "elb": true, "ebs": true, "security-groups": true}
+	if !reflect.DeepEqual(resolved, expected) {
+		t.Errorf("ResolveFeatures = %v, expected %v", resolved, expected)
+	}
+}
+
+func TestSortByDependencies(t *testing.T) {
+	const provider = "dependencies-test"
+	RegisterDependencies(provider, "vpc", "subnet", "security-group")
+	RegisterDependencies(provider, "subnet", "instance")
+	RegisterDependencies(provider, "security-group", "unregistered")
+
+	definitions := []CleanerDefinition{
+		{Provider: provider, Kind: "vpc"},
+		{Provider: provider, Kind: "bucket"},
+		{Provider: provider, Kind: "security-group"},
+		{Provider: provider, Kind: "subnet"},
+		{Provider: provider, Kind: "instance"},
+	}
+	sorted, err := SortByDependencies(definitions)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"instance", "subnet", "security-group", "vpc", "bucket"}
+	if !reflect.DeepEqual(kinds(sorted), expected) {
+		t.Errorf("SortByDependencies = %v, expected %v", kinds(sorted), expected)
+	}
+}
+
+func TestSortByDependenciesCycle(t *testing.T) {
+	const provider = "cycle-test"
+	RegisterDependencies(provider, "a", "b")
+	RegisterDependencies(provider, "b", "c")
+	RegisterDependencies(provider, "c", "a")
+
+	_, err := SortByDependencies([]CleanerDefinition{{Provider: provider, Kind: "a"}, {Provider: provider, Kind: "b"}, {Provider: provider, Kind: "c"}})
+	if err == nil {
+		t.Fatal("a dependency cycle should be refused")
+	}
+	if expected := "dependency cycle between cycle-test kinds: a -> b -> c -> a"; err.Error() != expected {
+		t.Errorf("unexpected error %q, expected %q", err.Error(), expected)
+	}
+}
@@ -0,0 +1,203 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	ReportFormatJSON = "json"
+	ReportFormatYAML = "yaml"
+)
+
+// ReportEntry describes a resource eligible to deletion and why it has been selected.
+type ReportEntry struct {
+	Provider     string     `json:"provider"`
+	Account      string     `json:"account,omitempty"`
+	Region       string     `json:"region,omitempty"`
+	Zone         string     `json:"zone,omitempty"`
+	Kind         string     `json:"kind"`
+	Identifier   string     `json:"identifier"`
+	Description  string     `json:"description,omitempty"`
+	TagValue     string     `json:"tagValue,omitempty"`
+	TTL          int64      `json:"ttl"`
+	CreationDate *time.Time `json:"creationDate,omitempty"`
+	ExpiresAt    *time.Time `json:"expiresAt,omitempty"`
+	Rule         string     `json:"rule"`
+	FirstSeen    *time.Time `json:"firstSeen,omitempty"`
+	Status       string     `json:"status,omitempty"`
+	Attempts     int        `json:"attempts,omitempty"`
+	LastError    string     `json:"lastError,omitempty"`
+	Stuck        bool       `json:"stuck,omitempty"`
+}
+
+// ReportWarning is a tag of a resource which can't be parsed.
+type ReportWarning struct {
+	Kind       string `json:"kind"`
+	Identifier string `json:"identifier"`
+	Message    string `json:"message"`
+}
+
+// ReportExclusion is a resource a rule excluded from deletion.
+type ReportExclusion struct {
+	Kind       string `json:"kind"`
+	Identifier string `json:"identifier"`
+	Rule       string `json:"rule"`
+}
+
+// UnsupportedResource is a resource carrying Pleco tags whose type no cleaner handles.
+type UnsupportedResource struct {
+	Type       string            `json:"type"`
+	Identifier string            `json:"identifier"`
+	Tags       map[string]string `json:"tags,omitempty"`
+}
+
+// Report is the plan of a single cleaning cycle of a provider location.
+type Report struct {
+	Provider    string                `json:"provider"`
+	Account     string                `json:"account,omitempty"`
+	Scope       Scope                 `json:"scope,omitempty"`
+	Location    string                `json:"location,omitempty"`
+	DryRun      bool                  `json:"dryRun"`
+	StartedAt   time.Time             `json:"startedAt"`
+	Resources   []ReportEntry         `json:"resources"`
+	Warnings    []ReportWarning       `json:"warnings,omitempty"`
+	Exclusions  []ReportExclusion     `json:"exclusions,omitempty"`
+	Unsupported []UnsupportedResource `json:"unsupported,omitempty"`
+}
+
+func NewReport(provider string, scope Scope, location string, dryRun bool) *Report {
+	return &Report{
+		Provider:  provider,
+		Scope:     scope,
+		Location:  location,
+		DryRun:    dryRun,
+		StartedAt: time.Now().UTC(),
+		Resources: []ReportEntry{},
+	}
+}
+
+// Add appends a resource to the report and returns its index.
+func (report *Report) Add(kind string, resource CloudProviderResource, rule string) int {
+	entry := ReportEntry{
+		Provider:    report.Provider,
+		Account:     report.Account,
+		Kind:        kind,
+		Identifier:  resource.Identifier,
+		Description: resource.Description,
+		TagValue:    resource.Tag,
+		TTL:         resource.TTL,
+		Rule:        rule,
+	}
+
+	switch report.Scope {
+	case RegionScope:
+		entry.Region = report.Location
+	case ZoneScope:
+		entry.Zone = report.Location
+	}
+
+	if !resource.CreationDate.IsZero() {
+		creationDate := resource.CreationDate.UTC()
+		entry.CreationDate = &creationDate
+	}
+
+	if expiresAt, ok := resource.ExpirationDate(); ok {
+		entry.ExpiresAt = &expiresAt
+	}
+
+	report.Resources = append(report.Resources, entry)
+	return len(report.Resources) - 1
+}
+
+func (report *Report) AddWarnings(kind string, resource CloudProviderResource) {
+	for _, warning := range resource.Warnings {
+		report.Warnings = append(report.Warnings, ReportWarning{Kind: kind, Identifier: resource.Identifier, Message: warning})
+	}
+}
+
+func (report *Report) AddExclusion(kind string, resource CloudProviderResource, rule string) {
+	report.Exclusions = append(report.Exclusions, ReportExclusion{Kind: kind, Identifier: resource.Identifier, Rule: rule})
+}
+
+func (report *Report) setState(index int, state *ResourceState, now time.Time) {
+	entry := &report.Resources[index]
+	firstSeen := state.FirstSeen.UTC()
+	entry.FirstSeen = &firstSeen
+	entry.Status = state.Status
+	entry.Attempts = state.Attempts
+	entry.LastError = state.LastError
+	entry.Stuck = !report.DryRun && state.isStuck(now)
+}
+
+// Reporter writes a report per cleaning cycle. JSON reports are written one per line, YAML ones as separate documents.
+type Reporter struct {
+	format string
+	writer io.Writer
+	mutex  sync.Mutex
+}
+
+func NewReporter(format string, path string) (*Reporter, error) {
+	if format != ReportFormatJSON && format != ReportFormatYAML {
+		return nil, fmt.Errorf("unknown report format %s, should be %s or %s", format, ReportFormatJSON, ReportFormatYAML)
+	}
+
+	reporter := &Reporter{format: format, writer: os.Stdout}
+	if path != "" {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("can't open report file %s: %s", path, err.Error())
+		}
+		reporter.writer = file
+	}
+
+	return reporter, nil
+}
+
+func (reporter *Reporter) Write(report *Report) error {
+	var content []byte
+	var err error
+
+	switch reporter.format {
+	case ReportFormatYAML:
+		content, err = yaml.Marshal(report)
+		content = append([]byte("---\n"), content...)
+	default:
+		content, err = json.Marshal(report)
+		content = append(content, '\n')
+	}
+	if err != nil {
+		return err
+	}
+
+	reporter.mutex.Lock()
+	defer reporter.mutex.Unlock()
+
+	_, err = reporter.writer.Write(content)
+	return err
+}
+
+var reporter *Reporter
+
+// SetReporter enables the report of every cleaning cycle.
+func SetReporter(newReporter *Reporter) {
+	reporter = newReporter
+}
+
+// WriteReport writes the report of a cleaning cycle if reporting is enabled.
+func WriteReport(report *Report) {
+	if reporter == nil {
+		return
+	}
+
+	if err := reporter.Write(report); err != nil {
+		log.Errorf("Can't write %s report: %s", report.Provider, err.Error())
+	}
+}
@@ -0,0 +1,262 @@
+package common
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	RuleActionExclude = "exclude"
+	RuleActionInclude = "include"
+
+	// RuleNotIncluded is reported for the resources no include rule matched
+	RuleNotIncluded = "not-included"
+	// RuleExclusions is reported for the resources matching the exclusions of their provider or kind policy
+	RuleExclusions = "exclusions"
+)
+
+// Rule selects resources to exclude from deletion, or to include when allow listing. Every criterion set must match,
+// and a criterion holding several values matches any of them. Identifiers, accounts, regions and tag values are glob
+// patterns, or regular expressions between slashes, eg. /^db-[0-9]+$/.
+type Rule struct {
+	Name string `json:"name,omitempty"`
+	// Action is exclude (default) or include. Once a provider has an include rule, its resources no include rule
+	// matches are excluded.
+	Action      string   `json:"action,omitempty"`
+	Providers   []string `json:"providers,omitempty"`
+	Accounts    []string `json:"accounts,omitempty"`
+	Regions     []string `json:"regions,omitempty"`
+	Kinds       []string `json:"kinds,omitempty"`
+	Identifiers []string `json:"identifiers,omitempty"`
+	// Tags are matched by key, an empty value matches any value
+	Tags map[string]string `json:"tags,omitempty"`
+	Vpcs []string          `json:"vpcs,omitempty"`
+}
+
+// RuleTarget is a listed resource, with the location it has been listed in.
+type RuleTarget struct {
+	Provider string
+	Account  string
+	Location string
+	Kind     string
+	Resource CloudProviderResource
+}
+
+func (rule Rule) name(index int) string {
+	if rule.Name != "" {
+		return rule.Name
+	}
+
+	return fmt.Sprintf("rules[%d]", index)
+}
+
+func (rule Rule) isInclude() bool {
+	return rule.Action == RuleActionInclude
+}
+
+func (rule Rule) appliesTo(provider string) bool {
+	return len(rule.Providers) == 0 || contains(rule.Providers, provider)
+}
+
+func (rule Rule) Matches(target RuleTarget) bool {
+	if !rule.appliesTo(target.Provider) {
+		return false
+	}
+	if len(rule.Kinds) > 0 && !contains(rule.Kinds, target.Kind) {
+		return false
+	}
+	if len(rule.Accounts) > 0 && !matchesAny(rule.Accounts, target.Account) {
+		return false
+	}
+	if len(rule.Regions) > 0 && !matchesAny(rule.Regions, target.Location) {
+		return false
+	}
+	if len(rule.Identifiers) > 0 && !matchesAny(rule.Identifiers, target.Resource.Identifier) {
+		return false
+	}
+	if len(rule.Vpcs) > 0 && (target.Resource.VpcId == "" || !matchesAny(rule.Vpcs, target.Resource.VpcId)) {
+		return false
+	}
+
+	for key, pattern := range rule.Tags {
+		value, ok := target.Resource.Tags[key]
+		if !ok || (pattern != "" && !matchesPattern(pattern, value)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (rule Rule) validate(field string) []string {
+	var errs []string
+	if rule.Action != "" && rule.Action != RuleActionExclude && rule.Action != RuleActionInclude {
+		errs = append(errs, fmt.Sprintf("%s.action: unknown action %q, should be %s or %s", field, rule.Action, RuleActionExclude, RuleActionInclude))
+	}
+
+	providers := rule.Providers
+	if len(providers) == 0 {
+		providers = GetProviderNames()
+	}
+	kinds := make(map[string]bool)
+	for _, provider := range providers {
+		if _, ok := GetProvider(provider); !ok {
+			errs = append(errs, fmt.Sprintf("%s.providers: unknown provider %q, should be one of %s", field, provider, strings.Join(GetProviderNames(), ", ")))
+		}
+		for _, definition := range GetProviderCleaners(provider) {
+			kinds[definition.Kind] = true
+		}
+	}
+	for _, kind := range rule.Kinds {
+		if !kinds[kind] {
+			errs = append(errs, fmt.Sprintf("%s.kinds: unknown resource kind %q", field, kind))
+		}
+	}
+
+	patterns := map[string][]string{"accounts": rule.Accounts, "regions": rule.Regions, "identifiers": rule.Identifiers, "vpcs": rule.Vpcs}
+	for name, values := range patterns {
+		for i, pattern := range values {
+			if err := validatePattern(pattern); err != nil {
+				errs = append(errs, fmt.Sprintf("%s.%s[%d]: %s", field, name, i, err.Error()))
+			}
+		}
+	}
+	for key, pattern := range rule.Tags {
+		if err := validatePattern(pattern); err != nil {
+			errs = append(errs, fmt.Sprintf("%s.tags.%s: %s", field, key, err.Error()))
+		}
+	}
+
+	return errs
+}
+
+// ExcludedBy returns the name of the rule excluding the resource: the first matching rule when it's an exclude one,
+// or RuleNotIncluded when the provider has include rules and none matched.
+func (policy *Policy) ExcludedBy(target RuleTarget) (string, bool) {
+	rules := policy.rules()
+
+	hasInclude := false
+	for i, rule := range rules {
+		if rule.isInclude() && rule.appliesTo(target.Provider) {
+			hasInclude = true
+		}
+
+		if rule.Matches(target) {
+			if rule.isInclude() {
+				return "", false
+			}
+			return rule.name(i), true
+		}
+	}
+
+	if hasInclude {
+		return RuleNotIncluded, true
+	}
+
+	return "", false
+}
+
+// ProtectsVPCs returns whether an exclude rule protects VPCs of the provider.
+func (policy *Policy) ProtectsVPCs(provider string) bool {
+	for _, rule := range policy.rules() {
+		if !rule.isInclude() && rule.appliesTo(provider) && len(rule.Vpcs) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (policy *Policy) rules() []Rule {
+	var rules []Rule
+	if policy != nil {
+		rules = append(rules, policy.Rules...)
+	}
+
+	if legacyRule, ok := getProtectedVPCRule(); ok {
+		rules = append(rules, legacyRule)
+	}
+
+	return rules
+}
+
+var protectedVPCWarning sync.Once
+
+// getProtectedVPCRule turns the deprecated PROTECTED_VPC_ID environment variable into an exclude rule.
+func getProtectedVPCRule() (Rule, bool) {
+	vpcId := strings.TrimSpace(os.Getenv("PROTECTED_VPC_ID"))
+	if vpcId == "" {
+		return Rule{}, false
+	}
+
+	protectedVPCWarning.Do(func() {
+		log.Warnf("PROTECTED_VPC_ID is deprecated, use an exclude rule with vpcs: [%s] in the config file instead", vpcId)
+	})
+
+	return Rule{Name: "PROTECTED_VPC_ID", Action: RuleActionExclude, Providers: []string{"aws"}, Vpcs: []string{vpcId}}, true
+}
+
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchesAny(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if matchesPattern(pattern, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+var regexps sync.Map
+
+func isRegexp(pattern string) bool {
+	return len(pattern) > 1 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/")
+}
+
+func matchesPattern(pattern string, value string) bool {
+	if !isRegexp(pattern) {
+		matched, _ := path.Match(pattern, value)
+		return matched
+	}
+
+	compiled, ok := regexps.Load(pattern)
+	if !ok {
+		expression, err := regexp.Compile(pattern[1 : len(pattern)-1])
+		if err != nil {
+			return false
+		}
+		compiled, _ = regexps.LoadOrStore(pattern, expression)
+	}
+
+	return compiled.(*regexp.Regexp).MatchString(value)
+}
+
+func validatePattern(pattern string) error {
+	if isRegexp(pattern) {
+		if _, err := regexp.Compile(pattern[1 : len(pattern)-1]); err != nil {
+			return fmt.Errorf("invalid regular expression %q: %s", pattern, err.Error())
+		}
+		return nil
+	}
+
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid pattern %q: %s", pattern, err.Error())
+	}
+
+	return nil
+}
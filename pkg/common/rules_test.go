@@ -0,0 +1,123 @@
+package common
+
+import "testing"
+
+func TestRuleMatches(t *testing.T) {
+	target := RuleTarget{
+		Provider: "aws",
+		Account:  "123456789012",
+		Location: "eu-west-3",
+		Kind:     "ec2-instance",
+		Resource: CloudProviderResource{
+			Identifier: "db-42",
+			Tags:       map[string]string{"team": "core", "env": "review-42"},
+			VpcId:      "vpc-1",
+		},
+	}
+
+	tests := []struct {
+		name     string
+		rule     Rule
+		expected bool
+	}{
+		{name: "empty rule", rule: Rule{}, expected: true},
+		{name: "provider", rule: Rule{Providers: []string{"gcp", "aws"}}, expected: true},
+		{name: "other provider", rule: Rule{Providers: []string{"gcp"}}, expected: false},
+		{name: "kind", rule: Rule{Kinds: []string{"ec2-instance"}}, expected: true},
+		{name: "other kind", rule: Rule{Kinds: []string{"vpc"}}, expected: false},
+		{name: "account glob", rule: Rule{Accounts: []string{"123*"}}, expected: true},
+		{name: "region glob", rule: Rule{Regions: []string{"us-*", "eu-*"}}, expected: true},
+		{name: "other region", rule: Rule{Regions: []string{"us-*"}}, expected: false},
+		{name: "identifier", rule: Rule{Identifiers: []string{"db-42"}}, expected: true},
+		{name: "identifier regexp", rule: Rule{Identifiers: []string{"/^db-[0-9]+$/"}}, expected: true},
+		{name: "identifier regexp mismatch", rule: Rule{Identifiers: []string{"/^db-[a-z]+$/"}}, expected: false},
+		{name: "invalid regexp", rule: Rule{Identifiers: []string{"/db-(/"}}, expected: false},
+		{name: "vpc", rule: Rule{Vpcs: []string{"vpc-*"}}, expected: true},
+		{name: "other vpc", rule: Rule{Vpcs: []string{"vpc-2"}}, expected: false},
+		{name: "tag value", rule: Rule{Tags: map[string]string{"team": "core"}}, expected: true},
+		{name: "tag without value", rule: Rule{Tags: map[string]string{"team": ""}}, expected: true},
+		{name: "tag value pattern", rule: Rule{Tags: map[string]string{"env": "review-*"}}, expected: true},
+		{name: "other tag value", rule: Rule{Tags: map[string]string{"team": "data"}}, expected: false},
+		{name: "missing tag", rule: Rule{Tags: map[string]string{"owner": ""}}, expected: false},
+		{name: "every criterion", rule: Rule{Providers: []string{"aws"}, Kinds: []string{"ec2-instance"}, Identifiers: []string{"db-*"}, Tags: map[string]string{"team": "core"}}, expected: true},
+		{name: "one criterion failing", rule: Rule{Providers: []string{"aws"}, Kinds: []string{"ec2-instance"}, Identifiers: []string{"web-*"}}, expected: false},
+	}
+
+	for _, test := range tests {
+		if matched := test.rule.Matches(target); matched != test.expected {
+			t.Errorf("%s: Matches = %t, expected %t", test.name, matched, test.expected)
+		}
+	}
+
+	withoutVpc := target
+	withoutVpc.Resource.VpcId = ""
+	if (Rule{Vpcs: []string{"*"}}).Matches(withoutVpc) {
+		t.Error("a vpc rule shouldn't match a resource outside of any vpc")
+	}
+}
+
+func TestPolicyExcludedBy(t *testing.T) {
+	t.Setenv("PROTECTED_VPC_ID", "")
+	target := func(provider string, identifier string) RuleTarget {
+		return RuleTarget{Provider: provider, Kind: "vpc", Resource: CloudProviderResource{Identifier: identifier}}
+	}
+
+	policy := &Policy{Rules: []Rule{
+		{Name: "keep-prod", Identifiers: []string{"prod-*"}},
+		{Action: RuleActionInclude, Providers: []string{"aws"}, Identifiers: []string{"review-*", "prod-*"}},
+	}}
+
+	tests := []struct {
+		target   RuleTarget
+		rule     string
+		excluded bool
+	}{
+		{target: target("aws", "prod-1"), rule: "keep-prod", excluded: true},
+		{target: target("aws", "review-1"), excluded: false},
+		{target: target("aws", "other"), rule: RuleNotIncluded, excluded: true},
+		{target: target("gcp", "other"), excluded: false},
+		{target: target("gcp", "prod-1"), rule: "keep-prod", excluded: true},
+	}
+	for _, test := range tests {
+		rule, excluded := policy.ExcludedBy(test.target)
+		if rule != test.rule || excluded != test.excluded {
+			t.Errorf("ExcludedBy(%s %s) = %q, %t, expected %q, %t", test.target.Provider, test.target.Resource.Identifier, rule, excluded, test.rule, test.excluded)
+		}
+	}
+
+	unnamed := &Policy{Rules: []Rule{{Kinds: []string{"s3"}}, {Kinds: []string{"vpc"}}}}
+	if rule, excluded := unnamed.ExcludedBy(target("aws", "vpc-1")); !excluded || rule != "rules[1]" {
+		t.Errorf("unnamed rules should be reported by index, got %q", rule)
+	}
+
+	var noPolicy *Policy
+	if _, excluded := noPolicy.ExcludedBy(target("aws", "vpc-1")); excluded {
+		t.Error("nothing should be excluded without policy")
+	}
+}
+
+func TestProtectedVPCRule(t *testing.T) {
+	t.Setenv("PROTECTED_VPC_ID", "vpc-1")
+
+	var noPolicy *Policy
+	target := RuleTarget{Provider: "aws", Kind: "subnet", Resource: CloudProviderResource{Identifier: "subnet-1", VpcId: "vpc-1"}}
+	if rule, excluded := noPolicy.ExcludedBy(target); !excluded || rule != "PROTECTED_VPC_ID" {
+		t.Errorf("resources of the protected vpc should be excluded, got %q, %t", rule, excluded)
+	}
+	if !noPolicy.ProtectsVPCs("aws") || noPolicy.ProtectsVPCs("gcp") {
+		t.Error("PROTECTED_VPC_ID should only protect AWS vpcs")
+	}
+}
+
+func TestValidatePattern(t *testing.T) {
+	for _, pattern := range []string{"db-*", "db-?", "[a-z]*", "/^db-[0-9]+$/", "/"} {
+		if err := validatePattern(pattern); err != nil {
+			t.Errorf("%q should be valid: %s", pattern, err.Error())
+		}
+	}
+	for _, pattern := range []string{"[a-", "/db-(/"} {
+		if err := validatePattern(pattern); err == nil {
+			t.Errorf("%q should be invalid", pattern)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// stateRetention is how long the outcome of a deleted resource is kept in the state
+	stateRetention = 7 * 24 * time.Hour
+	// stuckAttempts is the number of deletion attempts after which a resource is reported as stuck
+	stuckAttempts = 10
+)
+
+// stuckAfter is the delay after its expiration, or after it has been seen expired, a resource is reported as stuck.
+var stuckAfter = 24 * time.Hour
+
+func SetStuckAfter(delay time.Duration) {
+	stuckAfter = delay
+}
+
+// StateStore persists the resources states, so that Pleco keeps deletion attempts and first-seen dates across
+// restarts. Load and Save handle every state at once.
+type StateStore interface {
+	Load() ([]ResourceState, error)
+	Save(states []ResourceState) error
+}
+
+// FileStateStore keeps the states in a local JSON file.
+type FileStateStore struct {
+	path string
+}
+
+func NewFileStateStore(path string) *FileStateStore {
+	return &FileStateStore{path: path}
+}
+
+func (store *FileStateStore) Load() ([]ResourceState, error) {
+	content, err := os.ReadFile(store.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("can't read state file %s: %s", store.path, err.Error())
+	}
+
+	var states []ResourceState
+	if err := json.Unmarshal(content, &states); err != nil {
+		return nil, fmt.Errorf("can't parse state file %s: %s", store.path, err.Error())
+	}
+
+	return states, nil
+}
+
+func (store *FileStateStore) Save(states []ResourceState) error {
+	content, err := json.MarshalIndent(states, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// write then rename, to never leave a truncated state file
+	tmpFile, err := os.CreateTemp(filepath.Dir(store.path), filepath.Base(store.path)+".*")
+	if err != nil {
+		return fmt.Errorf("can't write state file %s: %s", store.path, err.Error())
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.Write(content); err != nil {
+		_ = tmpFile.Close()
+		return fmt.Errorf("can't write state file %s: %s", store.path, err.Error())
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("can't write state file %s: %s", store.path, err.Error())
+	}
+
+	return os.Rename(tmpFile.Name(), store.path)
+}
+
+// resourceStates holds the states of every engine, keyed by engine. Engines load theirs when created and save
+// them after each cycle.
+var resourceStates = struct {
+	sync.Mutex
+	store   StateStore
+	engines map[string][]ResourceState
+}{}
+
+// SetStateStore enables the persistence of the resources states and loads the existing ones.
+func SetStateStore(store StateStore) error {
+	states, err := store.Load()
+	if err != nil {
+		return err
+	}
+
+	resourceStates.Lock()
+	defer resourceStates.Unlock()
+
+	resourceStates.store = store
+	resourceStates.engines = make(map[string][]ResourceState)
+	for _, state := range states {
+		key := stateKey(EngineOptions{Provider: state.Provider, Account: state.Account, Scope: state.Scope, Location: state.Location})
+		resourceStates.engines[key] = append(resourceStates.engines[key], state)
+	}
+	log.Infof("Loaded %d resources states", len(states))
+
+	return nil
+}
+
+func stateKey(options EngineOptions) string {
+	return fmt.Sprintf("%s/%s/%s/%s", options.Provider, options.Account, options.Scope, options.Location)
+}
+
+func loadStates(options EngineOptions) progressTracker {
+	resourceStates.Lock()
+	defer resourceStates.Unlock()
+
+	tracker := progressTracker{}
+	for _, state := range resourceStates.engines[stateKey(options)] {
+		state := state
+		if tracker[state.Kind] == nil {
+			tracker[state.Kind] = make(map[string]*ResourceState)
+		}
+		tracker[state.Kind][state.Identifier] = &state
+	}
+
+	return tracker
+}
+
+func saveStates(options EngineOptions, tracker progressTracker) {
+	resourceStates.Lock()
+	defer resourceStates.Unlock()
+
+	if resourceStates.store == nil {
+		return
+	}
+
+	var states []ResourceState
+	for _, kindStates := range tracker {
+		for _, state := range kindStates {
+			states = append(states, *state)
+		}
+	}
+	resourceStates.engines[stateKey(options)] = states
+
+	var allStates []ResourceState
+	for _, engineStates := range resourceStates.engines {
+		allStates = append(allStates, engineStates...)
+	}
+	sort.Slice(allStates, func(i, j int) bool {
+		if allStates[i].Provider != allStates[j].Provider {
+			return allStates[i].Provider < allStates[j].Provider
+		}
+		if allStates[i].Account != allStates[j].Account {
+			return allStates[i].Account < allStates[j].Account
+		}
+		if allStates[i].Location != allStates[j].Location {
+			return allStates[i].Location < allStates[j].Location
+		}
+		if allStates[i].Kind != allStates[j].Kind {
+			return allStates[i].Kind < allStates[j].Kind
+		}
+		return allStates[i].Identifier < allStates[j].Identifier
+	})
+
+	if err := resourceStates.store.Save(allStates); err != nil {
+		log.Errorf("Can't save resources states: %s", err.Error())
+	}
+}
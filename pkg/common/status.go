@@ -0,0 +1,61 @@
+package common
+
+import (
+	"sync"
+	"time"
+)
+
+// maxCycleErrors is the number of errors kept per cycle status, the following ones are only logged.
+const maxCycleErrors = 10
+
+// CycleStatus sums up the last cycle of an engine.
+type CycleStatus struct {
+	Provider        string    `json:"provider"`
+	Account         string    `json:"account,omitempty"`
+	Location        string    `json:"location,omitempty"`
+	FinishedAt      time.Time `json:"finishedAt"`
+	DurationSeconds float64   `json:"durationSeconds"`
+	DryRun          bool      `json:"dryRun,omitempty"`
+	Discovered      int       `json:"discovered"`
+	Expired         int       `json:"expired"`
+	Deleted         int       `json:"deleted"`
+	Failed          int       `json:"failed"`
+	Errors          []string  `json:"errors,omitempty"`
+}
+
+// Key identifies the engine of the status.
+func (status CycleStatus) Key() string {
+	return status.Provider + "/" + status.Account + "/" + status.Location
+}
+
+func (status *CycleStatus) addError(message string) {
+	if len(status.Errors) < maxCycleErrors {
+		status.Errors = append(status.Errors, message)
+	}
+}
+
+// StatusWriter receives the status of every engine cycle, eg. to publish it on a Kubernetes object.
+type StatusWriter interface {
+	WriteStatus(status CycleStatus)
+}
+
+var currentStatusWriter = struct {
+	sync.RWMutex
+	writer StatusWriter
+}{}
+
+func SetStatusWriter(writer StatusWriter) {
+	currentStatusWriter.Lock()
+	defer currentStatusWriter.Unlock()
+
+	currentStatusWriter.writer = writer
+}
+
+func writeStatus(status CycleStatus) {
+	currentStatusWriter.RLock()
+	defer currentStatusWriter.RUnlock()
+
+	if currentStatusWriter.writer != nil {
+		currentStatusWriter.writer.WriteStatus(status)
+	}
+}
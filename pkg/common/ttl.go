@@ -0,0 +1,97 @@
+package common
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ttlUnits = map[string]time.Duration{
+	"s": time.Second,
+	"m": time.Minute,
+	"h": time.Hour,
+	"d": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour,
+}
+
+// maxTTL is the longest ttl, in seconds, a time.Duration holds (about 292 years).
+const maxTTL = int64(math.MaxInt64 / time.Second)
+
+var (
+	ttlPattern     = regexp.MustCompile(`^(\d+[smhdw])+$`)
+	ttlPartPattern = regexp.MustCompile(`(\d+)([smhdw])`)
+)
+
+// ParseTTL returns a ttl in seconds, set either as seconds or as a duration made of s, m, h, d and w units,
+// eg. 30m, 4h, 7d, 1w or 1d12h.
+func ParseTTL(value string) (int64, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds < 0 || seconds > maxTTL {
+			return 0, fmt.Errorf("%q: duration out of range", value)
+		}
+		return seconds, nil
+	}
+
+	if !ttlPattern.MatchString(value) {
+		return 0, fmt.Errorf("%q is neither seconds nor a duration like 30m, 4h, 7d or 1w", value)
+	}
+
+	var ttl int64
+	for _, part := range ttlPartPattern.FindAllStringSubmatch(value, -1) {
+		unit := int64(ttlUnits[part[2]] / time.Second)
+		count, err := strconv.ParseInt(part[1], 10, 64)
+		if err != nil || count > maxTTL/unit {
+			return 0, fmt.Errorf("%q: duration out of range", value)
+		}
+		if ttl > maxTTL-count*unit {
+			return 0, fmt.Errorf("%q: duration out of range", value)
+		}
+		ttl += count * unit
+	}
+
+	return ttl, nil
+}
+
+// extendTTL adds an extension to a ttl, without going past maxTTL.
+func extendTTL(ttl int64, extension int64) int64 {
+	if ttl > maxTTL-extension {
+		return maxTTL
+	}
+
+	return ttl + extension
+}
+
+// dateLayouts are the date formats accepted in tags, dates without time zone are UTC.
+var dateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05 -0700 MST",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// ParseDate reads a date set as RFC3339, as a unix timestamp in seconds, or as a date only (YYYY-MM-DD).
+func ParseDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if timestamp, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(timestamp, 0).UTC(), nil
+	}
+
+	// dates written by Go time.String() may end with the monotonic clock reading
+	if index := strings.Index(value, " m="); index > 0 {
+		value = value[:index]
+	}
+
+	for _, layout := range dateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date.UTC(), nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%q is neither a RFC3339 date, a unix timestamp nor a YYYY-MM-DD date", value)
+}
@@ -0,0 +1,104 @@
+package common
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTTL(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected int64
+		invalid  bool
+	}{
+		{value: "3600", expected: 3600},
+		{value: " 0 ", expected: 0},
+		{value: "-1", invalid: true},
+		{value: "45s", expected: 45},
+		{value: "30m", expected: 1800},
+		{value: "4h", expected: 14400},
+		{value: "7d", expected: 604800},
+		{value: "1w", expected: 604800},
+		{value: "4H", expected: 14400},
+		{value: "1d12h", expected: 129600},
+		{value: "1w2d3h4m5s", expected: 788645},
+		{value: "9223372036s", expected: 9223372036},
+		{value: "9223372037s", invalid: true},
+		{value: "9223372037", invalid: true},
+		{value: "15250284452w", invalid: true},
+		{value: "99999999999999999999d", invalid: true},
+		{value: "15250w15250w", invalid: true},
+		{value: "", invalid: true},
+		{value: "soon", invalid: true},
+		{value: "4 hours", invalid: true},
+		{value: "1y", invalid: true},
+		{value: "h4", invalid: true},
+		{value: "-4h", invalid: true},
+		{value: "1.5h", invalid: true},
+	}
+
+	for _, test := range tests {
+		ttl, err := ParseTTL(test.value)
+		if test.invalid {
+			if err == nil {
+				t.Errorf("ParseTTL(%q) = %d, expected an error", test.value, ttl)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseTTL(%q) returned %s", test.value, err.Error())
+			continue
+		}
+		if ttl != test.expected {
+			t.Errorf("ParseTTL(%q) = %d, expected %d", test.value, ttl, test.expected)
+		}
+	}
+}
+
+func TestExtendTTL(t *testing.T) {
+	if ttl := extendTTL(3600, 1800); ttl != 5400 {
+		t.Errorf("extendTTL(3600, 1800) = %d, expected 5400", ttl)
+	}
+	if ttl := extendTTL(maxTTL-10, 3600); ttl != maxTTL {
+		t.Errorf("extendTTL(maxTTL-10, 3600) = %d, expected maxTTL", ttl)
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected time.Time
+		invalid  bool
+	}{
+		{value: "1700000000", expected: time.Unix(1700000000, 0)},
+		{value: "2023-11-14T22:13:20Z", expected: time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC)},
+		{value: "2023-11-14T23:13:20.5+01:00", expected: time.Date(2023, 11, 14, 22, 13, 20, 500000000, time.UTC)},
+		{value: "2023-11-14T22:13:20", expected: time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC)},
+		{value: "2023-11-14 23:13:20 +0100 CET", expected: time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC)},
+		{value: "2023-11-14 23:13:20 +0100 CET m=+0.012345678", expected: time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC)},
+		{value: "2023-11-14 23:13:20 +0100", expected: time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC)},
+		{value: "2023-11-14 22:13:20", expected: time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC)},
+		{value: " 2023-11-14 ", expected: time.Date(2023, 11, 14, 0, 0, 0, 0, time.UTC)},
+		{value: "", invalid: true},
+		{value: "yesterday", invalid: true},
+		{value: "14/11/2023", invalid: true},
+		{value: "2023-13-01", invalid: true},
+	}
+
+	for _, test := range tests {
+		date, err := ParseDate(test.value)
+		if test.invalid {
+			if err == nil {
+				t.Errorf("ParseDate(%q) = %s, expected an error", test.value, date)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseDate(%q) returned %s", test.value, err.Error())
+			continue
+		}
+		if !date.Equal(test.expected) || date.Location() != time.UTC {
+			t.Errorf("ParseDate(%q) = %s, expected %s", test.value, date, test.expected.UTC())
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package common
 
 import (
+	"context"
 	"fmt"
 	"github.com/aws/aws-sdk-go/service/eventbridge"
 	"strconv"
@@ -37,9 +38,14 @@ type MyTag struct {
 type EssentialTags struct {
 	CreationDate time.Time
 	TTL          int64
+	ExpiresAt    time.Time
 	IsProtected  bool
 	ClusterId    string
 	Tag          string
+	// Warnings are the tags which can't be parsed
+	Warnings []string
+	// Tags are all the tags of the resource
+	Tags map[string]string
 }
 
 type CloudProviderResource struct {
@@ -49,6 +55,16 @@ type CloudProviderResource struct {
 	TTL          int64
 	Tag          string
 	IsProtected  bool
+	// ExpiresAt is set by the expires_at or delete_after tag, it takes precedence over the ttl
+	ExpiresAt time.Time
+	// Warnings are the tags which can't be parsed, they are reported
+	Warnings []string
+	// Tags are all the tags of the resource, matched by the policy rules
+	Tags map[string]string
+	// VpcId is the VPC, or network, the resource belongs to, when known
+	VpcId string
+	// Payload holds the provider specific resource the cleaner needs to delete it
+	Payload interface{}
 }
 
 func (resource *CloudProviderResource) IsResourceExpired(commandLineTagValue string, disableTTLCheck bool) bool {
@@ -58,11 +74,26 @@ func (resource *CloudProviderResource) IsResourceExpired(commandLineTagValue str
 	isDestroyingCommand := strings.TrimSpace(commandLineTagValue) != ""
 	if isDestroyingCommand {
 		return strings.EqualFold(resource.Tag, commandLineTagValue)
+	} else if !resource.ExpiresAt.IsZero() {
+		return time.Now().UTC().After(resource.ExpiresAt)
 	} else {
 		return CheckIfExpired(resource.CreationDate, resource.TTL, resource.Description, disableTTLCheck)
 	}
 }
 
+// ExpirationDate returns the expires_at date of the resource, or the date its ttl expires when both ttl and creation
+// date are known.
+func (resource *CloudProviderResource) ExpirationDate() (time.Time, bool) {
+	if !resource.ExpiresAt.IsZero() {
+		return resource.ExpiresAt.UTC(), true
+	}
+	if resource.TTL <= 0 || resource.CreationDate.Year() < 1972 {
+		return time.Time{}, false
+	}
+
+	return resource.CreationDate.UTC().Add(time.Duration(resource.TTL) * time.Second), true
+}
+
 func GetEssentialTags(tagsInput interface{}, tagName string) EssentialTags {
 	var tags []MyTag
 
@@ -117,7 +148,13 @@ func GetEssentialTags(tagsInput interface{}, tagName string) EssentialTags {
 		}
 	case map[string]*string:
 		for key, value := range typedTags {
-			tags = append(tags, MyTag{Key: key, Value: *value})
+			if value != nil {
+				tags = append(tags, MyTag{Key: key, Value: *value})
+			}
+		}
+	case map[string]string:
+		for key, value := range typedTags {
+			tags = append(tags, MyTag{Key: key, Value: value})
 		}
 	case []string:
 		for _, value := range typedTags {
@@ -135,24 +172,60 @@ func GetEssentialTags(tagsInput interface{}, tagName string) EssentialTags {
 		log.Debugf("Can't parse tags %s.", tagsInput)
 	}
 
-	essentialTags := EssentialTags{}
-	essentialTags.TTL = -1
+	essentialTags := EssentialTags{TTL: -1, Tags: make(map[string]string, len(tags))}
 	for i := range tags {
+		essentialTags.Tags[tags[i].Key] = tags[i].Value
 		switch tags[i].Key {
-		case "creationDate", "CreationDate":
-			essentialTags.CreationDate = stringDateToTimeDate(tags[i].Value)
+		case "creationDate", "CreationDate", "creation_date":
+			creationDate, err := ParseDate(tags[i].Value)
+			if err != nil {
+				essentialTags.Warnings = append(essentialTags.Warnings, fmt.Sprintf("invalid %s tag: %s", tags[i].Key, err.Error()))
+				continue
+			}
+			essentialTags.CreationDate = creationDate
 		case "ttl", "Ttl", "TTL":
-			result, _ := strconv.ParseInt(tags[i].Value, 10, 64)
-			essentialTags.TTL = result
+			ttl, err := ParseTTL(tags[i].Value)
+			if err != nil {
+				// as a ttl of 0, an invalid ttl keeps the resource
+				essentialTags.Warnings = append(essentialTags.Warnings, fmt.Sprintf("invalid %s tag, the resource is kept: %s", tags[i].Key, err.Error()))
+			}
+			essentialTags.TTL = ttl
+		case "expires_at", "delete_after":
+			expiresAt, err := ParseDate(tags[i].Value)
+			if err != nil {
+				essentialTags.Warnings = append(essentialTags.Warnings, fmt.Sprintf("invalid %s tag: %s", tags[i].Key, err.Error()))
+				continue
+			}
+			essentialTags.ExpiresAt = expiresAt
 		case "do_not_delete":
 			result, _ := strconv.ParseBool(tags[i].Value)
 			essentialTags.IsProtected = result
 		case "ClusterId":
 			essentialTags.ClusterId = tags[i].Value
+		case ExtendByTag, ExtendByLabel:
+			if _, err := ParseTTL(tags[i].Value); err != nil {
+				essentialTags.Warnings = append(essentialTags.Warnings, fmt.Sprintf("invalid %s tag: %s", tags[i].Key, err.Error()))
+			}
 		default:
 			continue
 		}
 	}
+	for key, label := range labelKeys {
+		if value, ok := essentialTags.Tags[label]; ok {
+			if _, ok := essentialTags.Tags[key]; !ok {
+				essentialTags.Tags[key] = value
+			}
+		}
+	}
+	if extension := tagExtension(essentialTags.Tags); extension > 0 {
+		if essentialTags.TTL > 0 {
+			essentialTags.TTL = extendTTL(essentialTags.TTL, extension)
+		}
+		if !essentialTags.ExpiresAt.IsZero() {
+			essentialTags.ExpiresAt = essentialTags.ExpiresAt.Add(time.Duration(extension) * time.Second)
+		}
+	}
+
 	// if 'tagName' value is present in above switch, then he won't be filled
 	for i := range tags {
 		if strings.EqualFold(tags[i].Key, tagName) {
@@ -165,6 +238,10 @@ func GetEssentialTags(tagsInput interface{}, tagName string) EssentialTags {
 
 func CheckIfExpired(creationTime time.Time, ttl int64, resourceNameDescription string, disableTTLCheck bool) bool {
 	if ttl == -1 && disableTTLCheck {
+		if creationTime.Year() < 1972 {
+			log.Warnf("Creation date is unknown. Can't check if resource %s is older than 4 hours.", resourceNameDescription)
+			return false
+		}
 		return time.Now().UTC().After(creationTime.Add(4 * time.Hour))
 	}
 
@@ -211,8 +288,12 @@ func ElemToDeleteFormattedInfos(elemName string, arraySize int, region string, i
 	return count, start
 }
 
-func IsAssociatedToLivingCluster(tagsInput interface{}, svc *eks.EKS) bool {
-	result, clusterErr := svc.ListClusters(&eks.ListClustersInput{})
+func IsAssociatedToLivingCluster(ctx context.Context, tagsInput interface{}, svc *eks.EKS) bool {
+	var clusters []*string
+	clusterErr := svc.ListClustersPagesWithContext(ctx, &eks.ListClustersInput{}, func(page *eks.ListClustersOutput, lastPage bool) bool {
+		clusters = append(clusters, page.Clusters...)
+		return true
+	})
 	if clusterErr != nil {
 		log.Error("Can't list cluster for ELB association check")
 		return false
@@ -220,7 +301,7 @@ func IsAssociatedToLivingCluster(tagsInput interface{}, svc *eks.EKS) bool {
 
 	switch typedTags := tagsInput.(type) {
 	case []*elbv2.Tag:
-		for _, cluster := range result.Clusters {
+		for _, cluster := range clusters {
 			for _, tag := range typedTags {
 				// ALB controller key contains '/cluster' and cluster name is the value
 				if strings.Contains(*tag.Key, "/cluster") && *tag.Value == *cluster {
@@ -233,7 +314,7 @@ func IsAssociatedToLivingCluster(tagsInput interface{}, svc *eks.EKS) bool {
 			}
 		}
 	case []*ec2.Tag:
-		for _, cluster := range result.Clusters {
+		for _, cluster := range clusters {
 			for _, tag := range typedTags {
 				if strings.Contains(*tag.Key, "/cluster/") && strings.Contains(*tag.Key, *cluster) {
 					return true
@@ -247,19 +328,14 @@ func IsAssociatedToLivingCluster(tagsInput interface{}, svc *eks.EKS) bool {
 	return false
 }
 
-func stringDateToTimeDate(date string) time.Time {
-	year, _ := strconv.ParseInt(date[0:4], 10, 32)
-	month, _ := strconv.ParseInt(date[5:7], 10, 32)
-	day, _ := strconv.ParseInt(date[8:10], 10, 32)
-	if len(date) <= 10 {
-		return time.Date(int(year), time.Month(month), int(day), 0, 0, 0, 0, time.UTC)
+// Sleep waits for the duration, unless ctx is done first.
+func Sleep(ctx context.Context, duration time.Duration) error {
+	select {
+	case <-time.After(duration):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
-
-	hour, _ := strconv.ParseInt(date[11:13], 10, 32)
-	minutes, _ := strconv.ParseInt(date[14:16], 10, 32)
-	seconds, _ := strconv.ParseInt(date[17:18], 10, 32)
-
-	return time.Date(int(year), time.Month(month), int(day), int(hour), int(minutes), int(seconds), 0, time.UTC)
 }
 
 func CheckSnapshot(snap *rds.DBSnapshot) bool {
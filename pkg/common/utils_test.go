@@ -0,0 +1,96 @@
+package common
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetEssentialTagsGCPLabels(t *testing.T) {
+	labels := map[string]string{
+		"ttl":           "3600",
+		"creation_date": "1700000000",
+		"do_not_delete": "true",
+		"pleco":         "my-cluster",
+		"team":          "core",
+	}
+
+	essentialTags := GetEssentialTags(labels, "pleco")
+	if essentialTags.TTL != 3600 {
+		t.Errorf("expected a ttl of 3600, got %d", essentialTags.TTL)
+	}
+	if expected := time.Unix(1700000000, 0).UTC(); !essentialTags.CreationDate.Equal(expected) {
+		t.Errorf("expected creation date %s, got %s", expected, essentialTags.CreationDate)
+	}
+	if !essentialTags.IsProtected {
+		t.Error("the resource should be protected")
+	}
+	if essentialTags.Tag != "my-cluster" {
+		t.Errorf("expected tag my-cluster, got %q", essentialTags.Tag)
+	}
+	if essentialTags.Tags["team"] != "core" || len(essentialTags.Tags) != len(labels) {
+		t.Errorf("every label should be kept, got %v", essentialTags.Tags)
+	}
+	if len(essentialTags.Warnings) != 0 {
+		t.Errorf("unexpected warnings %v", essentialTags.Warnings)
+	}
+}
+
+func TestGetEssentialTagsAzureTags(t *testing.T) {
+	ttl, expiresAt, empty := "1d", "2030-01-02T03:04:05Z", ""
+	tags := map[string]*string{
+		"TTL":        &ttl,
+		"expires_at": &expiresAt,
+		"Pleco":      &empty,
+		"unset":      nil,
+	}
+
+	essentialTags := GetEssentialTags(tags, "pleco")
+	if essentialTags.TTL != 86400 {
+		t.Errorf("expected a ttl of 86400, got %d", essentialTags.TTL)
+	}
+	if expected := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC); !essentialTags.ExpiresAt.Equal(expected) {
+		t.Errorf("expected expiration date %s, got %s", expected, essentialTags.ExpiresAt)
+	}
+	if _, ok := essentialTags.Tags["unset"]; ok {
+		t.Error("tags without value should be skipped")
+	}
+	if !essentialTags.CreationDate.IsZero() || essentialTags.IsProtected {
+		t.Errorf("unexpected creation date or protection: %+v", essentialTags)
+	}
+}
+
+func TestGetEssentialTagsInvalidValues(t *testing.T) {
+	essentialTags := GetEssentialTags(map[string]string{"ttl": "soon", "creation_date": "yesterday"}, "pleco")
+	if essentialTags.TTL != 0 {
+		t.Errorf("an invalid ttl should keep the resource with a ttl of 0, got %d", essentialTags.TTL)
+	}
+	if !essentialTags.CreationDate.IsZero() {
+		t.Errorf("an invalid creation date should be ignored, got %s", essentialTags.CreationDate)
+	}
+	if len(essentialTags.Warnings) != 2 {
+		t.Errorf("expected 2 warnings, got %v", essentialTags.Warnings)
+	}
+}
+
+func TestGetEssentialTagsWithoutTTL(t *testing.T) {
+	if essentialTags := GetEssentialTags(map[string]string{}, "pleco"); essentialTags.TTL != -1 {
+		t.Errorf("a resource without ttl should have a ttl of -1, got %d", essentialTags.TTL)
+	}
+}
+
+func TestGetEssentialTagsExtensionBounds(t *testing.T) {
+	essentialTags := GetEssentialTags(map[string]string{"ttl": "9223372036", ExtendByTag: "1w"}, "pleco")
+	if essentialTags.TTL != maxTTL {
+		t.Errorf("the extension shouldn't overflow the ttl, got %d", essentialTags.TTL)
+	}
+}
+
+func TestGetEssentialTagsLabelKeys(t *testing.T) {
+	essentialTags := GetEssentialTags(map[string]string{"ttl": "3600", ExtendByLabel: "1h", WarnedAtLabel: "1700000000"}, "pleco")
+	if essentialTags.TTL != 7200 {
+		t.Errorf("the extend-by label should extend the ttl to 7200, got %d", essentialTags.TTL)
+	}
+	if essentialTags.Tags[WarnedAtTag] != "1700000000" {
+		t.Errorf("the warned-at label should be read as the %s tag, got %v", WarnedAtTag, essentialTags.Tags)
+	}
+}
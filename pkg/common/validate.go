@@ -3,6 +3,7 @@ package common
 import (
 	"log"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
@@ -18,19 +19,13 @@ func isUsed(cmd *cobra.Command, serviceName string) bool {
 func CheckEnvVars(cloudProvider string, cmd *cobra.Command) {
 	var requiredEnvVars []string
 
-	switch cloudProvider {
-	case "aws":
-		requiredEnvVars = append(requiredEnvVars, checkAWSEnvVars(cmd)...)
-	case "azure":
-		requiredEnvVars = append(requiredEnvVars, checkAzureEnvVars(cmd)...)
-	case "scaleway":
-		requiredEnvVars = append(requiredEnvVars, checkScalewayEnvVars(cmd)...)
-	case "do":
-		requiredEnvVars = append(requiredEnvVars, checkDOEnvVars(cmd)...)
-	case "gcp":
-		requiredEnvVars = append(requiredEnvVars, checkGCPEnvVars(cmd)...)
-	default:
-		log.Fatalf("Unknown cloud provider: %s. Should be \"aws\", \"azure\", \"scaleway\" or \"do\"", cloudProvider)
+	provider, ok := GetProvider(cloudProvider)
+	if !ok {
+		log.Fatalf("Unknown cloud provider: %s. Should be one of \"%s\"", cloudProvider, strings.Join(GetProviderNames(), "\", \""))
+	}
+
+	if len(GetEnabledFeatures(cloudProvider, cmd)) > 0 {
+		requiredEnvVars = append(requiredEnvVars, provider.RequiredEnvVars...)
 	}
 
 	kubeConn, err := cmd.Flags().GetString("kube-conn")
@@ -44,101 +39,3 @@ func CheckEnvVars(cloudProvider string, cmd *cobra.Command) {
 		}
 	}
 }
-
-func checkAWSEnvVars(cmd *cobra.Command) []string {
-	var requiredEnvVars = []string{
-		"AWS_ACCESS_KEY_ID",
-		"AWS_SECRET_ACCESS_KEY",
-	}
-
-	if isUsed(cmd, "rds") ||
-		isUsed(cmd, "documentdb") ||
-		isUsed(cmd, "elasticache") ||
-		isUsed(cmd, "eks") ||
-		isUsed(cmd, "elb") ||
-		isUsed(cmd, "vpc") ||
-		isUsed(cmd, "s3") ||
-		isUsed(cmd, "ebs") ||
-		isUsed(cmd, "cloudwatch-logs") ||
-		isUsed(cmd, "kms") ||
-		isUsed(cmd, "iam") ||
-		isUsed(cmd, "ssh-keys") ||
-		isUsed(cmd, "ecr") {
-		return requiredEnvVars
-	}
-
-	return []string{}
-}
-
-func checkAzureEnvVars(cmd *cobra.Command) []string {
-	var requiredEnvVars = []string{
-		"AZURE_CLIENT_ID",
-		"AZURE_CLIENT_SECRET",
-		"AZURE_TENANT_ID",
-		"AZURE_SUBSCRIPTION_ID",
-	}
-
-	if isUsed(cmd, "rg") ||
-		isUsed(cmd, "acr") ||
-		isUsed(cmd, "storage-account") {
-		return requiredEnvVars
-	}
-
-	return []string{}
-}
-
-
-
-func checkScalewayEnvVars(cmd *cobra.Command) []string {
-	var requiredEnvVars = []string{
-		"SCW_ACCESS_KEY",
-		"SCW_SECRET_KEY",
-	}
-
-	if isUsed(cmd, "cluster") ||
-		isUsed(cmd, "db") ||
-		isUsed(cmd, "s3") ||
-		isUsed(cmd, "cr") ||
-		isUsed(cmd, "lb") ||
-		isUsed(cmd, "sg") ||
-		isUsed(cmd, "volume") {
-		return requiredEnvVars
-	}
-
-	return []string{}
-}
-
-func checkDOEnvVars(cmd *cobra.Command) []string {
-	var requiredEnvVars = []string{
-		"DO_API_TOKEN",
-		"DO_SPACES_KEY",
-		"DO_SPACES_KEY",
-	}
-
-	if isUsed(cmd, "cluster") ||
-		isUsed(cmd, "db") ||
-		isUsed(cmd, "s3") ||
-		isUsed(cmd, "lb") ||
-		isUsed(cmd, "volume") ||
-		isUsed(cmd, "firewall") ||
-		isUsed(cmd, "vpc") {
-		return requiredEnvVars
-	}
-
-	return []string{}
-}
-
-func checkGCPEnvVars(cmd *cobra.Command) []string {
-	var requiredEnvVars = []string{
-		"GOOGLE_APPLICATION_CREDENTIALS",
-	}
-	if isUsed(cmd, "cluster") ||
-		isUsed(cmd, "object-storage") ||
-		isUsed(cmd, "artifact-registry") ||
-		isUsed(cmd, "network") ||
-		isUsed(cmd, "iam") {
-		return requiredEnvVars
-	}
-
-	return []string{}
-}
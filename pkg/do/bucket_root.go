@@ -1,49 +1,53 @@
 package do
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
 	"github.com/digitalocean/godo"
 	"github.com/minio/minio-go/v7"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/Qovery/pleco/pkg/common"
 )
 
-func DeleteExpiredBuckets(sessions DOSessions, options DOOptions) {
-	expiredBuckets := emptyBuckets(sessions.Client, sessions.Bucket, &options)
+// bucketCleaner selects unused buckets which don't hold the kubeconfigs or the logs of a living cluster.
+type bucketCleaner struct {
+	doCleaner
+	common.AlwaysEvaluator
+	bucketApi *minio.Client
+}
 
-	count, start := common.ElemToDeleteFormattedInfos("expired bucket", len(expiredBuckets), options.Region)
+func init() {
+	registerRegionalCleaner("s3", "spaces-bucket", "expired bucket", func(base doCleaner) common.Cleaner {
+		return bucketCleaner{doCleaner: base, bucketApi: CreateMinIOSession(base.options.Region)}
+	})
+}
 
-	log.Info(count)
+func (c bucketCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	buckets := getBucketsToEmpty(ctx, c.client, c.bucketApi, &c.options)
 
-	if options.DryRun || len(expiredBuckets) == 0 {
-		return
+	var resources []common.CloudProviderResource
+	for _, bucket := range buckets {
+		resource := bucket.CloudProviderResource
+		resource.Payload = bucket
+		resources = append(resources, resource)
 	}
 
-	log.Info(start)
-
-	for _, expiredBucket := range expiredBuckets {
-		common.DeleteBucket(sessions.Bucket, expiredBucket, options.Region)
-	}
+	return resources, nil
 }
 
-func emptyBuckets(doApi *godo.Client, bucketApi *minio.Client, options *DOOptions) []common.MinioBucket {
-	buckets := getBucketsToEmpty(doApi, bucketApi, options)
-
-	for _, bucket := range buckets {
-		if !options.DryRun {
-			common.EmptyBucket(bucketApi, bucket.Identifier, bucket.ObjectsInfos)
-		}
-	}
+func (c bucketCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	bucket := resource.Payload.(common.MinioBucket)
+	common.EmptyBucket(c.bucketApi, ctx, bucket.Identifier, bucket.ObjectsInfos)
+	common.DeleteBucket(c.bucketApi, ctx, bucket, c.options.Region)
 
-	return buckets
+	return nil
 }
 
-func getBucketsToEmpty(doApi *godo.Client, bucketApi *minio.Client, options *DOOptions) []common.MinioBucket {
-	buckets := common.GetUnusedBuckets(bucketApi, options.TagName, options.Region, options.IsDestroyingCommand)
-	clusters := listClusters(doApi, options.TagName, options.Region)
+func getBucketsToEmpty(ctx context.Context, doApi *godo.Client, bucketApi *minio.Client, options *DOOptions) []common.MinioBucket {
+	buckets := common.GetUnusedBuckets(bucketApi, ctx, options.TagName, options.Region, options.IsDestroyingCommand)
+	clusters := listClusters(ctx, doApi, options.TagName, options.Region)
 
 	checkingBuckets := make(map[string]common.MinioBucket)
 	for _, bucket := range buckets {
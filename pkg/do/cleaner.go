@@ -0,0 +1,68 @@
+package do
+
+import (
+	"github.com/digitalocean/godo"
+
+	"github.com/Qovery/pleco/pkg/common"
+)
+
+const providerName = "do"
+
+func init() {
+	common.RegisterProvider(common.ProviderDefinition{
+		Name:                  providerName,
+		LocationsFlag:         "do-regions",
+		LocationsShorthand:    "a",
+		LocationsUsage:        "Set Digital Ocean regions (default is all, the available regions)",
+		ExcludedLocationsFlag: "do-exclude-regions",
+		RequiredEnvVars:       []string{"DO_API_TOKEN", "DO_SPACES_KEY", "DO_SPACES_SECRET"},
+		Features: []common.Feature{
+			{Name: "cluster", Shorthand: "e", Usage: "Enable Kubernetes clusters watch"},
+			{Name: "db", Shorthand: "r", Usage: "Enable databases watch"},
+			{Name: "s3", Shorthand: "s", Usage: "Enable buckets watch"},
+			{Name: "lb", Shorthand: "l", Usage: "Enable load balancers watch"},
+			{Name: "volume", Shorthand: "b", Usage: "Enable volumes watch"},
+			{Name: "firewall", Shorthand: "f", Usage: "Enable firewalls watch"},
+			{Name: "vpc", Shorthand: "v", Usage: "Enable VPCs watch"},
+		},
+	})
+
+	common.RegisterDependencies(providerName, "volume", "doks-cluster")
+	common.RegisterDependencies(providerName, "vpc", "load-balancer")
+}
+
+// doCleaner is embedded by every Digital Ocean cleaner, it is also the provider scope given to the cleaners constructors.
+type doCleaner struct {
+	common.TTLEvaluator
+	client  *godo.Client
+	options DOOptions
+}
+
+func newDOCleaner(client *godo.Client, options DOOptions) doCleaner {
+	return doCleaner{
+		TTLEvaluator: common.TTLEvaluator{TagValue: options.TagValue, DisableTTLCheck: options.DisableTTLCheck},
+		client:       client,
+		options:      options,
+	}
+}
+
+func registerCleaner(scope common.Scope, feature string, kind string, description string, newCleaner func(base doCleaner) common.Cleaner) {
+	common.RegisterCleaner(common.CleanerDefinition{
+		Provider:    providerName,
+		Feature:     feature,
+		Kind:        kind,
+		Description: description,
+		Scope:       scope,
+		New: func(providerScope interface{}) (common.Cleaner, error) {
+			return newCleaner(providerScope.(doCleaner)), nil
+		},
+	})
+}
+
+func registerRegionalCleaner(feature string, kind string, description string, newCleaner func(base doCleaner) common.Cleaner) {
+	registerCleaner(common.RegionScope, feature, kind, description, newCleaner)
+}
+
+func registerGlobalCleaner(feature string, kind string, description string, newCleaner func(base doCleaner) common.Cleaner) {
+	registerCleaner(common.GlobalScope, feature, kind, description, newCleaner)
+}
@@ -15,26 +15,36 @@ type DOCluster struct {
 	Name string
 }
 
-func DeleteExpiredClusters(sessions DOSessions, options DOOptions) {
-	expiredClusters, region := getExpiredClusters(sessions.Client, &options)
+type clusterCleaner struct {
+	doCleaner
+}
 
-	count, start := common.ElemToDeleteFormattedInfos("expired cluster", len(expiredClusters), region)
+func init() {
+	registerRegionalCleaner("cluster", "doks-cluster", "expired cluster", func(base doCleaner) common.Cleaner {
+		return clusterCleaner{base}
+	})
+}
 
-	log.Info(count)
+func (c clusterCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	clusters := listClusters(ctx, c.client, c.options.TagName, c.options.Region)
 
-	if options.DryRun || len(expiredClusters) == 0 {
-		return
+	var resources []common.CloudProviderResource
+	for _, cluster := range clusters {
+		resources = append(resources, cluster.CloudProviderResource)
 	}
 
-	log.Info(start)
+	return resources, nil
+}
 
-	for _, expiredCluster := range expiredClusters {
-		deleteCluster(sessions.Client, expiredCluster, region)
-	}
+func (c clusterCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	_, err := c.client.Kubernetes.Delete(ctx, resource.Identifier)
+	return err
 }
 
-func listClusters(client *godo.Client, tagName string, region string) []DOCluster {
-	result, _, err := client.Kubernetes.List(context.TODO(), &godo.ListOptions{})
+func listClusters(ctx context.Context, client *godo.Client, tagName string, region string) []DOCluster {
+	result, err := listAllPages(func(options *godo.ListOptions) ([]*godo.KubernetesCluster, *godo.Response, error) {
+		return client.Kubernetes.List(ctx, options)
+	})
 
 	if err != nil {
 		log.Errorf("Can't list cluster for region %s: %s", region, err.Error())
@@ -54,6 +64,9 @@ func listClusters(client *godo.Client, tagName string, region string) []DOCluste
 				TTL:          essentialTags.TTL,
 				Tag:          essentialTags.Tag,
 				IsProtected:  essentialTags.IsProtected,
+				ExpiresAt:    essentialTags.ExpiresAt,
+				Warnings:     essentialTags.Warnings,
+				Tags:         essentialTags.Tags,
 			},
 			Name: cluster.Name,
 		})
@@ -61,26 +74,3 @@ func listClusters(client *godo.Client, tagName string, region string) []DOCluste
 
 	return clusters
 }
-
-func getExpiredClusters(client *godo.Client, options *DOOptions) ([]DOCluster, string) {
-	clusters := listClusters(client, options.TagName, options.Region)
-
-	expiredClusters := []DOCluster{}
-	for _, cluster := range clusters {
-		if cluster.IsResourceExpired(options.TagValue, options.DisableTTLCheck) {
-			expiredClusters = append(expiredClusters, cluster)
-		}
-	}
-
-	return expiredClusters, options.Region
-}
-
-func deleteCluster(client *godo.Client, cluster DOCluster, region string) {
-	_, err := client.Kubernetes.Delete(context.TODO(), cluster.Identifier)
-
-	if err != nil {
-		log.Errorf("Can't delete cluster %s: %s", cluster.Name, err.Error())
-	} else {
-		log.Debugf("DOKS cluster %s in %s deleted.", cluster.Name, region)
-	}
-}
@@ -43,3 +43,26 @@ func volumeTimeout() time.Duration {
 
 	return 24
 }
+
+// listAllPages calls a godo list function page after page, until the last one.
+func listAllPages[T any](list func(options *godo.ListOptions) ([]T, *godo.Response, error)) ([]T, error) {
+	var items []T
+	options := &godo.ListOptions{PerPage: 200}
+	for {
+		result, response, err := list(options)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, result...)
+
+		if response == nil || response.Links == nil || response.Links.IsLastPage() {
+			return items, nil
+		}
+
+		page, err := response.Links.CurrentPage()
+		if err != nil {
+			return nil, err
+		}
+		options.Page = page + 1
+	}
+}
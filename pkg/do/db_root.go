@@ -15,39 +15,36 @@ type DODB struct {
 	Name string
 }
 
-func DeleteExpiredDatabases(sessions DOSessions, options DOOptions) {
-	expiredDatabases := getExpiredDatabases(sessions.Client, &options)
-
-	count, start := common.ElemToDeleteFormattedInfos("expired database", len(expiredDatabases), options.Region)
-
-	log.Info(count)
-
-	if options.DryRun || len(expiredDatabases) == 0 {
-		return
-	}
-
-	log.Info(start)
+type databaseCleaner struct {
+	doCleaner
+}
 
-	for _, expiredDb := range expiredDatabases {
-		deleteDB(sessions.Client, expiredDb, options.Region)
-	}
+func init() {
+	registerRegionalCleaner("db", "database-cluster", "expired database", func(base doCleaner) common.Cleaner {
+		return databaseCleaner{base}
+	})
 }
 
-func getExpiredDatabases(client *godo.Client, options *DOOptions) []DODB {
-	databases := listDatabases(client, options)
+func (c databaseCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	databases := listDatabases(ctx, c.client, &c.options)
 
-	expiredDbs := []DODB{}
+	var resources []common.CloudProviderResource
 	for _, db := range databases {
-		if db.IsResourceExpired(options.TagValue, options.DisableTTLCheck) {
-			expiredDbs = append(expiredDbs, db)
-		}
+		resources = append(resources, db.CloudProviderResource)
 	}
 
-	return expiredDbs
+	return resources, nil
 }
 
-func listDatabases(client *godo.Client, options *DOOptions) []DODB {
-	result, _, err := client.Databases.List(context.TODO(), &godo.ListOptions{})
+func (c databaseCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	_, err := c.client.Databases.Delete(ctx, resource.Identifier)
+	return err
+}
+
+func listDatabases(ctx context.Context, client *godo.Client, options *DOOptions) []DODB {
+	result, err := listAllPages(func(options *godo.ListOptions) ([]godo.Database, *godo.Response, error) {
+		return client.Databases.List(ctx, options)
+	})
 
 	if err != nil {
 		log.Errorf("Can't list databases for region %s: %s", options.Region, err.Error())
@@ -67,6 +64,9 @@ func listDatabases(client *godo.Client, options *DOOptions) []DODB {
 				TTL:          essentialTags.TTL,
 				Tag:          essentialTags.Tag,
 				IsProtected:  essentialTags.IsProtected,
+				ExpiresAt:    essentialTags.ExpiresAt,
+				Warnings:     essentialTags.Warnings,
+				Tags:         essentialTags.Tags,
 			},
 			Name: db.Name,
 		})
@@ -74,13 +74,3 @@ func listDatabases(client *godo.Client, options *DOOptions) []DODB {
 
 	return databases
 }
-
-func deleteDB(client *godo.Client, db DODB, region string) {
-	_, err := client.Databases.Delete(context.TODO(), db.Identifier)
-
-	if err != nil {
-		log.Errorf("Can't delete database %s: %s", db.Name, err.Error())
-	} else {
-		log.Debugf("Database %s in %s deleted.", db.Name, region)
-	}
-}
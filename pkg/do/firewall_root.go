@@ -18,27 +18,40 @@ type DOFirewall struct {
 	Droplets     []int
 }
 
-func DeleteExpiredFirewalls(sessions DOSessions, options DOOptions) {
-	expiredFirewalls := getDetachedFirewalls(sessions.Client, &options)
+type firewallCleaner struct {
+	doCleaner
+	common.AlwaysEvaluator
+}
 
-	count, start := common.ElemToDeleteFormattedInfos(fmt.Sprintf("detached (%d hours delay) firewall", volumeTimeout()), len(expiredFirewalls), options.Region)
+func init() {
+	registerGlobalCleaner("firewall", "firewall", fmt.Sprintf("detached (%d hours delay) firewall", volumeTimeout()), func(base doCleaner) common.Cleaner {
+		return firewallCleaner{doCleaner: base}
+	})
+}
 
-	log.Info(count)
+func (c firewallCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	detachedFirewalls := getDetachedFirewalls(ctx, c.client, &c.options)
 
-	if options.DryRun || len(expiredFirewalls) == 0 {
-		return
+	var resources []common.CloudProviderResource
+	for _, firewall := range detachedFirewalls {
+		resources = append(resources, common.CloudProviderResource{
+			Identifier:   firewall.ID,
+			Description:  "Firewall: " + firewall.Name,
+			CreationDate: firewall.CreationDate,
+		})
 	}
 
-	log.Info(start)
+	return resources, nil
+}
 
-	for _, expiredFirewall := range expiredFirewalls {
-		deleteFirewall(sessions.Client, expiredFirewall, options.Region)
-	}
+func (c firewallCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	_, err := c.client.Firewalls.Delete(ctx, resource.Identifier)
+	return err
 }
 
-func getFirewalls(client *godo.Client) []DOFirewall {
-	result, _, err := client.Firewalls.List(context.TODO(), &godo.ListOptions{
-		PerPage: 200,
+func getFirewalls(ctx context.Context, client *godo.Client) []DOFirewall {
+	result, err := listAllPages(func(options *godo.ListOptions) ([]godo.Firewall, *godo.Response, error) {
+		return client.Firewalls.List(ctx, options)
 	})
 	if err != nil {
 		log.Errorf("Can't list firewalls: %s", err.Error())
@@ -62,8 +75,8 @@ func getFirewalls(client *godo.Client) []DOFirewall {
 	return firewalls
 }
 
-func getDetachedFirewalls(client *godo.Client, options *DOOptions) []DOFirewall {
-	firewalls := getFirewalls(client)
+func getDetachedFirewalls(ctx context.Context, client *godo.Client, options *DOOptions) []DOFirewall {
+	firewalls := getFirewalls(ctx, client)
 
 	detachedFirewalls := []DOFirewall{}
 	for _, firewall := range firewalls {
@@ -75,13 +88,3 @@ func getDetachedFirewalls(client *godo.Client, options *DOOptions) []DOFirewall
 
 	return detachedFirewalls
 }
-
-func deleteFirewall(client *godo.Client, detachedFirewall DOFirewall, region string) {
-	_, err := client.Firewalls.Delete(context.TODO(), detachedFirewall.ID)
-
-	if err != nil {
-		log.Errorf("Can't delete detached firewall %s: %s", detachedFirewall.Name, err.Error())
-	} else {
-		log.Debugf("Firewall %s in %s deleted.", detachedFirewall.Name, region)
-	}
-}
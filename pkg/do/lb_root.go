@@ -17,26 +17,38 @@ type DOLB struct {
 	PublicIp string
 }
 
-func DeleteExpiredLBs(sessions DOSessions, options DOOptions) {
-	expiredLBs := getExpiredLBs(sessions.Client, &options)
+// lbCleaner relies on getExpiredLBs which also selects load balancers without droplets.
+type lbCleaner struct {
+	doCleaner
+	common.AlwaysEvaluator
+}
 
-	count, start := common.ElemToDeleteFormattedInfos("expired load balancer", len(expiredLBs), options.Region)
+func init() {
+	registerGlobalCleaner("lb", "load-balancer", "expired load balancer", func(base doCleaner) common.Cleaner {
+		return lbCleaner{doCleaner: base}
+	})
+}
 
-	log.Info(count)
+func (c lbCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	expiredLBs := getExpiredLBs(ctx, c.client, &c.options)
 
-	if options.DryRun || len(expiredLBs) == 0 {
-		return
+	var resources []common.CloudProviderResource
+	for _, lb := range expiredLBs {
+		resources = append(resources, lb.CloudProviderResource)
 	}
 
-	log.Info(start)
+	return resources, nil
+}
 
-	for _, expiredLB := range expiredLBs {
-		deleteLB(sessions.Client, expiredLB, options.Region)
-	}
+func (c lbCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	_, err := c.client.LoadBalancers.Delete(ctx, resource.Identifier)
+	return err
 }
 
-func listLBs(client *godo.Client, tagName string) []DOLB {
-	result, _, err := client.LoadBalancers.List(context.TODO(), &godo.ListOptions{PerPage: 200})
+func listLBs(ctx context.Context, client *godo.Client, tagName string) []DOLB {
+	result, err := listAllPages(func(options *godo.ListOptions) ([]godo.LoadBalancer, *godo.Response, error) {
+		return client.LoadBalancers.List(ctx, options)
+	})
 
 	if err != nil {
 		log.Errorf("Can't list load balancers: %s", err.Error())
@@ -56,6 +68,9 @@ func listLBs(client *godo.Client, tagName string) []DOLB {
 				TTL:          essentialTags.TTL,
 				Tag:          essentialTags.Tag,
 				IsProtected:  essentialTags.IsProtected,
+				ExpiresAt:    essentialTags.ExpiresAt,
+				Warnings:     essentialTags.Warnings,
+				Tags:         essentialTags.Tags,
 			},
 			Name:     lb.Name,
 			Droplets: lb.DropletIDs,
@@ -66,8 +81,8 @@ func listLBs(client *godo.Client, tagName string) []DOLB {
 	return loadBalancers
 }
 
-func getExpiredLBs(client *godo.Client, options *DOOptions) []DOLB {
-	lbs := listLBs(client, options.TagName)
+func getExpiredLBs(ctx context.Context, client *godo.Client, options *DOOptions) []DOLB {
+	lbs := listLBs(ctx, client, options.TagName)
 
 	expiredLBs := []DOLB{}
 	for _, lb := range lbs {
@@ -82,13 +97,3 @@ func getExpiredLBs(client *godo.Client, options *DOOptions) []DOLB {
 
 	return expiredLBs
 }
-
-func deleteLB(client *godo.Client, loadBalancer DOLB, region string) {
-	_, err := client.LoadBalancers.Delete(context.TODO(), loadBalancer.Identifier)
-
-	if err != nil {
-		log.Errorf("Can't delete load balancer %s: %s", loadBalancer.Name, err.Error())
-	} else {
-		log.Debugf("Load balancer %s: %s in %s deleted.", loadBalancer.Name, loadBalancer.PublicIp, region)
-	}
-}
@@ -0,0 +1,27 @@
+package do
+
+import (
+	"context"
+
+	"github.com/digitalocean/godo"
+)
+
+// DiscoverRegions returns the regions available for new resources.
+func DiscoverRegions(ctx context.Context) ([]string, error) {
+	client := CreateSession()
+	regions, err := listAllPages(func(options *godo.ListOptions) ([]godo.Region, *godo.Response, error) {
+		return client.Regions.List(ctx, options)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var slugs []string
+	for _, region := range regions {
+		if region.Available {
+			slugs = append(slugs, region.Slug)
+		}
+	}
+
+	return slugs, nil
+}
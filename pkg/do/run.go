@@ -1,12 +1,12 @@
 package do
 
 import (
-	"github.com/minio/minio-go/v7"
+	"context"
 	"sync"
-	"time"
 
-	"github.com/digitalocean/godo"
 	"github.com/sirupsen/logrus"
+
+	"github.com/Qovery/pleco/pkg/common"
 )
 
 type DOOptions struct {
@@ -16,107 +16,47 @@ type DOOptions struct {
 	IsDestroyingCommand bool
 	DryRun              bool
 	Region              string
-	EnableCluster       bool
-	EnableDB            bool
-	EnableBucket        bool
-	EnableLB            bool
-	EnableVolume        bool
-	EnableFirewall      bool
-	EnableVPC           bool
-}
-
-type DOSessions struct {
-	Client *godo.Client
-	Bucket *minio.Client
+	Features            common.FeatureSet
 }
 
-type funcDeleteExpired func(sessions DOSessions, options DOOptions)
-
-func RunPlecoDO(regions []string, interval int64, wg *sync.WaitGroup, options DOOptions) {
+func RunPlecoDO(ctx context.Context, regions []string, interval int64, wg *sync.WaitGroup, options DOOptions) {
 	for _, region := range regions {
 		wg.Add(1)
-		go runPlecoInRegion(region, interval, wg, options)
+		go runPlecoInRegion(ctx, region, interval, wg, options)
 	}
 
 	wg.Add(1)
-	go runPleco(interval, wg, options)
+	go runPleco(ctx, interval, wg, options)
 }
 
-func runPlecoInRegion(region string, interval int64, wg *sync.WaitGroup, options DOOptions) {
+func runPlecoInRegion(ctx context.Context, region string, interval int64, wg *sync.WaitGroup, options DOOptions) {
 	defer wg.Done()
-	sessions := DOSessions{}
-	sessions.Client = CreateSession()
 	options.Region = region
 
 	logrus.Infof("Starting to check expired resources in region %s.", options.Region)
 
-	var listServiceToCheckStatus []funcDeleteExpired
-
-	if options.EnableCluster {
-		listServiceToCheckStatus = append(listServiceToCheckStatus, DeleteExpiredClusters)
-	}
-
-	if options.EnableDB {
-		listServiceToCheckStatus = append(listServiceToCheckStatus, DeleteExpiredDatabases)
-	}
-
-	if options.EnableVolume {
-		listServiceToCheckStatus = append(listServiceToCheckStatus, DeleteExpiredVolumes)
-	}
-
-	if options.EnableBucket {
-		sessions.Bucket = CreateMinIOSession(options.Region)
+	engine := common.NewEngine(common.EngineOptions{
+		Provider: providerName,
+		Scope:    common.RegionScope,
+		Location: options.Region,
+		DryRun:   options.DryRun,
+		Features: options.Features,
+	}, newDOCleaner(CreateSession(), options))
 
-		listServiceToCheckStatus = append(listServiceToCheckStatus, DeleteExpiredBuckets)
-	}
-
-	if options.IsDestroyingCommand {
-		for _, check := range listServiceToCheckStatus {
-			check(sessions, options)
-		}
-	} else {
-		for {
-			for _, check := range listServiceToCheckStatus {
-				check(sessions, options)
-			}
-
-			time.Sleep(time.Duration(interval) * time.Second)
-		}
-	}
+	engine.Run(ctx, interval, options.IsDestroyingCommand)
 }
 
-func runPleco(interval int64, wg *sync.WaitGroup, options DOOptions) {
+func runPleco(ctx context.Context, interval int64, wg *sync.WaitGroup, options DOOptions) {
 	defer wg.Done()
-	sessions := DOSessions{}
-	sessions.Client = CreateSession()
 
 	logrus.Info("Starting to check global expired resources.")
 
-	var listServiceToCheckStatus []funcDeleteExpired
+	engine := common.NewEngine(common.EngineOptions{
+		Provider: providerName,
+		Scope:    common.GlobalScope,
+		DryRun:   options.DryRun,
+		Features: options.Features,
+	}, newDOCleaner(CreateSession(), options))
 
-	if options.EnableLB {
-		listServiceToCheckStatus = append(listServiceToCheckStatus, DeleteExpiredLBs)
-	}
-
-	if options.EnableFirewall {
-		listServiceToCheckStatus = append(listServiceToCheckStatus, DeleteExpiredFirewalls)
-	}
-
-	if options.EnableVPC {
-		listServiceToCheckStatus = append(listServiceToCheckStatus, DeleteExpiredVPCs)
-	}
-
-	if options.IsDestroyingCommand {
-		for _, check := range listServiceToCheckStatus {
-			check(sessions, options)
-		}
-	} else {
-		for {
-			for _, check := range listServiceToCheckStatus {
-				check(sessions, options)
-			}
-
-			time.Sleep(time.Duration(interval) * time.Second)
-		}
-	}
+	engine.Run(ctx, interval, options.IsDestroyingCommand)
 }
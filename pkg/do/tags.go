@@ -0,0 +1,65 @@
+package do
+
+import (
+	"context"
+
+	"github.com/digitalocean/godo"
+
+	"github.com/Qovery/pleco/pkg/common"
+)
+
+// SetTags sets the tags as "key:value", as DigitalOcean tags only hold letters, numbers, colons, dashes and underscores.
+func (c clusterCleaner) SetTags(ctx context.Context, resource common.CloudProviderResource, tags map[string]string) error {
+	cluster, _, err := c.client.Kubernetes.Get(ctx, resource.Identifier)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = c.client.Kubernetes.Update(ctx, resource.Identifier, &godo.KubernetesClusterUpdateRequest{
+		Name: cluster.Name,
+		Tags: common.TagList(cluster.Tags, common.LabelTags(tags), ":"),
+	})
+
+	return err
+}
+
+// SetTags replaces the database tags having the keys of the tags, as database tags are only set through the tags API.
+func (c databaseCleaner) SetTags(ctx context.Context, resource common.CloudProviderResource, tags map[string]string) error {
+	db, _, err := c.client.Databases.Get(ctx, resource.Identifier)
+	if err != nil {
+		return err
+	}
+
+	dbResource := []godo.Resource{{ID: resource.Identifier, Type: godo.DatabaseResourceType}}
+	dbTags := common.TagList(db.Tags, common.LabelTags(tags), ":")
+	for _, tag := range db.Tags {
+		if !contains(dbTags, tag) {
+			if _, err := c.client.Tags.UntagResources(ctx, tag, &godo.UntagResourcesRequest{Resources: dbResource}); err != nil {
+				return err
+			}
+		}
+	}
+	for _, tag := range dbTags {
+		if contains(db.Tags, tag) {
+			continue
+		}
+		if _, _, err := c.client.Tags.Create(ctx, &godo.TagCreateRequest{Name: tag}); err != nil {
+			return err
+		}
+		if _, err := c.client.Tags.TagResources(ctx, tag, &godo.TagResourcesRequest{Resources: dbResource}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+
+	return false
+}
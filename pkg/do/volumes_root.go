@@ -17,26 +17,41 @@ type DOVolume struct {
 	CreationDate time.Time
 }
 
-func DeleteExpiredVolumes(sessions DOSessions, options DOOptions) {
-	expiredVolumes := getDetachedVolumes(sessions.Client, &options)
+type volumeCleaner struct {
+	doCleaner
+	common.AlwaysEvaluator
+}
 
-	count, start := common.ElemToDeleteFormattedInfos(fmt.Sprintf("detached (%d hours delay) volume", volumeTimeout()), len(expiredVolumes), options.Region)
+func init() {
+	registerRegionalCleaner("volume", "volume", fmt.Sprintf("detached (%d hours delay) volume", volumeTimeout()), func(base doCleaner) common.Cleaner {
+		return volumeCleaner{doCleaner: base}
+	})
+}
 
-	log.Info(count)
+func (c volumeCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	detachedVolumes := getDetachedVolumes(ctx, c.client, &c.options)
 
-	if options.DryRun || len(expiredVolumes) == 0 {
-		return
+	var resources []common.CloudProviderResource
+	for _, volume := range detachedVolumes {
+		resources = append(resources, common.CloudProviderResource{
+			Identifier:   volume.ID,
+			Description:  "Detached volume: " + volume.Name,
+			CreationDate: volume.CreationDate,
+		})
 	}
 
-	log.Info(start)
+	return resources, nil
+}
 
-	for _, expiredVolume := range expiredVolumes {
-		deleteVolume(sessions.Client, expiredVolume, options.Region)
-	}
+func (c volumeCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	_, err := c.client.Storage.DeleteVolume(ctx, resource.Identifier)
+	return err
 }
 
-func getVolumes(client *godo.Client, region string) []DOVolume {
-	result, _, err := client.Storage.ListVolumes(context.TODO(), &godo.ListVolumeParams{Region: region})
+func getVolumes(ctx context.Context, client *godo.Client, region string) []DOVolume {
+	result, err := listAllPages(func(options *godo.ListOptions) ([]godo.Volume, *godo.Response, error) {
+		return client.Storage.ListVolumes(ctx, &godo.ListVolumeParams{Region: region, ListOptions: options})
+	})
 	if err != nil {
 		log.Errorf("Can't list volumes in zone %s: %s", region, err.Error())
 		return []DOVolume{}
@@ -57,8 +72,8 @@ func getVolumes(client *godo.Client, region string) []DOVolume {
 	return volumes
 }
 
-func getDetachedVolumes(client *godo.Client, options *DOOptions) []DOVolume {
-	volumes := getVolumes(client, options.Region)
+func getDetachedVolumes(ctx context.Context, client *godo.Client, options *DOOptions) []DOVolume {
+	volumes := getVolumes(ctx, client, options.Region)
 
 	detachedVolumes := []DOVolume{}
 	for _, volume := range volumes {
@@ -69,13 +84,3 @@ func getDetachedVolumes(client *godo.Client, options *DOOptions) []DOVolume {
 
 	return detachedVolumes
 }
-
-func deleteVolume(client *godo.Client, detachedVolume DOVolume, region string) {
-	_, err := client.Storage.DeleteVolume(context.TODO(), detachedVolume.ID)
-
-	if err != nil {
-		log.Errorf("Can't delete detached volume %s: %s", detachedVolume.Name, err.Error())
-	} else {
-		log.Debugf("Detached volume  %s in %s deleted.", detachedVolume.Name, region)
-	}
-}
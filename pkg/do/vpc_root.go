@@ -18,27 +18,40 @@ type DOVpc struct {
 	Members      []*godo.VPCMember
 }
 
-func DeleteExpiredVPCs(sessions DOSessions, options DOOptions) {
-	expiredVPCs := getExpiredVPCs(sessions.Client, &options)
+type vpcCleaner struct {
+	doCleaner
+	common.AlwaysEvaluator
+}
 
-	count, start := common.ElemToDeleteFormattedInfos(fmt.Sprintf("expired (%d hours delay) VPC", volumeTimeout()), len(expiredVPCs), options.Region)
+func init() {
+	registerGlobalCleaner("vpc", "vpc", fmt.Sprintf("expired (%d hours delay) VPC", volumeTimeout()), func(base doCleaner) common.Cleaner {
+		return vpcCleaner{doCleaner: base}
+	})
+}
 
-	log.Info(count)
+func (c vpcCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	expiredVPCs := getExpiredVPCs(ctx, c.client, &c.options)
 
-	if options.DryRun || len(expiredVPCs) == 0 {
-		return
+	var resources []common.CloudProviderResource
+	for _, VPC := range expiredVPCs {
+		resources = append(resources, common.CloudProviderResource{
+			Identifier:   VPC.ID,
+			Description:  "VPC: " + VPC.Name,
+			CreationDate: VPC.CreationDate,
+		})
 	}
 
-	log.Info(start)
+	return resources, nil
+}
 
-	for _, expiredVPC := range expiredVPCs {
-		deleteVPC(sessions.Client, expiredVPC, options.Region)
-	}
+func (c vpcCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	_, err := c.client.VPCs.Delete(ctx, resource.Identifier)
+	return err
 }
 
-func getVPCs(client *godo.Client, region string) []DOVpc {
-	result, _, err := client.VPCs.List(context.TODO(), &godo.ListOptions{
-		PerPage: 100,
+func getVPCs(ctx context.Context, client *godo.Client, region string) []DOVpc {
+	result, err := listAllPages(func(options *godo.ListOptions) ([]*godo.VPC, *godo.Response, error) {
+		return client.VPCs.List(ctx, options)
 	})
 
 	if err != nil {
@@ -48,11 +61,11 @@ func getVPCs(client *godo.Client, region string) []DOVpc {
 
 	VPCs := []DOVpc{}
 	for _, VPC := range result {
-		membersResult, _, membersErr := client.VPCs.ListMembers(context.TODO(), VPC.ID, &godo.VPCListMembersRequest{}, &godo.ListOptions{
-			PerPage: 200,
+		membersResult, membersErr := listAllPages(func(options *godo.ListOptions) ([]*godo.VPCMember, *godo.Response, error) {
+			return client.VPCs.ListMembers(ctx, VPC.ID, &godo.VPCListMembersRequest{}, options)
 		})
-		if membersErr != nil || membersResult == nil {
-			log.Errorf("Can't list members for VPC %s: %s", VPC.Name, err.Error())
+		if membersErr != nil {
+			log.Errorf("Can't list members for VPC %s: %s", VPC.Name, membersErr.Error())
 			continue
 		}
 
@@ -72,8 +85,8 @@ func getVPCs(client *godo.Client, region string) []DOVpc {
 	return VPCs
 }
 
-func getExpiredVPCs(client *godo.Client, options *DOOptions) []DOVpc {
-	VPCs := getVPCs(client, options.Region)
+func getExpiredVPCs(ctx context.Context, client *godo.Client, options *DOOptions) []DOVpc {
+	VPCs := getVPCs(ctx, client, options.Region)
 
 	expiredVPCs := []DOVpc{}
 	for _, VPC := range VPCs {
@@ -85,13 +98,3 @@ func getExpiredVPCs(client *godo.Client, options *DOOptions) []DOVpc {
 
 	return expiredVPCs
 }
-
-func deleteVPC(client *godo.Client, detachedVPC DOVpc, region string) {
-	_, err := client.VPCs.Delete(context.TODO(), detachedVPC.ID)
-
-	if err != nil {
-		log.Errorf("Can't delete expired VPC %s: %s", detachedVPC.Name, err.Error())
-	} else {
-		log.Debugf("VPC %s in %s deleted.", detachedVPC.Name, region)
-	}
-}
@@ -1,24 +1,40 @@
 package gcp
 
 import (
+	artifactregistry "cloud.google.com/go/artifactregistry/apiv1"
 	"cloud.google.com/go/artifactregistry/apiv1/artifactregistrypb"
 	"fmt"
+	"github.com/Qovery/pleco/pkg/common"
 	log "github.com/sirupsen/logrus"
 	"go.uber.org/ratelimit"
 	"golang.org/x/net/context"
-	"strconv"
-	"strings"
 	"time"
 )
 
-func DeleteExpiredArtifactRegistryRepositories(sessions GCPSessions, options GCPOptions) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
-	defer cancel()
+type artifactRegistryRepositoryCleaner struct {
+	gcpCleaner
+	limiter ratelimit.Limiter
+}
+
+func init() {
+	registerRegionalCleaner("artifact-registry", "artifact-registry-repository", "expired artifact registry repository", func(ctx context.Context, sessions *GCPSessions) error {
+		client, err := artifactregistry.NewClient(ctx)
+		if err != nil {
+			return fmt.Errorf("artifactregistry.NewClient: %w", err)
+		}
+		sessions.ArtifactRegistry = client
+
+		return nil
+	}, func(base gcpCleaner) common.Cleaner {
+		return artifactRegistryRepositoryCleaner{gcpCleaner: base, limiter: ratelimit.New(1, ratelimit.Per(1*time.Second))}
+	})
+}
 
-	limiter := ratelimit.New(1, ratelimit.Per(1*time.Second))
+func (c artifactRegistryRepositoryCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	var resources []common.CloudProviderResource
 	var pageToken = ""
 	for {
-		var repositoriesIterator = sessions.ArtifactRegistry.ListRepositories(ctx, &artifactregistrypb.ListRepositoriesRequest{Parent: fmt.Sprintf("projects/%s/locations/%s", options.ProjectID, options.Location), PageToken: pageToken, PageSize: 100})
+		var repositoriesIterator = c.sessions.ArtifactRegistry.ListRepositories(ctx, &artifactregistrypb.ListRepositoriesRequest{Parent: fmt.Sprintf("projects/%s/locations/%s", c.options.ProjectID, c.options.Location), PageToken: pageToken, PageSize: 100})
 
 		for {
 			repository, err := repositoriesIterator.Next()
@@ -26,39 +42,7 @@ func DeleteExpiredArtifactRegistryRepositories(sessions GCPSessions, options GCP
 				break
 			}
 
-			ttlStr, ok := repository.Labels["ttl"]
-			if !ok || strings.TrimSpace(ttlStr) == "" {
-				log.Info(fmt.Sprintf("No ttl label value found, ignoring this repository (`%s`)", repository.Name))
-				continue
-			}
-			ttl, err := strconv.ParseInt(ttlStr, 10, 64)
-			if err != nil {
-				log.Warn(fmt.Sprintf("ttl label value `%s` is not parsable to int64, ignoring this repository (`%s`)", ttlStr, repository.Name))
-				continue
-			}
-			creationTime := repository.CreateTime.AsTime()
-			// repository is not expired (or is protected TTL = 0)
-			if !ok || ttl == 0 || time.Now().UTC().Before(creationTime.UTC().Add(time.Second*time.Duration(ttl))) {
-				continue
-			}
-
-			if options.DryRun {
-				log.Info(fmt.Sprintf("Repository `%s will be deleted`", repository.Name))
-				continue
-			}
-
-			// repository is eligible to deletion
-			log.Info(fmt.Sprintf("Deleting repository `%s` created at `%s` UTC (TTL `{%d}` seconds)", repository.Name, creationTime.UTC(), ttl))
-
-			// wait for one available slot for deletion
-			ctxDelete, cancel := context.WithTimeout(context.Background(), time.Second*30)
-			defer cancel()
-			limiter.Take()
-			if _, err := sessions.ArtifactRegistry.DeleteRepository(ctxDelete, &artifactregistrypb.DeleteRepositoryRequest{
-				Name: repository.Name,
-			}); err != nil {
-				log.Error(fmt.Sprintf("Error deleting repository `%s`, error: %s", repository.Name, err))
-			}
+			resources = append(resources, c.newResource(repository.Name, "Repository: "+repository.Name, repository.CreateTime.AsTime(), repository.Labels))
 		}
 
 		var pageInfo = repositoriesIterator.PageInfo()
@@ -69,4 +53,20 @@ func DeleteExpiredArtifactRegistryRepositories(sessions GCPSessions, options GCP
 			break
 		}
 	}
+
+	return resources, nil
+}
+
+func (c artifactRegistryRepositoryCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	log.Info(fmt.Sprintf("Deleting repository `%s` created at `%s` UTC (TTL `{%d}` seconds)", resource.Identifier, resource.CreationDate, resource.TTL))
+
+	// wait for one available slot for deletion
+	ctxDelete, cancel := context.WithTimeout(ctx, time.Second*30)
+	defer cancel()
+	c.limiter.Take()
+	_, err := c.sessions.ArtifactRegistry.DeleteRepository(ctxDelete, &artifactregistrypb.DeleteRepositoryRequest{
+		Name: resource.Identifier,
+	})
+
+	return err
 }
@@ -0,0 +1,103 @@
+package gcp
+
+import (
+	"context"
+
+	"github.com/Qovery/pleco/pkg/common"
+)
+
+const providerName = "gcp"
+
+func init() {
+	common.RegisterProvider(common.ProviderDefinition{
+		Name:                  providerName,
+		LocationsFlag:         "gcp-regions",
+		LocationsUsage:        "Set GCP regions (default is all, the regions of the first project)",
+		ExcludedLocationsFlag: "gcp-exclude-regions",
+		AccountsFlag:          "gcp-projects",
+		AccountsUsage:         "Set GCP projects, or folders/<id> and organizations/<id> to clean all their projects (default is the project of the credentials)",
+		RequiredEnvVars:       []string{"GOOGLE_APPLICATION_CREDENTIALS"},
+		Features: []common.Feature{
+			{Name: "cluster", Usage: "Enable Kubernetes clusters watch"},
+			{Name: "object-storage", Usage: "Enable object storage buckets watch"},
+			{Name: "artifact-registry", Usage: "Enable artifact registry repositories watch"},
+			{Name: "network", Usage: "Enable Networks and its children watch"},
+			{Name: "router", Usage: "Enable Routers and its children watch"},
+			{Name: "iam", Usage: "Enable IAM (service accounts) watch"},
+			{Name: "job", Usage: "Enable Run Job watch"},
+		},
+	})
+
+	common.RegisterDependencies(providerName, "router", "gke-cluster")
+	common.RegisterDependencies(providerName, "network", "gke-cluster", "router")
+	common.RegisterDependencies(providerName, "non-existent-service-account-binding", "service-account")
+}
+
+// gcpCleaner is embedded by every GCP cleaner. GCP cleaners only hold the clients they need, created by
+// their constructor and closed once the engine stops.
+type gcpCleaner struct {
+	common.TTLEvaluator
+	sessions GCPSessions
+	options  GCPOptions
+}
+
+func (c gcpCleaner) Close() error {
+	if c.sessions.Bucket != nil {
+		_ = c.sessions.Bucket.Close()
+	}
+	if c.sessions.ArtifactRegistry != nil {
+		_ = c.sessions.ArtifactRegistry.Close()
+	}
+	if c.sessions.Cluster != nil {
+		_ = c.sessions.Cluster.Close()
+	}
+	if c.sessions.Network != nil {
+		_ = c.sessions.Network.Close()
+	}
+	if c.sessions.Subnetwork != nil {
+		_ = c.sessions.Subnetwork.Close()
+	}
+	if c.sessions.Route != nil {
+		_ = c.sessions.Route.Close()
+	}
+	if c.sessions.Router != nil {
+		_ = c.sessions.Router.Close()
+	}
+	if c.sessions.Job != nil {
+		_ = c.sessions.Job.Close()
+	}
+
+	return nil
+}
+
+func registerCleaner(scope common.Scope, feature string, kind string, description string, newSessions func(ctx context.Context, sessions *GCPSessions) error, newCleaner func(base gcpCleaner) common.Cleaner) {
+	common.RegisterCleaner(common.CleanerDefinition{
+		Provider:    providerName,
+		Feature:     feature,
+		Kind:        kind,
+		Description: description,
+		Scope:       scope,
+		New: func(providerScope interface{}) (common.Cleaner, error) {
+			options := providerScope.(GCPOptions)
+			base := gcpCleaner{
+				TTLEvaluator: common.TTLEvaluator{TagValue: options.TagValue, DisableTTLCheck: options.DisableTTLCheck},
+				options:      options,
+			}
+			if err := newSessions(context.Background(), &base.sessions); err != nil {
+				_ = base.Close()
+				return nil, err
+			}
+
+			return newCleaner(base), nil
+		},
+	})
+}
+
+func registerRegionalCleaner(feature string, kind string, description string, newSessions func(ctx context.Context, sessions *GCPSessions) error, newCleaner func(base gcpCleaner) common.Cleaner) {
+	registerCleaner(common.RegionScope, feature, kind, description, newSessions, newCleaner)
+}
+
+// registerProjectCleaner registers a cleaner of project wide resources, run once per project instead of once per region.
+func registerProjectCleaner(feature string, kind string, description string, newSessions func(ctx context.Context, sessions *GCPSessions) error, newCleaner func(base gcpCleaner) common.Cleaner) {
+	registerCleaner(common.GlobalScope, feature, kind, description, newSessions, newCleaner)
+}
@@ -1,63 +1,53 @@
 package gcp
 
 import (
+	container "cloud.google.com/go/container/apiv1"
 	"cloud.google.com/go/container/apiv1/containerpb"
 	"fmt"
+	"github.com/Qovery/pleco/pkg/common"
 	log "github.com/sirupsen/logrus"
 	"golang.org/x/net/context"
-	"strconv"
-	"strings"
 	"time"
 )
 
-func DeleteExpiredGKEClusters(sessions GCPSessions, options GCPOptions) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
-	defer cancel()
+type gkeClusterCleaner struct {
+	gcpCleaner
+}
+
+func init() {
+	registerRegionalCleaner("cluster", "gke-cluster", "expired GKE cluster", func(ctx context.Context, sessions *GCPSessions) error {
+		client, err := container.NewClusterManagerClient(ctx)
+		if err != nil {
+			return fmt.Errorf("container.NewClusterManagerClient: %w", err)
+		}
+		sessions.Cluster = client
+
+		return nil
+	}, func(base gcpCleaner) common.Cleaner {
+		return gkeClusterCleaner{base}
+	})
+}
 
-	clustersIterator, err := sessions.Cluster.ListClusters(ctx, &containerpb.ListClustersRequest{Parent: fmt.Sprintf("projects/%s/locations/%s", options.ProjectID, options.Location)})
+func (c gkeClusterCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	clustersIterator, err := c.sessions.Cluster.ListClusters(ctx, &containerpb.ListClustersRequest{Parent: fmt.Sprintf("projects/%s/locations/%s", c.options.ProjectID, c.options.Location)})
 	if err != nil {
-		log.Error(fmt.Sprintf("Error listing clusters, error: %s", err))
-		return
+		return nil, fmt.Errorf("error listing clusters, error: %s", err)
 	}
 
+	var resources []common.CloudProviderResource
 	for _, cluster := range clustersIterator.Clusters {
-		ttlStr, ok := cluster.ResourceLabels["ttl"]
-		if !ok || strings.TrimSpace(ttlStr) == "" {
-			log.Info(fmt.Sprintf("No ttl label value found, ignoring this cluster (`%s`)", cluster.Name))
-			continue
-		}
-		ttl, err := strconv.ParseInt(ttlStr, 10, 64)
-		if err != nil {
-			log.Warn(fmt.Sprintf("ttl label value `%s` is not parsable to int64, ignoring this cluster (`%s`)", ttlStr, cluster.Name))
-			continue
-		}
-		creationTimeStr, ok := cluster.ResourceLabels["creation_date"]
-		if !ok || strings.TrimSpace(ttlStr) == "" {
-			log.Info(fmt.Sprintf("No creation_date label value found, ignoring this cluster (`%s`)", cluster.Name))
-			continue
-		}
-		creationTimeInt64, err := strconv.ParseInt(creationTimeStr, 10, 64)
-		if err != nil {
-			log.Warn(fmt.Sprintf("creation_date label value `%s` is not parsable to int64, ignoring this cluster (`%s`)", ttlStr, cluster.Name))
-			continue
-		}
-		creationTime := time.Unix(creationTimeInt64, 0).UTC()
-		// cluster is not expired (or is protected TTL = 0)
-		if !ok || ttl == 0 || creationTimeInt64 == 0 || time.Now().UTC().Before(creationTime.Add(time.Second*time.Duration(ttl))) {
-			continue
-		}
+		creationTime, _ := time.Parse(time.RFC3339, cluster.CreateTime)
+		resources = append(resources, c.newResource(cluster.Name, "Cluster: "+cluster.Name, creationTime, cluster.ResourceLabels))
+	}
 
-		if options.DryRun {
-			log.Info(fmt.Sprintf("Cluster `%s will be deleted`", cluster.Name))
-			continue
-		}
+	return resources, nil
+}
 
-		// cluster is eligible to deletion
-		log.Info(fmt.Sprintf("Deleting cluster `%s` created at `%s` UTC (TTL `{%d}` seconds)", cluster.Name, creationTime.UTC(), ttl))
-		if _, err := sessions.Cluster.DeleteCluster(ctx, &containerpb.DeleteClusterRequest{
-			Name: fmt.Sprintf("projects/%s/locations/%s/clusters/%s", options.ProjectID, options.Location, cluster.Name),
-		}); err != nil {
-			log.Error(fmt.Sprintf("Error deleting cluster `%s`, error: %s", cluster.Name, err))
-		}
-	}
+func (c gkeClusterCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	log.Info(fmt.Sprintf("Deleting cluster `%s` created at `%s` UTC (TTL `{%d}` seconds)", resource.Identifier, resource.CreationDate, resource.TTL))
+	_, err := c.sessions.Cluster.DeleteCluster(ctx, &containerpb.DeleteClusterRequest{
+		Name: fmt.Sprintf("projects/%s/locations/%s/clusters/%s", c.options.ProjectID, c.options.Location, resource.Identifier),
+	})
+
+	return err
 }
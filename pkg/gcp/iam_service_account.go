@@ -1,9 +1,8 @@
 package gcp
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"strconv"
 	"strings"
 	"time"
 
@@ -11,71 +10,68 @@ import (
 	log "github.com/sirupsen/logrus"
 	cloudresourcemanager "google.golang.org/api/cloudresourcemanager/v1"
 	"google.golang.org/api/googleapi"
+	iam "google.golang.org/api/iam/v1"
 )
 
-func DeleteExpiredServiceAccounts(sessions GCPSessions, options GCPOptions) {
-	nextPageToken := ""
-	for {
-		serviceAccountsListResponse, err := sessions.IAM.Projects.ServiceAccounts.List("projects/" + options.ProjectID).
-			PageToken(nextPageToken).PageSize(100).Do()
-		if err != nil {
-			log.Error(fmt.Sprintf("Error listing service accounts, error: %s", err))
-			return
-		}
-
-		for _, serviceAccount := range serviceAccountsListResponse.Accounts {
-			resourceTags := common.ResourceTags{}
-			if err = json.Unmarshal([]byte(serviceAccount.Description), &resourceTags); err != nil {
-				log.Info(fmt.Sprintf("No resource tags found in description field, ignoring this service account (`%s`)", serviceAccount.Name))
-				continue
-			}
-
-			ttlStr := ""
-			if resourceTags.TTL != nil {
-				ttlStr = resourceTags.TTL.String()
-			} else {
-				log.Info(fmt.Sprintf("No ttl value found, ignoring this service account (`%s`)", serviceAccount.Name))
-				continue
-			}
+type serviceAccountCleaner struct {
+	gcpCleaner
+}
 
-			ttl, err := strconv.ParseInt(ttlStr, 10, 64)
-			if err != nil {
-				log.Warn(fmt.Sprintf("ttl label value `%s` is not parsable to int64, ignoring this service account (`%s`)", ttlStr, serviceAccount.Name))
-				continue
-			}
+// Binding cleaners only list bindings to delete, they aren't labelled.
+type orphanedIAMPolicyBindingCleaner struct {
+	gcpCleaner
+	common.AlwaysEvaluator
+}
 
-			creationTimeStr := ""
-			if resourceTags.CreationUnixTimestamp != nil {
-				creationTimeStr = resourceTags.CreationUnixTimestamp.String()
-			} else {
-				log.Info(fmt.Sprintf("No creation time value found, ignoring this service account (`%s`)", serviceAccount.Name))
-				continue
-			}
+type nonExistentServiceAccountBindingCleaner struct {
+	gcpCleaner
+	common.AlwaysEvaluator
+}
 
-			creationTimeInt64, err := strconv.ParseInt(creationTimeStr, 10, 64)
-			if err != nil {
-				log.Warn(fmt.Sprintf("creation_date label value `%s` is not parsable to int64, ignoring this service account (`%s`)", creationTimeStr, serviceAccount.Name))
-				continue
-			}
+// IAM cleaners are registered together so bindings of the service accounts deleted during the cycle are cleaned right after.
+func init() {
+	registerProjectCleaner("iam", "service-account", "expired service account", newIAMSessions, func(base gcpCleaner) common.Cleaner {
+		return serviceAccountCleaner{base}
+	})
+	registerProjectCleaner("iam", "orphaned-iam-policy-binding", "orphaned IAM policy binding", newIAMSessions, func(base gcpCleaner) common.Cleaner {
+		return orphanedIAMPolicyBindingCleaner{gcpCleaner: base}
+	})
+	registerProjectCleaner("iam", "non-existent-service-account-binding", "IAM binding for non-existent service account", newIAMSessions, func(base gcpCleaner) common.Cleaner {
+		return nonExistentServiceAccountBindingCleaner{gcpCleaner: base}
+	})
+}
 
-			creationTime := time.Unix(creationTimeInt64, 0).UTC()
+func newIAMSessions(ctx context.Context, sessions *GCPSessions) error {
+	iamService, err := iam.NewService(ctx)
+	if err != nil {
+		return fmt.Errorf("iam.NewService: %w", err)
+	}
+	sessions.IAM = iamService
 
-			if ttl == 0 || creationTimeInt64 == 0 || time.Now().UTC().Before(creationTime.Add(time.Second*time.Duration(ttl))) {
-				continue
-			}
+	crmService, err := cloudresourcemanager.NewService(ctx)
+	if err != nil {
+		return fmt.Errorf("cloudresourcemanager.NewService: %w", err)
+	}
+	sessions.CRM = crmService
 
-			if options.DryRun {
-				log.Info(fmt.Sprintf("Service account `%s` will be deleted", serviceAccount.Name))
-				continue
-			}
+	return nil
+}
 
-			log.Info(fmt.Sprintf("Deleting service account `%s`", serviceAccount.Name))
+func (c serviceAccountCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	var resources []common.CloudProviderResource
+	nextPageToken := ""
+	for {
+		serviceAccountsListResponse, err := c.sessions.IAM.Projects.ServiceAccounts.List("projects/" + c.options.ProjectID).
+			PageToken(nextPageToken).PageSize(100).Context(ctx).Do()
+		if err != nil {
+			return nil, fmt.Errorf("error listing service accounts, error: %s", err)
+		}
 
-			if _, err = sessions.IAM.Projects.ServiceAccounts.Delete(serviceAccount.Name).Do(); err != nil {
-				log.Error(fmt.Sprintf("Error deleting service account `%s`, error: %s", serviceAccount.Name, err))
-			} else {
-				removeServiceAccountIAMBindings(sessions, options, serviceAccount.Email)
-			}
+		for _, serviceAccount := range serviceAccountsListResponse.Accounts {
+			// service accounts have no creation date, it has to be set in their description
+			resource := c.newResource(serviceAccount.Name, "Service account: "+serviceAccount.Name, time.Time{}, descriptionLabels(serviceAccount.Description))
+			resource.Payload = serviceAccount.Email
+			resources = append(resources, resource)
 		}
 
 		nextPageToken = serviceAccountsListResponse.NextPageToken
@@ -84,6 +80,19 @@ func DeleteExpiredServiceAccounts(sessions GCPSessions, options GCPOptions) {
 			break
 		}
 	}
+
+	return resources, nil
+}
+
+func (c serviceAccountCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	log.Info(fmt.Sprintf("Deleting service account `%s`", resource.Identifier))
+
+	if _, err := c.sessions.IAM.Projects.ServiceAccounts.Delete(resource.Identifier).Context(ctx).Do(); err != nil {
+		return err
+	}
+
+	removeServiceAccountIAMBindings(ctx, c.sessions, c.options, resource.Payload.(string))
+	return nil
 }
 
 const iamPolicyMemberLimit = 1500
@@ -165,10 +174,10 @@ func isGCPManagedMember(member string) bool {
 // updateIAMPolicyWithRetry performs a read-modify-write on the project IAM policy,
 // retrying on 409 (concurrent modification) with exponential backoff.
 // The mutate function receives the current policy and returns whether it was changed.
-func updateIAMPolicyWithRetry(sessions GCPSessions, projectID string, mutate func(*cloudresourcemanager.Policy) bool) error {
+func updateIAMPolicyWithRetry(ctx context.Context, sessions GCPSessions, projectID string, mutate func(*cloudresourcemanager.Policy) bool) error {
 	backoff := 500 * time.Millisecond
 	for attempt := 0; attempt <= iamPolicyMaxRetries; attempt++ {
-		policy, err := sessions.CRM.Projects.GetIamPolicy(projectID, &cloudresourcemanager.GetIamPolicyRequest{}).Do()
+		policy, err := sessions.CRM.Projects.GetIamPolicy(projectID, &cloudresourcemanager.GetIamPolicyRequest{}).Context(ctx).Do()
 		if err != nil {
 			return fmt.Errorf("getting IAM policy: %w", err)
 		}
@@ -179,7 +188,7 @@ func updateIAMPolicyWithRetry(sessions GCPSessions, projectID string, mutate fun
 
 		_, err = sessions.CRM.Projects.SetIamPolicy(projectID, &cloudresourcemanager.SetIamPolicyRequest{
 			Policy: policy,
-		}).Do()
+		}).Context(ctx).Do()
 		if err == nil {
 			return nil
 		}
@@ -189,7 +198,9 @@ func updateIAMPolicyWithRetry(sessions GCPSessions, projectID string, mutate fun
 				return err
 			}
 			log.Debug(fmt.Sprintf("IAM policy conflict for project `%s`, retrying after %s (attempt %d/%d)", projectID, backoff, attempt+1, iamPolicyMaxRetries))
-			time.Sleep(backoff)
+			if err := common.Sleep(ctx, backoff); err != nil {
+				return err
+			}
 			backoff *= 2
 			continue
 		}
@@ -199,10 +210,10 @@ func updateIAMPolicyWithRetry(sessions GCPSessions, projectID string, mutate fun
 	return nil
 }
 
-func removeServiceAccountIAMBindings(sessions GCPSessions, options GCPOptions, serviceAccountEmail string) {
+func removeServiceAccountIAMBindings(ctx context.Context, sessions GCPSessions, options GCPOptions, serviceAccountEmail string) {
 	member := "serviceAccount:" + serviceAccountEmail
 
-	err := updateIAMPolicyWithRetry(sessions, options.ProjectID, func(policy *cloudresourcemanager.Policy) bool {
+	err := updateIAMPolicyWithRetry(ctx, sessions, options.ProjectID, func(policy *cloudresourcemanager.Policy) bool {
 		changed := false
 		var updatedBindings []*cloudresourcemanager.Binding
 		for _, binding := range policy.Bindings {
@@ -232,15 +243,14 @@ func removeServiceAccountIAMBindings(sessions GCPSessions, options GCPOptions, s
 	}
 }
 
-func DeleteBindingsWithNonExistentServiceAccounts(sessions GCPSessions, options GCPOptions) {
+func (c nonExistentServiceAccountBindingCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
 	existingSAs := make(map[string]struct{})
 	nextPageToken := ""
 	for {
-		resp, err := sessions.IAM.Projects.ServiceAccounts.List("projects/" + options.ProjectID).
-			PageToken(nextPageToken).PageSize(100).Do()
+		resp, err := c.sessions.IAM.Projects.ServiceAccounts.List("projects/" + c.options.ProjectID).
+			PageToken(nextPageToken).PageSize(100).Context(ctx).Do()
 		if err != nil {
-			log.Error(fmt.Sprintf("Error listing service accounts for project `%s`, error: %s", options.ProjectID, err))
-			return
+			return nil, fmt.Errorf("error listing service accounts for project `%s`, error: %s", c.options.ProjectID, err)
 		}
 		for _, sa := range resp.Accounts {
 			existingSAs["serviceAccount:"+sa.Email] = struct{}{}
@@ -251,36 +261,44 @@ func DeleteBindingsWithNonExistentServiceAccounts(sessions GCPSessions, options
 		}
 	}
 
-	policy, err := sessions.CRM.Projects.GetIamPolicy(options.ProjectID, &cloudresourcemanager.GetIamPolicyRequest{}).Do()
+	policy, err := c.sessions.CRM.Projects.GetIamPolicy(c.options.ProjectID, &cloudresourcemanager.GetIamPolicyRequest{}).Context(ctx).Do()
 	if err != nil {
-		log.Error(fmt.Sprintf("Error getting IAM policy for project `%s`, error: %s", options.ProjectID, err))
-		return
+		return nil, fmt.Errorf("error getting IAM policy for project `%s`, error: %s", c.options.ProjectID, err)
 	}
 
 	nonExistent := make(map[string]struct{})
+	var resources []common.CloudProviderResource
 	for _, binding := range policy.Bindings {
 		for _, member := range binding.Members {
 			if strings.HasPrefix(member, "serviceAccount:") {
 				if _, exists := existingSAs[member]; !exists {
+					if _, listed := nonExistent[member]; listed {
+						continue
+					}
 					nonExistent[member] = struct{}{}
+					resources = append(resources, common.CloudProviderResource{
+						Identifier:  member,
+						Description: "IAM bindings for non-existent service account " + member,
+					})
 				}
 			}
 		}
 	}
 
-	if len(nonExistent) == 0 {
-		log.Info("All serviceAccount members in IAM policy still exist, nothing to remove")
-		return
-	}
+	return resources, nil
+}
 
-	if options.DryRun {
-		for member := range nonExistent {
-			log.Info(fmt.Sprintf("DryRun: would remove all IAM bindings for non-existent service account `%s`", member))
-		}
-		return
+func (c nonExistentServiceAccountBindingCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	return c.DeleteBatch(ctx, []common.CloudProviderResource{resource})
+}
+
+func (c nonExistentServiceAccountBindingCleaner) DeleteBatch(ctx context.Context, resources []common.CloudProviderResource) error {
+	nonExistent := make(map[string]struct{})
+	for _, resource := range resources {
+		nonExistent[resource.Identifier] = struct{}{}
 	}
 
-	err = updateIAMPolicyWithRetry(sessions, options.ProjectID, func(policy *cloudresourcemanager.Policy) bool {
+	err := updateIAMPolicyWithRetry(ctx, c.sessions, c.options.ProjectID, func(policy *cloudresourcemanager.Policy) bool {
 		changed := false
 		var updatedBindings []*cloudresourcemanager.Binding
 		for _, binding := range policy.Bindings {
@@ -306,48 +324,48 @@ func DeleteBindingsWithNonExistentServiceAccounts(sessions GCPSessions, options
 		return changed
 	})
 	if err != nil {
-		log.Error(fmt.Sprintf("Error updating IAM policy for project `%s`, error: %s", options.ProjectID, err))
-		return
+		return fmt.Errorf("error updating IAM policy for project `%s`, error: %s", c.options.ProjectID, err)
 	}
 
-	log.Info(fmt.Sprintf("Removed IAM bindings for %d non-existent service accounts in project `%s`", len(nonExistent), options.ProjectID))
+	log.Info(fmt.Sprintf("Removed IAM bindings for %d non-existent service accounts in project `%s`", len(nonExistent), c.options.ProjectID))
+	return nil
 }
 
-func DeleteOrphanedIAMPolicyBindings(sessions GCPSessions, options GCPOptions) {
-	type orphanedBinding struct {
-		role   string
-		member string
-	}
-
-	var orphaned []orphanedBinding
+type orphanedBinding struct {
+	role   string
+	member string
+}
 
-	policy, err := sessions.CRM.Projects.GetIamPolicy(options.ProjectID, &cloudresourcemanager.GetIamPolicyRequest{}).Do()
+func (c orphanedIAMPolicyBindingCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	policy, err := c.sessions.CRM.Projects.GetIamPolicy(c.options.ProjectID, &cloudresourcemanager.GetIamPolicyRequest{}).Context(ctx).Do()
 	if err != nil {
-		log.Error(fmt.Sprintf("Error getting IAM policy for project `%s`, error: %s", options.ProjectID, err))
-		return
+		return nil, fmt.Errorf("error getting IAM policy for project `%s`, error: %s", c.options.ProjectID, err)
 	}
 
+	var resources []common.CloudProviderResource
 	for _, binding := range policy.Bindings {
 		for _, member := range binding.Members {
 			if strings.HasPrefix(member, "deleted:serviceAccount:") {
-				orphaned = append(orphaned, orphanedBinding{role: binding.Role, member: member})
-				if options.DryRun {
-					log.Info(fmt.Sprintf("Orphaned IAM policy binding will be removed: role=%s member=%s", binding.Role, member))
-				} else {
-					log.Info(fmt.Sprintf("Removing orphaned IAM policy binding: role=%s member=%s", binding.Role, member))
-				}
+				resources = append(resources, common.CloudProviderResource{
+					Identifier:  member,
+					Description: fmt.Sprintf("Orphaned IAM policy binding: role=%s member=%s", binding.Role, member),
+					Payload:     orphanedBinding{role: binding.Role, member: member},
+				})
 			}
 		}
 	}
 
-	if len(orphaned) == 0 {
-		log.Info("No eligible orphaned IAM policy bindings found")
-		return
-	}
+	return resources, nil
+}
+
+func (c orphanedIAMPolicyBindingCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	return c.DeleteBatch(ctx, []common.CloudProviderResource{resource})
+}
 
-	if options.DryRun {
-		log.Info("DryRun mode enabled, won't delete orphaned IAM policy bindings")
-		return
+func (c orphanedIAMPolicyBindingCleaner) DeleteBatch(ctx context.Context, resources []common.CloudProviderResource) error {
+	var orphaned []orphanedBinding
+	for _, resource := range resources {
+		orphaned = append(orphaned, resource.Payload.(orphanedBinding))
 	}
 
 	for start := 0; start < len(orphaned); start += iamPolicyMemberChunkSize {
@@ -367,7 +385,7 @@ func DeleteOrphanedIAMPolicyBindings(sessions GCPSessions, options GCPOptions) {
 			toRemove[ob.role][ob.member] = true
 		}
 
-		err := updateIAMPolicyWithRetry(sessions, options.ProjectID, func(p *cloudresourcemanager.Policy) bool {
+		err := updateIAMPolicyWithRetry(ctx, c.sessions, c.options.ProjectID, func(p *cloudresourcemanager.Policy) bool {
 			var updatedBindings []*cloudresourcemanager.Binding
 			for _, binding := range p.Bindings {
 				var remainingMembers []string
@@ -394,7 +412,7 @@ func DeleteOrphanedIAMPolicyBindings(sessions GCPSessions, options GCPOptions) {
 			if totalMembers > iamPolicyMemberLimit {
 				log.Warn(fmt.Sprintf(
 					"Skipping SetIamPolicy for project `%s`: resulting policy would have %d members (limit %d). Reduce existing members first.",
-					options.ProjectID, totalMembers, iamPolicyMemberLimit,
+					c.options.ProjectID, totalMembers, iamPolicyMemberLimit,
 				))
 				return false
 			}
@@ -403,11 +421,12 @@ func DeleteOrphanedIAMPolicyBindings(sessions GCPSessions, options GCPOptions) {
 			return true
 		})
 		if err != nil {
-			log.Error(fmt.Sprintf("Error updating IAM policy for project `%s`, error: %s", options.ProjectID, err))
-			return
+			return fmt.Errorf("error updating IAM policy for project `%s`, error: %s", c.options.ProjectID, err)
 		}
 
 		log.Info(fmt.Sprintf("Removed %d orphaned IAM policy bindings for project `%s` (batch %d/%d)",
-			len(chunk), options.ProjectID, batchNum, totalBatches))
+			len(chunk), c.options.ProjectID, batchNum, totalBatches))
 	}
+
+	return nil
 }
@@ -0,0 +1,90 @@
+package gcp
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"cloud.google.com/go/artifactregistry/apiv1/artifactregistrypb"
+	"cloud.google.com/go/container/apiv1/containerpb"
+	runpb "cloud.google.com/go/run/apiv2/runpb"
+	"cloud.google.com/go/storage"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	"github.com/Qovery/pleco/pkg/common"
+)
+
+// mergeLabels returns the current labels updated with the tags. Label keys can't hold "/" and label values ":",
+// so dates are set as unix timestamps.
+func mergeLabels(current map[string]string, tags map[string]string) map[string]string {
+	labels := make(map[string]string, len(current)+len(tags))
+	for key, value := range current {
+		labels[key] = value
+	}
+	for key, value := range common.LabelTags(tags) {
+		if date, err := time.Parse(time.RFC3339, value); err == nil {
+			value = strconv.FormatInt(date.Unix(), 10)
+		}
+		labels[key] = value
+	}
+
+	return labels
+}
+
+func (c bucketCleaner) SetTags(ctx context.Context, resource common.CloudProviderResource, tags map[string]string) error {
+	var attrs storage.BucketAttrsToUpdate
+	for key, value := range mergeLabels(nil, tags) {
+		attrs.SetLabel(key, value)
+	}
+
+	_, err := c.sessions.Bucket.Bucket(resource.Identifier).Update(ctx, attrs)
+
+	return err
+}
+
+func (c artifactRegistryRepositoryCleaner) SetTags(ctx context.Context, resource common.CloudProviderResource, tags map[string]string) error {
+	repository, err := c.sessions.ArtifactRegistry.GetRepository(ctx, &artifactregistrypb.GetRepositoryRequest{Name: resource.Identifier})
+	if err != nil {
+		return err
+	}
+
+	_, err = c.sessions.ArtifactRegistry.UpdateRepository(ctx, &artifactregistrypb.UpdateRepositoryRequest{
+		Repository: &artifactregistrypb.Repository{Name: repository.Name, Labels: mergeLabels(repository.Labels, tags)},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"labels"}},
+	})
+
+	return err
+}
+
+// SetTags replaces the cluster labels as a whole, the fingerprint of the current ones failing concurrent updates.
+func (c gkeClusterCleaner) SetTags(ctx context.Context, resource common.CloudProviderResource, tags map[string]string) error {
+	name := "projects/" + c.options.ProjectID + "/locations/" + c.options.Location + "/clusters/" + resource.Identifier
+	cluster, err := c.sessions.Cluster.GetCluster(ctx, &containerpb.GetClusterRequest{Name: name})
+	if err != nil {
+		return err
+	}
+
+	_, err = c.sessions.Cluster.SetLabels(ctx, &containerpb.SetLabelsRequest{
+		Name:             name,
+		ResourceLabels:   mergeLabels(cluster.ResourceLabels, tags),
+		LabelFingerprint: cluster.LabelFingerprint,
+	})
+
+	return err
+}
+
+func (c jobCleaner) SetTags(ctx context.Context, resource common.CloudProviderResource, tags map[string]string) error {
+	job, err := c.sessions.Job.GetJob(ctx, &runpb.GetJobRequest{Name: resource.Identifier})
+	if err != nil {
+		return err
+	}
+	job.Labels = mergeLabels(job.Labels, tags)
+
+	operation, err := c.sessions.Job.UpdateJob(ctx, &runpb.UpdateJobRequest{Job: job})
+	if err != nil {
+		return err
+	}
+
+	_, err = operation.Wait(ctx)
+	return err
+}
@@ -3,12 +3,12 @@ package gcp
 import (
 	"context"
 	"fmt"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"cloud.google.com/go/storage"
+	"github.com/Qovery/pleco/pkg/common"
 	log "github.com/sirupsen/logrus"
 	"golang.org/x/time/rate"
 	"google.golang.org/api/googleapi"
@@ -20,11 +20,11 @@ const bucketDeleteRatePerSec = 1
 const bucketListRatePerSec = 1
 const bucketDeleteMaxRetries = 6
 
-func deleteObjectWithRetry(bucketHandle *storage.BucketHandle, bucketName, objectName string, generation int64) error {
+func deleteObjectWithRetry(ctx context.Context, bucketHandle *storage.BucketHandle, bucketName, objectName string, generation int64) error {
 	backoff := 500 * time.Millisecond
 	for attempt := 0; attempt <= bucketDeleteMaxRetries; attempt++ {
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second*15)
-		err := bucketHandle.Object(objectName).Generation(generation).Delete(ctx)
+		ctxDelete, cancel := context.WithTimeout(ctx, time.Second*15)
+		err := bucketHandle.Object(objectName).Generation(generation).Delete(ctxDelete)
 		cancel()
 		if err == nil {
 			return nil
@@ -35,7 +35,9 @@ func deleteObjectWithRetry(bucketHandle *storage.BucketHandle, bucketName, objec
 				return err
 			}
 			log.Debug(fmt.Sprintf("Retrying object `%s` in bucket `%s` after %s (attempt %d/%d): %s", objectName, bucketName, backoff, attempt+1, bucketDeleteMaxRetries, err))
-			time.Sleep(backoff)
+			if err := common.Sleep(ctx, backoff); err != nil {
+				return err
+			}
 			backoff *= 2
 			continue
 		}
@@ -45,7 +47,7 @@ func deleteObjectWithRetry(bucketHandle *storage.BucketHandle, bucketName, objec
 	return nil
 }
 
-func emptyBucket(bucketHandle *storage.BucketHandle, bucketName string) bool {
+func emptyBucket(ctx context.Context, bucketHandle *storage.BucketHandle, bucketName string) bool {
 	type objectVersion struct {
 		name       string
 		generation int64
@@ -53,10 +55,10 @@ func emptyBucket(bucketHandle *storage.BucketHandle, bucketName string) bool {
 
 	var objects []objectVersion
 	listLimiter := rate.NewLimiter(rate.Limit(bucketListRatePerSec), 1)
-	it := bucketHandle.Objects(context.Background(), &storage.Query{Versions: true})
+	it := bucketHandle.Objects(ctx, &storage.Query{Versions: true})
 	pager := iterator.NewPager(it, 1000, "")
 	for {
-		if err := listLimiter.Wait(context.Background()); err != nil {
+		if err := listLimiter.Wait(ctx); err != nil {
 			log.Error(fmt.Sprintf("List rate limiter error for bucket `%s`: %s", bucketName, err))
 			return false
 		}
@@ -97,14 +99,14 @@ func emptyBucket(bucketHandle *storage.BucketHandle, bucketName string) bool {
 		go func() {
 			defer wg.Done()
 			for obj := range jobs {
-				if err := limiter.Wait(context.Background()); err != nil {
+				if err := limiter.Wait(ctx); err != nil {
 					log.Error(fmt.Sprintf("Rate limiter error for bucket `%s`: %s", bucketName, err))
 					mu.Lock()
 					emptied = false
 					mu.Unlock()
 					continue
 				}
-				if err := deleteObjectWithRetry(bucketHandle, bucketName, obj.name, obj.generation); err != nil {
+				if err := deleteObjectWithRetry(ctx, bucketHandle, bucketName, obj.name, obj.generation); err != nil {
 					log.Error(fmt.Sprintf("Error deleting object `%s` (generation %d) from bucket `%s`, error: %s", obj.name, obj.generation, bucketName, err))
 					mu.Lock()
 					emptied = false
@@ -118,55 +120,55 @@ func emptyBucket(bucketHandle *storage.BucketHandle, bucketName string) bool {
 	return emptied
 }
 
-func DeleteExpiredBuckets(sessions GCPSessions, options GCPOptions) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
-	defer cancel()
+type bucketCleaner struct {
+	gcpCleaner
+}
 
-	var bucketsIterator = sessions.Bucket.Buckets(ctx, options.ProjectID)
+func init() {
+	registerRegionalCleaner("object-storage", "bucket", "expired bucket", func(ctx context.Context, sessions *GCPSessions) error {
+		client, err := storage.NewClient(ctx)
+		if err != nil {
+			return fmt.Errorf("storage.NewClient: %w", err)
+		}
+		sessions.Bucket = client
+
+		return nil
+	}, func(base gcpCleaner) common.Cleaner {
+		return bucketCleaner{base}
+	})
+}
+
+func (c bucketCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	var resources []common.CloudProviderResource
+	var bucketsIterator = c.sessions.Bucket.Buckets(ctx, c.options.ProjectID)
 	for {
 		bucket, err := bucketsIterator.Next()
 		if err == iterator.Done {
 			break
 		}
 		if err != nil {
-			log.Error(fmt.Sprintf("Error listing buckets for project `%s`, error: %s", options.ProjectID, err))
-			break
-		}
-
-		if !strings.EqualFold(bucket.Location, options.Location) {
-			continue
+			return nil, fmt.Errorf("error listing buckets for project `%s`, error: %s", c.options.ProjectID, err)
 		}
 
-		ttlStr, ok := bucket.Labels["ttl"]
-		if !ok || strings.TrimSpace(ttlStr) == "" {
-			log.Info(fmt.Sprintf("No ttl label value found, ignoring this bucket (`%s`)", bucket.Name))
-			continue
-		}
-		ttl, err := strconv.ParseInt(ttlStr, 10, 64)
-		if err != nil {
-			log.Warn(fmt.Sprintf("ttl label value `%s` is not parsable to int64, ignoring this bucket (`%s`)", ttlStr, bucket.Name))
-			continue
-		}
-		if ttl == 0 || time.Now().UTC().Before(bucket.Created.UTC().Add(time.Second*time.Duration(ttl))) {
+		if !strings.EqualFold(bucket.Location, c.options.Location) {
 			continue
 		}
 
-		if options.DryRun {
-			log.Info(fmt.Sprintf("Bucket `%s` will be deleted", bucket.Name))
-			continue
-		}
+		resources = append(resources, c.newResource(bucket.Name, "Bucket: "+bucket.Name, bucket.Created, bucket.Labels))
+	}
 
-		log.Info(fmt.Sprintf("Deleting bucket `%s` created at `%s` UTC (TTL `%d` seconds)", bucket.Name, bucket.Created.UTC(), ttl))
+	return resources, nil
+}
 
-		if !emptyBucket(sessions.Bucket.Bucket(bucket.Name), bucket.Name) {
-			log.Warn(fmt.Sprintf("Skipping deletion of bucket `%s`: failed to empty it", bucket.Name))
-			continue
-		}
+func (c bucketCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	log.Info(fmt.Sprintf("Deleting bucket `%s` created at `%s` UTC (TTL `%d` seconds)", resource.Identifier, resource.CreationDate, resource.TTL))
 
-		ctxDeleteBucket, cancelDeleteBucket := context.WithTimeout(context.Background(), time.Second*60)
-		if err := sessions.Bucket.Bucket(bucket.Name).Delete(ctxDeleteBucket); err != nil {
-			log.Error(fmt.Sprintf("Error deleting bucket `%s`, error: %s", bucket.Name, err))
-		}
-		cancelDeleteBucket()
+	if !emptyBucket(ctx, c.sessions.Bucket.Bucket(resource.Identifier), resource.Identifier) {
+		return fmt.Errorf("failed to empty bucket `%s`", resource.Identifier)
 	}
+
+	ctxDeleteBucket, cancelDeleteBucket := context.WithTimeout(ctx, time.Second*60)
+	defer cancelDeleteBucket()
+
+	return c.sessions.Bucket.Bucket(resource.Identifier).Delete(ctxDeleteBucket)
 }
@@ -0,0 +1,101 @@
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"golang.org/x/oauth2/google"
+	cloudresourcemanager "google.golang.org/api/cloudresourcemanager/v3"
+)
+
+// ResolveProjects returns the IDs of the projects to clean. Entries are project IDs, or folders ("folders/<id>") and
+// organizations ("organizations/<id>") whose active projects, sub-folders included, are enumerated with Cloud Resource
+// Manager. Without entries, the project of the credentials is used.
+func ResolveProjects(ctx context.Context, entries []string) ([]string, error) {
+	if len(entries) == 0 {
+		credentials, err := google.FindDefaultCredentials(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("can't find GCP credentials: %s", err.Error())
+		}
+		if credentials.ProjectID == "" {
+			return nil, fmt.Errorf("no project found in GCP credentials, set the projects to clean")
+		}
+
+		return []string{credentials.ProjectID}, nil
+	}
+
+	var crm *cloudresourcemanager.Service
+	seen := make(map[string]bool)
+	var projects []string
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if !strings.HasPrefix(entry, "folders/") && !strings.HasPrefix(entry, "organizations/") {
+			if !seen[entry] {
+				seen[entry] = true
+				projects = append(projects, entry)
+			}
+			continue
+		}
+
+		if crm == nil {
+			var err error
+			crm, err = cloudresourcemanager.NewService(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("can't create Cloud Resource Manager client: %s", err.Error())
+			}
+		}
+
+		parentProjects, err := listProjects(ctx, crm, entry)
+		if err != nil {
+			return nil, err
+		}
+		for _, project := range parentProjects {
+			if !seen[project] {
+				seen[project] = true
+				projects = append(projects, project)
+			}
+		}
+	}
+
+	return projects, nil
+}
+
+// listProjects returns the active projects of a folder or an organization, and of their sub-folders.
+func listProjects(ctx context.Context, crm *cloudresourcemanager.Service, parent string) ([]string, error) {
+	var projects []string
+	err := crm.Projects.List().Parent(parent).Pages(ctx, func(response *cloudresourcemanager.ListProjectsResponse) error {
+		for _, project := range response.Projects {
+			if project.State == "ACTIVE" {
+				projects = append(projects, project.ProjectId)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("can't list projects of %s: %s", parent, err.Error())
+	}
+
+	var folders []string
+	err = crm.Folders.List().Parent(parent).Pages(ctx, func(response *cloudresourcemanager.ListFoldersResponse) error {
+		for _, folder := range response.Folders {
+			if folder.State == "ACTIVE" {
+				folders = append(folders, folder.Name)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("can't list folders of %s: %s", parent, err.Error())
+	}
+
+	for _, folder := range folders {
+		folderProjects, err := listProjects(ctx, crm, folder)
+		if err != nil {
+			return nil, err
+		}
+		projects = append(projects, folderProjects...)
+	}
+
+	return projects, nil
+}
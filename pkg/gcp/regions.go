@@ -0,0 +1,37 @@
+package gcp
+
+import (
+	"context"
+	"errors"
+
+	compute "cloud.google.com/go/compute/apiv1"
+	"cloud.google.com/go/compute/apiv1/computepb"
+	"google.golang.org/api/iterator"
+)
+
+// DiscoverRegions returns the regions of the project which are up.
+func DiscoverRegions(ctx context.Context, project string) ([]string, error) {
+	client, err := compute.NewRegionsRESTClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	var regions []string
+	regionsIterator := client.List(ctx, &computepb.ListRegionsRequest{Project: project})
+	for {
+		region, err := regionsIterator.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if region.GetStatus() == computepb.Region_UP.String() {
+			regions = append(regions, region.GetName())
+		}
+	}
+
+	return regions, nil
+}
@@ -1,25 +1,40 @@
 package gcp
 
 import (
+	compute "cloud.google.com/go/compute/apiv1"
 	"cloud.google.com/go/compute/apiv1/computepb"
 	"context"
-	"encoding/json"
 	"fmt"
 	"github.com/Qovery/pleco/pkg/common"
 	log "github.com/sirupsen/logrus"
-	"strconv"
 	"time"
 )
 
-func DeleteExpiredRouters(sessions GCPSessions, options GCPOptions) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
-	defer cancel()
+type routerCleaner struct {
+	gcpCleaner
+}
+
+func init() {
+	registerRegionalCleaner("router", "router", "expired router", func(ctx context.Context, sessions *GCPSessions) error {
+		client, err := compute.NewRoutersRESTClient(ctx)
+		if err != nil {
+			return fmt.Errorf("compute.NewRoutersRESTClient: %w", err)
+		}
+		sessions.Router = client
 
-	routersIterator := sessions.Router.List(ctx, &computepb.ListRoutersRequest{
-		Project: options.ProjectID,
-		Region:  options.Location,
+		return nil
+	}, func(base gcpCleaner) common.Cleaner {
+		return routerCleaner{base}
 	})
+}
 
+func (c routerCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	routersIterator := c.sessions.Router.List(ctx, &computepb.ListRoutersRequest{
+		Project: c.options.ProjectID,
+		Region:  c.options.Location,
+	})
+
+	var resources []common.CloudProviderResource
 	for {
 		router, err := routersIterator.Next()
 		if err != nil {
@@ -35,55 +50,20 @@ func DeleteExpiredRouters(sessions GCPSessions, options GCPOptions) {
 			routerDescription = *router.Description
 		}
 
-		resourceTags := common.ResourceTags{}
-		if err = json.Unmarshal([]byte(routerDescription), &resourceTags); err != nil {
-			log.Info(fmt.Sprintf("No resource tags found in description field, ignoring this router (`%s`)", routerName))
-			continue
-		}
-		ttlStr := ""
-		if resourceTags.TTL != nil {
-			ttlStr = resourceTags.TTL.String()
-		} else {
-			log.Info(fmt.Sprintf("No ttl value found, ignoring this router (`%s`)", routerName))
-			continue
-		}
-		ttl, err := strconv.ParseInt(ttlStr, 10, 64)
-		if err != nil {
-			log.Warn(fmt.Sprintf("ttl label value `%s` is not parsable to int64, ignoring this router (`%s`)", ttlStr, routerName))
-			continue
-		}
-		creationTimeStr := ""
-		if resourceTags.CreationUnixTimestamp != nil {
-			creationTimeStr = resourceTags.CreationUnixTimestamp.String()
-		} else {
-			log.Info(fmt.Sprintf("No creation time value found, ignoring this router (`%s`)", routerName))
-			continue
-		}
-		creationTimeInt64, err := strconv.ParseInt(creationTimeStr, 10, 64)
-		if err != nil {
-			log.Warn(fmt.Sprintf("creation_date label value `%s` is not parsable to int64, ignoring this router (`%s`)", creationTimeStr, routerName))
-			continue
-		}
-		creationTime := time.Unix(creationTimeInt64, 0).UTC()
+		creationTime, _ := time.Parse(time.RFC3339, router.GetCreationTimestamp())
+		resources = append(resources, c.newResource(routerName, "Router: "+routerName, creationTime, descriptionLabels(routerDescription)))
+	}
 
-		// Router is not expired (or is protected TTL = 0)
-		if ttl == 0 || creationTimeInt64 == 0 || time.Now().UTC().Before(creationTime.Add(time.Second*time.Duration(ttl))) {
-			continue
-		}
+	return resources, nil
+}
 
-		if options.DryRun {
-			log.Info(fmt.Sprintf("Router `%s will be deleted`", routerName))
-			continue
-		}
+func (c routerCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	log.Info(fmt.Sprintf("Deleting router `%s`", resource.Identifier))
+	_, err := c.sessions.Router.Delete(ctx, &computepb.DeleteRouterRequest{
+		Project: c.options.ProjectID,
+		Region:  c.options.Location,
+		Router:  resource.Identifier,
+	})
 
-		log.Info(fmt.Sprintf("Deleting router `%s`", routerName))
-		_, err = sessions.Router.Delete(ctx, &computepb.DeleteRouterRequest{
-			Project: options.ProjectID,
-			Region:  options.Location,
-			Router:  routerName,
-		})
-		if err != nil {
-			log.Error(fmt.Sprintf("Error deleting router `%s`, error: %s", routerName, err))
-		}
-	}
+	return err
 }
@@ -1,34 +1,30 @@
 package gcp
 
 import (
+	"context"
+	"sync"
+
 	artifactregistry "cloud.google.com/go/artifactregistry/apiv1"
 	compute "cloud.google.com/go/compute/apiv1"
 	container "cloud.google.com/go/container/apiv1"
 	run "cloud.google.com/go/run/apiv2"
 	"cloud.google.com/go/storage"
 	"github.com/sirupsen/logrus"
-	"golang.org/x/net/context"
 	cloudresourcemanager "google.golang.org/api/cloudresourcemanager/v1"
 	iam "google.golang.org/api/iam/v1"
-	"sync"
-	"time"
+
+	"github.com/Qovery/pleco/pkg/common"
 )
 
 type GCPOptions struct {
-	ProjectID              string
-	TagName                string
-	TagValue               string
-	DisableTTLCheck        bool
-	IsDestroyingCommand    bool
-	DryRun                 bool
-	Location               string
-	EnableCluster          bool
-	EnableBucket           bool
-	EnableNetwork          bool
-	EnableArtifactRegistry bool
-	EnableIAM              bool
-	EnableRouter           bool
-	EnableJob              bool
+	ProjectID           string
+	TagName             string
+	TagValue            string
+	DisableTTLCheck     bool
+	IsDestroyingCommand bool
+	DryRun              bool
+	Location            string
+	Features            common.FeatureSet
 }
 
 type GCPSessions struct {
@@ -44,142 +40,49 @@ type GCPSessions struct {
 	Job              *run.JobsClient
 }
 
-type funcDeleteExpired func(sessions GCPSessions, options GCPOptions)
+func RunPlecoGCP(ctx context.Context, projects []string, regions []string, interval int64, wg *sync.WaitGroup, options GCPOptions) {
+	for _, project := range projects {
+		options.ProjectID = project
+		for _, region := range regions {
+			wg.Add(1)
+			go runPlecoInRegion(ctx, region, interval, wg, options)
+		}
 
-func RunPlecoGCP(regions []string, interval int64, wg *sync.WaitGroup, options GCPOptions) {
-	for _, region := range regions {
 		wg.Add(1)
-		go runPlecoInRegion(region, interval, wg, options)
+		go runPlecoInProject(ctx, interval, wg, options)
 	}
 }
 
-func runPlecoInRegion(location string, interval int64, wg *sync.WaitGroup, options GCPOptions) {
+func runPlecoInRegion(ctx context.Context, location string, interval int64, wg *sync.WaitGroup, options GCPOptions) {
 	defer wg.Done()
 	options.Location = location
-	sessions := GCPSessions{}
-
-	logrus.Infof("Starting to check expired resources in region %s.", options.Location)
 
-	var listServiceToCheckStatus []funcDeleteExpired
-	ctx, _ := context.WithTimeout(context.Background(), time.Second*30)
+	logrus.Infof("Starting to check expired resources in region %s of project %s.", options.Location, options.ProjectID)
 
-	if options.EnableBucket {
-		client, err := storage.NewClient(ctx)
-		if err != nil {
-			logrus.Errorf("storage.NewClient: %s", err)
-			return
-		}
-		defer client.Close()
-		sessions.Bucket = client
-
-		listServiceToCheckStatus = append(listServiceToCheckStatus, DeleteExpiredBuckets)
-	}
-
-	if options.EnableArtifactRegistry {
-		client, err := artifactregistry.NewClient(ctx)
-		if err != nil {
-			logrus.Errorf("artifactregistry.NewClient: %s", err)
-			return
-		}
-		defer client.Close()
-		sessions.ArtifactRegistry = client
-
-		listServiceToCheckStatus = append(listServiceToCheckStatus, DeleteExpiredArtifactRegistryRepositories)
-	}
-
-	if options.EnableCluster {
-		client, err := container.NewClusterManagerClient(ctx)
-		if err != nil {
-			logrus.Errorf("container.NewClusterManagerClient: %s", err)
-			return
-		}
-		defer client.Close()
-		sessions.Cluster = client
-
-		listServiceToCheckStatus = append(listServiceToCheckStatus, DeleteExpiredGKEClusters)
-	}
-
-	if options.EnableNetwork {
-		networkClient, err := compute.NewNetworksRESTClient(ctx)
-		if err != nil {
-			logrus.Errorf("compute.NewNetworksRESTClient: %s", err)
-			return
-		}
-		defer networkClient.Close()
-		sessions.Network = networkClient
-
-		subnetworkClient, err := compute.NewSubnetworksRESTClient(ctx)
-		if err != nil {
-			logrus.Errorf("compute.NewSubnetworksRESTClient: %s", err)
-			return
-		}
-		defer subnetworkClient.Close()
-		sessions.Subnetwork = subnetworkClient
+	engine := common.NewEngine(common.EngineOptions{
+		Provider: providerName,
+		Account:  options.ProjectID,
+		Scope:    common.RegionScope,
+		Location: location,
+		DryRun:   options.DryRun,
+		Features: options.Features,
+	}, options)
 
-		routeClient, err := compute.NewRoutesRESTClient(ctx)
-		if err != nil {
-			logrus.Errorf("compute.NewRoutesRESTClient: %s", err)
-			return
-		}
-		defer routeClient.Close()
-		sessions.Route = routeClient
-
-		listServiceToCheckStatus = append(listServiceToCheckStatus, DeleteExpiredVPCs)
-	}
-
-	if options.EnableRouter {
-		routerClient, err := compute.NewRoutersRESTClient(ctx)
-		if err != nil {
-			logrus.Errorf("compute.NewRoutersRESTClient: %s", err)
-			return
-		}
-		defer routerClient.Close()
-		sessions.Router = routerClient
-
-		listServiceToCheckStatus = append(listServiceToCheckStatus, DeleteExpiredRouters)
-	}
-
-	if options.EnableIAM {
-		iamService, err := iam.NewService(ctx)
-		if err != nil {
-			logrus.Errorf("iam.NewService: %s", err)
-			return
-		}
-		sessions.IAM = iamService
-
-		crmService, err := cloudresourcemanager.NewService(ctx)
-		if err != nil {
-			logrus.Errorf("cloudresourcemanager.NewService: %s", err)
-			return
-		}
-		sessions.CRM = crmService
-
-		listServiceToCheckStatus = append(listServiceToCheckStatus, DeleteExpiredServiceAccounts, DeleteOrphanedIAMPolicyBindings, DeleteBindingsWithNonExistentServiceAccounts)
-	}
+	engine.Run(ctx, interval, options.IsDestroyingCommand)
+}
 
-	if options.EnableJob {
-		jobClient, err := run.NewJobsClient(ctx)
-		if err != nil {
-			logrus.Errorf("run.NewJobsClient: %s", err)
-			return
-		}
-		defer jobClient.Close()
-		sessions.Job = jobClient
+func runPlecoInProject(ctx context.Context, interval int64, wg *sync.WaitGroup, options GCPOptions) {
+	defer wg.Done()
 
-		listServiceToCheckStatus = append(listServiceToCheckStatus, DeleteExpiredJobs)
-	}
+	logrus.Infof("Starting to check project wide expired resources of project %s.", options.ProjectID)
 
-	if options.IsDestroyingCommand {
-		for _, check := range listServiceToCheckStatus {
-			check(sessions, options)
-		}
-	} else {
-		for {
-			for _, check := range listServiceToCheckStatus {
-				check(sessions, options)
-			}
+	engine := common.NewEngine(common.EngineOptions{
+		Provider: providerName,
+		Account:  options.ProjectID,
+		Scope:    common.GlobalScope,
+		DryRun:   options.DryRun,
+		Features: options.Features,
+	}, options)
 
-			time.Sleep(time.Duration(interval) * time.Second)
-		}
-	}
+	engine.Run(ctx, interval, options.IsDestroyingCommand)
 }
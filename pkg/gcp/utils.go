@@ -1,8 +1,13 @@
 package gcp
 
 import (
+	"encoding/json"
 	"fmt"
 	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Qovery/pleco/pkg/common"
 )
 
 func extractResourceRegion(urlStr string) (string, error) {
@@ -20,3 +25,42 @@ func extractResourceRegion(urlStr string) (string, error) {
 
 	return region, nil
 }
+
+// descriptionLabels reads the labels of the resources which don't support labels (networks, routers, service
+// accounts), set as a JSON object in their description, eg. {"ttl": 3600, "creation_date": 1700000000}.
+func descriptionLabels(description string) map[string]string {
+	var values map[string]interface{}
+	decoder := json.NewDecoder(strings.NewReader(description))
+	decoder.UseNumber()
+	if err := decoder.Decode(&values); err != nil {
+		return nil
+	}
+
+	labels := make(map[string]string, len(values))
+	for key, value := range values {
+		labels[key] = fmt.Sprint(value)
+	}
+
+	return labels
+}
+
+// newResource fills the ttl, tag and protection of a resource from its labels. The creation_date label, when set,
+// takes precedence over the creation date given by GCP.
+func (c gcpCleaner) newResource(identifier string, description string, creationDate time.Time, labels map[string]string) common.CloudProviderResource {
+	essentialTags := common.GetEssentialTags(labels, c.options.TagName)
+	if !essentialTags.CreationDate.IsZero() {
+		creationDate = essentialTags.CreationDate
+	}
+
+	return common.CloudProviderResource{
+		Identifier:   identifier,
+		Description:  description,
+		CreationDate: creationDate.UTC(),
+		TTL:          essentialTags.TTL,
+		Tag:          essentialTags.Tag,
+		IsProtected:  essentialTags.IsProtected,
+		ExpiresAt:    essentialTags.ExpiresAt,
+		Warnings:     essentialTags.Warnings,
+		Tags:         essentialTags.Tags,
+	}
+}
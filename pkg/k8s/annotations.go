@@ -0,0 +1,65 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/Qovery/pleco/pkg/common"
+)
+
+// annotationsPatch returns a merge patch setting the tags as annotations, which take precedence over the labels of
+// the objects.
+func annotationsPatch(tags map[string]string) []byte {
+	patch, _ := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"annotations": tags},
+	})
+
+	return patch
+}
+
+func (c deploymentCleaner) SetTags(ctx context.Context, resource common.CloudProviderResource, tags map[string]string) error {
+	namespace, name := splitIdentifier(resource.Identifier)
+	_, err := c.clientSet.AppsV1().Deployments(namespace).Patch(ctx, name, types.MergePatchType, annotationsPatch(tags), metav1.PatchOptions{})
+	return err
+}
+
+func (c statefulSetCleaner) SetTags(ctx context.Context, resource common.CloudProviderResource, tags map[string]string) error {
+	namespace, name := splitIdentifier(resource.Identifier)
+	_, err := c.clientSet.AppsV1().StatefulSets(namespace).Patch(ctx, name, types.MergePatchType, annotationsPatch(tags), metav1.PatchOptions{})
+	return err
+}
+
+func (c jobCleaner) SetTags(ctx context.Context, resource common.CloudProviderResource, tags map[string]string) error {
+	namespace, name := splitIdentifier(resource.Identifier)
+	_, err := c.clientSet.BatchV1().Jobs(namespace).Patch(ctx, name, types.MergePatchType, annotationsPatch(tags), metav1.PatchOptions{})
+	return err
+}
+
+func (c cronJobCleaner) SetTags(ctx context.Context, resource common.CloudProviderResource, tags map[string]string) error {
+	namespace, name := splitIdentifier(resource.Identifier)
+	_, err := c.clientSet.BatchV1().CronJobs(namespace).Patch(ctx, name, types.MergePatchType, annotationsPatch(tags), metav1.PatchOptions{})
+	return err
+}
+
+func (c pvcCleaner) SetTags(ctx context.Context, resource common.CloudProviderResource, tags map[string]string) error {
+	namespace, name := splitIdentifier(resource.Identifier)
+	_, err := c.clientSet.CoreV1().PersistentVolumeClaims(namespace).Patch(ctx, name, types.MergePatchType, annotationsPatch(tags), metav1.PatchOptions{})
+	return err
+}
+
+func (c lbServiceCleaner) SetTags(ctx context.Context, resource common.CloudProviderResource, tags map[string]string) error {
+	namespace, name := splitIdentifier(resource.Identifier)
+	_, err := c.clientSet.CoreV1().Services(namespace).Patch(ctx, name, types.MergePatchType, annotationsPatch(tags), metav1.PatchOptions{})
+	return err
+}
+
+// SetTags annotates the secret of the last revision, the release being read from it. An upgrade of the release
+// drops the annotations.
+func (c helmReleaseCleaner) SetTags(ctx context.Context, resource common.CloudProviderResource, tags map[string]string) error {
+	release := resource.Payload.(helmRelease)
+	_, err := c.clientSet.CoreV1().Secrets(release.Namespace).Patch(ctx, release.Secrets[len(release.Secrets)-1], types.MergePatchType, annotationsPatch(tags), metav1.PatchOptions{})
+	return err
+}
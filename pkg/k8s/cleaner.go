@@ -0,0 +1,118 @@
+package k8s
+
+import (
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/Qovery/pleco/pkg/common"
+)
+
+const providerName = "kubernetes"
+
+func init() {
+	common.RegisterProvider(common.ProviderDefinition{
+		Name:           providerName,
+		LocationsFlag:  "kube-namespaces",
+		LocationsUsage: "Set Kubernetes namespaces to clean objects in (default is every namespace)",
+		Features: []common.Feature{
+			{Name: "deployment", Usage: "Enable Kubernetes deployments watch"},
+			{Name: "statefulset", Usage: "Enable Kubernetes stateful sets watch"},
+			{Name: "job", Usage: "Enable Kubernetes jobs and cron jobs watch"},
+			{Name: "pvc", Usage: "Enable Kubernetes persistent volume claims watch"},
+			{Name: "lb-service", Usage: "Enable Kubernetes LoadBalancer services watch"},
+			{Name: "helm", Usage: "Enable Helm releases watch"},
+		},
+	})
+
+	// volumes are released once the workloads mounting them are deleted
+	common.RegisterDependencies(providerName, "job", "cronjob")
+	common.RegisterDependencies(providerName, "pvc", "deployment", "statefulset", "job", "cronjob", "helm-release")
+}
+
+// kubeCleaner is embedded by every Kubernetes cleaner, it is also the provider scope given to the cleaners
+// constructors. Objects are only selected by their ttl, the ttl check can't be disabled inside namespaces.
+type kubeCleaner struct {
+	common.TTLEvaluator
+	clientSet kubernetes.Interface
+	dynamic   dynamic.Interface
+	options   KubernetesOptions
+}
+
+func newKubeCleaner(clientSet kubernetes.Interface, dynamicClient dynamic.Interface, options KubernetesOptions) kubeCleaner {
+	return kubeCleaner{
+		TTLEvaluator: common.TTLEvaluator{TagValue: options.TagValue},
+		clientSet:    clientSet,
+		dynamic:      dynamicClient,
+		options:      options,
+	}
+}
+
+func registerCleaner(feature string, kind string, description string, newCleaner func(base kubeCleaner) common.Cleaner) {
+	common.RegisterCleaner(common.CleanerDefinition{
+		Provider:    providerName,
+		Feature:     feature,
+		Kind:        kind,
+		Description: description,
+		Scope:       common.GlobalScope,
+		New: func(providerScope interface{}) (common.Cleaner, error) {
+			return newCleaner(providerScope.(kubeCleaner)), nil
+		},
+	})
+}
+
+// objectResource returns the resource of an object, from its labels and annotations: the ttl is the --tag-name label,
+// or annotation, as for namespaces, while expires_at, delete_after and do_not_delete follow the cloud tags conventions.
+// Objects without expiration, owned by another object, or being deleted are skipped.
+func objectResource(description string, object metav1.ObjectMeta, tagName string) (common.CloudProviderResource, bool) {
+	if object.DeletionTimestamp != nil || len(object.OwnerReferences) > 0 {
+		return common.CloudProviderResource{}, false
+	}
+
+	tags := make(map[string]string, len(object.Labels)+len(object.Annotations))
+	for key, value := range object.Labels {
+		tags[key] = value
+	}
+	for key, value := range object.Annotations {
+		tags[key] = value
+	}
+
+	essentialTags := common.GetEssentialTags(tags, tagName)
+	if value, ok := tags[tagName]; ok && essentialTags.TTL == -1 {
+		ttl, err := common.ParseTTL(value)
+		if err != nil {
+			essentialTags.Warnings = append(essentialTags.Warnings, "invalid "+tagName+" label, the object is kept: "+err.Error())
+		}
+		essentialTags.TTL = ttl
+	}
+	if essentialTags.TTL == -1 && essentialTags.ExpiresAt.IsZero() && essentialTags.Tag == "" {
+		return common.CloudProviderResource{}, false
+	}
+
+	identifier := object.Namespace + "/" + object.Name
+	return common.CloudProviderResource{
+		Identifier:   identifier,
+		Description:  description + ": " + identifier,
+		CreationDate: object.CreationTimestamp.UTC(),
+		TTL:          essentialTags.TTL,
+		Tag:          essentialTags.Tag,
+		IsProtected:  essentialTags.IsProtected,
+		ExpiresAt:    essentialTags.ExpiresAt,
+		Warnings:     essentialTags.Warnings,
+		Tags:         essentialTags.Tags,
+	}, true
+}
+
+// backgroundDeletion deletes the dependents of an object, eg. the pods of a job, after the object itself.
+func backgroundDeletion() metav1.DeleteOptions {
+	propagation := metav1.DeletePropagationBackground
+	return metav1.DeleteOptions{PropagationPolicy: &propagation}
+}
+
+// splitIdentifier returns the namespace and the name of an object identifier.
+func splitIdentifier(identifier string) (string, string) {
+	namespace, name, _ := strings.Cut(identifier, "/")
+	return namespace, name
+}
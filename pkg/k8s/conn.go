@@ -39,3 +39,30 @@ func AuthenticateOutOfCluster() (*kubernetes.Clientset, error) {
 	}
 	return clientSet, nil
 }
+
+// Authenticate connects out of cluster, with KUBECONFIG, when connection is "out" and in cluster otherwise.
+func Authenticate(connection string) (*kubernetes.Clientset, error) {
+	if connection == "out" {
+		return AuthenticateOutOfCluster()
+	}
+
+	return AuthenticateInCluster()
+}
+
+// Config returns the client config of the connection, out of cluster with KUBECONFIG when connection is "out" and in
+// cluster otherwise.
+func Config(connection string) (*rest.Config, error) {
+	if connection == "out" {
+		config, err := clientcmd.BuildConfigFromFlags("", os.Getenv("KUBECONFIG"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get client config: %v", err)
+		}
+		return config, nil
+	}
+
+	config, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client config: %v", err)
+	}
+	return config, nil
+}
@@ -0,0 +1,205 @@
+package k8s
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/restmapper"
+	"sigs.k8s.io/yaml"
+
+	"github.com/Qovery/pleco/pkg/common"
+)
+
+const helmReleaseSecretType = "helm.sh/release.v1"
+
+// helmRelease is the payload of a release: its secrets, one per revision, and the manifest of its last revision.
+type helmRelease struct {
+	Namespace string
+	Name      string
+	Secrets   []string
+	Manifest  string
+}
+
+// helmReleaseCleaner uninstalls expired releases: the objects of their manifest are deleted, then their secrets.
+// The ttl is read from the labels and annotations of the last revision secret, eg. set with helm install --labels.
+type helmReleaseCleaner struct {
+	kubeCleaner
+}
+
+func init() {
+	registerCleaner("helm", "helm-release", "expired Helm release", func(base kubeCleaner) common.Cleaner {
+		return helmReleaseCleaner{kubeCleaner: base}
+	})
+}
+
+func (c helmReleaseCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	type revision struct {
+		version int
+		secret  int
+	}
+
+	var secrets []metav1.ObjectMeta
+	var data [][]byte
+	err := listAllPages(func(options metav1.ListOptions) (string, error) {
+		options.LabelSelector = "owner=helm"
+		result, err := c.clientSet.CoreV1().Secrets(c.options.Namespace).List(ctx, options)
+		if err != nil {
+			return "", err
+		}
+		for _, secret := range result.Items {
+			if string(secret.Type) == helmReleaseSecretType {
+				secrets = append(secrets, secret.ObjectMeta)
+				data = append(data, secret.Data["release"])
+			}
+		}
+		return result.Continue, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	revisions := make(map[string][]revision)
+	var releases []string
+	for i, secret := range secrets {
+		key := secret.Namespace + "/" + secret.Labels["name"]
+		if _, ok := revisions[key]; !ok {
+			releases = append(releases, key)
+		}
+		version, _ := strconv.Atoi(secret.Labels["version"])
+		revisions[key] = append(revisions[key], revision{version: version, secret: i})
+	}
+
+	var resources []common.CloudProviderResource
+	for _, key := range releases {
+		releaseRevisions := revisions[key]
+		sort.Slice(releaseRevisions, func(i, j int) bool {
+			return releaseRevisions[i].version < releaseRevisions[j].version
+		})
+
+		last := secrets[releaseRevisions[len(releaseRevisions)-1].secret]
+		// the release is identified by its name rather than by the name of its last revision secret
+		releaseMeta := last
+		releaseMeta.Name = last.Labels["name"]
+		releaseMeta.CreationTimestamp = secrets[releaseRevisions[0].secret].CreationTimestamp
+
+		resource, ok := objectResource("Helm release", releaseMeta, c.options.TagName)
+		if !ok {
+			continue
+		}
+
+		release := helmRelease{Namespace: last.Namespace, Name: releaseMeta.Name}
+		for _, releaseRevision := range releaseRevisions {
+			release.Secrets = append(release.Secrets, secrets[releaseRevision.secret].Name)
+		}
+		release.Manifest, err = decodeHelmManifest(data[releaseRevisions[len(releaseRevisions)-1].secret])
+		if err != nil {
+			log.Warnf("Can't read the manifest of Helm release %s, only its secrets will be deleted: %s", key, err.Error())
+		}
+
+		resource.Payload = release
+		resources = append(resources, resource)
+	}
+
+	return resources, nil
+}
+
+func (c helmReleaseCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	release := resource.Payload.(helmRelease)
+
+	if err := c.deleteManifest(ctx, release); err != nil {
+		return err
+	}
+
+	for _, secret := range release.Secrets {
+		err := c.clientSet.CoreV1().Secrets(release.Namespace).Delete(ctx, secret, metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deleteManifest deletes every object of the release manifest, the ones already deleted are ignored.
+func (c helmReleaseCleaner) deleteManifest(ctx context.Context, release helmRelease) error {
+	if strings.TrimSpace(release.Manifest) == "" {
+		return nil
+	}
+
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(c.clientSet.Discovery()))
+	for _, document := range strings.Split(release.Manifest, "\n---") {
+		content := make(map[string]interface{})
+		if err := yaml.Unmarshal([]byte(document), &content); err != nil {
+			return fmt.Errorf("can't parse the manifest of release %s: %s", release.Name, err.Error())
+		}
+		if len(content) == 0 {
+			continue
+		}
+
+		object := unstructured.Unstructured{Object: content}
+		gvk := object.GroupVersionKind()
+		mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			return fmt.Errorf("can't find the %s API of %s: %s", gvk.Kind, object.GetName(), err.Error())
+		}
+
+		client := c.dynamic.Resource(mapping.Resource)
+		if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+			namespace := object.GetNamespace()
+			if namespace == "" {
+				namespace = release.Namespace
+			}
+			err = client.Namespace(namespace).Delete(ctx, object.GetName(), backgroundDeletion())
+		} else {
+			err = client.Delete(ctx, object.GetName(), backgroundDeletion())
+		}
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("can't delete %s %s: %s", gvk.Kind, object.GetName(), err.Error())
+		}
+	}
+
+	return nil
+}
+
+// decodeHelmManifest returns the manifest of a release secret, stored as base64 encoded gzipped JSON.
+func decodeHelmManifest(data []byte) (string, error) {
+	content, err := base64.StdEncoding.DecodeString(string(data))
+	if err != nil {
+		return "", err
+	}
+
+	if bytes.HasPrefix(content, []byte{0x1f, 0x8b}) {
+		reader, err := gzip.NewReader(bytes.NewReader(content))
+		if err != nil {
+			return "", err
+		}
+		defer reader.Close()
+
+		if content, err = io.ReadAll(reader); err != nil {
+			return "", err
+		}
+	}
+
+	var release struct {
+		Manifest string `json:"manifest"`
+	}
+	if err := json.Unmarshal(content, &release); err != nil {
+		return "", err
+	}
+
+	return release.Manifest, nil
+}
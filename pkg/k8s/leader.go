@@ -0,0 +1,81 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+const (
+	leaseDuration = 15 * time.Second
+	renewDeadline = 10 * time.Second
+	retryPeriod   = 2 * time.Second
+)
+
+// RunLeaderElection waits to hold the Lease, then runs the checks until ctx is cancelled. Pleco exits when the
+// leadership is lost, and a standby replica takes over. The Lease is released once the checks return.
+func RunLeaderElection(ctx context.Context, config *rest.Config, namespace string, name string, run func(ctx context.Context)) error {
+	identity, err := os.Hostname()
+	if err != nil {
+		return fmt.Errorf("can't get the leader election identity: %s", err.Error())
+	}
+
+	lock, err := resourcelock.NewFromKubeconfig(resourcelock.LeasesResourceLock, namespace, name, resourcelock.ResourceLockConfig{
+		Identity: identity,
+	}, config, renewDeadline)
+	if err != nil {
+		return fmt.Errorf("can't create Lease lock %s/%s: %s", namespace, name, err.Error())
+	}
+
+	// the Lease is kept until the checks in progress return, then released for a standby replica
+	electionCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancel()
+	var leading atomic.Bool
+	stopWaiting := context.AfterFunc(ctx, func() {
+		if !leading.Load() {
+			cancel()
+		}
+	})
+	defer stopWaiting()
+
+	log.Infof("Waiting to hold Lease %s/%s as %s", namespace, name, identity)
+	leaderelection.RunOrDie(electionCtx, leaderelection.LeaderElectionConfig{
+		Lock:            lock,
+		LeaseDuration:   leaseDuration,
+		RenewDeadline:   renewDeadline,
+		RetryPeriod:     retryPeriod,
+		ReleaseOnCancel: true,
+		Name:            name,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(leaderCtx context.Context) {
+				leading.Store(true)
+				log.Infof("Lease %s/%s held, starting checks", namespace, name)
+
+				runCtx, stopRun := context.WithCancel(leaderCtx)
+				defer stopRun()
+				context.AfterFunc(ctx, stopRun)
+				run(runCtx)
+				cancel()
+			},
+			OnStoppedLeading: func() {
+				if electionCtx.Err() == nil {
+					log.Fatalf("Lease %s/%s lost, exiting", namespace, name)
+				}
+			},
+			OnNewLeader: func(leader string) {
+				if leader != identity {
+					log.Infof("Lease %s/%s held by %s, standing by", namespace, name, leader)
+				}
+			},
+		},
+	})
+
+	return nil
+}
@@ -3,7 +3,6 @@ package k8s
 import (
 	"context"
 	"regexp"
-	"strconv"
 	"time"
 
 	"github.com/Qovery/pleco/pkg/common"
@@ -13,6 +12,8 @@ import (
 	"k8s.io/client-go/kubernetes"
 )
 
+const namespaceKind = "namespace"
+
 type kubernetesNamespace struct {
 	Name                string
 	NamespaceCreateTime time.Time
@@ -20,7 +21,7 @@ type kubernetesNamespace struct {
 	TTL                 int64
 }
 
-func listNamespaces(clientSet *kubernetes.Clientset, tagName string, disableTTLCheck bool) []v1.Namespace {
+func listNamespaces(ctx context.Context, clientSet kubernetes.Interface, tagName string, disableTTLCheck bool) []v1.Namespace {
 	var listOptions metav1.ListOptions
 	if !disableTTLCheck {
 		listOptions = metav1.ListOptions{
@@ -29,16 +30,46 @@ func listNamespaces(clientSet *kubernetes.Clientset, tagName string, disableTTLC
 	}
 
 	log.Debugf("Listing all Kubernetes namespaces with %s label", tagName)
-	namespaces, err := clientSet.CoreV1().Namespaces().List(context.TODO(), listOptions)
+	ctx, cancel := common.CallContext(ctx)
+	defer cancel()
+
+	namespaces, err := clientSet.CoreV1().Namespaces().List(ctx, listOptions)
 	if err != nil {
 		log.Errorf("Can't list namespaces with %s label: %s", tagName, err.Error())
+		return nil
 	}
 
 	return namespaces.Items
 }
 
-func getExpiredNamespaces(clientSet *kubernetes.Clientset, tagName string, disableTTLCheck bool) []kubernetesNamespace {
-	namespaces := listNamespaces(clientSet, tagName, disableTTLCheck)
+// namespaceExcludedBy returns the exclusion or the policy rule keeping a namespace, as the engine does for the other
+// kinds.
+func namespaceExcludedBy(kindPolicy common.KindPolicy, namespace v1.Namespace) (string, bool) {
+	resource := namespaceResource(namespace)
+	if kindPolicy.IsExcluded(resource) {
+		return common.RuleExclusions, true
+	}
+
+	return common.GetPolicy().ExcludedBy(common.RuleTarget{Provider: providerName, Kind: namespaceKind, Resource: resource})
+}
+
+func namespaceResource(namespace v1.Namespace) common.CloudProviderResource {
+	return common.CloudProviderResource{
+		Identifier:   namespace.Name,
+		Description:  "Namespace: " + namespace.Name,
+		CreationDate: namespace.CreationTimestamp.UTC(),
+		Tags:         namespace.Labels,
+	}
+}
+
+func getExpiredNamespaces(ctx context.Context, clientSet kubernetes.Interface, tagName string, disableTTLCheck bool, report *common.Report) []kubernetesNamespace {
+	kindPolicy := common.GetPolicy().GetKindPolicy(providerName, namespaceKind)
+	if !kindPolicy.Enabled {
+		log.Debug("Skipping namespaces: disabled by policy")
+		return nil
+	}
+
+	namespaces := listNamespaces(ctx, clientSet, tagName, disableTTLCheck)
 
 	expiredNamespaces := []kubernetesNamespace{}
 	for _, namespace := range namespaces {
@@ -46,6 +77,12 @@ func getExpiredNamespaces(clientSet *kubernetes.Clientset, tagName string, disab
 			continue
 		}
 
+		if rule, excluded := namespaceExcludedBy(kindPolicy, namespace); excluded {
+			log.Debugf("Skipping namespace %s: excluded by %s", namespace.Name, rule)
+			report.AddExclusion(namespaceKind, namespaceResource(namespace), rule)
+			continue
+		}
+
 		if disableTTLCheck {
 			match, _ := regexp.Compile("z([a-z0-9]+)-z(([a-z0-9]+))")
 			if !match.MatchString(namespace.Name) {
@@ -55,7 +92,7 @@ func getExpiredNamespaces(clientSet *kubernetes.Clientset, tagName string, disab
 
 		for key, value := range namespace.ObjectMeta.Labels {
 			if key == tagName || disableTTLCheck {
-				ttlValue, err := strconv.Atoi(value)
+				ttlValue, err := common.ParseTTL(value)
 
 				if err != nil && !disableTTLCheck {
 					log.Errorf("ttl value unrecognized for namespace %s", namespace.Name)
@@ -66,12 +103,12 @@ func getExpiredNamespaces(clientSet *kubernetes.Clientset, tagName string, disab
 					ttlValue = -1
 				}
 				creationDate, _ := time.Parse(time.RFC3339, namespace.CreationTimestamp.Time.Format(time.RFC3339))
-				if common.CheckIfExpired(creationDate, int64(ttlValue), "Namespace: "+namespace.Name, disableTTLCheck) {
+				if common.CheckIfExpired(creationDate, ttlValue, "Namespace: "+namespace.Name, disableTTLCheck) {
 					expiredNamespaces = append(expiredNamespaces, kubernetesNamespace{
 						Name:                namespace.Name,
 						NamespaceCreateTime: namespace.CreationTimestamp.Time,
 						Status:              string(namespace.Status.Phase),
-						TTL:                 int64(ttlValue),
+						TTL:                 ttlValue,
 					})
 				}
 			}
@@ -81,22 +118,43 @@ func getExpiredNamespaces(clientSet *kubernetes.Clientset, tagName string, disab
 	return expiredNamespaces
 }
 
-func deleteNamespace(clientSet *kubernetes.Clientset, namespace kubernetesNamespace, dryRun bool) {
+func deleteNamespace(ctx context.Context, clientSet kubernetes.Interface, namespace kubernetesNamespace, dryRun bool) {
 	deleteOptions := metav1.DeleteOptions{}
 
 	if !dryRun {
-		err := clientSet.CoreV1().Namespaces().Delete(context.TODO(), namespace.Name, deleteOptions)
+		ctx, cancel := common.CallContext(ctx)
+		defer cancel()
+
+		err := clientSet.CoreV1().Namespaces().Delete(ctx, namespace.Name, deleteOptions)
 		if err != nil {
 			log.Errorf("Can't delete namsespace %s", namespace.Name)
+			common.RecordFailedResources("kubernetes", "", "", "namespace", 1)
 		} else {
 			log.Debugf("K8S namespace %s deleted.", namespace.Name)
+			common.RecordDeletedResources("kubernetes", "", "", "namespace", 1)
 		}
 	}
 
 }
 
-func DeleteExpiredNamespaces(clientSet *kubernetes.Clientset, tagName string, dryRun bool, disableTTLCheck bool) {
-	namespaces := getExpiredNamespaces(clientSet, tagName, disableTTLCheck)
+func DeleteExpiredNamespaces(ctx context.Context, clientSet kubernetes.Interface, tagName string, dryRun bool, disableTTLCheck bool) {
+	report := common.NewReport("kubernetes", common.GlobalScope, "", dryRun)
+	namespaces := getExpiredNamespaces(ctx, clientSet, tagName, disableTTLCheck, report)
+
+	rule := common.RuleTTL
+	if disableTTLCheck {
+		rule = common.RuleTTLCheckDisabled
+	}
+	for _, namespace := range namespaces {
+		report.Add(namespaceKind, common.CloudProviderResource{
+			Identifier:   namespace.Name,
+			Description:  "Namespace: " + namespace.Name,
+			CreationDate: namespace.NamespaceCreateTime,
+			TTL:          namespace.TTL,
+		}, rule)
+	}
+	common.WriteReport(report)
+	common.RecordExpiredResources("kubernetes", "", "", namespaceKind, len(namespaces))
 
 	count, start := common.ElemToDeleteFormattedInfos("expired Kubernetes namespace", len(namespaces), "")
 
@@ -109,6 +167,9 @@ func DeleteExpiredNamespaces(clientSet *kubernetes.Clientset, tagName string, dr
 	log.Info(start)
 
 	for _, namespace := range namespaces {
-		deleteNamespace(clientSet, namespace, dryRun)
+		if ctx.Err() != nil {
+			return
+		}
+		deleteNamespace(ctx, clientSet, namespace, dryRun)
 	}
 }
@@ -0,0 +1,49 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/Qovery/pleco/pkg/common"
+)
+
+func expiredNamespace(name string, labels map[string]string) *v1.Namespace {
+	labels["ttl"] = "60"
+	return &v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels, CreationTimestamp: metav1.NewTime(time.Now().Add(-time.Hour))},
+		Status:     v1.NamespaceStatus{Phase: v1.NamespaceActive},
+	}
+}
+
+func TestGetExpiredNamespacesAppliesPolicy(t *testing.T) {
+	clientSet := fake.NewSimpleClientset(
+		expiredNamespace("review-1", map[string]string{}),
+		expiredNamespace("review-2", map[string]string{"team": "core"}),
+		expiredNamespace("prod", map[string]string{}),
+	)
+
+	common.SetPolicy(&common.Policy{
+		Providers: map[string]common.ProviderPolicy{providerName: {Exclusions: []string{"prod"}}},
+		Rules:     []common.Rule{{Name: "keep-core", Kinds: []string{namespaceKind}, Tags: map[string]string{"team": "core"}}},
+	})
+	defer common.SetPolicy(nil)
+
+	report := common.NewReport(providerName, common.GlobalScope, "", true)
+	namespaces := getExpiredNamespaces(context.Background(), clientSet, "ttl", false, report)
+	if len(namespaces) != 1 || namespaces[0].Name != "review-1" {
+		t.Errorf("only review-1 should expire, got %+v", namespaces)
+	}
+
+	excluded := make(map[string]string)
+	for _, exclusion := range report.Exclusions {
+		excluded[exclusion.Identifier] = exclusion.Rule
+	}
+	if excluded["prod"] != common.RuleExclusions || excluded["review-2"] != "keep-core" {
+		t.Errorf("prod and review-2 should be reported as excluded, got %v", excluded)
+	}
+}
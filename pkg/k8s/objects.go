@@ -0,0 +1,210 @@
+package k8s
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/Qovery/pleco/pkg/common"
+)
+
+type deploymentCleaner struct {
+	kubeCleaner
+}
+
+type statefulSetCleaner struct {
+	kubeCleaner
+}
+
+type jobCleaner struct {
+	kubeCleaner
+}
+
+type cronJobCleaner struct {
+	kubeCleaner
+}
+
+type pvcCleaner struct {
+	kubeCleaner
+}
+
+type lbServiceCleaner struct {
+	kubeCleaner
+}
+
+func init() {
+	registerCleaner("deployment", "deployment", "expired deployment", func(base kubeCleaner) common.Cleaner {
+		return deploymentCleaner{kubeCleaner: base}
+	})
+	registerCleaner("statefulset", "statefulset", "expired stateful set", func(base kubeCleaner) common.Cleaner {
+		return statefulSetCleaner{kubeCleaner: base}
+	})
+	registerCleaner("job", "cronjob", "expired cron job", func(base kubeCleaner) common.Cleaner {
+		return cronJobCleaner{kubeCleaner: base}
+	})
+	registerCleaner("job", "job", "expired job", func(base kubeCleaner) common.Cleaner {
+		return jobCleaner{kubeCleaner: base}
+	})
+	registerCleaner("pvc", "pvc", "expired persistent volume claim", func(base kubeCleaner) common.Cleaner {
+		return pvcCleaner{kubeCleaner: base}
+	})
+	registerCleaner("lb-service", "lb-service", "expired LoadBalancer service", func(base kubeCleaner) common.Cleaner {
+		return lbServiceCleaner{kubeCleaner: base}
+	})
+}
+
+func (c deploymentCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	var resources []common.CloudProviderResource
+	err := listAllPages(func(options metav1.ListOptions) (string, error) {
+		result, err := c.clientSet.AppsV1().Deployments(c.options.Namespace).List(ctx, options)
+		if err != nil {
+			return "", err
+		}
+		for _, deployment := range result.Items {
+			if resource, ok := objectResource("Deployment", deployment.ObjectMeta, c.options.TagName); ok {
+				resources = append(resources, resource)
+			}
+		}
+		return result.Continue, nil
+	})
+
+	return resources, err
+}
+
+func (c deploymentCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	namespace, name := splitIdentifier(resource.Identifier)
+	return c.clientSet.AppsV1().Deployments(namespace).Delete(ctx, name, backgroundDeletion())
+}
+
+func (c statefulSetCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	var resources []common.CloudProviderResource
+	err := listAllPages(func(options metav1.ListOptions) (string, error) {
+		result, err := c.clientSet.AppsV1().StatefulSets(c.options.Namespace).List(ctx, options)
+		if err != nil {
+			return "", err
+		}
+		for _, statefulSet := range result.Items {
+			if resource, ok := objectResource("StatefulSet", statefulSet.ObjectMeta, c.options.TagName); ok {
+				resources = append(resources, resource)
+			}
+		}
+		return result.Continue, nil
+	})
+
+	return resources, err
+}
+
+func (c statefulSetCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	namespace, name := splitIdentifier(resource.Identifier)
+	return c.clientSet.AppsV1().StatefulSets(namespace).Delete(ctx, name, backgroundDeletion())
+}
+
+// List skips the jobs created by cron jobs, they are deleted with their cron job.
+func (c jobCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	var resources []common.CloudProviderResource
+	err := listAllPages(func(options metav1.ListOptions) (string, error) {
+		result, err := c.clientSet.BatchV1().Jobs(c.options.Namespace).List(ctx, options)
+		if err != nil {
+			return "", err
+		}
+		for _, job := range result.Items {
+			if resource, ok := objectResource("Job", job.ObjectMeta, c.options.TagName); ok {
+				resources = append(resources, resource)
+			}
+		}
+		return result.Continue, nil
+	})
+
+	return resources, err
+}
+
+func (c jobCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	namespace, name := splitIdentifier(resource.Identifier)
+	return c.clientSet.BatchV1().Jobs(namespace).Delete(ctx, name, backgroundDeletion())
+}
+
+func (c cronJobCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	var resources []common.CloudProviderResource
+	err := listAllPages(func(options metav1.ListOptions) (string, error) {
+		result, err := c.clientSet.BatchV1().CronJobs(c.options.Namespace).List(ctx, options)
+		if err != nil {
+			return "", err
+		}
+		for _, cronJob := range result.Items {
+			if resource, ok := objectResource("CronJob", cronJob.ObjectMeta, c.options.TagName); ok {
+				resources = append(resources, resource)
+			}
+		}
+		return result.Continue, nil
+	})
+
+	return resources, err
+}
+
+func (c cronJobCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	namespace, name := splitIdentifier(resource.Identifier)
+	return c.clientSet.BatchV1().CronJobs(namespace).Delete(ctx, name, backgroundDeletion())
+}
+
+func (c pvcCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	var resources []common.CloudProviderResource
+	err := listAllPages(func(options metav1.ListOptions) (string, error) {
+		result, err := c.clientSet.CoreV1().PersistentVolumeClaims(c.options.Namespace).List(ctx, options)
+		if err != nil {
+			return "", err
+		}
+		for _, claim := range result.Items {
+			if resource, ok := objectResource("PVC", claim.ObjectMeta, c.options.TagName); ok {
+				resources = append(resources, resource)
+			}
+		}
+		return result.Continue, nil
+	})
+
+	return resources, err
+}
+
+func (c pvcCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	namespace, name := splitIdentifier(resource.Identifier)
+	return c.clientSet.CoreV1().PersistentVolumeClaims(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+}
+
+// List only returns services of type LoadBalancer, the ones holding a cloud load balancer.
+func (c lbServiceCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	var resources []common.CloudProviderResource
+	err := listAllPages(func(options metav1.ListOptions) (string, error) {
+		result, err := c.clientSet.CoreV1().Services(c.options.Namespace).List(ctx, options)
+		if err != nil {
+			return "", err
+		}
+		for _, service := range result.Items {
+			if service.Spec.Type != v1.ServiceTypeLoadBalancer {
+				continue
+			}
+			if resource, ok := objectResource("LoadBalancer Service", service.ObjectMeta, c.options.TagName); ok {
+				resources = append(resources, resource)
+			}
+		}
+		return result.Continue, nil
+	})
+
+	return resources, err
+}
+
+func (c lbServiceCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	namespace, name := splitIdentifier(resource.Identifier)
+	return c.clientSet.CoreV1().Services(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+}
+
+// listAllPages calls list until the returned continue token is empty.
+func listAllPages(list func(options metav1.ListOptions) (string, error)) error {
+	options := metav1.ListOptions{Limit: 500}
+	for {
+		next, err := list(options)
+		if err != nil || next == "" {
+			return err
+		}
+		options.Continue = next
+	}
+}
@@ -0,0 +1,249 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+
+	"github.com/Qovery/pleco/pkg/common"
+)
+
+var cleanupPolicyResource = schema.GroupVersionResource{Group: "pleco.qovery.com", Version: "v1alpha1", Resource: "cleanuppolicies"}
+
+const (
+	policyRetryDelay    = 10 * time.Second
+	statusFlushInterval = 15 * time.Second
+)
+
+// PolicyWatcher keeps the policy in sync with a CleanupPolicy object, whose spec holds the same fields as the
+// config file, and publishes the last cycle of every engine in its status.
+type PolicyWatcher struct {
+	client    dynamic.ResourceInterface
+	namespace string
+	name      string
+
+	mutex sync.Mutex
+	// generation is the last generation of the object read, valid or not
+	generation int64
+	loadedAt   time.Time
+	policyErr  string
+	cycles     map[string]common.CycleStatus
+	dirty      bool
+}
+
+// WatchPolicy loads the CleanupPolicy, then reloads it on every change. An invalid policy is reported in the object
+// status, and the current policy is kept.
+func WatchPolicy(config *rest.Config, namespace string, name string) (*PolicyWatcher, error) {
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate dynamic client: %v", err)
+	}
+
+	watcher := &PolicyWatcher{
+		client:    dynamicClient.Resource(cleanupPolicyResource).Namespace(namespace),
+		namespace: namespace,
+		name:      name,
+		cycles:    make(map[string]common.CycleStatus),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	object, err := watcher.client.Get(ctx, name, metav1.GetOptions{})
+	switch {
+	case errors.IsNotFound(err):
+		log.Warnf("CleanupPolicy %s/%s not found, waiting for it to be created", namespace, name)
+	case err != nil:
+		return nil, fmt.Errorf("can't get CleanupPolicy %s/%s: %s", namespace, name, err.Error())
+	default:
+		watcher.apply(object)
+	}
+
+	go watcher.watch()
+	go watcher.flushStatus()
+
+	return watcher, nil
+}
+
+func (watcher *PolicyWatcher) watch() {
+	resourceVersion := ""
+	for {
+		events, err := watcher.client.Watch(context.Background(), metav1.ListOptions{
+			FieldSelector:   "metadata.name=" + watcher.name,
+			ResourceVersion: resourceVersion,
+		})
+		if err != nil {
+			log.Errorf("Can't watch CleanupPolicy %s/%s: %s", watcher.namespace, watcher.name, err.Error())
+			time.Sleep(policyRetryDelay)
+			continue
+		}
+
+		for event := range events.ResultChan() {
+			switch event.Type {
+			case watch.Added, watch.Modified:
+				object, ok := event.Object.(*unstructured.Unstructured)
+				if !ok {
+					continue
+				}
+				resourceVersion = object.GetResourceVersion()
+				watcher.apply(object)
+			case watch.Deleted:
+				log.Warnf("CleanupPolicy %s/%s deleted, the current policy is kept", watcher.namespace, watcher.name)
+			case watch.Error:
+				// the resource version may be too old, the object is read again
+				resourceVersion = ""
+			}
+		}
+	}
+}
+
+// apply sets the policy of the object, unless its generation has already been read. Status updates don't change it.
+func (watcher *PolicyWatcher) apply(object *unstructured.Unstructured) {
+	watcher.mutex.Lock()
+	defer watcher.mutex.Unlock()
+
+	if object.GetGeneration() == watcher.generation {
+		return
+	}
+	watcher.generation = object.GetGeneration()
+	watcher.loadedAt = time.Now().UTC()
+	watcher.dirty = true
+
+	policy, err := parseCleanupPolicy(object)
+	if err != nil {
+		watcher.policyErr = err.Error()
+		log.Errorf("CleanupPolicy %s/%s generation %d, keeping the current policy: %s", watcher.namespace, watcher.name, watcher.generation, err.Error())
+		return
+	}
+
+	watcher.policyErr = ""
+	common.SetPolicy(policy)
+	log.Infof("CleanupPolicy %s/%s generation %d loaded", watcher.namespace, watcher.name, watcher.generation)
+}
+
+func parseCleanupPolicy(object *unstructured.Unstructured) (*common.Policy, error) {
+	spec, ok := object.Object["spec"]
+	if !ok {
+		spec = map[string]interface{}{}
+	}
+
+	content, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	return common.ParsePolicy(content)
+}
+
+func (watcher *PolicyWatcher) WriteStatus(status common.CycleStatus) {
+	watcher.mutex.Lock()
+	defer watcher.mutex.Unlock()
+
+	watcher.cycles[status.Key()] = status
+	watcher.dirty = true
+}
+
+// flushStatus updates the object status with the last changes, at most every statusFlushInterval.
+func (watcher *PolicyWatcher) flushStatus() {
+	for range time.Tick(statusFlushInterval) {
+		if err := watcher.updateStatus(); err != nil {
+			log.Errorf("Can't update CleanupPolicy %s/%s status: %s", watcher.namespace, watcher.name, err.Error())
+		}
+	}
+}
+
+func (watcher *PolicyWatcher) updateStatus() error {
+	// the status is only written by the leader, with the engines status
+	if common.IsStandby() {
+		return nil
+	}
+
+	watcher.mutex.Lock()
+	if !watcher.dirty {
+		watcher.mutex.Unlock()
+		return nil
+	}
+	status := watcher.status()
+	watcher.dirty = false
+	watcher.mutex.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	object, err := watcher.client.Get(ctx, watcher.name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err == nil {
+		object.Object["status"] = status
+		_, err = watcher.client.UpdateStatus(ctx, object, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		// the status is written again at the next flush
+		watcher.mutex.Lock()
+		watcher.dirty = true
+		watcher.mutex.Unlock()
+	}
+
+	return err
+}
+
+// policyStatus is the status of a CleanupPolicy object.
+type policyStatus struct {
+	ObservedGeneration int64                `json:"observedGeneration"`
+	Valid              bool                 `json:"valid"`
+	Message            string               `json:"message,omitempty"`
+	LastReloadTime     *time.Time           `json:"lastReloadTime,omitempty"`
+	LastCycleTime      *time.Time           `json:"lastCycleTime,omitempty"`
+	Expired            int                  `json:"expired"`
+	Deleted            int                  `json:"deleted"`
+	Failed             int                  `json:"failed"`
+	Errors             int                  `json:"errors"`
+	Engines            []common.CycleStatus `json:"engines,omitempty"`
+}
+
+// status returns the object status, as unstructured content. The mutex must be held.
+func (watcher *PolicyWatcher) status() map[string]interface{} {
+	status := policyStatus{
+		ObservedGeneration: watcher.generation,
+		Valid:              watcher.policyErr == "",
+		Message:            watcher.policyErr,
+	}
+	if !watcher.loadedAt.IsZero() {
+		status.LastReloadTime = &watcher.loadedAt
+	}
+
+	for _, cycle := range watcher.cycles {
+		status.Engines = append(status.Engines, cycle)
+		if status.LastCycleTime == nil || cycle.FinishedAt.After(*status.LastCycleTime) {
+			finishedAt := cycle.FinishedAt
+			status.LastCycleTime = &finishedAt
+		}
+		status.Expired += cycle.Expired
+		status.Deleted += cycle.Deleted
+		status.Failed += cycle.Failed
+		status.Errors += len(cycle.Errors)
+	}
+	sort.Slice(status.Engines, func(i, j int) bool {
+		return status.Engines[i].Key() < status.Engines[j].Key()
+	})
+
+	// unstructured content only holds JSON values
+	content, _ := json.Marshal(status)
+	unstructuredStatus := make(map[string]interface{})
+	_ = json.Unmarshal(content, &unstructuredStatus)
+
+	return unstructuredStatus
+}
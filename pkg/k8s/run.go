@@ -1,47 +1,127 @@
 package k8s
 
 import (
+	"context"
 	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+
+	"github.com/Qovery/pleco/pkg/common"
 )
 
-func RunPlecoKubernetes(cmd *cobra.Command, interval int64, dryRun bool, disableTTLCheck bool, wg *sync.WaitGroup) {
+type KubernetesOptions struct {
+	// Connection is in or out of cluster
+	Connection          string
+	TagName             string
+	TagValue            string
+	IsDestroyingCommand bool
+	DryRun              bool
+	// Namespace restricts the objects to a namespace, every namespace is cleaned if empty
+	Namespace string
+	Features  common.FeatureSet
+}
+
+func RunPlecoKubernetes(ctx context.Context, cmd *cobra.Command, interval int64, dryRun bool, disableTTLCheck bool, wg *sync.WaitGroup) {
 	wg.Add(1)
-	go runPlecoOnKube(cmd, interval, dryRun, disableTTLCheck, wg)
+	go runPlecoOnKube(ctx, cmd, interval, dryRun, disableTTLCheck, wg)
 }
 
-func runPlecoOnKube(cmd *cobra.Command, interval int64, dryRun bool, disableTTLCheck bool, wg *sync.WaitGroup) {
+func runPlecoOnKube(ctx context.Context, cmd *cobra.Command, interval int64, dryRun bool, disableTTLCheck bool, wg *sync.WaitGroup) {
 	defer wg.Done()
 
 	// Kubernetes connection
 	var k8sClientSet *kubernetes.Clientset
-	var err error
-	kubernetesEnabled := true
+	var dynamicClient dynamic.Interface
 	KubernetesConn, _ := cmd.Flags().GetString("kube-conn")
 	tagName, _ := cmd.Flags().GetString("tag-name")
+	stuckNamespaces := StuckNamespacesOptions{}
+	stuckNamespaces.After, _ = cmd.Flags().GetDuration("kube-stuck-namespaces-after")
+	stuckNamespaces.RemoveFinalizers, _ = cmd.Flags().GetBool("kube-remove-finalizers")
 
-	switch KubernetesConn {
-	case "in":
-		k8sClientSet, err = AuthenticateInCluster()
-	case "out":
-		k8sClientSet, err = AuthenticateOutOfCluster()
-	default:
-		kubernetesEnabled = false
-	}
-	if err != nil {
-		logrus.Errorf("failed to authenticate on kubernetes with %s connection: %v", KubernetesConn, err)
+	kubernetesEnabled := KubernetesConn == "in" || KubernetesConn == "out"
+	if kubernetesEnabled {
+		config, err := Config(KubernetesConn)
+		if err == nil {
+			k8sClientSet, err = kubernetes.NewForConfig(config)
+		}
+		if err == nil {
+			dynamicClient, err = dynamic.NewForConfig(config)
+		}
+		if err != nil {
+			logrus.Errorf("failed to authenticate on kubernetes with %s connection: %v", KubernetesConn, err)
+			kubernetesEnabled = false
+		}
 	}
 
-	// check Kubernetes
+	// check Kubernetes, until the policy changes or Pleco stops
+	policyChanged := common.PolicyChanged()
 	for {
 		if kubernetesEnabled {
-			DeleteExpiredNamespaces(k8sClientSet, tagName, dryRun, disableTTLCheck)
+			DeleteExpiredNamespaces(ctx, k8sClientSet, tagName, dryRun, disableTTLCheck)
+			if stuckNamespaces.After > 0 {
+				HandleStuckNamespaces(ctx, k8sClientSet, dynamicClient, tagName, dryRun, disableTTLCheck, stuckNamespaces)
+			}
+		}
+
+		select {
+		case <-time.After(time.Duration(interval) * time.Second):
+		case <-policyChanged:
+			return
+		case <-ctx.Done():
+			return
 		}
-		time.Sleep(time.Duration(interval) * time.Second)
 	}
+}
+
+// RunPlecoKubernetesObjects cleans the expired objects inside namespaces, with an engine per namespace, or a single one
+// for every namespace when none is given.
+func RunPlecoKubernetesObjects(ctx context.Context, namespaces []string, interval int64, wg *sync.WaitGroup, options KubernetesOptions) {
+	config, err := Config(options.Connection)
+	if err != nil {
+		logrus.Errorf("failed to authenticate on kubernetes with %s connection: %v", options.Connection, err)
+		return
+	}
+	clientSet, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		logrus.Errorf("failed to generate client set: %v", err)
+		return
+	}
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		logrus.Errorf("failed to generate dynamic client: %v", err)
+		return
+	}
+
+	if len(namespaces) == 0 {
+		namespaces = []string{""}
+	}
+	for _, namespace := range namespaces {
+		wg.Add(1)
+		go runPlecoInNamespace(ctx, clientSet, dynamicClient, namespace, interval, wg, options)
+	}
+}
+
+func runPlecoInNamespace(ctx context.Context, clientSet *kubernetes.Clientset, dynamicClient dynamic.Interface, namespace string, interval int64, wg *sync.WaitGroup, options KubernetesOptions) {
+	defer wg.Done()
+	options.Namespace = namespace
+
+	if namespace == "" {
+		logrus.Info("Starting to check expired Kubernetes objects in every namespace.")
+	} else {
+		logrus.Infof("Starting to check expired Kubernetes objects in namespace %s.", namespace)
+	}
+
+	engine := common.NewEngine(common.EngineOptions{
+		Provider: providerName,
+		Scope:    common.GlobalScope,
+		Location: namespace,
+		DryRun:   options.DryRun,
+		Features: options.Features,
+	}, newKubeCleaner(clientSet, dynamicClient, options))
 
+	engine.Run(ctx, interval, options.IsDestroyingCommand)
 }
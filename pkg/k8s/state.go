@@ -0,0 +1,88 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/Qovery/pleco/pkg/common"
+)
+
+const stateConfigMapKey = "states.json"
+
+// ConfigMapStateStore keeps the resources states in a ConfigMap, for Pleco running in a cluster. As ConfigMaps are
+// limited to 1MiB, it fits a few thousands of resources.
+type ConfigMapStateStore struct {
+	clientSet *kubernetes.Clientset
+	namespace string
+	name      string
+}
+
+func NewConfigMapStateStore(clientSet *kubernetes.Clientset, namespace string, name string) *ConfigMapStateStore {
+	return &ConfigMapStateStore{clientSet: clientSet, namespace: namespace, name: name}
+}
+
+func (store *ConfigMapStateStore) Load() ([]common.ResourceState, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	configMap, err := store.clientSet.CoreV1().ConfigMaps(store.namespace).Get(ctx, store.name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("can't get state config map %s/%s: %s", store.namespace, store.name, err.Error())
+	}
+
+	content, ok := configMap.Data[stateConfigMapKey]
+	if !ok {
+		return nil, nil
+	}
+
+	var states []common.ResourceState
+	if err := json.Unmarshal([]byte(content), &states); err != nil {
+		return nil, fmt.Errorf("can't parse state config map %s/%s: %s", store.namespace, store.name, err.Error())
+	}
+
+	return states, nil
+}
+
+func (store *ConfigMapStateStore) Save(states []common.ResourceState) error {
+	content, err := json.Marshal(states)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	configMaps := store.clientSet.CoreV1().ConfigMaps(store.namespace)
+	configMap, err := configMaps.Get(ctx, store.name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		_, err = configMaps.Create(ctx, &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   store.name,
+				Labels: map[string]string{"app.kubernetes.io/managed-by": "pleco"},
+			},
+			Data: map[string]string{stateConfigMapKey: string(content)},
+		}, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("can't get state config map %s/%s: %s", store.namespace, store.name, err.Error())
+	}
+
+	if configMap.Data == nil {
+		configMap.Data = make(map[string]string)
+	}
+	configMap.Data[stateConfigMapKey] = string(content)
+
+	_, err = configMaps.Update(ctx, configMap, metav1.UpdateOptions{})
+	return err
+}
@@ -0,0 +1,172 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/Qovery/pleco/pkg/common"
+)
+
+var removeFinalizersPatch = []byte(`{"metadata":{"finalizers":null}}`)
+
+type StuckNamespacesOptions struct {
+	// After is how long a namespace can be terminating before being reported as stuck, 0 disables the check
+	After time.Duration
+	// RemoveFinalizers removes the finalizers of the remaining objects, then of the namespace itself
+	RemoveFinalizers bool
+}
+
+// blockingObject is an object left in a terminating namespace because of its finalizers.
+type blockingObject struct {
+	resource   schema.GroupVersionResource
+	kind       string
+	name       string
+	finalizers []string
+}
+
+func (object blockingObject) String() string {
+	return fmt.Sprintf("%s %s (%s)", object.kind, object.name, strings.Join(object.finalizers, ", "))
+}
+
+// HandleStuckNamespaces reports the namespaces terminating for longer than the threshold, with the conditions and the
+// objects blocking their deletion. Finalizers are removed when enabled, unless in dry run.
+func HandleStuckNamespaces(ctx context.Context, clientSet *kubernetes.Clientset, dynamicClient dynamic.Interface, tagName string, dryRun bool, disableTTLCheck bool, options StuckNamespacesOptions) {
+	now := time.Now()
+	stuck := 0
+	for _, namespace := range listNamespaces(ctx, clientSet, tagName, disableTTLCheck) {
+		if ctx.Err() != nil {
+			return
+		}
+		if namespace.Status.Phase != v1.NamespaceTerminating || namespace.DeletionTimestamp == nil {
+			continue
+		}
+		if now.Sub(namespace.DeletionTimestamp.Time) < options.After {
+			continue
+		}
+		stuck++
+
+		var reasons []string
+		for _, condition := range namespace.Status.Conditions {
+			if condition.Status == v1.ConditionTrue {
+				reasons = append(reasons, condition.Message)
+			}
+		}
+		log.Warnf("Namespace %s is stuck terminating since %s: %s", namespace.Name, namespace.DeletionTimestamp.Format(time.RFC3339), strings.Join(reasons, "; "))
+
+		objects, err := listBlockingObjects(ctx, clientSet.Discovery(), dynamicClient, namespace.Name)
+		if err != nil {
+			log.Errorf("Can't list the objects left in namespace %s: %s", namespace.Name, err.Error())
+		}
+		for _, object := range objects {
+			log.Warnf("Namespace %s deletion is blocked by %s", namespace.Name, object)
+		}
+
+		if !options.RemoveFinalizers || dryRun || err != nil {
+			continue
+		}
+
+		if err := removeFinalizers(ctx, clientSet, dynamicClient, namespace, objects); err != nil {
+			log.Errorf("Can't remove the finalizers of namespace %s: %s", namespace.Name, err.Error())
+			continue
+		}
+		log.Infof("Finalizers of namespace %s and of its %d remaining objects removed", namespace.Name, len(objects))
+	}
+
+	common.RecordStuckResources("kubernetes", "", "", "namespace", stuck)
+}
+
+// listBlockingObjects returns the objects left in a namespace holding finalizers. Resources which can't be listed,
+// eg. served by an unreachable API service, are skipped.
+func listBlockingObjects(ctx context.Context, discoveryClient discovery.DiscoveryInterface, dynamicClient dynamic.Interface, namespace string) ([]blockingObject, error) {
+	resourceLists, err := discoveryClient.ServerPreferredNamespacedResources()
+	if err != nil && len(resourceLists) == 0 {
+		return nil, err
+	}
+
+	var objects []blockingObject
+	for _, resourceList := range resourceLists {
+		groupVersion, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil {
+			continue
+		}
+
+		for _, apiResource := range resourceList.APIResources {
+			if !contains(apiResource.Verbs, "list") || !contains(apiResource.Verbs, "patch") {
+				continue
+			}
+
+			resource := groupVersion.WithResource(apiResource.Name)
+			listCtx, cancel := common.CallContext(ctx)
+			list, err := dynamicClient.Resource(resource).Namespace(namespace).List(listCtx, metav1.ListOptions{})
+			cancel()
+			if err != nil {
+				log.Debugf("Can't list %s in namespace %s: %s", resource.String(), namespace, err.Error())
+				continue
+			}
+
+			for _, item := range list.Items {
+				if len(item.GetFinalizers()) > 0 {
+					objects = append(objects, blockingObject{
+						resource:   resource,
+						kind:       apiResource.Kind,
+						name:       item.GetName(),
+						finalizers: item.GetFinalizers(),
+					})
+				}
+			}
+		}
+	}
+
+	return objects, nil
+}
+
+func removeFinalizers(ctx context.Context, clientSet *kubernetes.Clientset, dynamicClient dynamic.Interface, namespace v1.Namespace, objects []blockingObject) error {
+	ctx, cancel := common.CallContext(ctx)
+	defer cancel()
+
+	for _, object := range objects {
+		_, err := dynamicClient.Resource(object.resource).Namespace(namespace.Name).Patch(ctx, object.name, types.MergePatchType, removeFinalizersPatch, metav1.PatchOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("can't remove the finalizers of %s: %s", object, err.Error())
+		}
+	}
+
+	if len(namespace.Finalizers) > 0 {
+		_, err := clientSet.CoreV1().Namespaces().Patch(ctx, namespace.Name, types.MergePatchType, removeFinalizersPatch, metav1.PatchOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	if len(namespace.Spec.Finalizers) > 0 {
+		namespace.Spec.Finalizers = nil
+		_, err := clientSet.CoreV1().Namespaces().Finalize(ctx, &namespace, metav1.UpdateOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -1,26 +1,43 @@
 package scaleway
 
 import (
-	log "github.com/sirupsen/logrus"
+	"context"
+
+	"github.com/minio/minio-go/v7"
 
 	"github.com/Qovery/pleco/pkg/common"
 )
 
-func DeleteExpiredBuckets(sessions ScalewaySessions, options ScalewayOptions) {
-	expiredBuckets := common.GetExpiredBuckets(sessions.Bucket, options.TagName, options.Region.String(), options.TagValue, options.DisableTTLCheck)
+// bucketCleaner relies on GetExpiredBuckets which also selects empty buckets older than 4 hours.
+type bucketCleaner struct {
+	scalewayCleaner
+	common.AlwaysEvaluator
+	bucketApi *minio.Client
+}
 
-	count, start := common.ElemToDeleteFormattedInfos("expired bucket", len(expiredBuckets), string(options.Region))
+func init() {
+	registerRegionalCleaner("s3", "bucket", "expired bucket", func(base scalewayCleaner) common.Cleaner {
+		return bucketCleaner{scalewayCleaner: base, bucketApi: CreateMinIOSession(base.client)}
+	})
+}
 
-	log.Info(count)
+func (c bucketCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	expiredBuckets := common.GetExpiredBuckets(c.bucketApi, ctx, c.options.TagName, c.options.Region.String(), c.options.TagValue, c.options.DisableTTLCheck)
 
-	if options.DryRun || len(expiredBuckets) == 0 {
-		return
+	var resources []common.CloudProviderResource
+	for _, expiredBucket := range expiredBuckets {
+		resource := expiredBucket.CloudProviderResource
+		resource.Payload = expiredBucket
+		resources = append(resources, resource)
 	}
 
-	log.Info(start)
+	return resources, nil
+}
 
-	for _, expiredBucket := range expiredBuckets {
-		common.EmptyBucket(sessions.Bucket, expiredBucket.Identifier, expiredBucket.ObjectsInfos)
-		common.DeleteBucket(sessions.Bucket, expiredBucket, options.Zone)
-	}
+func (c bucketCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	expiredBucket := resource.Payload.(common.MinioBucket)
+	common.EmptyBucket(c.bucketApi, ctx, expiredBucket.Identifier, expiredBucket.ObjectsInfos)
+	common.DeleteBucket(c.bucketApi, ctx, expiredBucket, c.options.Region.String())
+
+	return nil
 }
@@ -0,0 +1,75 @@
+package scaleway
+
+import (
+	"github.com/scaleway/scaleway-sdk-go/scw"
+
+	"github.com/Qovery/pleco/pkg/common"
+)
+
+const providerName = "scaleway"
+
+func init() {
+	common.RegisterProvider(common.ProviderDefinition{
+		Name:                  providerName,
+		LocationsFlag:         "scw-zones",
+		LocationsShorthand:    "a",
+		LocationsUsage:        "Set Scaleway zones (default is all)",
+		ExcludedLocationsFlag: "scw-exclude-zones",
+		RequiredEnvVars:       []string{"SCW_ACCESS_KEY", "SCW_SECRET_KEY"},
+		Features: []common.Feature{
+			{Name: "cluster", Shorthand: "e", Usage: "Enable Kubernetes clusters watch"},
+			{Name: "db", Shorthand: "r", Usage: "Enable databases watch"},
+			{Name: "cr", Shorthand: "o", Usage: "Enable containers registries watch"},
+			{Name: "s3", Shorthand: "s", Usage: "Enable buckets watch"},
+			{Name: "lb", Shorthand: "l", Usage: "Enable load balancers watch"},
+			{Name: "volume", Shorthand: "b", Usage: "Enable volumes watch"},
+			{Name: "sg", Shorthand: "g", Usage: "Enable security groups watch"},
+			{Name: "orphan-ip", Usage: "Enable orphan IPs watch"},
+			{Name: "vpc", Shorthand: "p", Usage: "Enable VPC watch"},
+			{Name: "private-network", Shorthand: "n", Usage: "Enable private networks watch"},
+		},
+	})
+
+	common.RegisterDependencies(providerName, "volume", "kapsule-cluster", "rdb-instance")
+	common.RegisterDependencies(providerName, "security-group", "kapsule-cluster")
+	common.RegisterDependencies(providerName, "orphan-ip", "kapsule-cluster")
+	common.RegisterDependencies(providerName, "private-network", "kapsule-cluster", "rdb-instance")
+	common.RegisterDependencies(providerName, "vpc", "private-network")
+}
+
+// scalewayCleaner is embedded by every Scaleway cleaner, it is also the provider scope given to the cleaners
+// constructors. The client is bound to the zone (or the region) the engine runs in.
+type scalewayCleaner struct {
+	common.TTLEvaluator
+	client  *scw.Client
+	options ScalewayOptions
+}
+
+func newScalewayCleaner(client *scw.Client, options ScalewayOptions) scalewayCleaner {
+	return scalewayCleaner{
+		TTLEvaluator: common.TTLEvaluator{TagValue: options.TagValue, DisableTTLCheck: options.DisableTTLCheck},
+		client:       client,
+		options:      options,
+	}
+}
+
+func registerCleaner(scope common.Scope, feature string, kind string, description string, newCleaner func(base scalewayCleaner) common.Cleaner) {
+	common.RegisterCleaner(common.CleanerDefinition{
+		Provider:    providerName,
+		Feature:     feature,
+		Kind:        kind,
+		Description: description,
+		Scope:       scope,
+		New: func(providerScope interface{}) (common.Cleaner, error) {
+			return newCleaner(providerScope.(scalewayCleaner)), nil
+		},
+	})
+}
+
+func registerZonalCleaner(feature string, kind string, description string, newCleaner func(base scalewayCleaner) common.Cleaner) {
+	registerCleaner(common.ZoneScope, feature, kind, description, newCleaner)
+}
+
+func registerRegionalCleaner(feature string, kind string, description string, newCleaner func(base scalewayCleaner) common.Cleaner) {
+	registerCleaner(common.RegionScope, feature, kind, description, newCleaner)
+}
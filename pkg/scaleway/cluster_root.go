@@ -1,9 +1,11 @@
 package scaleway
 
 import (
+	"context"
 	"time"
 
 	"github.com/scaleway/scaleway-sdk-go/api/k8s/v1"
+	"github.com/scaleway/scaleway-sdk-go/scw"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/Qovery/pleco/pkg/common"
@@ -14,27 +16,40 @@ type ScalewayCluster struct {
 	Name string
 }
 
-func DeleteExpiredClusters(sessions ScalewaySessions, options ScalewayOptions) {
-	expiredClusters, _ := getExpiredClusters(sessions.Cluster, &options)
+type clusterCleaner struct {
+	scalewayCleaner
+	clusterAPI *k8s.API
+}
 
-	count, start := common.ElemToDeleteFormattedInfos("expired cluster", len(expiredClusters), options.Zone, true)
+func init() {
+	registerZonalCleaner("cluster", "kapsule-cluster", "expired cluster", func(base scalewayCleaner) common.Cleaner {
+		return clusterCleaner{scalewayCleaner: base, clusterAPI: k8s.NewAPI(base.client)}
+	})
+}
 
-	log.Info(count)
+func (c clusterCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	clusters, _, err := ListClusters(ctx, c.clusterAPI, c.options.TagName)
+	if err != nil {
+		return nil, err
+	}
 
-	if options.DryRun || len(expiredClusters) == 0 {
-		return
+	var resources []common.CloudProviderResource
+	for _, cluster := range clusters {
+		resource := cluster.CloudProviderResource
+		resource.Payload = cluster
+		resources = append(resources, resource)
 	}
 
-	log.Info(start)
+	return resources, nil
+}
 
-	for _, expiredCluster := range expiredClusters {
-		deleteCluster(sessions.Cluster, expiredCluster, options.Zone)
-	}
+func (c clusterCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	return deleteCluster(ctx, c.clusterAPI, resource.Payload.(ScalewayCluster))
 }
 
-func ListClusters(clusterAPI *k8s.API, tagName string) ([]ScalewayCluster, string, error) {
+func ListClusters(ctx context.Context, clusterAPI *k8s.API, tagName string) ([]ScalewayCluster, string, error) {
 	input := &k8s.ListClustersRequest{}
-	result, err := clusterAPI.ListClusters(input)
+	result, err := clusterAPI.ListClusters(input, scw.WithAllPages(), scw.WithContext(ctx))
 
 	if err != nil {
 		log.Errorf("Can't list cluster for region %s: %s", input.Region, err.Error())
@@ -54,6 +69,9 @@ func ListClusters(clusterAPI *k8s.API, tagName string) ([]ScalewayCluster, strin
 				TTL:          essentialTags.TTL,
 				Tag:          essentialTags.Tag,
 				IsProtected:  essentialTags.IsProtected,
+				ExpiresAt:    essentialTags.ExpiresAt,
+				Warnings:     essentialTags.Warnings,
+				Tags:         essentialTags.Tags,
 			},
 			Name: cluster.Name,
 		})
@@ -62,29 +80,12 @@ func ListClusters(clusterAPI *k8s.API, tagName string) ([]ScalewayCluster, strin
 	return clusters, input.Region.String(), nil
 }
 
-func getExpiredClusters(clusterAPI *k8s.API, options *ScalewayOptions) ([]ScalewayCluster, string) {
-	clusters, region, _ := ListClusters(clusterAPI, options.TagName)
-
-	expiredClusters := []ScalewayCluster{}
-	for _, cluster := range clusters {
-		if cluster.IsResourceExpired(options.TagValue, options.DisableTTLCheck) {
-			expiredClusters = append(expiredClusters, cluster)
-		}
-	}
-
-	return expiredClusters, region
-}
-
-func deleteCluster(clusterAPI *k8s.API, cluster ScalewayCluster, region string) {
+func deleteCluster(ctx context.Context, clusterAPI *k8s.API, cluster ScalewayCluster) error {
 	_, err := clusterAPI.DeleteCluster(
 		&k8s.DeleteClusterRequest{
 			ClusterID:               cluster.Identifier,
 			WithAdditionalResources: true,
-		})
+		}, scw.WithContext(ctx))
 
-	if err != nil {
-		log.Errorf("Can't delete cluster %s: %s", cluster.Name, err.Error())
-	} else {
-		log.Debugf("Kapsule cluster %s in %s deleted.", cluster.Name, region)
-	}
+	return err
 }
@@ -86,3 +86,13 @@ func GetRegionfromZone(zone string) string {
 
 	return scwRegion.String()
 }
+
+// DiscoverZones returns the zones known by the Scaleway SDK.
+func DiscoverZones() ([]string, error) {
+	zones := make([]string, 0, len(scw.AllZones))
+	for _, zone := range scw.AllZones {
+		zones = append(zones, zone.String())
+	}
+
+	return zones, nil
+}
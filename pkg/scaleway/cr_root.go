@@ -1,6 +1,7 @@
 package scaleway
 
 import (
+	"context"
 	"strings"
 	"time"
 
@@ -13,9 +14,27 @@ import (
 	"github.com/Qovery/pleco/pkg/common"
 )
 
-func DeleteEmptyContainerRegistries(sessions ScalewaySessions, options ScalewayOptions) {
-	emptyRegistries, _ := getEmptyRegistries(sessions.Namespace, &options)
-	expiredRegirstries, _ := getExpiredRegistries(sessions.Cluster, sessions.Namespace, &options)
+// containerRegistryCleaner selects empty namespaces and namespaces no longer used by a cluster.
+type containerRegistryCleaner struct {
+	scalewayCleaner
+	common.AlwaysEvaluator
+	clusterAPI  *k8s.API
+	registryAPI *registry.API
+}
+
+func init() {
+	registerZonalCleaner("cr", "registry-namespace", "expired Scaleway namespace", func(base scalewayCleaner) common.Cleaner {
+		return containerRegistryCleaner{
+			scalewayCleaner: base,
+			clusterAPI:      k8s.NewAPI(base.client),
+			registryAPI:     registry.NewAPI(base.client),
+		}
+	})
+}
+
+func (c containerRegistryCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	emptyRegistries, _ := getEmptyRegistries(ctx, c.registryAPI, &c.options)
+	expiredRegirstries, _ := getExpiredRegistries(ctx, c.clusterAPI, c.registryAPI, &c.options)
 	deletableRegistriesIds := make(map[string]string)
 
 	for _, emptyRegistry := range emptyRegistries {
@@ -26,24 +45,24 @@ func DeleteEmptyContainerRegistries(sessions ScalewaySessions, options ScalewayO
 		deletableRegistriesIds[expiredRegirstry] = expiredRegirstry
 	}
 
-	count, start := common.ElemToDeleteFormattedInfos("expired Scaleway namespace", len(deletableRegistriesIds), options.Region.String(), false)
-
-	log.Info(count)
-
-	if options.DryRun || len(deletableRegistriesIds) == 0 {
-		return
+	var resources []common.CloudProviderResource
+	for _, deletableRegistryId := range deletableRegistriesIds {
+		resources = append(resources, common.CloudProviderResource{
+			Identifier:  deletableRegistryId,
+			Description: "Namespace: " + deletableRegistryId,
+		})
 	}
 
-	log.Info(start)
+	return resources, nil
+}
 
-	for _, deletableRegistryId := range deletableRegistriesIds {
-		deleteRegistry(sessions.Namespace, deletableRegistryId, options.Region.String())
-	}
+func (c containerRegistryCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	return deleteRegistry(ctx, c.registryAPI, resource.Identifier, c.options.Region.String())
 }
 
-func listRegistries(registryAPI *registry.API) ([]*registry.Namespace, string) {
+func listRegistries(ctx context.Context, registryAPI *registry.API) ([]*registry.Namespace, string) {
 	input := &registry.ListNamespacesRequest{PageSize: scw.Uint32Ptr(500)}
-	result, err := registryAPI.ListNamespaces(input)
+	result, err := registryAPI.ListNamespaces(input, scw.WithAllPages(), scw.WithContext(ctx))
 	if err != nil {
 		log.Errorf("Can't list container registries for region %s: %s", input.Region, err.Error())
 		return []*registry.Namespace{}, input.Region.String()
@@ -52,8 +71,8 @@ func listRegistries(registryAPI *registry.API) ([]*registry.Namespace, string) {
 	return result.Namespaces, input.Region.String()
 }
 
-func getEmptyRegistries(registryAPI *registry.API, options *ScalewayOptions) ([]*registry.Namespace, string) {
-	registries, region := listRegistries(registryAPI)
+func getEmptyRegistries(ctx context.Context, registryAPI *registry.API, options *ScalewayOptions) ([]*registry.Namespace, string) {
+	registries, region := listRegistries(ctx, registryAPI)
 
 	emptyRegistries := []*registry.Namespace{}
 	for _, reg := range registries {
@@ -65,9 +84,9 @@ func getEmptyRegistries(registryAPI *registry.API, options *ScalewayOptions) ([]
 	return emptyRegistries, region
 }
 
-func getExpiredRegistries(clusterAPI *k8s.API, registryAPI *registry.API, options *ScalewayOptions) ([]string, string) {
-	clusters, _, _ := ListClusters(clusterAPI, options.TagName)
-	registries, region := listRegistries(registryAPI)
+func getExpiredRegistries(ctx context.Context, clusterAPI *k8s.API, registryAPI *registry.API, options *ScalewayOptions) ([]string, string) {
+	clusters, _, _ := ListClusters(ctx, clusterAPI, options.TagName)
+	registries, region := listRegistries(ctx, registryAPI)
 
 	checkingRegistries := make(map[string]string)
 	for _, registry := range registries {
@@ -91,17 +110,14 @@ func getExpiredRegistries(clusterAPI *k8s.API, registryAPI *registry.API, option
 	return expiredRegistriesId, region
 }
 
-func deleteRegistry(registryAPI *registry.API, registryId string, region string) {
-	ns, err := registryAPI.DeleteNamespace(
+func deleteRegistry(ctx context.Context, registryAPI *registry.API, registryId string, region string) error {
+	_, err := registryAPI.DeleteNamespace(
 		&registry.DeleteNamespaceRequest{
 			Region:      scw.Region(region),
 			NamespaceID: registryId,
 		},
+		scw.WithContext(ctx),
 	)
 
-	if err != nil {
-		log.Errorf("Can't delete container registry %s in %s: %s", ns.Name, ns.Region, err.Error())
-	} else {
-		log.Debugf("Registry %s in %s deleted.", ns.Name, ns.Region)
-	}
+	return err
 }
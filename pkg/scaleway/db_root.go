@@ -1,9 +1,11 @@
 package scaleway
 
 import (
+	"context"
 	"time"
 
 	"github.com/scaleway/scaleway-sdk-go/api/rdb/v1"
+	"github.com/scaleway/scaleway-sdk-go/scw"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/Qovery/pleco/pkg/common"
@@ -14,40 +16,37 @@ type ScalewayDB struct {
 	Name string
 }
 
-func DeleteExpiredDatabases(sessions ScalewaySessions, options ScalewayOptions) {
-	expiredDatabases, _ := getExpiredDatabases(sessions.Database, &options)
-
-	count, start := common.ElemToDeleteFormattedInfos("expired database", len(expiredDatabases), options.Zone, true)
-
-	log.Info(count)
-
-	if options.DryRun || len(expiredDatabases) == 0 {
-		return
-	}
-
-	log.Info(start)
+type databaseCleaner struct {
+	scalewayCleaner
+	dbAPI *rdb.API
+}
 
-	for _, expiredDb := range expiredDatabases {
-		deleteDB(sessions.Database, expiredDb, options.Zone)
-	}
+func init() {
+	registerZonalCleaner("db", "rdb-instance", "expired database", func(base scalewayCleaner) common.Cleaner {
+		return databaseCleaner{scalewayCleaner: base, dbAPI: rdb.NewAPI(base.client)}
+	})
 }
 
-func getExpiredDatabases(dbAPI *rdb.API, options *ScalewayOptions) ([]ScalewayDB, string) {
-	databases, region := listDatabases(dbAPI, options.TagName)
+func (c databaseCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	databases, _ := listDatabases(ctx, c.dbAPI, c.options.TagName)
 
-	expiredDbs := []ScalewayDB{}
+	var resources []common.CloudProviderResource
 	for _, db := range databases {
-		if db.IsResourceExpired(options.TagValue, options.DisableTTLCheck) {
-			expiredDbs = append(expiredDbs, db)
-		}
+		resource := db.CloudProviderResource
+		resource.Payload = db
+		resources = append(resources, resource)
 	}
 
-	return expiredDbs, region
+	return resources, nil
+}
+
+func (c databaseCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	return deleteDB(ctx, c.dbAPI, resource.Payload.(ScalewayDB))
 }
 
-func listDatabases(dbAPI *rdb.API, tagName string) ([]ScalewayDB, string) {
+func listDatabases(ctx context.Context, dbAPI *rdb.API, tagName string) ([]ScalewayDB, string) {
 	input := &rdb.ListInstancesRequest{}
-	result, err := dbAPI.ListInstances(input)
+	result, err := dbAPI.ListInstances(input, scw.WithAllPages(), scw.WithContext(ctx))
 
 	if err != nil {
 		log.Errorf("Can't list databases for region %s: %s", input.Region, err.Error())
@@ -67,6 +66,9 @@ func listDatabases(dbAPI *rdb.API, tagName string) ([]ScalewayDB, string) {
 				TTL:          essentialTags.TTL,
 				Tag:          essentialTags.Tag,
 				IsProtected:  essentialTags.IsProtected,
+				ExpiresAt:    essentialTags.ExpiresAt,
+				Warnings:     essentialTags.Warnings,
+				Tags:         essentialTags.Tags,
 			},
 			Name: db.Name,
 		})
@@ -75,15 +77,11 @@ func listDatabases(dbAPI *rdb.API, tagName string) ([]ScalewayDB, string) {
 	return databases, input.Region.String()
 }
 
-func deleteDB(dbAPI *rdb.API, db ScalewayDB, region string) {
+func deleteDB(ctx context.Context, dbAPI *rdb.API, db ScalewayDB) error {
 	_, err := dbAPI.DeleteInstance(
 		&rdb.DeleteInstanceRequest{
 			InstanceID: db.Identifier,
-		})
+		}, scw.WithContext(ctx))
 
-	if err != nil {
-		log.Errorf("Can't delete database %s: %s", db.Name, err.Error())
-	} else {
-		log.Debugf("Database %s in %s deleted.", db.Name, region)
-	}
+	return err
 }
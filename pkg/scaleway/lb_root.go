@@ -1,6 +1,7 @@
 package scaleway
 
 import (
+	"context"
 	"strings"
 
 	"github.com/scaleway/scaleway-sdk-go/api/k8s/v1"
@@ -18,22 +19,39 @@ type ScalewayLB struct {
 	PublicIps []string
 }
 
-func DeleteExpiredLBs(sessions ScalewaySessions, options ScalewayOptions) {
-	expiredLBs, region := getExpiredLBs(sessions.Cluster, sessions.LoadBalancer, &options)
+// lbCleaner relies on getExpiredLBs which also selects load balancers not linked to a living cluster.
+type lbCleaner struct {
+	scalewayCleaner
+	common.AlwaysEvaluator
+	clusterAPI *k8s.API
+	lbAPI      *lb.ZonedAPI
+}
 
-	count, start := common.ElemToDeleteFormattedInfos("expired load balancer", len(expiredLBs), region)
+func init() {
+	registerRegionalCleaner("lb", "load-balancer", "expired load balancer", func(base scalewayCleaner) common.Cleaner {
+		return lbCleaner{
+			scalewayCleaner: base,
+			clusterAPI:      k8s.NewAPI(base.client),
+			lbAPI:           lb.NewZonedAPI(base.client),
+		}
+	})
+}
 
-	log.Info(count)
+func (c lbCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	expiredLBs, _ := getExpiredLBs(ctx, c.clusterAPI, c.lbAPI, &c.options)
 
-	if options.DryRun || len(expiredLBs) == 0 {
-		return
+	var resources []common.CloudProviderResource
+	for _, expiredLB := range expiredLBs {
+		resource := expiredLB.CloudProviderResource
+		resource.Payload = expiredLB
+		resources = append(resources, resource)
 	}
 
-	log.Info(start)
+	return resources, nil
+}
 
-	for _, expiredLB := range expiredLBs {
-		deleteLB(sessions.LoadBalancer, scw.Zone(options.Zone), expiredLB)
-	}
+func (c lbCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	return deleteLB(ctx, c.lbAPI, scw.Zone(c.options.Zone), resource.Payload.(ScalewayLB))
 }
 
 func getLbClusterId(tags []string) string {
@@ -67,11 +85,11 @@ func listUnlinkedLoadBalancers(lbs []ScalewayLB, clusters []ScalewayCluster) []S
 	return unlinkedLbs
 }
 
-func listLBs(lbAPI *lb.ZonedAPI, zone scw.Zone, tagName string) ([]ScalewayLB, string) {
+func listLBs(ctx context.Context, lbAPI *lb.ZonedAPI, zone scw.Zone, tagName string) ([]ScalewayLB, string) {
 	input := &lb.ZonedAPIListLBsRequest{
 		Zone: zone,
 	}
-	result, err := lbAPI.ListLBs(input)
+	result, err := lbAPI.ListLBs(input, scw.WithAllPages(), scw.WithContext(ctx))
 	if err != nil {
 		log.Errorf("Can't list load balancers in zone %s: %s", input.Zone.String(), err.Error())
 		return []ScalewayLB{}, input.Zone.String()
@@ -92,6 +110,9 @@ func listLBs(lbAPI *lb.ZonedAPI, zone scw.Zone, tagName string) ([]ScalewayLB, s
 				TTL:          essentialTags.TTL,
 				Tag:          essentialTags.Tag,
 				IsProtected:  essentialTags.IsProtected,
+				ExpiresAt:    essentialTags.ExpiresAt,
+				Warnings:     essentialTags.Warnings,
+				Tags:         essentialTags.Tags,
 			},
 			Name:      lb.Name,
 			ClusterId: getLbClusterId(lb.Tags),
@@ -102,9 +123,9 @@ func listLBs(lbAPI *lb.ZonedAPI, zone scw.Zone, tagName string) ([]ScalewayLB, s
 	return loadBalancers, input.Zone.String()
 }
 
-func getExpiredLBs(clusterAPI *k8s.API, lbAPI *lb.ZonedAPI, options *ScalewayOptions) ([]ScalewayLB, string) {
-	lbs, _ := listLBs(lbAPI, scw.Zone(options.Zone), options.TagName)
-	clusters, _, err := ListClusters(clusterAPI, options.TagName)
+func getExpiredLBs(ctx context.Context, clusterAPI *k8s.API, lbAPI *lb.ZonedAPI, options *ScalewayOptions) ([]ScalewayLB, string) {
+	lbs, _ := listLBs(ctx, lbAPI, scw.Zone(options.Zone), options.TagName)
+	clusters, _, err := ListClusters(ctx, clusterAPI, options.TagName)
 
 	// Early return to avoid side effect in listUnlinkedLoadBalancers methods: as no clusters would be fetched,
 	// the load balancers listed will be considered as unlinked therefore pleco will clean them
@@ -125,18 +146,19 @@ func getExpiredLBs(clusterAPI *k8s.API, lbAPI *lb.ZonedAPI, options *ScalewayOpt
 	return expiredLBs, options.Region.String()
 }
 
-func deleteLB(lbAPI *lb.ZonedAPI, zone scw.Zone, loadBalancer ScalewayLB) {
+func deleteLB(ctx context.Context, lbAPI *lb.ZonedAPI, zone scw.Zone, loadBalancer ScalewayLB) error {
 	err := lbAPI.DeleteLB(
 		&lb.ZonedAPIDeleteLBRequest{
 			LBID:      loadBalancer.Identifier,
 			Zone:      zone,
 			ReleaseIP: true,
 		},
+		scw.WithContext(ctx),
 	)
-
 	if err != nil {
-		log.Errorf("Can't delete load balancer %s: %s", loadBalancer.Name, err.Error())
-	} else {
-		log.Debugf("Load balancer %s: %v in %s deleted.", loadBalancer.Name, loadBalancer.PublicIps, zone)
+		return err
 	}
+
+	log.Debugf("Load balancer %s: %v in %s deleted.", loadBalancer.Name, loadBalancer.PublicIps, zone)
+	return nil
 }
@@ -1,8 +1,11 @@
 package scaleway
 
 import (
+	"context"
+
 	"github.com/Qovery/pleco/pkg/common"
 	"github.com/scaleway/scaleway-sdk-go/api/vpc/v2"
+	"github.com/scaleway/scaleway-sdk-go/scw"
 	log "github.com/sirupsen/logrus"
 	"time"
 )
@@ -12,31 +15,41 @@ type ScalewayPrivateNetwork struct {
 	common.CloudProviderResource
 }
 
-func DeleteExpiredPrivateNetworks(sessions ScalewaySessions, options ScalewayOptions) {
-	expiredPrivateNetworks, _ := getExpiredPrivateNetworks(sessions.Network, options)
-
-	count, start := common.ElemToDeleteFormattedInfos("expired private network", len(expiredPrivateNetworks), options.Zone, true)
+type privateNetworkCleaner struct {
+	scalewayCleaner
+	common.AlwaysEvaluator
+	vpcAPI *vpc.API
+}
 
-	log.Info(count)
+func init() {
+	registerZonalCleaner("private-network", "private-network", "expired private network", func(base scalewayCleaner) common.Cleaner {
+		return privateNetworkCleaner{scalewayCleaner: base, vpcAPI: vpc.NewAPI(base.client)}
+	})
+}
 
-	if options.DryRun || len(expiredPrivateNetworks) == 0 {
-		return
+func (c privateNetworkCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	expiredPrivateNetworks, err := getExpiredPrivateNetworks(ctx, c.vpcAPI, c.options)
+	if err != nil {
+		return nil, err
 	}
 
-	log.Info(start)
-
+	var resources []common.CloudProviderResource
 	for _, expiredPrivateNetwork := range expiredPrivateNetworks {
-		log.Info("Deleting private network: ", expiredPrivateNetwork.Name)
-		if err := sessions.Network.DeletePrivateNetwork(&vpc.DeletePrivateNetworkRequest{
-			Region:           options.Region,
-			PrivateNetworkID: expiredPrivateNetwork.Identifier,
-		}); err != nil {
-			log.Errorf("Error deleting private network %s: %v", expiredPrivateNetwork.Name, err)
-		}
+		resources = append(resources, expiredPrivateNetwork.CloudProviderResource)
 	}
+
+	return resources, nil
+}
+
+func (c privateNetworkCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	log.Info("Deleting private network: ", resource.Description)
+	return c.vpcAPI.DeletePrivateNetwork(&vpc.DeletePrivateNetworkRequest{
+		Region:           c.options.Region,
+		PrivateNetworkID: resource.Identifier,
+	}, scw.WithContext(ctx))
 }
 
-func getExpiredPrivateNetworks(vpcAPI *vpc.API, options ScalewayOptions) ([]ScalewayPrivateNetwork, error) {
+func getExpiredPrivateNetworks(ctx context.Context, vpcAPI *vpc.API, options ScalewayOptions) ([]ScalewayPrivateNetwork, error) {
 	// List all IPs
 	expiredPrivateNetworks := make([]ScalewayPrivateNetwork, 0)
 	var page int32 = 1
@@ -47,7 +60,7 @@ func getExpiredPrivateNetworks(vpcAPI *vpc.API, options ScalewayOptions) ([]Scal
 			Page:     &page,
 			PageSize: &itemsPerPage,
 			Region:   options.Region,
-		})
+		}, scw.WithContext(ctx))
 		if err != nil {
 			log.Fatalf("Error listing private networks: %v", err)
 			return nil, err
@@ -65,6 +78,9 @@ func getExpiredPrivateNetworks(vpcAPI *vpc.API, options ScalewayOptions) ([]Scal
 					TTL:          essentialTags.TTL,
 					Tag:          essentialTags.Tag,
 					IsProtected:  essentialTags.IsProtected,
+					ExpiresAt:    essentialTags.ExpiresAt,
+					Warnings:     essentialTags.Warnings,
+					Tags:         essentialTags.Tags,
 				},
 				Name: privateNetwork.Name,
 			}
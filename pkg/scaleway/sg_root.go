@@ -1,9 +1,11 @@
 package scaleway
 
 import (
+	"context"
 	"time"
 
 	"github.com/scaleway/scaleway-sdk-go/api/instance/v1"
+	"github.com/scaleway/scaleway-sdk-go/scw"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/Qovery/pleco/pkg/common"
@@ -19,27 +21,47 @@ type ScalewaySecurityGroup struct {
 	IsProtected bool
 }
 
-func DeleteDetachedSecurityGroups(sessions ScalewaySessions, options ScalewayOptions) {
-	detachedSGs, _ := getDetachedSG(sessions.SG, &options)
+type securityGroupCleaner struct {
+	scalewayCleaner
+	common.AlwaysEvaluator
+	instanceAPI *instance.API
+}
 
-	count, start := common.ElemToDeleteFormattedInfos("detached security group", len(detachedSGs), options.Zone, true)
+func init() {
+	registerZonalCleaner("sg", "security-group", "detached security group", func(base scalewayCleaner) common.Cleaner {
+		return securityGroupCleaner{scalewayCleaner: base, instanceAPI: instance.NewAPI(base.client)}
+	})
+}
 
-	log.Info(count)
+func (c securityGroupCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	detachedSGs, _ := getDetachedSG(ctx, c.instanceAPI, &c.options)
 
-	if options.DryRun || len(detachedSGs) == 0 {
-		return
+	var resources []common.CloudProviderResource
+	for _, detachedSG := range detachedSGs {
+		resources = append(resources, common.CloudProviderResource{
+			Identifier:   detachedSG.ID,
+			Description:  "Security group: " + detachedSG.Name,
+			CreationDate: detachedSG.UpdateDate,
+			TTL:          detachedSG.TTL,
+			IsProtected:  detachedSG.IsProtected,
+		})
 	}
 
-	log.Info(start)
+	return resources, nil
+}
 
-	for _, detachedSG := range detachedSGs {
-		deleteSG(sessions.SG, detachedSG, options.Zone)
-	}
+func (c securityGroupCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	return c.instanceAPI.DeleteSecurityGroup(
+		&instance.DeleteSecurityGroupRequest{
+			SecurityGroupID: resource.Identifier,
+		},
+		scw.WithContext(ctx),
+	)
 }
 
-func listSecurityGroups(instanceAPI *instance.API) ([]ScalewaySecurityGroup, string) {
+func listSecurityGroups(ctx context.Context, instanceAPI *instance.API) ([]ScalewaySecurityGroup, string) {
 	input := &instance.ListSecurityGroupsRequest{}
-	result, err := instanceAPI.ListSecurityGroups(input)
+	result, err := instanceAPI.ListSecurityGroups(input, scw.WithAllPages(), scw.WithContext(ctx))
 	region := GetRegionfromZone(input.Zone.String())
 
 	if err != nil {
@@ -65,8 +87,8 @@ func listSecurityGroups(instanceAPI *instance.API) ([]ScalewaySecurityGroup, str
 	return SGs, region
 }
 
-func getDetachedSG(instanceAPI *instance.API, options *ScalewayOptions) ([]ScalewaySecurityGroup, string) {
-	SGs, region := listSecurityGroups(instanceAPI)
+func getDetachedSG(ctx context.Context, instanceAPI *instance.API, options *ScalewayOptions) ([]ScalewaySecurityGroup, string) {
+	SGs, region := listSecurityGroups(ctx, instanceAPI)
 
 	detachedSgs := []ScalewaySecurityGroup{}
 	for _, SG := range SGs {
@@ -78,17 +100,3 @@ func getDetachedSG(instanceAPI *instance.API, options *ScalewayOptions) ([]Scale
 
 	return detachedSgs, region
 }
-
-func deleteSG(instanceAPI *instance.API, securityGroup ScalewaySecurityGroup, region string) {
-	err := instanceAPI.DeleteSecurityGroup(
-		&instance.DeleteSecurityGroupRequest{
-			SecurityGroupID: securityGroup.ID,
-		},
-	)
-
-	if err != nil {
-		log.Errorf("Can't delete security group %s: %s", securityGroup.Name, err.Error())
-	} else {
-		log.Debugf("Database %s in %s deleted.", securityGroup.Name, region)
-	}
-}
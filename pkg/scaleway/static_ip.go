@@ -1,6 +1,8 @@
 package scaleway
 
 import (
+	"context"
+
 	"github.com/Qovery/pleco/pkg/common"
 	"github.com/scaleway/scaleway-sdk-go/api/lb/v1"
 	"github.com/scaleway/scaleway-sdk-go/scw"
@@ -12,31 +14,44 @@ type ScalewayIP struct {
 	Address string
 }
 
-func DeleteOrphanIPAddresses(sessions ScalewaySessions, options ScalewayOptions) {
-	orphanIPs, _ := getOrphanIPAddresses(sessions.LoadBalancer, options)
-
-	count, start := common.ElemToDeleteFormattedInfos("orphan IP address", len(orphanIPs), options.Zone, true)
+type orphanIPCleaner struct {
+	scalewayCleaner
+	common.AlwaysEvaluator
+	lbAPI *lb.ZonedAPI
+}
 
-	log.Info(count)
+func init() {
+	registerZonalCleaner("orphan-ip", "orphan-ip", "orphan IP address", func(base scalewayCleaner) common.Cleaner {
+		return orphanIPCleaner{scalewayCleaner: base, lbAPI: lb.NewZonedAPI(base.client)}
+	})
+}
 
-	if options.DryRun || len(orphanIPs) == 0 {
-		return
+func (c orphanIPCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	orphanIPs, err := getOrphanIPAddresses(ctx, c.lbAPI, c.options)
+	if err != nil {
+		return nil, err
 	}
 
-	log.Info(start)
-
+	var resources []common.CloudProviderResource
 	for _, orphanIP := range orphanIPs {
-		log.Info("Deleting orphan IP address: ", orphanIP.Address)
-		if err := sessions.LoadBalancer.ReleaseIP(&lb.ZonedAPIReleaseIPRequest{
-			Zone: scw.Zone(options.Zone),
-			IPID: orphanIP.ID,
-		}); err != nil {
-			log.Errorf("Error deleting IP %s: %v", orphanIP.Address, err)
-		}
+		resources = append(resources, common.CloudProviderResource{
+			Identifier:  orphanIP.ID,
+			Description: "IP address: " + orphanIP.Address,
+		})
 	}
+
+	return resources, nil
 }
 
-func getOrphanIPAddresses(lbAPI *lb.ZonedAPI, options ScalewayOptions) ([]ScalewayIP, error) {
+func (c orphanIPCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	log.Info("Deleting orphan IP address: ", resource.Description)
+	return c.lbAPI.ReleaseIP(&lb.ZonedAPIReleaseIPRequest{
+		Zone: scw.Zone(c.options.Zone),
+		IPID: resource.Identifier,
+	}, scw.WithContext(ctx))
+}
+
+func getOrphanIPAddresses(ctx context.Context, lbAPI *lb.ZonedAPI, options ScalewayOptions) ([]ScalewayIP, error) {
 	// List all IPs
 	orphanIPs := make([]ScalewayIP, 0)
 	var page int32 = 1
@@ -47,7 +62,7 @@ func getOrphanIPAddresses(lbAPI *lb.ZonedAPI, options ScalewayOptions) ([]Scalew
 			Page:     &page,
 			PageSize: &itemsPerPage,
 			Zone:     scw.Zone(options.Zone),
-		})
+		}, scw.WithContext(ctx))
 		if err != nil {
 			log.Fatalf("Error listing IPs: %v", err)
 			return nil, err
@@ -0,0 +1,35 @@
+package scaleway
+
+import (
+	"context"
+
+	"github.com/scaleway/scaleway-sdk-go/api/k8s/v1"
+	"github.com/scaleway/scaleway-sdk-go/api/rdb/v1"
+	"github.com/scaleway/scaleway-sdk-go/scw"
+
+	"github.com/Qovery/pleco/pkg/common"
+)
+
+func (c clusterCleaner) SetTags(ctx context.Context, resource common.CloudProviderResource, tags map[string]string) error {
+	cluster, err := c.clusterAPI.GetCluster(&k8s.GetClusterRequest{ClusterID: resource.Identifier}, scw.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	clusterTags := common.TagList(cluster.Tags, tags, "=")
+	_, err = c.clusterAPI.UpdateCluster(&k8s.UpdateClusterRequest{ClusterID: resource.Identifier, Tags: &clusterTags}, scw.WithContext(ctx))
+
+	return err
+}
+
+func (c databaseCleaner) SetTags(ctx context.Context, resource common.CloudProviderResource, tags map[string]string) error {
+	db, err := c.dbAPI.GetInstance(&rdb.GetInstanceRequest{InstanceID: resource.Identifier}, scw.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	dbTags := common.TagList(db.Tags, tags, "=")
+	_, err = c.dbAPI.UpdateInstance(&rdb.UpdateInstanceRequest{InstanceID: resource.Identifier, Tags: &dbTags}, scw.WithContext(ctx))
+
+	return err
+}
@@ -1,10 +1,12 @@
 package scaleway
 
 import (
+	"context"
 	"fmt"
 	"time"
 
 	"github.com/scaleway/scaleway-sdk-go/api/instance/v1"
+	"github.com/scaleway/scaleway-sdk-go/scw"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/Qovery/pleco/pkg/common"
@@ -17,27 +19,45 @@ type ScalewayVolume struct {
 	ServerId  string
 }
 
-func DeleteExpiredVolumes(sessions ScalewaySessions, options ScalewayOptions) {
-	expiredVolumes := getDetachedVolumes(sessions.Instance, &options)
+type volumeCleaner struct {
+	scalewayCleaner
+	common.AlwaysEvaluator
+	instanceAPI *instance.API
+}
 
-	count, start := common.ElemToDeleteFormattedInfos(fmt.Sprintf("detached (%d hours delay) volume", volumeTimeout()), len(expiredVolumes), options.Zone, true)
+func init() {
+	registerZonalCleaner("volume", "volume", fmt.Sprintf("detached (%d hours delay) volume", volumeTimeout()), func(base scalewayCleaner) common.Cleaner {
+		return volumeCleaner{scalewayCleaner: base, instanceAPI: instance.NewAPI(base.client)}
+	})
+}
 
-	log.Info(count)
+func (c volumeCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	detachedVolumes := getDetachedVolumes(ctx, c.instanceAPI, &c.options)
 
-	if options.DryRun || len(expiredVolumes) == 0 {
-		return
+	var resources []common.CloudProviderResource
+	for _, detachedVolume := range detachedVolumes {
+		resources = append(resources, common.CloudProviderResource{
+			Identifier:   detachedVolume.ID,
+			Description:  "Detached volume: " + detachedVolume.Name,
+			CreationDate: detachedVolume.UpdatedAt,
+		})
 	}
 
-	log.Info(start)
+	return resources, nil
+}
 
-	for _, expiredVolume := range expiredVolumes {
-		deleteVolume(sessions.Instance, expiredVolume, options.Zone)
-	}
+func (c volumeCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	return c.instanceAPI.DeleteVolume(
+		&instance.DeleteVolumeRequest{
+			VolumeID: resource.Identifier,
+		},
+		scw.WithContext(ctx),
+	)
 }
 
-func getVolumes(volumeAPI *instance.API, zone string) []ScalewayVolume {
+func getVolumes(ctx context.Context, volumeAPI *instance.API, zone string) []ScalewayVolume {
 	input := &instance.ListVolumesRequest{}
-	result, err := volumeAPI.ListVolumes(input)
+	result, err := volumeAPI.ListVolumes(input, scw.WithAllPages(), scw.WithContext(ctx))
 	if err != nil {
 		log.Errorf("Can't list volumes in zone %s: %s", zone, err.Error())
 		return []ScalewayVolume{}
@@ -63,8 +83,8 @@ func getVolumes(volumeAPI *instance.API, zone string) []ScalewayVolume {
 	return volumes
 }
 
-func getDetachedVolumes(volumeAPI *instance.API, options *ScalewayOptions) []ScalewayVolume {
-	volumes := getVolumes(volumeAPI, options.Zone)
+func getDetachedVolumes(ctx context.Context, volumeAPI *instance.API, options *ScalewayOptions) []ScalewayVolume {
+	volumes := getVolumes(ctx, volumeAPI, options.Zone)
 
 	detachedVolumes := []ScalewayVolume{}
 	for _, volume := range volumes {
@@ -76,17 +96,3 @@ func getDetachedVolumes(volumeAPI *instance.API, options *ScalewayOptions) []Sca
 
 	return detachedVolumes
 }
-
-func deleteVolume(volumeAPI *instance.API, detachedVolume ScalewayVolume, region string) {
-	err := volumeAPI.DeleteVolume(
-		&instance.DeleteVolumeRequest{
-			VolumeID: detachedVolume.ID,
-		},
-	)
-
-	if err != nil {
-		log.Errorf("Can't delete detached volume %s: %s", detachedVolume.Name, err.Error())
-	} else {
-		log.Debugf("Detached volume %s in %s deleted.", detachedVolume.Name, region)
-	}
-}
@@ -1,8 +1,11 @@
 package scaleway
 
 import (
+	"context"
+
 	"github.com/Qovery/pleco/pkg/common"
 	"github.com/scaleway/scaleway-sdk-go/api/vpc/v2"
+	"github.com/scaleway/scaleway-sdk-go/scw"
 	log "github.com/sirupsen/logrus"
 	"time"
 )
@@ -12,31 +15,41 @@ type ScalewayVPC struct {
 	common.CloudProviderResource
 }
 
-func DeleteExpiredVPCs(sessions ScalewaySessions, options ScalewayOptions) {
-	expiredVPCs, _ := getExpiredVPCs(sessions.Network, options)
-
-	count, start := common.ElemToDeleteFormattedInfos("expired VPC", len(expiredVPCs), options.Zone, true)
+type vpcCleaner struct {
+	scalewayCleaner
+	common.AlwaysEvaluator
+	vpcAPI *vpc.API
+}
 
-	log.Info(count)
+func init() {
+	registerZonalCleaner("vpc", "vpc", "expired VPC", func(base scalewayCleaner) common.Cleaner {
+		return vpcCleaner{scalewayCleaner: base, vpcAPI: vpc.NewAPI(base.client)}
+	})
+}
 
-	if options.DryRun || len(expiredVPCs) == 0 {
-		return
+func (c vpcCleaner) List(ctx context.Context) ([]common.CloudProviderResource, error) {
+	expiredVPCs, err := getExpiredVPCs(ctx, c.vpcAPI, c.options)
+	if err != nil {
+		return nil, err
 	}
 
-	log.Info(start)
-
+	var resources []common.CloudProviderResource
 	for _, expiredVPC := range expiredVPCs {
-		log.Info("Deleting VPC: ", expiredVPC.Name)
-		if err := sessions.Network.DeleteVPC(&vpc.DeleteVPCRequest{
-			Region: options.Region,
-			VpcID:  expiredVPC.Identifier,
-		}); err != nil {
-			log.Errorf("Error deleting VPC %s: %v", expiredVPC.Name, err)
-		}
+		resources = append(resources, expiredVPC.CloudProviderResource)
 	}
+
+	return resources, nil
+}
+
+func (c vpcCleaner) Delete(ctx context.Context, resource common.CloudProviderResource) error {
+	log.Info("Deleting VPC: ", resource.Description)
+	return c.vpcAPI.DeleteVPC(&vpc.DeleteVPCRequest{
+		Region: c.options.Region,
+		VpcID:  resource.Identifier,
+	}, scw.WithContext(ctx))
 }
 
-func getExpiredVPCs(vpcAPI *vpc.API, options ScalewayOptions) ([]ScalewayVPC, error) {
+func getExpiredVPCs(ctx context.Context, vpcAPI *vpc.API, options ScalewayOptions) ([]ScalewayVPC, error) {
 	// List all IPs
 	expiredVPCs := make([]ScalewayVPC, 0)
 	var page int32 = 1
@@ -47,7 +60,7 @@ func getExpiredVPCs(vpcAPI *vpc.API, options ScalewayOptions) ([]ScalewayVPC, er
 			Page:     &page,
 			PageSize: &itemsPerPage,
 			Region:   options.Region,
-		})
+		}, scw.WithContext(ctx))
 		if err != nil {
 			log.Fatalf("Error listing VPCs: %v", err)
 			return nil, err
@@ -65,6 +78,9 @@ func getExpiredVPCs(vpcAPI *vpc.API, options ScalewayOptions) ([]ScalewayVPC, er
 					TTL:          essentialTags.TTL,
 					Tag:          essentialTags.Tag,
 					IsProtected:  essentialTags.IsProtected,
+					ExpiresAt:    essentialTags.ExpiresAt,
+					Warnings:     essentialTags.Warnings,
+					Tags:         essentialTags.Tags,
 				},
 				Name: vpcItem.Name,
 			}